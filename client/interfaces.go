@@ -5,6 +5,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/sftp"
@@ -213,6 +214,7 @@ type InstanceServer interface {
 
 	GetInstanceState(name string) (state *api.InstanceState, ETag string, err error)
 	UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (op Operation, err error)
+	GetInstanceStateHistory(name string, since time.Time) (history *api.InstanceStateHistory, err error)
 
 	GetInstanceLogfiles(name string) (logfiles []string, err error)
 	GetInstanceLogfile(name string, filename string) (content io.ReadCloser, err error)
@@ -226,6 +228,15 @@ type InstanceServer interface {
 	CreateInstanceTemplateFile(instanceName string, templateName string, content io.ReadSeeker) (err error)
 	DeleteInstanceTemplateFile(name string, templateName string) (err error)
 
+	// Instance template functions ("instance_templates" API extension)
+	GetInstanceTemplateNames() (names []string, err error)
+	GetInstanceTemplates() (templates []api.InstanceTemplate, err error)
+	GetInstanceTemplate(name string) (template *api.InstanceTemplate, ETag string, err error)
+	CreateInstanceTemplate(template api.InstanceTemplatesPost) (err error)
+	UpdateInstanceTemplate(name string, template api.InstanceTemplatePut, ETag string) (err error)
+	RenameInstanceTemplate(name string, template api.InstanceTemplatePost) (err error)
+	DeleteInstanceTemplate(name string) (err error)
+
 	// Event handling functions
 	GetEvents() (listener *EventListener, err error)
 	GetEventsAllProjects() (listener *EventListener, err error)
@@ -262,6 +273,15 @@ type InstanceServer interface {
 	UpdateNetworkForward(networkName string, listenAddress string, forward api.NetworkForwardPut, ETag string) (err error)
 	DeleteNetworkForward(networkName string, listenAddress string) (err error)
 
+	// Network ingress functions ("network_ingress" API extension)
+	GetNetworkIngressHostnames(networkName string) ([]string, error)
+	GetNetworkIngresses(networkName string) ([]api.NetworkIngress, error)
+	GetNetworkIngress(networkName string, hostname string) (ingress *api.NetworkIngress, ETag string, err error)
+	GetNetworkIngressState(networkName string, hostname string) (state *api.NetworkIngressState, err error)
+	CreateNetworkIngress(networkName string, ingress api.NetworkIngressesPost) error
+	UpdateNetworkIngress(networkName string, hostname string, ingress api.NetworkIngressPut, ETag string) (err error)
+	DeleteNetworkIngress(networkName string, hostname string) (err error)
+
 	// Network load balancer functions ("network_load_balancer" API extension)
 	GetNetworkLoadBalancerAddresses(networkName string) ([]string, error)
 	GetNetworkLoadBalancers(networkName string) ([]api.NetworkLoadBalancer, error)
@@ -358,6 +378,16 @@ type InstanceServer interface {
 	UpdateStoragePoolBucketKey(poolName string, bucketName string, keyName string, key api.StorageBucketKeyPut, ETag string) (err error)
 	DeleteStoragePoolBucketKey(poolName string, bucketName string, keyName string) (err error)
 
+	// Storage bucket backup functions ("storage_bucket_backup" API extension)
+	GetStoragePoolBucketBackupNames(poolName string, bucketName string) (names []string, err error)
+	GetStoragePoolBucketBackups(poolName string, bucketName string) (backups []api.StorageBucketBackup, err error)
+	GetStoragePoolBucketBackup(poolName string, bucketName string, name string) (backup *api.StorageBucketBackup, ETag string, err error)
+	CreateStoragePoolBucketBackup(poolName string, bucketName string, backup api.StorageBucketBackupsPost) (op Operation, err error)
+	RenameStoragePoolBucketBackup(poolName string, bucketName string, name string, backup api.StorageBucketBackupPost) (op Operation, err error)
+	DeleteStoragePoolBucketBackup(poolName string, bucketName string, name string) (op Operation, err error)
+	GetStoragePoolBucketBackupFile(poolName string, bucketName string, name string, req *BackupFileRequest) (resp *BackupFileResponse, err error)
+	CreateStoragePoolBucketFromBackup(poolName string, args StoragePoolBucketBackupArgs) (op Operation, err error)
+
 	// List all volumes functions ("storage_volumes_all" API extension)
 	GetVolumesWithFilter(filters []string) (volumes []api.StorageVolume, err error)
 	GetVolumesWithFilterAllProjects(filters []string) (volumes []api.StorageVolume, err error)
@@ -414,6 +444,7 @@ type InstanceServer interface {
 	UpdateClusterCertificate(certs api.ClusterCertificatePut, ETag string) (err error)
 	GetClusterMemberState(name string) (*api.ClusterMemberState, string, error)
 	UpdateClusterMemberState(name string, state api.ClusterMemberStatePost) (op Operation, err error)
+	GetClusterDevices() (devices []api.ClusterDevice, err error)
 	GetClusterGroups() ([]api.ClusterGroup, error)
 	GetClusterGroupNames() ([]string, error)
 	RenameClusterGroup(name string, group api.ClusterGroupPost) error
@@ -603,6 +634,16 @@ type StoragePoolVolumeBackupArgs struct {
 	Name string
 }
 
+// The StoragePoolBucketBackupArgs struct is used when creating a storage bucket from a backup.
+// API extension: storage_bucket_backup.
+type StoragePoolBucketBackupArgs struct {
+	// The backup file
+	BackupFile io.Reader
+
+	// Name to import backup as
+	Name string
+}
+
 // The InstanceBackupArgs struct is used when creating a instance from a backup.
 type InstanceBackupArgs struct {
 	// The backup file
@@ -638,6 +679,10 @@ type InstanceCopyArgs struct {
 
 	// API extension: instance_allow_inconsistent_copy
 	AllowInconsistent bool
+
+	// API extension: instance_fork
+	// If set, the running virtual machine is forked into a clone sharing its memory
+	Fork bool
 }
 
 // The InstanceSnapshotCopyArgs struct is used to pass additional options during instance copy.
@@ -218,6 +218,22 @@ func (r *ProtocolLXD) UpdateClusterMemberState(name string, state api.ClusterMem
 	return op, nil
 }
 
+// GetClusterDevices returns the devices of the cluster members that can be passed through to instances.
+func (r *ProtocolLXD) GetClusterDevices() ([]api.ClusterDevice, error) {
+	err := r.CheckExtension("cluster_device_inventory")
+	if err != nil {
+		return nil, err
+	}
+
+	devices := []api.ClusterDevice{}
+	_, err = r.queryStruct("GET", api.NewURL().Path("cluster", "devices").String(), nil, "", &devices)
+	if err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
+
 // GetClusterGroups returns the cluster groups.
 func (r *ProtocolLXD) GetClusterGroups() ([]api.ClusterGroup, error) {
 	err := r.CheckExtension("clustering_groups")
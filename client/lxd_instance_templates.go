@@ -0,0 +1,129 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Instance template handling functions
+
+// GetInstanceTemplateNames returns a list of instance template names.
+func (r *ProtocolLXD) GetInstanceTemplateNames() ([]string, error) {
+	err := r.CheckExtension("instance_templates")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/instance-templates"
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetInstanceTemplates returns a list of instance template structs.
+func (r *ProtocolLXD) GetInstanceTemplates() ([]api.InstanceTemplate, error) {
+	err := r.CheckExtension("instance_templates")
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []api.InstanceTemplate{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", "/instance-templates?recursion=1", nil, "", &templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// GetInstanceTemplate returns an instance template entry for the provided name.
+func (r *ProtocolLXD) GetInstanceTemplate(name string) (*api.InstanceTemplate, string, error) {
+	err := r.CheckExtension("instance_templates")
+	if err != nil {
+		return nil, "", err
+	}
+
+	template := api.InstanceTemplate{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/instance-templates/%s", url.PathEscape(name)), nil, "", &template)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &template, etag, nil
+}
+
+// CreateInstanceTemplate defines a new instance template.
+func (r *ProtocolLXD) CreateInstanceTemplate(template api.InstanceTemplatesPost) error {
+	err := r.CheckExtension("instance_templates")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", "/instance-templates", template, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateInstanceTemplate updates the instance template to match the provided struct.
+func (r *ProtocolLXD) UpdateInstanceTemplate(name string, template api.InstanceTemplatePut, ETag string) error {
+	err := r.CheckExtension("instance_templates")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("PUT", fmt.Sprintf("/instance-templates/%s", url.PathEscape(name)), template, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenameInstanceTemplate renames an existing instance template entry.
+func (r *ProtocolLXD) RenameInstanceTemplate(name string, template api.InstanceTemplatePost) error {
+	err := r.CheckExtension("instance_templates")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", fmt.Sprintf("/instance-templates/%s", url.PathEscape(name)), template, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteInstanceTemplate deletes an instance template.
+func (r *ProtocolLXD) DeleteInstanceTemplate(name string) error {
+	err := r.CheckExtension("instance_templates")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", fmt.Sprintf("/instance-templates/%s", url.PathEscape(name)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/sftp"
@@ -700,6 +701,13 @@ func (r *ProtocolLXD) CreateInstance(instance api.InstancesPost) (Operation, err
 		}
 	}
 
+	if instance.Template != "" {
+		err := r.CheckExtension("instance_templates")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", path, instance, "", true)
 	if err != nil {
@@ -860,6 +868,12 @@ func (r *ProtocolLXD) CopyInstance(source InstanceServer, instance api.Instance,
 			}
 		}
 
+		if args.Fork {
+			if !r.HasExtension("instance_fork") {
+				return nil, fmt.Errorf("The target server is missing the required \"instance_fork\" API extension")
+			}
+		}
+
 		// Allow overriding the target name
 		if args.Name != "" {
 			req.Name = args.Name
@@ -870,6 +884,7 @@ func (r *ProtocolLXD) CopyInstance(source InstanceServer, instance api.Instance,
 		req.Source.ContainerOnly = args.InstanceOnly // For legacy servers.
 		req.Source.Refresh = args.Refresh
 		req.Source.AllowInconsistent = args.AllowInconsistent
+		req.Source.Fork = args.Fork
 	}
 
 	if req.Source.Live {
@@ -922,6 +937,11 @@ func (r *ProtocolLXD) CopyInstance(source InstanceServer, instance api.Instance,
 		return &rop, nil
 	}
 
+	// Forking requires the clone to be on the same server as the source.
+	if req.Source.Fork {
+		return nil, fmt.Errorf("Forking is only possible within the same server")
+	}
+
 	// Source request
 	sourceReq := api.InstancePost{
 		Migration:         true,
@@ -2250,6 +2270,34 @@ func (r *ProtocolLXD) UpdateInstanceState(name string, state api.InstanceStatePu
 	return op, nil
 }
 
+// GetInstanceStateHistory returns the resource usage history of the instance.
+// Only the samples ending after since are returned, unless since is the zero time.
+func (r *ProtocolLXD) GetInstanceStateHistory(name string, since time.Time) (*api.InstanceStateHistory, error) {
+	err := r.CheckExtension("instance_state_history")
+	if err != nil {
+		return nil, err
+	}
+
+	path, v, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	if !since.IsZero() {
+		v.Set("since", since.Format(time.RFC3339))
+	}
+
+	history := api.InstanceStateHistory{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/state/history?%s", path, url.PathEscape(name), v.Encode()), nil, "", &history)
+	if err != nil {
+		return nil, err
+	}
+
+	return &history, nil
+}
+
 // GetInstanceLogfiles returns a list of logfiles for the instance.
 func (r *ProtocolLXD) GetInstanceLogfiles(name string) ([]string, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2574,6 +2622,13 @@ func (r *ProtocolLXD) ConsoleInstance(instanceName string, console api.InstanceC
 		}
 	}
 
+	if console.Port != "" {
+		err = r.CheckExtension("instance_serial_device")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Send the request
 	useEventListener := r.CheckExtension("operation_wait") != nil
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/console", path, url.PathEscape(instanceName)), console, "", useEventListener)
@@ -2879,6 +2934,13 @@ func (r *ProtocolLXD) CreateInstanceBackup(instanceName string, backup api.Insta
 		return nil, err
 	}
 
+	if backup.Stateful {
+		err = r.CheckExtension("instance_stateful_backups")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("%s/%s/backups", path, url.PathEscape(instanceName)), backup, "", true)
 	if err != nil {
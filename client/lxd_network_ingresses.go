@@ -0,0 +1,129 @@
+package lxd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetNetworkIngressHostnames returns a list of network ingress host names.
+func (r *ProtocolLXD) GetNetworkIngressHostnames(networkName string) ([]string, error) {
+	err := r.CheckExtension("network_ingress")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := fmt.Sprintf("/networks/%s/ingresses", url.PathEscape(networkName))
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetNetworkIngresses returns a list of Network ingress structs.
+func (r *ProtocolLXD) GetNetworkIngresses(networkName string) ([]api.NetworkIngress, error) {
+	err := r.CheckExtension("network_ingress")
+	if err != nil {
+		return nil, err
+	}
+
+	ingresses := []api.NetworkIngress{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", fmt.Sprintf("/networks/%s/ingresses?recursion=1", url.PathEscape(networkName)), nil, "", &ingresses)
+	if err != nil {
+		return nil, err
+	}
+
+	return ingresses, nil
+}
+
+// GetNetworkIngress returns a Network ingress entry for the provided network and host name.
+func (r *ProtocolLXD) GetNetworkIngress(networkName string, hostname string) (*api.NetworkIngress, string, error) {
+	err := r.CheckExtension("network_ingress")
+	if err != nil {
+		return nil, "", err
+	}
+
+	ingress := api.NetworkIngress{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/ingresses/%s", url.PathEscape(networkName), url.PathEscape(hostname)), nil, "", &ingress)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &ingress, etag, nil
+}
+
+// GetNetworkIngressState returns the certificate, request and target health state of a Network ingress.
+func (r *ProtocolLXD) GetNetworkIngressState(networkName string, hostname string) (*api.NetworkIngressState, error) {
+	err := r.CheckExtension("network_ingress")
+	if err != nil {
+		return nil, err
+	}
+
+	state := api.NetworkIngressState{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct("GET", fmt.Sprintf("/networks/%s/ingresses/%s/state", url.PathEscape(networkName), url.PathEscape(hostname)), nil, "", &state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// CreateNetworkIngress defines a new network ingress using the provided struct.
+func (r *ProtocolLXD) CreateNetworkIngress(networkName string, ingress api.NetworkIngressesPost) error {
+	err := r.CheckExtension("network_ingress")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("POST", fmt.Sprintf("/networks/%s/ingresses", url.PathEscape(networkName)), ingress, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateNetworkIngress updates the network ingress to match the provided struct.
+func (r *ProtocolLXD) UpdateNetworkIngress(networkName string, hostname string, ingress api.NetworkIngressPut, ETag string) error {
+	err := r.CheckExtension("network_ingress")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("PUT", fmt.Sprintf("/networks/%s/ingresses/%s", url.PathEscape(networkName), url.PathEscape(hostname)), ingress, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkIngress deletes an existing network ingress.
+func (r *ProtocolLXD) DeleteNetworkIngress(networkName string, hostname string) error {
+	err := r.CheckExtension("network_ingress")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query("DELETE", fmt.Sprintf("/networks/%s/ingresses/%s", url.PathEscape(networkName), url.PathEscape(hostname)), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
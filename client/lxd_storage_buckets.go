@@ -1,7 +1,14 @@
 package lxd
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/cancel"
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/units"
 )
 
 // GetStoragePoolBucketNames returns a list of storage bucket names.
@@ -233,3 +240,231 @@ func (r *ProtocolLXD) DeleteStoragePoolBucketKey(poolName string, bucketName str
 
 	return nil
 }
+
+// GetStoragePoolBucketBackupNames returns a list of storage bucket backup names.
+func (r *ProtocolLXD) GetStoragePoolBucketBackupNames(poolName string, bucketName string) ([]string, error) {
+	err := r.CheckExtension("storage_bucket_backup")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	u := api.NewURL().Path("storage-pools", poolName, "buckets", bucketName, "backups")
+	_, err = r.queryStruct("GET", u.String(), nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(u.String(), urls...)
+}
+
+// GetStoragePoolBucketBackups returns a list of storage bucket backups.
+func (r *ProtocolLXD) GetStoragePoolBucketBackups(poolName string, bucketName string) ([]api.StorageBucketBackup, error) {
+	err := r.CheckExtension("storage_bucket_backup")
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []api.StorageBucketBackup{}
+
+	// Fetch the raw value.
+	u := api.NewURL().Path("storage-pools", poolName, "buckets", bucketName, "backups").WithQuery("recursion", "1")
+	_, err = r.queryStruct("GET", u.String(), nil, "", &backups)
+	if err != nil {
+		return nil, err
+	}
+
+	return backups, nil
+}
+
+// GetStoragePoolBucketBackup returns a storage bucket backup.
+func (r *ProtocolLXD) GetStoragePoolBucketBackup(poolName string, bucketName string, name string) (*api.StorageBucketBackup, string, error) {
+	err := r.CheckExtension("storage_bucket_backup")
+	if err != nil {
+		return nil, "", err
+	}
+
+	backup := api.StorageBucketBackup{}
+
+	// Fetch the raw value.
+	u := api.NewURL().Path("storage-pools", poolName, "buckets", bucketName, "backups", name)
+	etag, err := r.queryStruct("GET", u.String(), nil, "", &backup)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &backup, etag, nil
+}
+
+// CreateStoragePoolBucketBackup creates a new storage bucket backup.
+func (r *ProtocolLXD) CreateStoragePoolBucketBackup(poolName string, bucketName string, backup api.StorageBucketBackupsPost) (Operation, error) {
+	err := r.CheckExtension("storage_bucket_backup")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request.
+	u := api.NewURL().Path("storage-pools", poolName, "buckets", bucketName, "backups")
+	op, _, err := r.queryOperation("POST", u.String(), backup, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// RenameStoragePoolBucketBackup renames a storage bucket backup.
+func (r *ProtocolLXD) RenameStoragePoolBucketBackup(poolName string, bucketName string, name string, backup api.StorageBucketBackupPost) (Operation, error) {
+	err := r.CheckExtension("storage_bucket_backup")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request.
+	u := api.NewURL().Path("storage-pools", poolName, "buckets", bucketName, "backups", name)
+	op, _, err := r.queryOperation("POST", u.String(), backup, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// DeleteStoragePoolBucketBackup deletes a storage bucket backup.
+func (r *ProtocolLXD) DeleteStoragePoolBucketBackup(poolName string, bucketName string, name string) (Operation, error) {
+	err := r.CheckExtension("storage_bucket_backup")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request.
+	u := api.NewURL().Path("storage-pools", poolName, "buckets", bucketName, "backups", name)
+	op, _, err := r.queryOperation("DELETE", u.String(), nil, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// GetStoragePoolBucketBackupFile requests the storage bucket backup content.
+func (r *ProtocolLXD) GetStoragePoolBucketBackupFile(poolName string, bucketName string, name string, req *BackupFileRequest) (*BackupFileResponse, error) {
+	err := r.CheckExtension("storage_bucket_backup")
+	if err != nil {
+		return nil, err
+	}
+
+	// Build the URL.
+	u := api.NewURL().Path("storage-pools", poolName, "buckets", bucketName, "backups", name, "export")
+	uri, err := r.setQueryAttributes(fmt.Sprintf("%s/1.0%s", r.httpBaseURL.String(), u.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare the download request.
+	request, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.httpUserAgent != "" {
+		request.Header.Set("User-Agent", r.httpUserAgent)
+	}
+
+	// Start the request.
+	response, doneCh, err := cancel.CancelableDownload(req.Canceler, r.DoHTTP, request)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = response.Body.Close() }()
+	defer close(doneCh)
+
+	if response.StatusCode != http.StatusOK {
+		_, _, err := lxdParseResponse(response)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Handle the data.
+	body := response.Body
+	if req.ProgressHandler != nil {
+		body = &ioprogress.ProgressReader{
+			ReadCloser: response.Body,
+			Tracker: &ioprogress.ProgressTracker{
+				Length: response.ContentLength,
+				Handler: func(percent int64, speed int64) {
+					req.ProgressHandler(ioprogress.ProgressData{Text: fmt.Sprintf("%d%% (%s/s)", percent, units.GetByteSizeString(speed, 2))})
+				},
+			},
+		}
+	}
+
+	size, err := io.Copy(req.BackupFile, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := BackupFileResponse{}
+	resp.Size = size
+
+	return &resp, nil
+}
+
+// CreateStoragePoolBucketFromBackup creates a storage bucket from a backup file.
+func (r *ProtocolLXD) CreateStoragePoolBucketFromBackup(poolName string, args StoragePoolBucketBackupArgs) (Operation, error) {
+	err := r.CheckExtension("storage_bucket_backup")
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare the HTTP request.
+	u := api.NewURL().Path("storage-pools", poolName, "buckets")
+	reqURL, err := r.setQueryAttributes(fmt.Sprintf("%s/1.0%s", r.httpBaseURL.String(), u.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", reqURL, args.BackupFile)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	if args.Name != "" {
+		req.Header.Set("X-LXD-name", args.Name)
+	}
+
+	// Send the request.
+	resp, err := r.DoHTTP(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	// Handle errors.
+	response, _, err := lxdParseResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get to the operation.
+	respOperation, err := response.MetadataAsOperation()
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup an Operation wrapper.
+	op := operation{
+		Operation: *respOperation,
+		r:         r,
+		chActive:  make(chan bool),
+	}
+
+	return &op, nil
+}
@@ -27,6 +27,7 @@ type cmdConsole struct {
 
 	flagShowLog bool
 	flagType    string
+	flagPort    string
 }
 
 func (c *cmdConsole) Command() *cobra.Command {
@@ -37,11 +38,14 @@ func (c *cmdConsole) Command() *cobra.Command {
 		`Attach to instance consoles
 
 This command allows you to interact with the boot console of an instance
-as well as retrieve past log entries from it.`))
+as well as retrieve past log entries from it.
+
+Use --port to attach to one of the instance's serial devices instead.`))
 
 	cmd.RunE = c.Run
 	cmd.Flags().BoolVar(&c.flagShowLog, "show-log", false, i18n.G("Retrieve the instance's console log"))
 	cmd.Flags().StringVarP(&c.flagType, "type", "t", "console", i18n.G("Type of connection to establish: 'console' for serial console, 'vga' for SPICE graphical output")+"``")
+	cmd.Flags().StringVar(&c.flagPort, "port", "", i18n.G("Name of the serial device to attach to")+"``")
 
 	return cmd
 }
@@ -108,6 +112,14 @@ func (c *cmdConsole) Run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("Unknown output type %q"), c.flagType)
 	}
 
+	if c.flagPort != "" && c.flagType != "console" {
+		return fmt.Errorf(i18n.G("The --port flag is only supported by the 'console' output type"))
+	}
+
+	if c.flagPort != "" && c.flagShowLog {
+		return fmt.Errorf(i18n.G("The --port and --show-log flags can't be used together"))
+	}
+
 	// Connect to LXD
 	remote, name, err := conf.ParseRemote(args[0])
 	if err != nil {
@@ -182,6 +194,7 @@ func (c *cmdConsole) console(d lxd.InstanceServer, name string) error {
 		Width:  width,
 		Height: height,
 		Type:   "console",
+		Port:   c.flagPort,
 	}
 
 	consoleDisconnect := make(chan bool)
@@ -31,6 +31,7 @@ type cmdCopy struct {
 	flagTargetProject     string
 	flagRefresh           bool
 	flagAllowInconsistent bool
+	flagFork              bool
 }
 
 func (c *cmdCopy) Command() *cobra.Command {
@@ -63,6 +64,7 @@ The pull transfer mode is the default as it is compatible with all LXD versions.
 	cmd.Flags().BoolVar(&c.flagNoProfiles, "no-profiles", false, i18n.G("Create the instance with no profiles applied"))
 	cmd.Flags().BoolVar(&c.flagRefresh, "refresh", false, i18n.G("Perform an incremental copy"))
 	cmd.Flags().BoolVar(&c.flagAllowInconsistent, "allow-inconsistent", false, i18n.G("Ignore copy errors for volatile files"))
+	cmd.Flags().BoolVar(&c.flagFork, "fork", false, i18n.G("Fork a running virtual machine into a started clone sharing its memory"))
 
 	return cmd
 }
@@ -265,6 +267,7 @@ func (c *cmdCopy) copyInstance(conf *config.Config, sourceResource string, destR
 			Mode:              mode,
 			Refresh:           c.flagRefresh,
 			AllowInconsistent: c.flagAllowInconsistent,
+			Fork:              c.flagFork,
 		}
 
 		// Copy of an instance into a new instance
@@ -472,6 +475,16 @@ func (c *cmdCopy) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	stateful := !c.flagStateless && !c.flagRefresh
+
+	if c.flagFork {
+		if !stateful {
+			return fmt.Errorf(i18n.G("--fork can't be used with --stateless or --refresh"))
+		}
+
+		if shared.IsSnapshot(args[0]) {
+			return fmt.Errorf(i18n.G("--fork can't be used with snapshots"))
+		}
+	}
 	keepVolatile := c.flagRefresh
 	instanceOnly := c.flagInstanceOnly
 
@@ -22,24 +22,29 @@ type cmdExport struct {
 
 	flagInstanceOnly         bool
 	flagOptimizedStorage     bool
+	flagStateful             bool
 	flagCompressionAlgorithm string
 }
 
 func (c *cmdExport) Command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("export", i18n.G("[<remote>:]<instance> [target] [--instance-only] [--optimized-storage]"))
+	cmd.Use = usage("export", i18n.G("[<remote>:]<instance> [target] [--instance-only] [--optimized-storage] [--stateful]"))
 	cmd.Short = i18n.G("Export instance backups")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
 		`Export instances as backup tarballs.`))
 	cmd.Example = cli.FormatSection("", i18n.G(
 		`lxc export u1 backup0.tar.gz
-    Download a backup tarball of the u1 instance.`))
+    Download a backup tarball of the u1 instance.
+
+lxc export c1 backup0.tar.gz --stateful
+    Download a backup tarball of the running c1 container, including its running state.`))
 
 	cmd.RunE = c.Run
 	cmd.Flags().BoolVar(&c.flagInstanceOnly, "instance-only", false,
 		i18n.G("Whether or not to only backup the instance (without snapshots)"))
 	cmd.Flags().BoolVar(&c.flagOptimizedStorage, "optimized-storage", false,
 		i18n.G("Use storage driver optimized format (can only be restored on a similar pool)"))
+	cmd.Flags().BoolVar(&c.flagStateful, "stateful", false, i18n.G("Include the running state of the container (requires CRIU)"))
 	cmd.Flags().StringVar(&c.flagCompressionAlgorithm, "compression", "", i18n.G("Compression algorithm to use (none for uncompressed)")+"``")
 
 	return cmd
@@ -73,6 +78,7 @@ func (c *cmdExport) Run(cmd *cobra.Command, args []string) error {
 		ContainerOnly:        instanceOnly,
 		InstanceOnly:         instanceOnly,
 		OptimizedStorage:     c.flagOptimizedStorage,
+		Stateful:             c.flagStateful,
 		CompressionAlgorithm: c.flagCompressionAlgorithm,
 	}
 
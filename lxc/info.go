@@ -5,6 +5,7 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
@@ -33,7 +34,7 @@ func (c *cmdInfo) Command() *cobra.Command {
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
 		`Show instance or server information`))
 	cmd.Example = cli.FormatSection("", i18n.G(
-		`lxc info [<remote>:]<instance> [--show-log]
+		`lxc info [<remote>:]<instance> [--show-log] [--resources]
     For instance information.
 
 lxc info [<remote>:] [--resources]
@@ -41,7 +42,7 @@ lxc info [<remote>:] [--resources]
 
 	cmd.RunE = c.Run
 	cmd.Flags().BoolVar(&c.flagShowLog, "show-log", false, i18n.G("Show the instance's last 100 log lines?"))
-	cmd.Flags().BoolVar(&c.flagResources, "resources", false, i18n.G("Show the resources available to the server"))
+	cmd.Flags().BoolVar(&c.flagResources, "resources", false, i18n.G("Show the resources available to the server or the resource usage history of the instance"))
 	cmd.Flags().StringVar(&c.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
 
 	return cmd
@@ -363,6 +364,10 @@ func (c *cmdInfo) remoteInfo(d lxd.InstanceServer) error {
 			}
 		}
 
+		if len(resources.CPU.ConfidentialComputing) > 0 {
+			fmt.Printf("  "+i18n.G("Confidential computing: %s")+"\n", strings.Join(resources.CPU.ConfidentialComputing, ", "))
+		}
+
 		// Memory
 		fmt.Printf("\n" + i18n.G("Memory:") + "\n")
 		if resources.Memory.HugepagesTotal > 0 {
@@ -417,6 +422,26 @@ func (c *cmdInfo) remoteInfo(d lxd.InstanceServer) error {
 			}
 		}
 
+		// vDPA devices
+		if len(resources.Network.VDPA) > 0 {
+			fmt.Printf("\n" + i18n.G("vDPA devices:") + "\n")
+			for _, vdpa := range resources.Network.VDPA {
+				fmt.Printf("  - %s\n", vdpa.Name)
+				fmt.Printf("    "+i18n.G("Management device: %v")+"\n", vdpa.ManagementDevice)
+				if vdpa.Driver != "" {
+					fmt.Printf("    "+i18n.G("Driver: %v")+"\n", vdpa.Driver)
+				}
+
+				if vdpa.VhostDevice != "" {
+					fmt.Printf("    "+i18n.G("vhost device: %v")+"\n", vdpa.VhostDevice)
+				}
+
+				if vdpa.UsedBy != "" {
+					fmt.Printf("    "+i18n.G("Used by: %v")+"\n", vdpa.UsedBy)
+				}
+			}
+		}
+
 		// Storage
 		if len(resources.Storage.Disks) == 1 {
 			fmt.Printf("\n" + i18n.G("Disk:") + "\n")
@@ -447,6 +472,43 @@ func (c *cmdInfo) remoteInfo(d lxd.InstanceServer) error {
 	return nil
 }
 
+func (c *cmdInfo) renderStateHistory(samples []api.InstanceStateHistorySample) {
+	formatBytes := func(value float64) string {
+		return units.GetByteSizeStringIEC(int64(value), 2)
+	}
+
+	formatRate := func(value float64) string {
+		return units.GetByteSizeStringIEC(int64(value), 2) + "/s"
+	}
+
+	series := []struct {
+		name   string
+		format func(value float64) string
+		value  func(sample api.InstanceStateHistorySample) float64
+	}{
+		{i18n.G("CPU"), func(value float64) string { return fmt.Sprintf("%.2f", value) }, func(sample api.InstanceStateHistorySample) float64 { return sample.CPUUsage }},
+		{i18n.G("Memory"), formatBytes, func(sample api.InstanceStateHistorySample) float64 { return float64(sample.MemoryUsage) }},
+		{i18n.G("Disk read"), formatRate, func(sample api.InstanceStateHistorySample) float64 { return float64(sample.DiskReadRate) }},
+		{i18n.G("Disk write"), formatRate, func(sample api.InstanceStateHistorySample) float64 { return float64(sample.DiskWriteRate) }},
+		{i18n.G("Network received"), formatRate, func(sample api.InstanceStateHistorySample) float64 { return float64(sample.NetworkReceiveRate) }},
+		{i18n.G("Network sent"), formatRate, func(sample api.InstanceStateHistorySample) float64 { return float64(sample.NetworkTransmitRate) }},
+	}
+
+	fmt.Println("\n" + i18n.G("Usage history (last hour):"))
+
+	for _, entry := range series {
+		values := make([]float64, 0, len(samples))
+		var peak float64
+		for _, sample := range samples {
+			value := entry.value(sample)
+			values = append(values, value)
+			peak = max(peak, value)
+		}
+
+		fmt.Printf("  %-18s %s  "+i18n.G("current: %s, peak: %s")+"\n", entry.name+":", sparkline(values), entry.format(values[len(values)-1]), entry.format(peak))
+	}
+}
+
 func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, remote config.Remote, name string, showLog bool) error {
 	// Quick checks.
 	if c.flagTarget != "" {
@@ -493,6 +555,19 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, remote config.Remote, name
 		fmt.Printf(i18n.G("Last Used: %s")+"\n", inst.LastUsedAt.Local().Format(layout))
 	}
 
+	if inst.State.Confidential != nil {
+		fmt.Println("\n" + i18n.G("Confidential computing:"))
+		fmt.Printf("  %s: %s\n", i18n.G("Type"), inst.State.Confidential.Type)
+
+		if inst.State.Confidential.Policy != "" {
+			fmt.Printf("  %s: %s\n", i18n.G("Policy"), inst.State.Confidential.Policy)
+		}
+
+		if inst.State.Confidential.LaunchMeasurement != "" {
+			fmt.Printf("  %s: %s\n", i18n.G("Launch measurement"), inst.State.Confidential.LaunchMeasurement)
+		}
+	}
+
 	if inst.State.Pid != 0 {
 		fmt.Println("\n" + i18n.G("Resources:"))
 		// Processes
@@ -513,6 +588,50 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, remote config.Remote, name
 			fmt.Print(diskInfo)
 		}
 
+		// Directory shares
+		shareInfo := ""
+		if inst.State.Disk != nil {
+			for entry, disk := range inst.State.Disk {
+				if disk.Protocol != "" {
+					shareInfo += fmt.Sprintf("    %s: %s\n", entry, disk.Protocol)
+				}
+			}
+		}
+
+		if shareInfo != "" {
+			fmt.Printf("  %s\n", i18n.G("Directory shares:"))
+			fmt.Print(shareInfo)
+		}
+
+		// Disk I/O
+		diskIOInfo := ""
+		if inst.State.Disk != nil {
+			for entry, disk := range inst.State.Disk {
+				if disk.Counters == nil {
+					continue
+				}
+
+				diskIOInfo += fmt.Sprintf("    %s:\n", entry)
+				diskIOInfo += fmt.Sprintf("      %s: %s\n", i18n.G("Bytes read"), units.GetByteSizeStringIEC(disk.Counters.BytesRead, 2))
+				diskIOInfo += fmt.Sprintf("      %s: %s\n", i18n.G("Bytes written"), units.GetByteSizeStringIEC(disk.Counters.BytesWritten, 2))
+				diskIOInfo += fmt.Sprintf("      %s: %d\n", i18n.G("Read operations"), disk.Counters.ReadsCompleted)
+				diskIOInfo += fmt.Sprintf("      %s: %d\n", i18n.G("Write operations"), disk.Counters.WritesCompleted)
+
+				if disk.Counters.ReadTime > 0 && disk.Counters.ReadsCompleted > 0 {
+					diskIOInfo += fmt.Sprintf("      %s: %.2fms\n", i18n.G("Read latency (average)"), float64(disk.Counters.ReadTime)/float64(disk.Counters.ReadsCompleted))
+				}
+
+				if disk.Counters.WriteTime > 0 && disk.Counters.WritesCompleted > 0 {
+					diskIOInfo += fmt.Sprintf("      %s: %.2fms\n", i18n.G("Write latency (average)"), float64(disk.Counters.WriteTime)/float64(disk.Counters.WritesCompleted))
+				}
+			}
+		}
+
+		if diskIOInfo != "" {
+			fmt.Printf("  %s\n", i18n.G("Disk I/O:"))
+			fmt.Print(diskIOInfo)
+		}
+
 		// CPU usage
 		cpuInfo := ""
 		if inst.State.CPU.Usage != 0 {
@@ -589,6 +708,18 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, remote config.Remote, name
 		}
 	}
 
+	// Resource usage history
+	if c.flagResources && d.HasExtension("instance_state_history") {
+		history, err := d.GetInstanceStateHistory(name, time.Now().Add(-time.Hour))
+		if err != nil {
+			return err
+		}
+
+		if len(history.Samples) > 0 {
+			c.renderStateHistory(history.Samples)
+		}
+	}
+
 	// List snapshots
 	firstSnapshot := true
 	if len(inst.Snapshots) > 0 {
@@ -34,13 +34,17 @@ type cmdInit struct {
 	flagNoProfiles bool
 	flagEmpty      bool
 	flagVM         bool
+	flagTemplate   string
 }
 
 func (c *cmdInit) Command() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Use = usage("init", i18n.G("[<remote>:]<image> [<remote>:][<name>]"))
 	cmd.Short = i18n.G("Create instances from images")
-	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Create instances from images`))
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Create instances from images
+
+When --template is used, the image comes from the instance template and the arguments are
+the optional [<remote>:][<name>] of the instance followed by key=value template parameters.`))
 	cmd.Example = cli.FormatSection("", i18n.G(`lxc init ubuntu:24.04 u1
     Create a container (but do not start it)
 
@@ -51,7 +55,10 @@ lxc init ubuntu:24.04 v1 --vm -c limits.cpu=4 -c limits.memory=4GiB
     Create a virtual machine with 4 vCPUs and 4GiB of RAM
 
 lxc init ubuntu:24.04 v1 --vm -c limits.cpu=2 -c limits.memory=8GiB -d root,size=32GiB
-    Create a virtual machine with 2 vCPUs, 8GiB of RAM and a root disk of 32GiB`))
+    Create a virtual machine with 2 vCPUs, 8GiB of RAM and a root disk of 32GiB
+
+lxc init --template web w1 size=large
+    Create an instance from the "web" instance template, setting its "size" parameter`))
 
 	cmd.RunE = c.Run
 	cmd.Flags().StringArrayVarP(&c.flagConfig, "config", "c", nil, i18n.G("Config key/value to apply to the new instance")+"``")
@@ -65,18 +72,24 @@ lxc init ubuntu:24.04 v1 --vm -c limits.cpu=2 -c limits.memory=8GiB -d root,size
 	cmd.Flags().BoolVar(&c.flagNoProfiles, "no-profiles", false, i18n.G("Create the instance with no profiles applied"))
 	cmd.Flags().BoolVar(&c.flagEmpty, "empty", false, i18n.G("Create an empty instance"))
 	cmd.Flags().BoolVar(&c.flagVM, "vm", false, i18n.G("Create a virtual machine"))
+	cmd.Flags().StringVar(&c.flagTemplate, "template", "", i18n.G("Instance template to create the instance from")+"``")
 
 	return cmd
 }
 
 func (c *cmdInit) Run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 0, 2)
+	maxArgs := 2
+	if c.flagTemplate != "" {
+		maxArgs = -1
+	}
+
+	exit, err := c.global.CheckArgs(cmd, args, 0, maxArgs)
 	if exit {
 		return err
 	}
 
-	if len(args) == 0 && !c.flagEmpty {
+	if len(args) == 0 && !c.flagEmpty && c.flagTemplate == "" {
 		_ = cmd.Usage()
 		return nil
 	}
@@ -95,6 +108,7 @@ func (c *cmdInit) create(conf *config.Config, args []string) (lxd.InstanceServer
 	var devicesMap map[string]map[string]string
 	var configMap map[string]string
 	var profiles []string
+	var templateParameters map[string]string
 
 	// If stdin isn't a terminal, read text from it
 	if !termios.IsTerminal(getStdinFd()) {
@@ -109,7 +123,16 @@ func (c *cmdInit) create(conf *config.Config, args []string) (lxd.InstanceServer
 		}
 	}
 
-	if len(args) > 0 {
+	if c.flagTemplate != "" {
+		if c.flagEmpty {
+			return nil, "", fmt.Errorf(i18n.G("--empty cannot be combined with --template"))
+		}
+
+		remote, name, templateParameters, err = parseTemplateArgs(conf, args)
+		if err != nil {
+			return nil, "", err
+		}
+	} else if len(args) > 0 {
 		iremote, image, err = conf.ParseRemote(args[0])
 		if err != nil {
 			return nil, "", err
@@ -258,6 +281,16 @@ func (c *cmdInit) create(conf *config.Config, args []string) (lxd.InstanceServer
 	req.Ephemeral = c.flagEphemeral
 	req.Description = stdinData.Description
 
+	if c.flagTemplate != "" {
+		req.Template = c.flagTemplate
+		req.TemplateParameters = templateParameters
+
+		// Let the template pick the instance type unless one was requested.
+		if !c.flagVM {
+			req.Type = ""
+		}
+	}
+
 	if !c.flagNoProfiles && len(profiles) == 0 {
 		if len(stdinData.Profiles) > 0 {
 			req.Profiles = stdinData.Profiles
@@ -305,7 +338,35 @@ func (c *cmdInit) create(conf *config.Config, args []string) (lxd.InstanceServer
 	req.Devices = devicesMap
 
 	var opInfo api.Operation
-	if !c.flagEmpty {
+	if c.flagTemplate != "" {
+		// Create the instance from the template
+		op, err := d.CreateInstance(req)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// Watch the background operation
+		progress := cli.ProgressRenderer{
+			Format: i18n.G("Retrieving image: %s"),
+			Quiet:  c.global.flagQuiet,
+		}
+
+		_, err = op.AddHandler(progress.UpdateOp)
+		if err != nil {
+			progress.Done("")
+			return nil, "", err
+		}
+
+		err = cli.CancelableWait(op, &progress)
+		if err != nil {
+			progress.Done("")
+			return nil, "", err
+		}
+
+		progress.Done("")
+
+		opInfo = op.Get()
+	} else if !c.flagEmpty {
 		// Get the image server and image info
 		iremote, image = guessImage(conf, d, remote, iremote, image)
 
@@ -401,6 +462,33 @@ func (c *cmdInit) create(conf *config.Config, args []string) (lxd.InstanceServer
 	return d, name, nil
 }
 
+// parseTemplateArgs splits the arguments of an instance creation from a template into the optional instance name and
+// the values of the template parameters, given as key=value.
+func parseTemplateArgs(conf *config.Config, args []string) (remote string, name string, parameters map[string]string, err error) {
+	parameters = map[string]string{}
+	instanceArg := ""
+	for _, arg := range args {
+		key, value, found := strings.Cut(arg, "=")
+		if found {
+			parameters[key] = value
+			continue
+		}
+
+		if instanceArg != "" {
+			return "", "", nil, fmt.Errorf(i18n.G("Only one instance name can be given"))
+		}
+
+		instanceArg = arg
+	}
+
+	remote, name, err = conf.ParseRemote(instanceArg)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return remote, name, parameters, nil
+}
+
 func (c *cmdInit) checkNetwork(d lxd.InstanceServer, name string) {
 	ct, _, err := d.GetInstance(name)
 	if err != nil {
@@ -22,7 +22,10 @@ func (c *cmdLaunch) Command() *cobra.Command {
 	cmd.Use = usage("launch", i18n.G("[<remote>:]<image> [<remote>:][<name>]"))
 	cmd.Short = i18n.G("Create and start instances from images")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Create and start instances from images`))
+		`Create and start instances from images
+
+When --template is used, the image comes from the instance template and the arguments are
+the optional [<remote>:][<name>] of the instance followed by key=value template parameters.`))
 	cmd.Example = cli.FormatSection("", i18n.G(`lxc launch ubuntu:24.04 u1
     Create and start a container
 
@@ -36,7 +39,10 @@ lxc launch ubuntu:24.04 v1 --vm -c limits.cpu=4 -c limits.memory=4GiB
     Create and start a virtual machine with 4 vCPUs and 4GiB of RAM
 
 lxc launch ubuntu:24.04 v1 --vm -c limits.cpu=2 -c limits.memory=8GiB -d root,size=32GiB
-    Create and start a virtual machine with 2 vCPUs, 8GiB of RAM and a root disk of 32GiB`))
+    Create and start a virtual machine with 2 vCPUs, 8GiB of RAM and a root disk of 32GiB
+
+lxc launch --template web w1 size=large
+    Create and start an instance from the "web" instance template, setting its "size" parameter`))
 
 	cmd.Hidden = false
 
@@ -52,7 +58,12 @@ func (c *cmdLaunch) Run(cmd *cobra.Command, args []string) error {
 	conf := c.global.conf
 
 	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	minArgs, maxArgs := 1, 2
+	if c.init.flagTemplate != "" {
+		minArgs, maxArgs = 0, -1
+	}
+
+	exit, err := c.global.CheckArgs(cmd, args, minArgs, maxArgs)
 	if exit {
 		return err
 	}
@@ -65,7 +76,12 @@ func (c *cmdLaunch) Run(cmd *cobra.Command, args []string) error {
 
 	// Get the remote
 	var remote string
-	if len(args) == 2 {
+	if c.init.flagTemplate != "" {
+		remote, _, _, err = parseTemplateArgs(conf, args)
+		if err != nil {
+			return err
+		}
+	} else if len(args) == 2 {
 		remote, _, err = conf.ParseRemote(args[1])
 		if err != nil {
 			return err
@@ -96,6 +96,7 @@ Pre-defined column shorthand chars:
   d - Description
   D - disk usage
   e - Project name
+  h - Health (as reported by health checks)
   l - Last used date
   m - Memory usage
   M - Memory usage (%)
@@ -559,6 +560,7 @@ func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 		'e': {i18n.G("PROJECT"), c.projectColumnData, false, false},
 		'f': {i18n.G("BASE IMAGE"), c.baseImageColumnData, false, false},
 		'F': {i18n.G("BASE IMAGE"), c.baseImageFullColumnData, false, false},
+		'h': {i18n.G("HEALTH"), c.healthColumnData, false, false},
 		'l': {i18n.G("LAST USED AT"), c.LastUsedColumnData, false, false},
 		'm': {i18n.G("MEMORY USAGE"), c.memoryUsageColumnData, true, false},
 		'M': {i18n.G("MEMORY USAGE%"), c.memoryUsagePercentColumnData, true, false},
@@ -756,6 +758,14 @@ func (c *cmdList) statusColumnData(cInfo api.InstanceFull) string {
 	return strings.ToUpper(cInfo.Status)
 }
 
+func (c *cmdList) healthColumnData(cInfo api.InstanceFull) string {
+	if !cInfo.IsActive() {
+		return ""
+	}
+
+	return strings.ToUpper(cInfo.ExpandedConfig["volatile.last_state.health"])
+}
+
 func (c *cmdList) IP4ColumnData(cInfo api.InstanceFull) string {
 	if cInfo.IsActive() && cInfo.State != nil && cInfo.State.Network != nil {
 		ipv4s := []string{}
@@ -104,6 +104,10 @@ func (c *cmdNetwork) Command() *cobra.Command {
 	networkForwardCmd := cmdNetworkForward{global: c.global}
 	cmd.AddCommand(networkForwardCmd.Command())
 
+	// Ingress
+	networkIngressCmd := cmdNetworkIngress{global: c.global}
+	cmd.AddCommand(networkIngressCmd.Command())
+
 	// Load Balancer
 	networkLoadBalancerCmd := cmdNetworkLoadBalancer{global: c.global}
 	cmd.AddCommand(networkLoadBalancerCmd.Command())
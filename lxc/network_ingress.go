@@ -0,0 +1,1045 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+)
+
+type cmdNetworkIngress struct {
+	global     *cmdGlobal
+	flagTarget string
+}
+
+func (c *cmdNetworkIngress) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("ingress")
+	cmd.Short = i18n.G("Manage network ingresses")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Manage network ingresses"))
+
+	// List.
+	networkIngressListCmd := cmdNetworkIngressList{global: c.global, networkIngress: c}
+	cmd.AddCommand(networkIngressListCmd.Command())
+
+	// Show.
+	networkIngressShowCmd := cmdNetworkIngressShow{global: c.global, networkIngress: c}
+	cmd.AddCommand(networkIngressShowCmd.Command())
+
+	// Info.
+	networkIngressInfoCmd := cmdNetworkIngressInfo{global: c.global, networkIngress: c}
+	cmd.AddCommand(networkIngressInfoCmd.Command())
+
+	// Create.
+	networkIngressCreateCmd := cmdNetworkIngressCreate{global: c.global, networkIngress: c}
+	cmd.AddCommand(networkIngressCreateCmd.Command())
+
+	// Get.
+	networkIngressGetCmd := cmdNetworkIngressGet{global: c.global, networkIngress: c}
+	cmd.AddCommand(networkIngressGetCmd.Command())
+
+	// Set.
+	networkIngressSetCmd := cmdNetworkIngressSet{global: c.global, networkIngress: c}
+	cmd.AddCommand(networkIngressSetCmd.Command())
+
+	// Unset.
+	networkIngressUnsetCmd := cmdNetworkIngressUnset{global: c.global, networkIngress: c, networkIngressSet: &networkIngressSetCmd}
+	cmd.AddCommand(networkIngressUnsetCmd.Command())
+
+	// Edit.
+	networkIngressEditCmd := cmdNetworkIngressEdit{global: c.global, networkIngress: c}
+	cmd.AddCommand(networkIngressEditCmd.Command())
+
+	// Delete.
+	networkIngressDeleteCmd := cmdNetworkIngressDelete{global: c.global, networkIngress: c}
+	cmd.AddCommand(networkIngressDeleteCmd.Command())
+
+	// Route.
+	networkIngressRouteCmd := cmdNetworkIngressRoute{global: c.global, networkIngress: c}
+	cmd.AddCommand(networkIngressRouteCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdNetworkIngressList struct {
+	global         *cmdGlobal
+	networkIngress *cmdNetworkIngress
+
+	flagFormat string
+}
+
+func (c *cmdNetworkIngressList) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]<network>"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available network ingresses")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List available network ingresses"))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkIngressList) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	ingresses, err := resource.server.GetNetworkIngresses(resource.name)
+	if err != nil {
+		return err
+	}
+
+	clustered := resource.server.IsClustered()
+
+	data := make([][]string, 0, len(ingresses))
+	for _, ingress := range ingresses {
+		tls := "none"
+		if shared.IsTrue(ingress.Config["tls.acme"]) {
+			tls = "acme"
+		} else if ingress.Config["tls.certificate"] != "" {
+			tls = "manual"
+		}
+
+		details := []string{
+			ingress.Hostname,
+			ingress.ListenAddress,
+			ingress.Description,
+			tls,
+			fmt.Sprintf("%d", len(ingress.Routes)),
+		}
+
+		if clustered {
+			details = append(details, ingress.Location)
+		}
+
+		data = append(data, details)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("HOSTNAME"),
+		i18n.G("LISTEN ADDRESS"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("TLS"),
+		i18n.G("ROUTES"),
+	}
+
+	if clustered {
+		header = append(header, i18n.G("LOCATION"))
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, ingresses)
+}
+
+// Show.
+type cmdNetworkIngressShow struct {
+	global         *cmdGlobal
+	networkIngress *cmdNetworkIngress
+}
+
+func (c *cmdNetworkIngressShow) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<network> <hostname>"))
+	cmd.Short = i18n.G("Show network ingress configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show network ingress configurations"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.networkIngress.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkIngressShow) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return fmt.Errorf(i18n.G("Missing host name"))
+	}
+
+	client := resource.server
+
+	// If a target was specified, use the ingress on the given member.
+	if c.networkIngress.flagTarget != "" {
+		client = client.UseTarget(c.networkIngress.flagTarget)
+	}
+
+	// Show the network ingress config.
+	ingress, _, err := client.GetNetworkIngress(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&ingress)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Info.
+type cmdNetworkIngressInfo struct {
+	global         *cmdGlobal
+	networkIngress *cmdNetworkIngress
+}
+
+func (c *cmdNetworkIngressInfo) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("info", i18n.G("[<remote>:]<network> <hostname>"))
+	cmd.Short = i18n.G("Get runtime information on network ingresses")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Get runtime information on network ingresses
+
+The certificate status, the request counters and the health of the targets are shown.`))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.networkIngress.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkIngressInfo) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return fmt.Errorf(i18n.G("Missing host name"))
+	}
+
+	client := resource.server
+
+	// If a target was specified, use the ingress on the given member.
+	if c.networkIngress.flagTarget != "" {
+		client = client.UseTarget(c.networkIngress.flagTarget)
+	}
+
+	state, err := client.GetNetworkIngressState(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	const layout = "2006/01/02 15:04 MST"
+
+	fmt.Printf(i18n.G("Host name: %s")+"\n", args[1])
+	fmt.Printf(i18n.G("Requests: %d")+"\n", state.Requests)
+	fmt.Printf(i18n.G("Errors: %d")+"\n", state.Errors)
+
+	// Certificate information.
+	fmt.Println("")
+	fmt.Println(i18n.G("Certificate:"))
+	fmt.Printf("  %s: %s\n", i18n.G("Source"), state.Certificate.Source)
+	fmt.Printf("  %s: %s\n", i18n.G("Status"), state.Certificate.Status)
+	if !state.Certificate.ExpiresAt.IsZero() {
+		fmt.Printf("  %s: %s\n", i18n.G("Expires at"), state.Certificate.ExpiresAt.Local().Format(layout))
+	}
+
+	if state.Certificate.Error != "" {
+		fmt.Printf("  %s: %s\n", i18n.G("Error"), state.Certificate.Error)
+	}
+
+	// Target information.
+	if len(state.Targets) > 0 {
+		fmt.Println("")
+		fmt.Println(i18n.G("Targets:"))
+		for _, target := range state.Targets {
+			fmt.Printf("  %s:\n", target.Address)
+			fmt.Printf("    %s: %s\n", i18n.G("Status"), target.Status)
+			fmt.Printf("    %s: %d\n", i18n.G("Requests"), target.Requests)
+			if !target.LastCheck.IsZero() {
+				fmt.Printf("    %s: %s\n", i18n.G("Last check"), target.LastCheck.Local().Format(layout))
+			}
+
+			if target.Error != "" {
+				fmt.Printf("    %s: %s\n", i18n.G("Error"), target.Error)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Create.
+type cmdNetworkIngressCreate struct {
+	global         *cmdGlobal
+	networkIngress *cmdNetworkIngress
+}
+
+func (c *cmdNetworkIngressCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<network> <hostname> <listen_address> [key=value...]"))
+	cmd.Short = i18n.G("Create new network ingresses")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Create new network ingresses"))
+	cmd.Example = cli.FormatSection("", i18n.G(`lxc network ingress create lxdbr0 www.example.net 192.0.2.1 tls.acme=true
+    Create an ingress for www.example.net listening on 192.0.2.1, with a certificate obtained through ACME.
+
+lxc network ingress create lxdbr0 www.example.net 192.0.2.1 < config.yaml
+    Create an ingress with the configuration and routes from config.yaml.`))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.networkIngress.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkIngressCreate) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return fmt.Errorf(i18n.G("Missing host name"))
+	}
+
+	if args[2] == "" {
+		return fmt.Errorf(i18n.G("Missing listen address"))
+	}
+
+	client := resource.server
+
+	// If stdin isn't a terminal, read yaml from it.
+	var ingressPut api.NetworkIngressPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &ingressPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ingressPut.Config == nil {
+		ingressPut.Config = map[string]string{}
+	}
+
+	// Get config from arguments.
+	for i := 3; i < len(args); i++ {
+		entry := strings.SplitN(args[i], "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), args[i])
+		}
+
+		ingressPut.Config[entry[0]] = entry[1]
+	}
+
+	// Create the network ingress.
+	ingress := api.NetworkIngressesPost{
+		Hostname:          args[1],
+		ListenAddress:     args[2],
+		NetworkIngressPut: ingressPut,
+	}
+
+	ingress.Normalise()
+
+	// If a target was specified, create the ingress on the given member.
+	if c.networkIngress.flagTarget != "" {
+		client = client.UseTarget(c.networkIngress.flagTarget)
+	}
+
+	err = client.CreateNetworkIngress(resource.name, ingress)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network ingress %s created")+"\n", ingress.Hostname)
+	}
+
+	return nil
+}
+
+// Get.
+type cmdNetworkIngressGet struct {
+	global         *cmdGlobal
+	networkIngress *cmdNetworkIngress
+
+	flagIsProperty bool
+}
+
+func (c *cmdNetworkIngressGet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", i18n.G("[<remote>:]<network> <hostname> <key>"))
+	cmd.Short = i18n.G("Get values for network ingress configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Get values for network ingress configuration keys"))
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Get the key as a network ingress property"))
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdNetworkIngressGet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	client := resource.server
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return fmt.Errorf(i18n.G("Missing host name"))
+	}
+
+	// Get the current config.
+	ingress, _, err := client.GetNetworkIngress(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if c.flagIsProperty {
+		w := ingress.Writable()
+		res, err := getFieldByJsonTag(&w, args[2])
+		if err != nil {
+			return fmt.Errorf(i18n.G("The property %q does not exist on the network ingress %q: %v"), args[2], args[1], err)
+		}
+
+		fmt.Printf("%v\n", res)
+	} else {
+		for k, v := range ingress.Config {
+			if k == args[2] {
+				fmt.Printf("%s\n", v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Set.
+type cmdNetworkIngressSet struct {
+	global         *cmdGlobal
+	networkIngress *cmdNetworkIngress
+
+	flagIsProperty bool
+}
+
+func (c *cmdNetworkIngressSet) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<network> <hostname> <key>=<value>..."))
+	cmd.Short = i18n.G("Set network ingress keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Set network ingress keys"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Set the key as a network ingress property"))
+	cmd.Flags().StringVar(&c.networkIngress.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkIngressSet) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return fmt.Errorf(i18n.G("Missing host name"))
+	}
+
+	client := resource.server
+
+	// If a target was specified, use the ingress on the given member.
+	if c.networkIngress.flagTarget != "" {
+		client = client.UseTarget(c.networkIngress.flagTarget)
+	}
+
+	// Get the current config.
+	ingress, etag, err := client.GetNetworkIngress(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if ingress.Config == nil {
+		ingress.Config = map[string]string{}
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[2:]...)
+	if err != nil {
+		return err
+	}
+
+	writable := ingress.Writable()
+	if c.flagIsProperty {
+		if cmd.Name() == "unset" {
+			for k := range keys {
+				err := unsetFieldByJsonTag(&writable, k)
+				if err != nil {
+					return fmt.Errorf(i18n.G("Error unsetting property: %v"), err)
+				}
+			}
+		} else {
+			err := unpackKVToWritable(&writable, keys)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Error setting properties: %v"), err)
+			}
+		}
+	} else {
+		for k, v := range keys {
+			writable.Config[k] = v
+		}
+	}
+
+	writable.Normalise()
+
+	return client.UpdateNetworkIngress(resource.name, ingress.Hostname, writable, etag)
+}
+
+// Unset.
+type cmdNetworkIngressUnset struct {
+	global            *cmdGlobal
+	networkIngress    *cmdNetworkIngress
+	networkIngressSet *cmdNetworkIngressSet
+
+	flagIsProperty bool
+}
+
+func (c *cmdNetworkIngressUnset) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", i18n.G("[<remote>:]<network> <hostname> <key>"))
+	cmd.Short = i18n.G("Unset network ingress configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Unset network ingress keys"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Unset the key as a network ingress property"))
+	return cmd
+}
+
+func (c *cmdNetworkIngressUnset) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 3, 3)
+	if exit {
+		return err
+	}
+
+	c.networkIngressSet.flagIsProperty = c.flagIsProperty
+
+	args = append(args, "")
+	return c.networkIngressSet.Run(cmd, args)
+}
+
+// Edit.
+type cmdNetworkIngressEdit struct {
+	global         *cmdGlobal
+	networkIngress *cmdNetworkIngress
+}
+
+func (c *cmdNetworkIngressEdit) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<network> <hostname>"))
+	cmd.Short = i18n.G("Edit network ingress configurations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit network ingress configurations as YAML"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.networkIngress.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkIngressEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the network ingress.
+### Any line starting with a '# will be ignored.
+###
+### A network ingress routes the HTTP and HTTPS requests for a host name received on a listen address
+### to the instances of the network, based on the path prefix of the requests.
+###
+### An example would look like:
+### hostname: www.example.net
+### listen_address: 192.0.2.1
+### config:
+###   tls.acme: "true"
+###   healthcheck.path: /healthz
+### description: test desc
+### routes:
+### - description: API servers
+###   path: /api
+###   target_address: 198.51.100.2
+###   target_port: "8080"
+### - path: /
+###   target_address: 198.51.100.3
+###   target_port: "80"
+### location: lxd01
+###
+### Note that the hostname, listen_address and location cannot be changed.`)
+}
+
+func (c *cmdNetworkIngressEdit) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return fmt.Errorf(i18n.G("Missing host name"))
+	}
+
+	client := resource.server
+
+	// If a target was specified, use the ingress on the given member.
+	if c.networkIngress.flagTarget != "" {
+		client = client.UseTarget(c.networkIngress.flagTarget)
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc network ingress show` command to be passed in here, but only take the
+		// contents of the NetworkIngressPut fields when updating. The other fields are silently discarded.
+		newData := api.NetworkIngress{}
+		err = yaml.UnmarshalStrict(contents, &newData)
+		if err != nil {
+			return err
+		}
+
+		newData.Normalise()
+
+		return client.UpdateNetworkIngress(resource.name, args[1], newData.Writable(), "")
+	}
+
+	// Get the current config.
+	ingress, etag, err := client.GetNetworkIngress(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&ingress)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newData := api.NetworkIngress{} // We show the full info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newData)
+		if err == nil {
+			newData.Normalise()
+			err = client.UpdateNetworkIngress(resource.name, args[1], newData.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdNetworkIngressDelete struct {
+	global         *cmdGlobal
+	networkIngress *cmdNetworkIngress
+}
+
+func (c *cmdNetworkIngressDelete) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<network> <hostname>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete network ingresses")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete network ingresses"))
+	cmd.RunE = c.Run
+
+	cmd.Flags().StringVar(&c.networkIngress.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkIngressDelete) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return fmt.Errorf(i18n.G("Missing host name"))
+	}
+
+	client := resource.server
+
+	// If a target was specified, use the ingress on the given member.
+	if c.networkIngress.flagTarget != "" {
+		client = client.UseTarget(c.networkIngress.flagTarget)
+	}
+
+	// Delete the network ingress.
+	err = client.DeleteNetworkIngress(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Network ingress %s deleted")+"\n", args[1])
+	}
+
+	return nil
+}
+
+// Add/Remove Route.
+type cmdNetworkIngressRoute struct {
+	global          *cmdGlobal
+	networkIngress  *cmdNetworkIngress
+	flagRemoveForce bool
+}
+
+func (c *cmdNetworkIngressRoute) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("route")
+	cmd.Short = i18n.G("Manage network ingress routes")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Manage network ingress routes"))
+
+	// Route Add.
+	cmd.AddCommand(c.CommandAdd())
+
+	// Route Remove.
+	cmd.AddCommand(c.CommandRemove())
+
+	return cmd
+}
+
+func (c *cmdNetworkIngressRoute) CommandAdd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("add", i18n.G("[<remote>:]<network> <hostname> <path> <target_address> [<target_port>]"))
+	cmd.Short = i18n.G("Add routes to an ingress")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Add routes to an ingress
+
+Requests are sent to the route with the longest matching path prefix.
+Adding several routes with the same path balances the requests between their targets.`))
+	cmd.RunE = c.RunAdd
+
+	cmd.Flags().StringVar(&c.networkIngress.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkIngressRoute) RunAdd(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 4, 5)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return fmt.Errorf(i18n.G("Missing host name"))
+	}
+
+	client := resource.server
+
+	// If a target was specified, use the ingress on the given member.
+	if c.networkIngress.flagTarget != "" {
+		client = client.UseTarget(c.networkIngress.flagTarget)
+	}
+
+	// Get the network ingress.
+	ingress, etag, err := client.GetNetworkIngress(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	route := api.NetworkIngressRoute{
+		Path:          args[2],
+		TargetAddress: args[3],
+	}
+
+	if len(args) > 4 {
+		route.TargetPort = args[4]
+	}
+
+	ingress.Routes = append(ingress.Routes, route)
+
+	ingress.Normalise()
+
+	return client.UpdateNetworkIngress(resource.name, ingress.Hostname, ingress.Writable(), etag)
+}
+
+func (c *cmdNetworkIngressRoute) CommandRemove() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remove", i18n.G("[<remote>:]<network> <hostname> [<path>] [<target_address>]"))
+	cmd.Short = i18n.G("Remove routes from an ingress")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Remove routes from an ingress"))
+	cmd.Flags().BoolVar(&c.flagRemoveForce, "force", false, i18n.G("Remove all routes that match"))
+	cmd.RunE = c.RunRemove
+
+	cmd.Flags().StringVar(&c.networkIngress.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkIngressRoute) RunRemove(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 4)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network name"))
+	}
+
+	if args[1] == "" {
+		return fmt.Errorf(i18n.G("Missing host name"))
+	}
+
+	client := resource.server
+
+	// If a target was specified, use the ingress on the given member.
+	if c.networkIngress.flagTarget != "" {
+		client = client.UseTarget(c.networkIngress.flagTarget)
+	}
+
+	// Get the network ingress.
+	ingress, etag, err := client.GetNetworkIngress(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	// Normalise the filter values the same way as the routes.
+	filter := api.NetworkIngressRoute{}
+	if len(args) > 2 {
+		filter.Path = args[2]
+	}
+
+	if len(args) > 3 {
+		filter.TargetAddress = args[3]
+	}
+
+	filter.Normalise()
+
+	// isFilterMatch returns whether the supplied route has matching field values in the filterArgs supplied.
+	// If no filterArgs are supplied, then the route is considered to have matched.
+	isFilterMatch := func(route *api.NetworkIngressRoute, filterArgs []string) bool {
+		switch len(filterArgs) {
+		case 3:
+			if route.TargetAddress != filter.TargetAddress {
+				return false
+			}
+
+			fallthrough
+		case 2:
+			if route.Path != filter.Path {
+				return false
+			}
+		}
+
+		return true // Match found as all struct fields match the supplied filter values.
+	}
+
+	// removeFromRoutes removes a single route that matches the filterArgs supplied. If multiple routes match then
+	// an error is returned unless c.flagRemoveForce is true, in which case all matching routes are removed.
+	removeFromRoutes := func(routes []api.NetworkIngressRoute, filterArgs []string) ([]api.NetworkIngressRoute, error) {
+		removed := false
+		newRoutes := make([]api.NetworkIngressRoute, 0, len(routes))
+
+		for _, route := range routes {
+			if isFilterMatch(&route, filterArgs) {
+				if removed && !c.flagRemoveForce {
+					return nil, fmt.Errorf(i18n.G("Multiple routes match. Use --force to remove them all"))
+				}
+
+				removed = true
+				continue // Don't add removed route to newRoutes.
+			}
+
+			newRoutes = append(newRoutes, route)
+		}
+
+		if !removed {
+			return nil, fmt.Errorf(i18n.G("No matching route(s) found"))
+		}
+
+		return newRoutes, nil
+	}
+
+	routes, err := removeFromRoutes(ingress.Routes, args[1:])
+	if err != nil {
+		return err
+	}
+
+	ingress.Routes = routes
+
+	ingress.Normalise()
+
+	return client.UpdateNetworkIngress(resource.name, ingress.Hostname, ingress.Writable(), etag)
+}
@@ -472,6 +472,7 @@ func (c *cmdStorageInfo) Run(cmd *cobra.Command, args []string) error {
 	descriptionstring := i18n.G("description")
 	totalspacestring := i18n.G("total space")
 	spaceusedstring := i18n.G("space used")
+	spaceprovisionedstring := i18n.G("space provisioned")
 
 	// Initialize the usedby map
 	poolusedby[usedbystring] = make(map[string][]string)
@@ -554,6 +555,14 @@ func (c *cmdStorageInfo) Run(cmd *cobra.Command, args []string) error {
 		poolinfo[infostring][spaceusedstring] = units.GetByteSizeStringIEC(int64(res.Space.Used), 2)
 	}
 
+	if res.Space.Provisioned > 0 {
+		if c.flagBytes {
+			poolinfo[infostring][spaceprovisionedstring] = strconv.FormatUint(res.Space.Provisioned, 10)
+		} else {
+			poolinfo[infostring][spaceprovisionedstring] = units.GetByteSizeStringIEC(int64(res.Space.Provisioned), 2)
+		}
+	}
+
 	poolinfodata, err := yaml.Marshal(poolinfo)
 	if err != nil {
 		return err
@@ -3,18 +3,24 @@ package main
 import (
 	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"path"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 
+	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	cli "github.com/canonical/lxd/shared/cmd"
 	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/ioprogress"
 	"github.com/canonical/lxd/shared/termios"
+	"github.com/canonical/lxd/shared/units"
 )
 
 type cmdStorageBucket struct {
@@ -40,10 +46,18 @@ func (c *cmdStorageBucket) Command() *cobra.Command {
 	storageBucketEditCmd := cmdStorageBucketEdit{global: c.global, storageBucket: c}
 	cmd.AddCommand(storageBucketEditCmd.Command())
 
+	// Export.
+	storageBucketExportCmd := cmdStorageBucketExport{global: c.global, storageBucket: c}
+	cmd.AddCommand(storageBucketExportCmd.Command())
+
 	// Get.
 	storageBucketGetCmd := cmdStorageBucketGet{global: c.global, storageBucket: c}
 	cmd.AddCommand(storageBucketGetCmd.Command())
 
+	// Import.
+	storageBucketImportCmd := cmdStorageBucketImport{global: c.global, storageBucket: c}
+	cmd.AddCommand(storageBucketImportCmd.Command())
+
 	// List.
 	storageBucketListCmd := cmdStorageBucketList{global: c.global, storageBucket: c}
 	cmd.AddCommand(storageBucketListCmd.Command())
@@ -362,6 +376,155 @@ func (c *cmdStorageBucketEdit) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Export.
+type cmdStorageBucketExport struct {
+	global        *cmdGlobal
+	storageBucket *cmdStorageBucket
+
+	flagCompressionAlgorithm string
+}
+
+func (c *cmdStorageBucketExport) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("export", i18n.G("[<remote>:]<pool> <bucket> [<path>]"))
+	cmd.Short = i18n.G("Export storage buckets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Export storage buckets including their objects, configuration and keys.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc storage bucket export default b1 b1.tar.gz
+    Export the objects, configuration and keys of bucket b1 into b1.tar.gz.`))
+
+	cmd.Flags().StringVar(&c.flagCompressionAlgorithm, "compression", "", i18n.G("Define a compression algorithm: for backup or none")+"``")
+	cmd.Flags().StringVar(&c.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdStorageBucketExport) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 3)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing pool name"))
+	}
+
+	if args[1] == "" {
+		return fmt.Errorf(i18n.G("Missing bucket name"))
+	}
+
+	client := resource.server
+
+	// If a target was specified, export the bucket on the given member.
+	if c.storageBucket.flagTarget != "" {
+		client = client.UseTarget(c.storageBucket.flagTarget)
+	}
+
+	req := api.StorageBucketBackupsPost{
+		Name:                 "",
+		ExpiresAt:            time.Now().Add(24 * time.Hour),
+		CompressionAlgorithm: c.flagCompressionAlgorithm,
+	}
+
+	op, err := client.CreateStoragePoolBucketBackup(resource.name, args[1], req)
+	if err != nil {
+		return fmt.Errorf("Failed to create storage bucket backup: %w", err)
+	}
+
+	// Watch the background operation.
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Backing up storage bucket: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	// Wait until backup is done.
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	err = op.Wait()
+	if err != nil {
+		return err
+	}
+
+	// Get name of backup.
+	uStr := op.Get().Resources["backups"][0]
+	u, err := url.Parse(uStr)
+	if err != nil {
+		return fmt.Errorf("Invalid URL %q: %w", uStr, err)
+	}
+
+	backupName, err := url.PathUnescape(path.Base(u.EscapedPath()))
+	if err != nil {
+		return fmt.Errorf("Invalid backup name segment in path %q: %w", u.EscapedPath(), err)
+	}
+
+	defer func() {
+		// Delete backup after we're done.
+		op, err = client.DeleteStoragePoolBucketBackup(resource.name, args[1], backupName)
+		if err == nil {
+			_ = op.Wait()
+		}
+	}()
+
+	var targetName string
+	if len(args) > 2 {
+		targetName = args[2]
+	} else {
+		targetName = "backup.tar.gz"
+	}
+
+	target, err := os.Create(shared.HostPathFollow(targetName))
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = target.Close() }()
+
+	// Prepare the download request.
+	progress = cli.ProgressRenderer{
+		Format: i18n.G("Exporting the backup: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	backupFileRequest := lxd.BackupFileRequest{
+		BackupFile:      io.WriteSeeker(target),
+		ProgressHandler: progress.UpdateProgress,
+	}
+
+	// Export tarball.
+	_, err = client.GetStoragePoolBucketBackupFile(resource.name, args[1], backupName, &backupFileRequest)
+	if err != nil {
+		_ = os.Remove(targetName)
+		progress.Done("")
+		return fmt.Errorf("Failed to fetch storage bucket backup file: %w", err)
+	}
+
+	progress.Done(i18n.G("Backup exported successfully!"))
+	return nil
+}
+
 // Get.
 type cmdStorageBucketGet struct {
 	global        *cmdGlobal
@@ -437,6 +600,109 @@ func (c *cmdStorageBucketGet) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Import.
+type cmdStorageBucketImport struct {
+	global        *cmdGlobal
+	storageBucket *cmdStorageBucket
+}
+
+func (c *cmdStorageBucketImport) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("import", i18n.G("[<remote>:]<pool> <backup file> [<bucket>]"))
+	cmd.Short = i18n.G("Import storage buckets")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Import backups of storage buckets including their objects, configuration and keys.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc storage bucket import default b1.tar.gz
+    Create a new storage bucket using b1.tar.gz as the source.
+
+lxc storage bucket import default b1.tar.gz b2
+    Create a new storage bucket named b2 using b1.tar.gz as the source.`))
+
+	cmd.Flags().StringVar(&c.storageBucket.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdStorageBucketImport) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 3)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing pool name"))
+	}
+
+	client := resource.server
+
+	// If a target was specified, import the bucket on the given member.
+	if c.storageBucket.flagTarget != "" {
+		client = client.UseTarget(c.storageBucket.flagTarget)
+	}
+
+	file, err := os.Open(shared.HostPathFollow(args[1]))
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	fstat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	bucketName := ""
+	if len(args) >= 3 {
+		bucketName = args[2]
+	}
+
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Importing storage bucket: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	createArgs := lxd.StoragePoolBucketBackupArgs{
+		BackupFile: &ioprogress.ProgressReader{
+			ReadCloser: file,
+			Tracker: &ioprogress.ProgressTracker{
+				Length: fstat.Size(),
+				Handler: func(percent int64, speed int64) {
+					progress.UpdateProgress(ioprogress.ProgressData{Text: fmt.Sprintf("%d%% (%s/s)", percent, units.GetByteSizeString(speed, 2))})
+				},
+			},
+		},
+		Name: bucketName,
+	}
+
+	op, err := client.CreateStoragePoolBucketFromBackup(resource.name, createArgs)
+	if err != nil {
+		return err
+	}
+
+	// Wait for operation to finish.
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	return nil
+}
+
 // List.
 type cmdStorageBucketList struct {
 	global        *cmdGlobal
@@ -300,6 +300,30 @@ func instancesExist(resources []remoteResource) error {
 	return nil
 }
 
+// sparkline renders the values as a line of block characters scaled between zero and the highest value.
+func sparkline(values []float64) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+
+	var peak float64
+	for _, value := range values {
+		if value > peak {
+			peak = value
+		}
+	}
+
+	var line strings.Builder
+	for _, value := range values {
+		level := 0
+		if peak > 0 && value > 0 {
+			level = int(value / peak * float64(len(blocks)-1))
+		}
+
+		line.WriteRune(blocks[level])
+	}
+
+	return line.String()
+}
+
 // structHasField checks if specified struct includes field with given name.
 func structHasField(typ reflect.Type, field string) bool {
 	var parent reflect.Type
@@ -82,3 +82,9 @@ func (s *utilsTestSuite) TestGetServerSupportedFilters() {
 	s.Equal([]string{"type=container"}, supportedFilters)
 	s.Equal([]string{"foo", "user.blah=a", "status=running,stopped"}, unsupportedFilters)
 }
+
+func (s *utilsTestSuite) TestSparkline() {
+	s.Equal("", sparkline(nil))
+	s.Equal("▁▁▁", sparkline([]float64{0, 0, 0}))
+	s.Equal("▁▄█▁", sparkline([]float64{0, 2, 4, -1}))
+}
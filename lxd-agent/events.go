@@ -144,8 +144,19 @@ func eventsProcess(event api.Event) {
 	// Attempt to perform the mount.
 	mntSource := fmt.Sprintf("lxd_%s", e.Name)
 
+	args := []string{"-t", "virtiofs", mntSource, e.Config["path"]}
+
+	if shared.IsTrue(e.Config["readonly"]) {
+		args = append(args, "-o", "ro")
+	}
+
+	// Map the files through the DAX window if one is configured.
+	if e.Config["virtiofs.dax"] != "" {
+		args = append(args, "-o", "dax")
+	}
+
 	_ = os.MkdirAll(e.Config["path"], 0755)
-	_, err = shared.RunCommand("mount", "-t", "virtiofs", mntSource, e.Config["path"])
+	_, err = shared.RunCommand("mount", args...)
 	if err != nil {
 		logger.Infof("Failed to mount hotplug %q (Type: %q) to %q", mntSource, "virtiofs", e.Config["path"])
 		return
@@ -313,6 +313,11 @@ func (c *cmdAgent) mountHostShares() {
 		args := []string{"-t", mount.FSType, mount.Source, mount.Target}
 
 		for _, opt := range mount.Options {
+			// Ignore the DAX mount option when falling back to 9p as it is specific to virtio-fs.
+			if mount.FSType == "9p" && opt == "dax" {
+				continue
+			}
+
 			args = append(args, "-o", opt)
 		}
 
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/migration"
+)
+
+// blockTrackerBlockSize is the size of the blocks of a disk tracked between transfer passes.
+const blockTrackerBlockSize = 1024 * 1024
+
+// blockTrackerState is the persisted state of a blockTracker.
+type blockTrackerState struct {
+	BlockSize int64
+	Hashes    [][sha256.Size]byte
+
+	// Set while a pass is running. The target content isn't known if the migration stopped during a pass.
+	Pending bool
+}
+
+// blockTracker tracks the blocks of a disk that changed since the last completed transfer pass.
+// The hashes of the blocks are persisted to a state file after each completed pass so that an interrupted
+// migration can be resumed by only sending the blocks that changed since then.
+type blockTracker struct {
+	statePath string
+	hashes    [][sha256.Size]byte
+	next      [][sha256.Size]byte
+
+	// Indexes of the blocks written to the target during the last pass.
+	written []int
+
+	// Number of bytes sent during the last pass.
+	sent int64
+}
+
+// blockTrackerStatePath returns the path of the block tracking state for an instance on the target server.
+func blockTrackerStatePath(server lxd.InstanceServer, projectName string, instanceName string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	info, err := server.GetConnectionInfo()
+	if err != nil {
+		return "", err
+	}
+
+	key := sha256.Sum256([]byte(info.URL + "/" + projectName + "/" + instanceName))
+
+	return filepath.Join(cacheDir, "lxd-migrate", fmt.Sprintf("%x.blocks", key)), nil
+}
+
+// newBlockTracker returns a blockTracker, loading the hashes of a previous migration from statePath if any.
+func newBlockTracker(statePath string) (*blockTracker, error) {
+	t := &blockTracker{statePath: statePath}
+
+	f, err := os.Open(statePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return t, nil
+		}
+
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	state := blockTrackerState{}
+	err = gob.NewDecoder(f).Decode(&state)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading block tracking state %q: %w", statePath, err)
+	}
+
+	// Ignore state recorded with a different block size or during a pass that never finished.
+	if state.BlockSize == blockTrackerBlockSize && !state.Pending {
+		t.hashes = state.Hashes
+	}
+
+	return t, nil
+}
+
+// canDiff returns whether the blocks sent in a previous pass are known.
+func (t *blockTracker) canDiff() bool {
+	return len(t.hashes) > 0
+}
+
+// send reads the disk from r and writes it to w. If diff is true, only the blocks that changed since the
+// last completed pass are written as a block diff stream, otherwise the whole disk is written.
+func (t *blockTracker) send(w io.Writer, r io.Reader, diff bool) error {
+	buf := make([]byte, blockTrackerBlockSize)
+
+	t.next = nil
+	t.written = nil
+	t.sent = 0
+
+	// Don't trust the recorded hashes if the migration stops before the end of the pass.
+	err := t.save(true)
+	if err != nil {
+		return fmt.Errorf("Failed saving block tracking state: %w", err)
+	}
+
+	for i := 0; ; i++ {
+		n, err := io.ReadFull(r, buf)
+		if n == 0 {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+
+		block := buf[:n]
+		hash := sha256.Sum256(block)
+		t.next = append(t.next, hash)
+
+		if !diff {
+			t.written = append(t.written, i)
+
+			_, err = w.Write(block)
+			if err != nil {
+				return err
+			}
+
+			t.sent += int64(n)
+			continue
+		}
+
+		if i < len(t.hashes) && t.hashes[i] == hash {
+			continue
+		}
+
+		t.written = append(t.written, i)
+
+		err = migration.WriteBlockDiff(w, int64(i)*blockTrackerBlockSize, block)
+		if err != nil {
+			return err
+		}
+
+		t.sent += int64(n)
+	}
+}
+
+// commit records the blocks sent during the last pass as known by the target and persists them.
+func (t *blockTracker) commit() error {
+	t.hashes = t.next
+	t.next = nil
+	t.written = nil
+
+	return t.save(false)
+}
+
+// invalidate forgets the blocks written to the target during the last pass and persists the remaining ones.
+// It must be called when a pass failed as the target may have applied only some of those blocks.
+func (t *blockTracker) invalidate() error {
+	for _, i := range t.written {
+		if i < len(t.hashes) {
+			t.hashes[i] = [sha256.Size]byte{}
+		}
+	}
+
+	t.next = nil
+	t.written = nil
+
+	return t.save(false)
+}
+
+// save persists the hashes of the blocks known by the target.
+func (t *blockTracker) save(pending bool) error {
+	err := os.MkdirAll(filepath.Dir(t.statePath), 0700)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(t.statePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	err = gob.NewEncoder(f).Encode(blockTrackerState{BlockSize: blockTrackerBlockSize, Hashes: t.hashes, Pending: pending})
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// remove deletes the persisted state.
+func (t *blockTracker) remove() {
+	_ = os.Remove(t.statePath)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/migration"
+)
+
+// memoryWriterAt is an in-memory io.WriterAt standing for the disk of the target.
+type memoryWriterAt struct {
+	data []byte
+}
+
+func (m *memoryWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	end := int(off) + len(p)
+	if end > len(m.data) {
+		m.data = append(m.data, make([]byte, end-len(m.data))...)
+	}
+
+	copy(m.data[off:], p)
+
+	return len(p), nil
+}
+
+// failingWriter fails once more than limit bytes were written.
+type failingWriter struct {
+	w     *bytes.Buffer
+	limit int
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if f.w.Len()+len(p) > f.limit {
+		return 0, errors.New("Connection lost")
+	}
+
+	return f.w.Write(p)
+}
+
+// newTestDisk returns a disk of the given number of blocks, each filled with the matching byte of content.
+func newTestDisk(content string) []byte {
+	disk := make([]byte, 0, len(content)*blockTrackerBlockSize)
+	for _, c := range []byte(content) {
+		disk = append(disk, bytes.Repeat([]byte{c}, blockTrackerBlockSize)...)
+	}
+
+	return disk
+}
+
+// sendPass runs a transfer pass of source to target, applying the block diff if the tracker can diff.
+func sendPass(t *testing.T, tracker *blockTracker, source []byte, target *memoryWriterAt) {
+	diff := tracker.canDiff()
+
+	stream := &bytes.Buffer{}
+	require.NoError(t, tracker.send(stream, bytes.NewReader(source), diff))
+
+	if diff {
+		_, err := migration.ApplyBlockDiff(stream, target)
+		require.NoError(t, err)
+	} else {
+		target.data = stream.Bytes()
+	}
+
+	require.NoError(t, tracker.commit())
+}
+
+func TestBlockTracker(t *testing.T) {
+	tests := []struct {
+		name   string
+		passes []string
+		sent   []int64
+	}{
+		{
+			name:   "Single pass",
+			passes: []string{"abcd"},
+			sent:   []int64{4},
+		},
+		{
+			name:   "Unchanged disk",
+			passes: []string{"abcd", "abcd"},
+			sent:   []int64{4, 0},
+		},
+		{
+			name:   "Changed blocks",
+			passes: []string{"abcd", "abxd", "zbxy"},
+			sent:   []int64{4, 1, 2},
+		},
+		{
+			name:   "Grown disk",
+			passes: []string{"ab", "abcd"},
+			sent:   []int64{2, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, err := newBlockTracker(filepath.Join(t.TempDir(), "state"))
+			require.NoError(t, err)
+			assert.False(t, tracker.canDiff())
+
+			target := &memoryWriterAt{}
+			for i, pass := range tt.passes {
+				source := newTestDisk(pass)
+				sendPass(t, tracker, source, target)
+
+				assert.Equal(t, tt.sent[i]*blockTrackerBlockSize, tracker.sent)
+				assert.Equal(t, source, target.data)
+			}
+		})
+	}
+}
+
+func TestBlockTrackerResume(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state")
+
+	tracker, err := newBlockTracker(statePath)
+	require.NoError(t, err)
+
+	target := &memoryWriterAt{}
+	sendPass(t, tracker, newTestDisk("abcd"), target)
+
+	// A new tracker resumes from the persisted state.
+	tracker, err = newBlockTracker(statePath)
+	require.NoError(t, err)
+	require.True(t, tracker.canDiff())
+
+	source := newTestDisk("abxd")
+	sendPass(t, tracker, source, target)
+	assert.Equal(t, int64(blockTrackerBlockSize), tracker.sent)
+	assert.Equal(t, source, target.data)
+}
+
+func TestBlockTrackerFailedPass(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state")
+
+	tracker, err := newBlockTracker(statePath)
+	require.NoError(t, err)
+
+	target := &memoryWriterAt{}
+	sendPass(t, tracker, newTestDisk("abcd"), target)
+
+	// The pass fails while sending the second changed block, after the first one was applied.
+	stream := &bytes.Buffer{}
+	err = tracker.send(&failingWriter{w: stream, limit: 2*blockTrackerBlockSize + 16}, bytes.NewReader(newTestDisk("xycd")), true)
+	require.Error(t, err)
+
+	_, err = migration.ApplyBlockDiff(stream, target)
+	require.Error(t, err)
+
+	// The migration stopped during the pass, so the state can't be trusted.
+	stopped, err := newBlockTracker(statePath)
+	require.NoError(t, err)
+	assert.False(t, stopped.canDiff())
+
+	// After invalidating the failed pass, the blocks it wrote are sent again even if the source reverted.
+	require.NoError(t, tracker.invalidate())
+
+	tracker, err = newBlockTracker(statePath)
+	require.NoError(t, err)
+	require.True(t, tracker.canDiff())
+
+	source := newTestDisk("abcd")
+	sendPass(t, tracker, source, target)
+	assert.Equal(t, int64(2*blockTrackerBlockSize), tracker.sent)
+	assert.Equal(t, source, target.data)
+}
+
+func TestBlockTrackerRemove(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state")
+
+	tracker, err := newBlockTracker(statePath)
+	require.NoError(t, err)
+
+	sendPass(t, tracker, newTestDisk("ab"), &memoryWriterAt{})
+	assert.FileExists(t, statePath)
+
+	tracker.remove()
+	assert.NoFileExists(t, statePath)
+
+	tracker, err = newBlockTracker(statePath)
+	require.NoError(t, err)
+	assert.False(t, tracker.canDiff())
+}
@@ -12,6 +12,7 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/sys/unix"
@@ -30,7 +31,9 @@ import (
 type cmdMigrate struct {
 	global *cmdGlobal
 
-	flagRsyncArgs string
+	flagRsyncArgs      string
+	flagSyncPasses     int
+	flagCutoverCommand string
 }
 
 func (c *cmdMigrate) command() *cobra.Command {
@@ -48,18 +51,32 @@ func (c *cmdMigrate) command() *cobra.Command {
   API to create a new instance from it.
 
   The same set of options as ` + "`lxc launch`" + ` are also supported.
+
+  To keep the downtime of the source short, the transfer can be done in
+  multiple passes. The given number of synchronization passes are run while
+  the source is still running, only transferring what changed since the
+  previous pass. The cutover command (or the user) then stops the services
+  on the source before a final pass transfers the remaining changes.
+
+  If a migration is interrupted after its first pass, running the tool again
+  with the same instance name resumes the transfer into the existing instance.
 `
 	cmd.RunE = c.run
 	cmd.Flags().StringVar(&c.flagRsyncArgs, "rsync-args", "", "Extra arguments to pass to rsync"+"``")
+	cmd.Flags().IntVar(&c.flagSyncPasses, "sync-passes", 0, "Number of synchronization passes to run while the source is running, before the final pass"+"``")
+	cmd.Flags().StringVar(&c.flagCutoverCommand, "cutover-command", "", "Command to run on the source before the final pass, for example to stop its services"+"``")
 
 	return cmd
 }
 
 type cmdMigrateData struct {
 	SourcePath   string
+	SourceFormat string
+	ConvertPath  string
 	Mounts       []string
 	InstanceArgs api.InstancesPost
 	Project      string
+	Resume       bool
 }
 
 func (c *cmdMigrateData) render() string {
@@ -68,6 +85,8 @@ func (c *cmdMigrateData) render() string {
 		Project     string            `yaml:"Project"`
 		Type        api.InstanceType  `yaml:"Type"`
 		Source      string            `yaml:"Source"`
+		Format      string            `yaml:"Source format,omitempty"`
+		Resume      bool              `yaml:"Resume transfer,omitempty"`
 		Mounts      []string          `yaml:"Mounts,omitempty"`
 		Profiles    []string          `yaml:"Profiles,omitempty"`
 		StoragePool string            `yaml:"Storage pool,omitempty"`
@@ -79,6 +98,8 @@ func (c *cmdMigrateData) render() string {
 		c.Project,
 		c.InstanceArgs.Type,
 		c.SourcePath,
+		c.SourceFormat,
+		c.Resume,
 		c.Mounts,
 		c.InstanceArgs.Profiles,
 		"",
@@ -298,8 +319,26 @@ func (c *cmdMigrate) runInteractive(server lxd.InstanceServer) (cmdMigrateData,
 		}
 
 		if shared.ValueInSlice(instanceName, instanceNames) {
-			fmt.Printf("Instance %q already exists\n", instanceName)
-			continue
+			inst, _, err := server.UseProject(config.Project).GetInstance(instanceName)
+			if err != nil {
+				return cmdMigrateData{}, err
+			}
+
+			if inst.Type != string(config.InstanceArgs.Type) {
+				fmt.Printf("Instance %q already exists\n", instanceName)
+				continue
+			}
+
+			resume, err := c.global.asker.AskBool(fmt.Sprintf("Instance %q already exists, would you like to resume transferring to it? [default=no]: ", instanceName), "no")
+			if err != nil {
+				return cmdMigrateData{}, err
+			}
+
+			if !resume {
+				continue
+			}
+
+			config.Resume = true
 		}
 
 		config.InstanceArgs.Name = instanceName
@@ -331,6 +370,36 @@ func (c *cmdMigrate) runInteractive(server lxd.InstanceServer) (cmdMigrateData,
 		return cmdMigrateData{}, err
 	}
 
+	// Detect disk images that need converting to raw.
+	if config.InstanceArgs.Type == api.InstanceTypeVM {
+		config.SourceFormat, err = diskImageFormat(config.SourcePath)
+		if err != nil {
+			return cmdMigrateData{}, err
+		}
+
+		if config.SourceFormat != "" && config.SourceFormat != "raw" {
+			convert, err := c.global.asker.AskBool(fmt.Sprintf("The disk image is in %s format, would you like to convert it to raw? [default=yes]: ", config.SourceFormat), "yes")
+			if err != nil {
+				return cmdMigrateData{}, err
+			}
+
+			if !convert {
+				return cmdMigrateData{}, fmt.Errorf("Disk images in %s format must be converted to raw", config.SourceFormat)
+			}
+
+			config.ConvertPath, err = c.global.asker.AskString(fmt.Sprintf("Please provide a directory to store the converted image [default=%s]: ", os.TempDir()), os.TempDir(), func(s string) error {
+				if !shared.IsDir(s) {
+					return errors.New("Path is not a directory")
+				}
+
+				return nil
+			})
+			if err != nil {
+				return cmdMigrateData{}, err
+			}
+		}
+	}
+
 	if config.InstanceArgs.Type == api.InstanceTypeVM {
 		architectureName, _ := osarch.ArchitectureGetLocal()
 
@@ -436,6 +505,10 @@ func (c *cmdMigrate) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if c.flagSyncPasses < 0 {
+		return fmt.Errorf("Invalid number of synchronization passes %d", c.flagSyncPasses)
+	}
+
 	// Server
 	server, clientFingerprint, err := c.askServer()
 	if err != nil {
@@ -474,6 +547,30 @@ func (c *cmdMigrate) run(cmd *cobra.Command, args []string) error {
 		server = server.UseProject(config.Project)
 	}
 
+	// Convert the disk image to raw.
+	if config.ConvertPath != "" {
+		if c.flagSyncPasses > 0 {
+			return fmt.Errorf("Synchronization passes aren't supported when converting the disk image")
+		}
+
+		convertDir, err := os.MkdirTemp(config.ConvertPath, "lxd-migrate_image_")
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = os.RemoveAll(convertDir) }()
+
+		rawPath := filepath.Join(convertDir, "root.img")
+
+		fmt.Printf("Converting the disk image from %s to raw\n", config.SourceFormat)
+		err = convertDiskImage(ctx, config.SourcePath, config.SourceFormat, rawPath)
+		if err != nil {
+			return fmt.Errorf("Failed converting the disk image: %w", err)
+		}
+
+		config.SourcePath = rawPath
+	}
+
 	config.Mounts = append(config.Mounts, config.SourcePath)
 
 	// Get and sort the mounts
@@ -553,33 +650,144 @@ func (c *cmdMigrate) run(cmd *cobra.Command, args []string) error {
 
 	config.InstanceArgs.Architecture = architectureName
 
+	// Track the blocks sent in previous passes (and previous runs when resuming) for virtual machines.
+	var blocks *blockTracker
+	if config.InstanceArgs.Type == api.InstanceTypeVM {
+		statePath, err := blockTrackerStatePath(server, config.Project, config.InstanceArgs.Name)
+		if err != nil {
+			return err
+		}
+
+		// Don't reuse the state of another migration to an instance with the same name.
+		if !config.Resume {
+			_ = os.Remove(statePath)
+		}
+
+		blocks, err = newBlockTracker(statePath)
+		if err != nil {
+			return err
+		}
+	}
+
 	revert := revert.New()
 	defer revert.Fail()
 
-	// Create the instance
-	op, err := server.CreateInstance(config.InstanceArgs)
-	if err != nil {
-		return err
+	passes := c.flagSyncPasses + 1
+	for pass := 1; pass <= passes; pass++ {
+		live := pass < passes
+
+		// Stop the source services before the final pass.
+		if pass > 1 && !live {
+			err = c.cutover()
+			if err != nil {
+				return err
+			}
+		}
+
+		// Create the instance, or refresh it if it already exists.
+		args := config.InstanceArgs
+		args.Source.Refresh = pass > 1 || config.Resume
+
+		op, err := server.CreateInstance(args)
+		if err != nil {
+			return err
+		}
+
+		if !args.Source.Refresh {
+			revert.Add(func() {
+				_, _ = server.DeleteInstance(config.InstanceArgs.Name)
+			})
+		}
+
+		format := "Transferring instance: %s"
+		if passes > 1 {
+			format = fmt.Sprintf("Transferring instance (pass %d/%d): %%s", pass, passes)
+		}
+
+		progress := cli.ProgressRenderer{Format: format}
+		_, err = op.AddHandler(progress.UpdateOp)
+		if err != nil {
+			progress.Done("")
+			return err
+		}
+
+		start := time.Now()
+
+		err = transferRootfs(ctx, server, op, fullPath, c.flagRsyncArgs, config.InstanceArgs.Type, live, blocks)
+		if err != nil {
+			progress.Done("")
+
+			// The blocks sent during the failed pass may have been partially written by the target.
+			// Fall back to a full transfer when resuming if the remaining blocks can't be recorded.
+			if blocks != nil && blocks.invalidate() != nil {
+				blocks.remove()
+			}
+
+			// The target instance is kept once a pass completed so that the transfer can be resumed.
+			if args.Source.Refresh {
+				return fmt.Errorf("%w\nThe instance %q was kept, run lxd-migrate again with the same instance name to resume the transfer", err, config.InstanceArgs.Name)
+			}
+
+			return err
+		}
+
+		revert.Success()
+
+		duration := time.Since(start).Round(time.Second)
+		msg := fmt.Sprintf("Pass %d/%d completed in %s", pass, passes, duration)
+		if blocks != nil {
+			msg = fmt.Sprintf("%s (sent %s of disk data", msg, units.GetByteSizeStringIEC(blocks.sent, 2))
+			if duration > 0 {
+				msg = fmt.Sprintf("%s at %s/s", msg, units.GetByteSizeStringIEC(blocks.sent/int64(duration.Seconds()), 2))
+			}
+
+			msg += ")"
+		}
+
+		if passes > 1 {
+			progress.Done(msg)
+		} else {
+			progress.Done(fmt.Sprintf("Instance %s successfully created", config.InstanceArgs.Name))
+		}
 	}
 
-	revert.Add(func() {
-		_, _ = server.DeleteInstance(config.InstanceArgs.Name)
-	})
+	if blocks != nil {
+		blocks.remove()
+	}
 
-	progress := cli.ProgressRenderer{Format: "Transferring instance: %s"}
-	_, err = op.AddHandler(progress.UpdateOp)
-	if err != nil {
-		progress.Done("")
-		return err
+	if passes > 1 {
+		fmt.Printf("Instance %s successfully created\n", config.InstanceArgs.Name)
 	}
 
-	err = transferRootfs(ctx, server, op, fullPath, c.flagRsyncArgs, config.InstanceArgs.Type)
-	if err != nil {
-		return err
+	return nil
+}
+
+// cutover stops the services of the source before the final transfer pass, either by running the
+// cutover command or by waiting for the user to do so.
+func (c *cmdMigrate) cutover() error {
+	if c.flagCutoverCommand == "" {
+		ready, err := c.global.asker.AskBool("Please stop the services on the source, ready to run the final pass? [default=yes]: ", "yes")
+		if err != nil {
+			return err
+		}
+
+		if !ready {
+			return fmt.Errorf("Migration aborted before the final pass")
+		}
+
+		return nil
 	}
 
-	progress.Done(fmt.Sprintf("Instance %s successfully created", config.InstanceArgs.Name))
-	revert.Success()
+	fmt.Printf("Running cutover command %q\n", c.flagCutoverCommand)
+
+	cmd := exec.Command("sh", "-c", c.flagCutoverCommand)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("Failed running cutover command: %w", err)
+	}
 
 	return nil
 }
@@ -21,7 +21,8 @@ import (
 )
 
 // Send an rsync stream of a path over a websocket.
-func rsyncSend(ctx context.Context, conn *websocket.Conn, path string, rsyncArgs string, instanceType api.InstanceType) error {
+// If live is true, files vanishing during the transfer aren't considered an error.
+func rsyncSend(ctx context.Context, conn *websocket.Conn, path string, rsyncArgs string, instanceType api.InstanceType, live bool) error {
 	cmd, dataSocket, stderr, err := rsyncSendSetup(ctx, path, rsyncArgs, instanceType)
 	if err != nil {
 		return err
@@ -45,6 +46,12 @@ func rsyncSend(ctx context.Context, conn *websocket.Conn, path string, rsyncArgs
 	err = cmd.Wait()
 	<-readDone
 
+	// Exit status 24 means that some source files vanished, which is expected while the source is running.
+	status, _ := shared.ExitStatus(err)
+	if live && status == 24 {
+		return nil
+	}
+
 	if err != nil {
 		return fmt.Errorf("Failed to rsync: %v\n%s", err, output)
 	}
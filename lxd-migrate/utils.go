@@ -4,17 +4,19 @@ import (
 	"bufio"
 	"context"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
-	"io"
 	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"reflect"
 	"strings"
 
 	"golang.org/x/sys/unix"
 	"golang.org/x/term"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/migration"
@@ -24,7 +26,10 @@ import (
 	"github.com/canonical/lxd/shared/ws"
 )
 
-func transferRootfs(ctx context.Context, dst lxd.InstanceServer, op lxd.Operation, rootfs string, rsyncArgs string, instanceType api.InstanceType) error {
+// transferRootfs runs a single transfer pass of the source to the target instance.
+// If live is true the source is still running, so files vanishing during the transfer are expected.
+// For virtual machines, blocks tracks the blocks of the disk that were sent in previous passes.
+func transferRootfs(ctx context.Context, dst lxd.InstanceServer, op lxd.Operation, rootfs string, rsyncArgs string, instanceType api.InstanceType, live bool, blocks *blockTracker) error {
 	opAPI := op.Get()
 
 	// Connect to the websockets
@@ -73,6 +78,11 @@ func transferRootfs(ctx context.Context, dst lxd.InstanceServer, op lxd.Operatio
 		size := stat.Size()
 		offerHeader.VolumeSize = &size
 		rootfs = shared.AddSlash(rootfs)
+
+		// Only send the changed blocks if the target already has the disk from a previous pass.
+		if blocks.canDiff() {
+			offerHeader.BlockDiff = proto.Bool(true)
+		}
 	}
 
 	err = migration.ProtoSend(wsControl, &offerHeader)
@@ -94,7 +104,7 @@ func transferRootfs(ctx context.Context, dst lxd.InstanceServer, op lxd.Operatio
 	}
 
 	// Send the filesystem
-	err = rsyncSend(ctx, wsFs, rootfs, rsyncArgs, instanceType)
+	err = rsyncSend(ctx, wsFs, rootfs, rsyncArgs, instanceType, live)
 	if err != nil {
 		return abort(fmt.Errorf("Failed sending filesystem volume: %w", err))
 	}
@@ -116,7 +126,7 @@ func transferRootfs(ctx context.Context, dst lxd.InstanceServer, op lxd.Operatio
 			_ = f.Close()
 		}()
 
-		_, err = io.Copy(conn, f)
+		err = blocks.send(conn, f, respHeader.GetBlockDiff())
 		if err != nil {
 			return abort(fmt.Errorf("Failed sending block volume: %w", err))
 		}
@@ -139,6 +149,14 @@ func transferRootfs(ctx context.Context, dst lxd.InstanceServer, op lxd.Operatio
 		return fmt.Errorf(msg.GetMessage())
 	}
 
+	// Record the blocks now known by the target.
+	if instanceType == api.InstanceTypeVM {
+		err = blocks.commit()
+		if err != nil {
+			return fmt.Errorf("Failed saving block tracking state: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -298,6 +316,49 @@ func (c *cmdMigrate) connectTarget(url string, certPath string, keyPath string,
 	return instanceServer, clientFingerprint, nil
 }
 
+// diskImageFormat returns the format of the disk image at path as detected by qemu-img.
+// An empty string is returned if path isn't a regular file or if qemu-img isn't available.
+func diskImageFormat(path string) (string, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	if !stat.Mode().IsRegular() {
+		return "", nil
+	}
+
+	_, err = exec.LookPath("qemu-img")
+	if err != nil {
+		return "", nil
+	}
+
+	out, err := shared.RunCommand("qemu-img", "info", "--output=json", path)
+	if err != nil {
+		return "", fmt.Errorf("Failed detecting the disk image format: %w", err)
+	}
+
+	info := struct {
+		Format string `json:"format"`
+	}{}
+
+	err = json.Unmarshal([]byte(out), &info)
+	if err != nil {
+		return "", fmt.Errorf("Failed parsing the disk image information: %w", err)
+	}
+
+	return info.Format, nil
+}
+
+// convertDiskImage converts the disk image at path from the given format to a raw image at target.
+func convertDiskImage(ctx context.Context, path string, format string, target string) error {
+	cmd := exec.CommandContext(ctx, "qemu-img", "convert", "-p", "-f", format, "-O", "raw", path, target)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
 func setupSource(path string, mounts []string) error {
 	prefix := "/"
 	if len(mounts) > 0 {
@@ -1,6 +1,7 @@
 package acme
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -13,6 +14,7 @@ import (
 	"github.com/go-acme/lego/v4/acme"
 	"github.com/go-acme/lego/v4/certcrypto"
 	"github.com/go-acme/lego/v4/certificate"
+	"github.com/go-acme/lego/v4/challenge"
 	"github.com/go-acme/lego/v4/lego"
 	"github.com/go-acme/lego/v4/registration"
 
@@ -32,9 +34,9 @@ const retries = 5
 // certificate at a later stage.
 const ClusterCertFilename = "cluster.crt.new"
 
-// certificateNeedsUpdate returns true if the domain doesn't match the certificate's DNS names
+// CertificateNeedsUpdate returns true if the domain doesn't match the certificate's DNS names
 // or it's valid for less than 30 days.
-func certificateNeedsUpdate(domain string, cert *x509.Certificate) bool {
+func CertificateNeedsUpdate(domain string, cert *x509.Certificate) bool {
 	return !shared.ValueInSlice(domain, cert.DNSNames) || time.Now().After(cert.NotAfter.Add(-30*24*time.Hour))
 }
 
@@ -70,7 +72,7 @@ func UpdateCertificate(s *state.State, provider HTTP01Provider, clustered bool,
 			return nil, fmt.Errorf("Failed to parse certificate: %w", err)
 		}
 
-		if !certificateNeedsUpdate(domain, cert) {
+		if !CertificateNeedsUpdate(domain, cert) {
 			return &certificate.Resource{
 				Certificate: clusterCert,
 				PrivateKey:  key,
@@ -93,20 +95,34 @@ func UpdateCertificate(s *state.State, provider HTTP01Provider, clustered bool,
 		return nil, fmt.Errorf("Failed to parse certificate: %w", err)
 	}
 
-	if !force && !certificateNeedsUpdate(domain, cert) {
+	if !force && !CertificateNeedsUpdate(domain, cert) {
 		l.Debug("Skipping certificate renewal as it is still valid for more than 30 days")
 		return nil, nil
 	}
 
+	return obtainCertificate(l, provider, domain, email, caURL, certcrypto.RSA2048, certInfo.KeyPair().PrivateKey)
+}
+
+// IssueCertificate obtains a new certificate for the domain using a newly generated private key.
+// The HTTP-01 challenges are answered by the provider.
+func IssueCertificate(provider challenge.Provider, domain string, email string, caURL string) (*certificate.Resource, error) {
+	l := logger.AddContext(logger.Ctx{"domain": domain, "caURL": caURL})
+
+	return obtainCertificate(l, provider, domain, email, caURL, certcrypto.EC256, nil)
+}
+
+// obtainCertificate registers a new account and obtains a certificate for the domain.
+// If privateKey is nil, a new key of type keyType is generated for the certificate.
+func obtainCertificate(l logger.Logger, provider challenge.Provider, domain string, email string, caURL string, keyType certcrypto.KeyType, privateKey crypto.PrivateKey) (*certificate.Resource, error) {
 	// Generate new private key for user. This key needs to be different from the server's private key.
-	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	accountKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
 		return nil, fmt.Errorf("Failed generating private key for user account: %w", err)
 	}
 
 	user := user{
 		Email: email,
-		Key:   privateKey,
+		Key:   accountKey,
 	}
 
 	config := lego.NewConfig(&user)
@@ -118,7 +134,7 @@ func UpdateCertificate(s *state.State, provider HTTP01Provider, clustered bool,
 		config.CADirURL = "https://acme-v02.api.letsencrypt.org/directory"
 	}
 
-	config.Certificate.KeyType = certcrypto.RSA2048
+	config.Certificate.KeyType = keyType
 
 	client, err := lego.NewClient(config)
 	if err != nil {
@@ -158,7 +174,7 @@ func UpdateCertificate(s *state.State, provider HTTP01Provider, clustered bool,
 	request := certificate.ObtainRequest{
 		Domains:    []string{domain},
 		Bundle:     true,
-		PrivateKey: certInfo.KeyPair().PrivateKey,
+		PrivateKey: privateKey,
 	}
 
 	var certificates *certificate.Resource
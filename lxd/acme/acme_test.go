@@ -8,7 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func Test_certificateNeedsUpdate(t *testing.T) {
+func Test_CertificateNeedsUpdate(t *testing.T) {
 	type args struct {
 		domain string
 		cert   *x509.Certificate
@@ -67,7 +67,7 @@ func Test_certificateNeedsUpdate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			needsUpdate := certificateNeedsUpdate(tt.args.domain, tt.args.cert)
+			needsUpdate := CertificateNeedsUpdate(tt.args.domain, tt.args.cert)
 			require.Equal(t, needsUpdate, tt.want)
 		})
 	}
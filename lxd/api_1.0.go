@@ -49,6 +49,7 @@ var api10 = []APIEndpoint{
 	clusterNodeStateCmd,
 	clusterNodesCmd,
 	clusterCertificateCmd,
+	clusterDevicesCmd,
 	instanceBackupCmd,
 	instanceBackupExportCmd,
 	instanceBackupsCmd,
@@ -68,6 +69,9 @@ var api10 = []APIEndpoint{
 	instanceSnapshotCmd,
 	instanceSnapshotsCmd,
 	instanceStateCmd,
+	instanceStateHistoryCmd,
+	instanceTemplateCmd,
+	instanceTemplatesCmd,
 	instanceUEFIVarsCmd,
 	eventsCmd,
 	imageAliasCmd,
@@ -88,6 +92,9 @@ var api10 = []APIEndpoint{
 	networkAllocationsCmd,
 	networkForwardCmd,
 	networkForwardsCmd,
+	networkIngressCmd,
+	networkIngressesCmd,
+	networkIngressStateCmd,
 	networkLoadBalancerCmd,
 	networkLoadBalancersCmd,
 	networkPeerCmd,
@@ -112,6 +119,9 @@ var api10 = []APIEndpoint{
 	storagePoolBucketCmd,
 	storagePoolBucketKeysCmd,
 	storagePoolBucketKeyCmd,
+	storagePoolBucketBackupsCmd,
+	storagePoolBucketBackupCmd,
+	storagePoolBucketBackupExportCmd,
 	storagePoolVolumesCmd,
 	storagePoolVolumeSnapshotsTypeCmd,
 	storagePoolVolumeSnapshotTypeCmd,
@@ -325,6 +335,7 @@ func api10Get(d *Daemon, r *http.Request) response.Response {
 		"seccomp_listener":          fmt.Sprintf("%v", s.OS.SeccompListener),
 		"seccomp_listener_continue": fmt.Sprintf("%v", s.OS.SeccompListenerContinue),
 		"idmapped_mounts":           fmt.Sprintf("%v", s.OS.IdmappedMounts),
+		"checkpoint_restore":        fmt.Sprintf("%v", s.OS.CheckpointRestore),
 	}
 
 	drivers := instanceDrivers.DriverStatuses()
@@ -3252,7 +3252,8 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 
 	metadata := make(map[string]any)
 
-	for _, inst := range opts.instances {
+	// Move the instances depending on others out of the way first.
+	for _, inst := range instancesSortByDependencies(opts.instances, true) {
 		instProject := inst.Project()
 		l := logger.AddContext(logger.Ctx{"project": instProject.Name, "instance": inst.Name()})
 
@@ -3303,9 +3304,21 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 				return err
 			}
 
+			// Only keep the members having free devices matching the passthrough devices of the instance.
+			candidateMembers, err = clusterDevicesFilterMembers(ctx, tx, candidateMembers, inst.Type(), inst.ExpandedDevices().CloneNative())
+			if err != nil {
+				return err
+			}
+
 			return nil
 		})
 		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				// Skip migration if no member has the devices needed by the instance.
+				l.Warn("No migration target with the instance devices available for instance")
+				continue
+			}
+
 			return err
 		}
 
@@ -3403,8 +3416,8 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 
 		metadata := make(map[string]any)
 
-		// Restart the local instances.
-		for _, inst := range localInstances {
+		// Restart the local instances, after the instances they depend on.
+		for _, inst := range instancesSortByDependencies(localInstances, false) {
 			// Don't start instances which were stopped by the user.
 			if inst.LocalConfig()["volatile.last_state.power"] != instance.PowerStateRunning {
 				continue
@@ -3419,14 +3432,19 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 			metadata["evacuation_progress"] = fmt.Sprintf("Starting %q in project %q", inst.Name(), inst.Project().Name)
 			_ = op.UpdateMetadata(metadata)
 
+			err = instanceDependenciesWait(context.TODO(), s, inst)
+			if err != nil {
+				return err
+			}
+
 			err = inst.Start(false)
 			if err != nil {
 				return fmt.Errorf("Failed to start instance %q: %w", inst.Name(), err)
 			}
 		}
 
-		// Migrate back the remote instances.
-		for _, inst := range instances {
+		// Migrate back the remote instances, after the instances they depend on.
+		for _, inst := range instancesSortByDependencies(instances, false) {
 			l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
 			// Check if live-migratable.
@@ -3546,6 +3564,11 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 			metadata["evacuation_progress"] = fmt.Sprintf("Starting %q in project %q", inst.Name(), inst.Project().Name)
 			_ = op.UpdateMetadata(metadata)
 
+			err = instanceDependenciesWait(context.TODO(), s, inst)
+			if err != nil {
+				return err
+			}
+
 			err = inst.Start(false)
 			if err != nil {
 				return fmt.Errorf("Failed to start instance %q: %w", inst.Name(), err)
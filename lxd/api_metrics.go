@@ -22,6 +22,7 @@ import (
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
@@ -36,6 +37,11 @@ type metricsCacheEntry struct {
 var metricsCache map[string]metricsCacheEntry
 var metricsCacheLock sync.Mutex
 
+// storagePoolMetricsCache holds the storage pool metrics of the local member, so that the pool resources aren't
+// queried on every scrape.
+var storagePoolMetricsCache metricsCacheEntry
+var storagePoolMetricsCacheLock sync.Mutex
+
 var metricsCmd = APIEndpoint{
 	Path: "metrics",
 
@@ -135,6 +141,9 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Register storage pool metrics.
+	intMetrics.Merge(storagePoolMetrics(s))
+
 	// invalidProjectFilters returns project filters which are either not in cache or have expired.
 	invalidProjectFilters := func(projectNames []string) []dbCluster.InstanceFilter {
 		metricsCacheLock.Lock()
@@ -283,6 +292,20 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 	wg.Wait()
 	close(instMetricsCh)
 
+	// Add the network ingress metrics of the local member.
+	for _, project := range projectsToFetch {
+		ingressMetrics := d.ingress.Metrics(*project.Project)
+		if ingressMetrics == nil {
+			continue
+		}
+
+		if newMetrics[*project.Project] == nil {
+			newMetrics[*project.Project] = metrics.NewMetricSet(nil)
+		}
+
+		newMetrics[*project.Project].Merge(ingressMetrics)
+	}
+
 	// Put the new data in the global cache and in response.
 	metricsCacheLock.Lock()
 
@@ -432,3 +455,64 @@ func internalMetrics(ctx context.Context, daemonStartTime time.Time, tx *db.Clus
 
 	return out
 }
+
+// storagePoolMetrics returns the space metrics of the storage pools available on the local member.
+func storagePoolMetrics(s *state.State) *metrics.MetricSet {
+	storagePoolMetricsCacheLock.Lock()
+	defer storagePoolMetricsCacheLock.Unlock()
+
+	if storagePoolMetricsCache.metrics != nil && time.Now().Before(storagePoolMetricsCache.expiry) {
+		return storagePoolMetricsCache.metrics
+	}
+
+	out := metrics.NewMetricSet(nil)
+
+	var poolNames []string
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		poolNames, err = tx.GetCreatedStoragePoolNames(ctx)
+
+		return err
+	})
+	if err != nil {
+		if !response.IsNotFoundError(err) {
+			logger.Warn("Failed to get storage pools", logger.Ctx{"err": err})
+		}
+
+		return out
+	}
+
+	for _, poolName := range poolNames {
+		pool, err := storagePools.LoadByName(s, poolName)
+		if err != nil {
+			logger.Warn("Failed to load storage pool", logger.Ctx{"pool": poolName, "err": err})
+			continue
+		}
+
+		res, err := pool.GetResources()
+		if err != nil {
+			logger.Debug("Failed to get storage pool resources", logger.Ctx{"pool": poolName, "err": err})
+			continue
+		}
+
+		labels := map[string]string{"pool": poolName, "driver": pool.Driver().Info().Name}
+		out.AddSamples(metrics.StoragePoolSpaceTotalBytes, metrics.Sample{Labels: labels, Value: float64(res.Space.Total)})
+		out.AddSamples(metrics.StoragePoolSpaceUsedBytes, metrics.Sample{Labels: labels, Value: float64(res.Space.Used)})
+
+		provisioned, err := pool.GetProvisionedSpace()
+		if err != nil {
+			logger.Debug("Failed to get storage pool provisioned space", logger.Ctx{"pool": poolName, "err": err})
+			continue
+		}
+
+		out.AddSamples(metrics.StoragePoolSpaceProvisionedBytes, metrics.Sample{Labels: labels, Value: float64(provisioned)})
+	}
+
+	storagePoolMetricsCache = metricsCacheEntry{
+		metrics: out,
+		expiry:  time.Now().Add(8 * time.Second),
+	}
+
+	return out
+}
@@ -1272,6 +1272,24 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  defaultdesc: `block`
 		//  shortdesc: Whether to prevent using devices of type `pci`
 		"restricted.devices.pci": isEitherAllowOrBlock,
+		// lxdmeta:generate(entities=project; group=restricted; key=restricted.devices.shm)
+		// Possible values are `allow` or `block`.
+		// ---
+		//  type: string
+		//  defaultdesc: `block`
+		//  shortdesc: Whether to prevent using devices of type `shm`
+		"restricted.devices.shm": isEitherAllowOrBlock,
+		// lxdmeta:generate(entities=project; group=restricted; key=restricted.devices.serial)
+		// Possible values are `allow`, `block`, or `unix`.
+		//
+		// - When set to `block`, this option prevents using all serial devices.
+		// - When set to `unix`, this option allows using serial devices only if they are exposed on a Unix socket.
+		// - When set to `allow`, serial devices can also be exposed on a TCP listener, which gives access to the serial port without authentication.
+		// ---
+		//  type: string
+		//  defaultdesc: `unix`
+		//  shortdesc: Which serial devices can be used
+		"restricted.devices.serial": validate.Optional(validate.IsOneOf("block", "allow", "unix")),
 		// lxdmeta:generate(entities=project; group=restricted; key=restricted.devices.proxy)
 		// Possible values are `allow` or `block`.
 		// ---
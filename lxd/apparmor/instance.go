@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/canonical/lxd/lxd/cgroup"
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/sys"
@@ -20,6 +21,7 @@ type instance interface {
 	Project() api.Project
 	Name() string
 	ExpandedConfig() map[string]string
+	ExpandedDevices() deviceConfig.Devices
 	Type() instancetype.Type
 	LogPath() string
 	Path() string
@@ -193,15 +195,38 @@ func instanceProfile(sysOS *sys.OS, inst instance) (string, error) {
 			execPath = execPathFull
 		}
 
+		// Allow access to the file backed memory of forkable VMs and to the fork base being restored.
+		forkPaths := []string{}
+		if shared.IsTrue(inst.ExpandedConfig()["migration.fork"]) {
+			forkPaths = append(forkPaths, shared.VarPath("virtual-machines-forks", project.Instance(inst.Project().Name, inst.Name())+".memory"))
+		}
+
+		forkBaseID := inst.ExpandedConfig()["volatile.fork.restore"]
+		if forkBaseID != "" {
+			forkPaths = append(forkPaths, shared.VarPath("virtual-machines-forks", forkBaseID, "memory"))
+		}
+
+		// Allow access to the files backing the shared memory objects used by the instance.
+		shmPaths := []string{}
+		for _, dev := range inst.ExpandedDevices().Sorted() {
+			if dev.Config["type"] != "shm" || dev.Config["name"] == "" {
+				continue
+			}
+
+			shmPaths = append(shmPaths, filepath.Join("/dev/shm/lxd-shm", project.Instance(inst.Project().Name, dev.Config["name"]), "memory"))
+		}
+
 		err = qemuProfileTpl.Execute(sb, map[string]any{
 			"devicesPath": inst.DevicesPath(),
 			"exePath":     execPath,
+			"forkPaths":   forkPaths,
 			"libraryPath": strings.Split(os.Getenv("LD_LIBRARY_PATH"), ":"),
 			"logPath":     inst.LogPath(),
 			"name":        InstanceProfileName(inst),
 			"path":        path,
 			"raw":         rawContent,
 			"rootPath":    rootPath,
+			"shmPaths":    shmPaths,
 			"snap":        shared.InSnap(),
 			"userns":      sysOS.RunningInUserNS,
 			"qemuFwPaths": qemuFwPathsArr,
@@ -55,6 +55,12 @@ profile "{{ .name }}" flags=(attach_disconnected,mediate_deleted) {
   {{ .logPath }}/** rwk,
   {{ .path }}/** rwk,
   {{ .devicesPath }}/** rwk,
+{{- range $index, $element := .forkPaths }}
+  {{ $element }} rw,
+{{- end }}
+{{- range $index, $element := .shmPaths }}
+  {{ $element }} rw,
+{{- end }}
 
   # Needed for lxd fork commands
   {{ .exePath }} mr,
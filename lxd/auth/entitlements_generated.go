@@ -10,13 +10,13 @@ import (
 type Entitlement string
 
 const (
-	// EntitlementCanView is the "can_view" entitlement. It applies to the following entities: entity.TypeCertificate, entity.TypeAuthGroup, entity.TypeIdentity, entity.TypeIdentityProviderGroup, entity.TypeImage, entity.TypeImageAlias, entity.TypeInstance, entity.TypeNetwork, entity.TypeNetworkACL, entity.TypeNetworkZone, entity.TypeProfile, entity.TypeProject, entity.TypeStorageBucket, entity.TypeStorageVolume.
+	// EntitlementCanView is the "can_view" entitlement. It applies to the following entities: entity.TypeCertificate, entity.TypeAuthGroup, entity.TypeIdentity, entity.TypeIdentityProviderGroup, entity.TypeImage, entity.TypeImageAlias, entity.TypeInstance, entity.TypeInstanceTemplate, entity.TypeNetwork, entity.TypeNetworkACL, entity.TypeNetworkZone, entity.TypeProfile, entity.TypeProject, entity.TypeStorageBucket, entity.TypeStorageVolume.
 	EntitlementCanView Entitlement = "can_view"
 
-	// EntitlementCanEdit is the "can_edit" entitlement. It applies to the following entities: entity.TypeCertificate, entity.TypeAuthGroup, entity.TypeIdentity, entity.TypeIdentityProviderGroup, entity.TypeImage, entity.TypeImageAlias, entity.TypeInstance, entity.TypeNetwork, entity.TypeNetworkACL, entity.TypeNetworkZone, entity.TypeProfile, entity.TypeProject, entity.TypeServer, entity.TypeStorageBucket, entity.TypeStoragePool, entity.TypeStorageVolume.
+	// EntitlementCanEdit is the "can_edit" entitlement. It applies to the following entities: entity.TypeCertificate, entity.TypeAuthGroup, entity.TypeIdentity, entity.TypeIdentityProviderGroup, entity.TypeImage, entity.TypeImageAlias, entity.TypeInstance, entity.TypeInstanceTemplate, entity.TypeNetwork, entity.TypeNetworkACL, entity.TypeNetworkZone, entity.TypeProfile, entity.TypeProject, entity.TypeServer, entity.TypeStorageBucket, entity.TypeStoragePool, entity.TypeStorageVolume.
 	EntitlementCanEdit Entitlement = "can_edit"
 
-	// EntitlementCanDelete is the "can_delete" entitlement. It applies to the following entities: entity.TypeCertificate, entity.TypeAuthGroup, entity.TypeIdentity, entity.TypeIdentityProviderGroup, entity.TypeImage, entity.TypeImageAlias, entity.TypeInstance, entity.TypeNetwork, entity.TypeNetworkACL, entity.TypeNetworkZone, entity.TypeProfile, entity.TypeProject, entity.TypeStorageBucket, entity.TypeStoragePool, entity.TypeStorageVolume.
+	// EntitlementCanDelete is the "can_delete" entitlement. It applies to the following entities: entity.TypeCertificate, entity.TypeAuthGroup, entity.TypeIdentity, entity.TypeIdentityProviderGroup, entity.TypeImage, entity.TypeImageAlias, entity.TypeInstance, entity.TypeInstanceTemplate, entity.TypeNetwork, entity.TypeNetworkACL, entity.TypeNetworkZone, entity.TypeProfile, entity.TypeProject, entity.TypeStorageBucket, entity.TypeStoragePool, entity.TypeStorageVolume.
 	EntitlementCanDelete Entitlement = "can_delete"
 
 	// EntitlementAdmin is the "admin" entitlement. It applies to the following entities: entity.TypeServer.
@@ -160,6 +160,21 @@ const (
 	// EntitlementCanOperateInstances is the "can_operate_instances" entitlement. It applies to the following entities: entity.TypeProject.
 	EntitlementCanOperateInstances Entitlement = "can_operate_instances"
 
+	// EntitlementInstanceTemplateManager is the "instance_template_manager" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementInstanceTemplateManager Entitlement = "instance_template_manager"
+
+	// EntitlementCanCreateInstanceTemplates is the "can_create_instance_templates" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementCanCreateInstanceTemplates Entitlement = "can_create_instance_templates"
+
+	// EntitlementCanViewInstanceTemplates is the "can_view_instance_templates" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementCanViewInstanceTemplates Entitlement = "can_view_instance_templates"
+
+	// EntitlementCanEditInstanceTemplates is the "can_edit_instance_templates" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementCanEditInstanceTemplates Entitlement = "can_edit_instance_templates"
+
+	// EntitlementCanDeleteInstanceTemplates is the "can_delete_instance_templates" entitlement. It applies to the following entities: entity.TypeProject.
+	EntitlementCanDeleteInstanceTemplates Entitlement = "can_delete_instance_templates"
+
 	// EntitlementNetworkManager is the "network_manager" entitlement. It applies to the following entities: entity.TypeProject.
 	EntitlementNetworkManager Entitlement = "network_manager"
 
@@ -356,6 +371,14 @@ var entityTypeToEntitlements = map[entity.Type][]Entitlement{
 		// Grants permission to start a terminal session.
 		EntitlementCanExec,
 	},
+	entity.TypeInstanceTemplate: {
+		// Grants permission to edit the instance template.
+		EntitlementCanEdit,
+		// Grants permission to delete the instance template.
+		EntitlementCanDelete,
+		// Grants permission to view the instance template.
+		EntitlementCanView,
+	},
 	entity.TypeNetwork: {
 		// Grants permission to edit the network.
 		EntitlementCanEdit,
@@ -431,6 +454,16 @@ var entityTypeToEntitlements = map[entity.Type][]Entitlement{
 		EntitlementCanDeleteInstances,
 		// Grants permission to view instances, manage their state, manage their snapshots and backups, start terminal or console sessions, and access their files.
 		EntitlementCanOperateInstances,
+		// Grants permission to create, view, edit, and delete all instance templates belonging to the project.
+		EntitlementInstanceTemplateManager,
+		// Grants permission to create instance templates.
+		EntitlementCanCreateInstanceTemplates,
+		// Grants permission to view instance templates.
+		EntitlementCanViewInstanceTemplates,
+		// Grants permission to edit instance templates.
+		EntitlementCanEditInstanceTemplates,
+		// Grants permission to delete instance templates.
+		EntitlementCanDeleteInstanceTemplates,
 		// Grants permission to create, view, edit, and delete all networks belonging to the project.
 		EntitlementNetworkManager,
 		// Grants permission to create networks.
@@ -123,6 +123,23 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 	defer func() { _ = tarFileWriter.Close() }()
 	revert.Add(func() { _ = os.Remove(target) })
 
+	// Dump the running state of the container into its volume so that it is included in the backup.
+	if b.Stateful() {
+		c, ok := sourceInst.(instance.Container)
+		if !ok {
+			return fmt.Errorf("Stateful backups are only supported for containers")
+		}
+
+		l.Debug("Taking stateful checkpoint")
+		err = c.CheckpointState()
+		if err != nil {
+			return fmt.Errorf("Failed taking stateful checkpoint: %w", err)
+		}
+
+		// Make sure we don't keep state around after the backup has been made.
+		defer func() { _ = os.RemoveAll(sourceInst.StatePath()) }()
+	}
+
 	// Get IDMap to unshift container as the tarball is created.
 	var idmap *idmap.IdmapSet
 	if sourceInst.Type() == instancetype.Container {
@@ -182,7 +199,7 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 
 	// Write index file.
 	l.Debug("Adding backup index file")
-	err = backupWriteIndex(sourceInst, pool, b.OptimizedStorage(), !b.InstanceOnly(), tarWriter)
+	err = backupWriteIndex(sourceInst, pool, b.OptimizedStorage(), !b.InstanceOnly(), b.Stateful(), tarWriter)
 
 	// Check compression errors.
 	if compressErr != nil {
@@ -228,7 +245,7 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 }
 
 // backupWriteIndex generates an index.yaml file and then writes it to the root of the backup tarball.
-func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, optimized bool, snapshots bool, tarWriter *instancewriter.InstanceTarWriter) error {
+func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, optimized bool, snapshots bool, stateful bool, tarWriter *instancewriter.InstanceTarWriter) error {
 	// Indicate whether the driver will include a driver-specific optimized header.
 	poolDriverOptimizedHeader := false
 	if optimized {
@@ -255,6 +272,11 @@ func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, opti
 		return fmt.Errorf("Failed generating instance backup config: %w", err)
 	}
 
+	// Restoring the backup should resume the instance from the state included in it.
+	if stateful {
+		config.Container.Stateful = true
+	}
+
 	indexInfo := backup.Info{
 		Name:             sourceInst.Name(),
 		Pool:             pool.Name(),
@@ -311,6 +333,11 @@ func pruneExpiredBackupsTask(d *Daemon) (task.Func, task.Schedule) {
 				return fmt.Errorf("Failed pruning expired storage volume backups: %w", err)
 			}
 
+			err = pruneExpiredStorageBucketBackups(ctx, s)
+			if err != nil {
+				return fmt.Errorf("Failed pruning expired storage bucket backups: %w", err)
+			}
+
 			return nil
 		}
 
@@ -372,7 +399,7 @@ func pruneExpiredInstanceBackups(ctx context.Context, s *state.State) error {
 			return fmt.Errorf("Error loading instance for deleting backup %q: %w", b.Name, err)
 		}
 
-		instBackup := backup.NewInstanceBackup(s, inst, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.InstanceOnly, b.OptimizedStorage)
+		instBackup := backup.NewInstanceBackup(s, inst, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.InstanceOnly, b.OptimizedStorage, b.Stateful)
 		err = instBackup.Delete()
 		if err != nil {
 			return fmt.Errorf("Error deleting instance backup %q: %w", b.Name, err)
@@ -630,3 +657,227 @@ func pruneExpiredStorageVolumeBackups(ctx context.Context, s *state.State) error
 
 	return nil
 }
+
+func bucketBackupCreate(s *state.State, args db.StoragePoolBucketBackup, projectName string, poolName string, bucketName string) error {
+	l := logger.AddContext(logger.Ctx{"project": projectName, "storage_bucket": bucketName, "name": args.Name})
+	l.Debug("Bucket backup started")
+	defer l.Debug("Bucket backup finished")
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Get storage pool.
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return fmt.Errorf("Failed loading storage pool %q: %w", poolName, err)
+	}
+
+	// Create the database entry.
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.CreateStoragePoolBucketBackup(ctx, args)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed creating storage bucket backup record: %w", err)
+	}
+
+	revert.Add(func() {
+		_ = s.DB.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.DeleteStoragePoolBucketBackup(ctx, args.BucketID, args.Name)
+		})
+	})
+
+	// Detect compression method.
+	var compress string
+
+	if args.CompressionAlgorithm != "" {
+		compress = args.CompressionAlgorithm
+	} else {
+		compress = s.GlobalConfig.BackupsCompressionAlgorithm()
+	}
+
+	// Create the target path if needed.
+	backupsPath := backup.BucketBackupPath(projectName, pool.Name(), bucketName)
+	if !shared.PathExists(backupsPath) {
+		err := os.MkdirAll(backupsPath, 0700)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { _ = os.Remove(backupsPath) })
+	}
+
+	target := backup.BucketBackupPath(projectName, pool.Name(), args.Name)
+
+	// Setup the tarball writer.
+	l.Debug("Opening backup tarball for writing", logger.Ctx{"path": target})
+	tarFileWriter, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("Error opening backup tarball for writing %q: %w", target, err)
+	}
+
+	defer func() { _ = tarFileWriter.Close() }()
+	revert.Add(func() { _ = os.Remove(target) })
+
+	// Create the tarball.
+	tarPipeReader, tarPipeWriter := io.Pipe()
+	defer func() { _ = tarPipeWriter.Close() }() // Ensure that go routine below always ends.
+	tarWriter := instancewriter.NewInstanceTarWriter(tarPipeWriter, nil)
+
+	// Setup tar writer go routine, with optional compression.
+	tarWriterRes := make(chan error)
+	var compressErr error
+
+	go func(resCh chan<- error) {
+		l.Debug("Started backup tarball writer")
+		defer l.Debug("Finished backup tarball writer")
+		if compress != "none" {
+			compressErr = compressFile(compress, tarPipeReader, tarFileWriter)
+
+			// If a compression error occurred, close the tarPipeWriter to end the export.
+			if compressErr != nil {
+				_ = tarPipeWriter.Close()
+			}
+		} else {
+			_, err = io.Copy(tarFileWriter, tarPipeReader)
+		}
+
+		resCh <- err
+	}(tarWriterRes)
+
+	// Write index file.
+	l.Debug("Adding backup index file")
+	err = bucketBackupWriteIndex(projectName, bucketName, pool, tarWriter)
+
+	// Check compression errors.
+	if compressErr != nil {
+		return compressErr
+	}
+
+	// Check bucketBackupWriteIndex for errors.
+	if err != nil {
+		return fmt.Errorf("Error writing backup index file: %w", err)
+	}
+
+	err = pool.BackupBucket(projectName, bucketName, tarWriter, nil)
+	if err != nil {
+		return fmt.Errorf("Backup create: %w", err)
+	}
+
+	// Close off the tarball file.
+	err = tarWriter.Close()
+	if err != nil {
+		return fmt.Errorf("Error closing tarball writer: %w", err)
+	}
+
+	// Close off the tarball pipe writer (this will end the go routine above).
+	err = tarPipeWriter.Close()
+	if err != nil {
+		return fmt.Errorf("Error closing tarball pipe writer: %w", err)
+	}
+
+	err = <-tarWriterRes
+	if err != nil {
+		return fmt.Errorf("Error writing tarball: %w", err)
+	}
+
+	err = tarFileWriter.Close()
+	if err != nil {
+		return fmt.Errorf("Error closing tar file: %w", err)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// bucketBackupWriteIndex generates an index.yaml file and then writes it to the root of the backup tarball.
+func bucketBackupWriteIndex(projectName string, bucketName string, pool storagePools.Pool, tarWriter *instancewriter.InstanceTarWriter) error {
+	config, err := pool.GenerateBucketBackupConfig(projectName, bucketName, nil)
+	if err != nil {
+		return fmt.Errorf("Failed generating bucket backup config: %w", err)
+	}
+
+	indexInfo := backup.Info{
+		Name:    config.Bucket.Name,
+		Pool:    pool.Name(),
+		Backend: pool.Driver().Info().Name,
+		Type:    backup.TypeBucket,
+		Config:  config,
+	}
+
+	// Convert to YAML.
+	indexData, err := yaml.Marshal(indexInfo)
+	if err != nil {
+		return err
+	}
+
+	r := bytes.NewReader(indexData)
+
+	indexFileInfo := instancewriter.FileInfo{
+		FileName:    "backup/index.yaml",
+		FileSize:    int64(len(indexData)),
+		FileMode:    0644,
+		FileModTime: time.Now(),
+	}
+
+	// Write to tarball.
+	err = tarWriter.WriteFileFromReader(r, &indexFileInfo)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func pruneExpiredStorageBucketBackups(ctx context.Context, s *state.State) error {
+	var bucketBackups []*backup.BucketBackup
+
+	// Get the list of expired backups.
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		backups, err := tx.GetExpiredStorageBucketBackups(ctx)
+		if err != nil {
+			return fmt.Errorf("Unable to retrieve the list of expired storage bucket backups: %w", err)
+		}
+
+		if len(backups) == 0 {
+			return nil
+		}
+
+		// Only consider buckets on this member, including those on remote pools.
+		buckets, err := tx.GetStoragePoolBuckets(ctx, true)
+		if err != nil {
+			return fmt.Errorf("Failed getting storage buckets: %w", err)
+		}
+
+		bucketsByID := make(map[int64]*db.StorageBucket, len(buckets))
+		for _, bucket := range buckets {
+			bucketsByID[bucket.ID] = bucket
+		}
+
+		for _, b := range backups {
+			bucket, found := bucketsByID[b.BucketID]
+			if !found {
+				continue
+			}
+
+			bucketBackup := backup.NewBucketBackup(s, bucket.Project, bucket.PoolName, bucket.Name, bucket.ID, b.ID, b.Name, b.CreationDate, b.ExpiryDate)
+
+			bucketBackups = append(bucketBackups, bucketBackup)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The deletion is done outside of the transaction to avoid any unnecessary IO while inside of
+	// the transaction.
+	for _, b := range bucketBackups {
+		err := b.Delete()
+		if err != nil {
+			return fmt.Errorf("Error deleting storage bucket backup %q: %w", b.Name(), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/revert"
+)
+
+// BucketBackup represents a storage bucket backup.
+type BucketBackup struct {
+	CommonBackup
+
+	projectName string
+	poolName    string
+	bucketName  string
+	bucketID    int64
+}
+
+// NewBucketBackup instantiates a new BucketBackup struct.
+func NewBucketBackup(state *state.State, projectName, poolName, bucketName string, bucketID int64, ID int, name string, creationDate, expiryDate time.Time) *BucketBackup {
+	return &BucketBackup{
+		CommonBackup: CommonBackup{
+			state:        state,
+			id:           ID,
+			name:         name,
+			creationDate: creationDate,
+			expiryDate:   expiryDate,
+		},
+		projectName: projectName,
+		poolName:    poolName,
+		bucketName:  bucketName,
+		bucketID:    bucketID,
+	}
+}
+
+// BucketBackupPath returns the path of the backup tarball with the given full backup name.
+func BucketBackupPath(projectName string, poolName string, backupName string) string {
+	return shared.VarPath("backups", "buckets", poolName, project.StorageVolume(projectName, backupName))
+}
+
+// Rename renames a bucket backup.
+func (b *BucketBackup) Rename(newName string) error {
+	oldBackupPath := BucketBackupPath(b.projectName, b.poolName, b.name)
+	newBackupPath := BucketBackupPath(b.projectName, b.poolName, newName)
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Create the new backup path if doesn't exist.
+	newParentBackupsPath := BucketBackupPath(b.projectName, b.poolName, b.bucketName)
+	if !shared.PathExists(newParentBackupsPath) {
+		err := os.MkdirAll(newParentBackupsPath, 0700)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Rename the backup file.
+	err := os.Rename(oldBackupPath, newBackupPath)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { _ = os.Rename(newBackupPath, oldBackupPath) })
+
+	// Rename the database record.
+	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.RenameStoragePoolBucketBackup(ctx, b.bucketID, b.name, newName)
+	})
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// Delete removes a bucket backup.
+func (b *BucketBackup) Delete() error {
+	backupPath := BucketBackupPath(b.projectName, b.poolName, b.name)
+
+	// Delete the on-disk data.
+	if shared.PathExists(backupPath) {
+		err := os.RemoveAll(backupPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check if we can remove the bucket directory.
+	backupsPath := BucketBackupPath(b.projectName, b.poolName, b.bucketName)
+	empty, _ := shared.PathIsEmpty(backupsPath)
+	if empty {
+		err := os.Remove(backupsPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Remove the database record.
+	err := b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteStoragePoolBucketBackup(ctx, b.bucketID, b.name)
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Render returns a BucketBackup struct of the backup.
+func (b *BucketBackup) Render() *api.StorageBucketBackup {
+	return &api.StorageBucketBackup{
+		Name:      strings.SplitN(b.name, "/", 2)[1],
+		CreatedAt: b.creationDate,
+		ExpiresAt: b.expiryDate,
+	}
+}
@@ -118,6 +118,11 @@ func UpdateInstanceConfig(c *db.Cluster, b Info, mountPath string) error {
 	if backup.Container != nil {
 		backup.Container.Name = b.Name
 		backup.Container.Project = b.Project
+
+		// Stateful backups include the running state of the instance, captured after its backup.yaml was written.
+		if b.Config != nil && b.Config.Container != nil && b.Config.Container.Stateful {
+			backup.Container.Stateful = true
+		}
 	}
 
 	// Update volume information in the backup.yaml.
@@ -29,10 +29,11 @@ type InstanceBackup struct {
 
 	instance     Instance
 	instanceOnly bool
+	stateful     bool
 }
 
 // NewInstanceBackup instantiates a new InstanceBackup struct.
-func NewInstanceBackup(state *state.State, inst Instance, ID int, name string, creationDate time.Time, expiryDate time.Time, instanceOnly bool, optimizedStorage bool) *InstanceBackup {
+func NewInstanceBackup(state *state.State, inst Instance, ID int, name string, creationDate time.Time, expiryDate time.Time, instanceOnly bool, optimizedStorage bool, stateful bool) *InstanceBackup {
 	return &InstanceBackup{
 		CommonBackup: CommonBackup{
 			state:            state,
@@ -44,6 +45,7 @@ func NewInstanceBackup(state *state.State, inst Instance, ID int, name string, c
 		},
 		instance:     inst,
 		instanceOnly: instanceOnly,
+		stateful:     stateful,
 	}
 }
 
@@ -52,6 +54,11 @@ func (b *InstanceBackup) InstanceOnly() bool {
 	return b.instanceOnly
 }
 
+// Stateful returns whether the running state of the instance is included in the backup.
+func (b *InstanceBackup) Stateful() bool {
+	return b.stateful
+}
+
 // Instance returns the instance to be backed up.
 func (b *InstanceBackup) Instance() Instance {
 	return b.instance
@@ -151,5 +158,6 @@ func (b *InstanceBackup) Render() *api.InstanceBackup {
 		InstanceOnly:     b.instanceOnly,
 		ContainerOnly:    b.instanceOnly,
 		OptimizedStorage: b.optimizedStorage,
+		Stateful:         b.stateful,
 	}
 }
@@ -673,6 +673,7 @@ func (cg *CGroup) SetBlkioWeight(limit int64) error {
 }
 
 // SetBlkioLimit sets the specified read or write limit for a device.
+// A limit of 0 removes any previous limit.
 func (cg *CGroup) SetBlkioLimit(dev string, oType string, uType string, limit int64) error {
 	if !shared.ValueInSlice(oType, []string{"read", "write"}) {
 		return fmt.Errorf("Invalid I/O operation type: %s", oType)
@@ -696,7 +697,13 @@ func (cg *CGroup) SetBlkioLimit(dev string, oType string, uType string, limit in
 			op = fmt.Sprintf("w%s", uType)
 		}
 
-		return cg.rw.Set(version, "io", "io.max", fmt.Sprintf("%s %s=%d", dev, op, limit))
+		// The kernel ignores a 0 value in io.max, "max" is used to remove the limit instead.
+		value := "max"
+		if limit > 0 {
+			value = fmt.Sprintf("%d", limit)
+		}
+
+		return cg.rw.Set(version, "io", "io.max", fmt.Sprintf("%s %s=%s", dev, op, value))
 	}
 
 	return ErrUnknownVersion
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/inventory"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+var clusterDevicesCmd = APIEndpoint{
+	Path: "cluster/devices",
+
+	Get: APIEndpointAction{Handler: clusterDevicesGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanViewResources)},
+}
+
+// swagger:operation GET /1.0/cluster/devices cluster cluster_devices_get
+//
+//	Get the cluster device inventory
+//
+//	Returns the devices of the cluster members that can be passed through to instances,
+//	along with the instances they are allocated to.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of devices
+//	          items:
+//	            $ref: "#/definitions/ClusterDevice"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterDevicesGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	target := request.QueryParam(r, "target")
+
+	devices := []api.ClusterDevice{}
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		nodeDevices, err := tx.GetNodeDevices(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, nodeDevice := range nodeDevices {
+			if target != "" && nodeDevice.Location != target {
+				continue
+			}
+
+			devices = append(devices, nodeDevice.ClusterDevice)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, devices)
+}
+
+// clusterDevicesSyncTask returns a task that refreshes the local devices in the cluster device inventory.
+func clusterDevicesSyncTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := clusterDevicesSync(ctx, d.State())
+		if err != nil {
+			logger.Warn("Failed updating cluster device inventory", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(10 * time.Minute)
+}
+
+// clusterDevicesHandleEvent refreshes the device allocations when local instances are created, updated or deleted.
+func (d *Daemon) clusterDevicesHandleEvent(event api.Event) {
+	if event.Type != api.EventTypeLifecycle || (event.Location != "" && event.Location != d.serverName) {
+		return
+	}
+
+	lifecycleEvent := api.EventLifecycle{}
+	err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+	if err != nil {
+		return
+	}
+
+	switch lifecycleEvent.Action {
+	case string(lifecycle.InstanceCreated), string(lifecycle.InstanceStarted), string(lifecycle.InstanceUpdated), string(lifecycle.InstanceDeleted):
+		d.taskClusterDevicesSync.Reset()
+	}
+}
+
+// clusterDevicesSync collects the local devices that can be passed through to instances and allocates them to
+// the local instances requiring them. Existing allocations are kept when still valid.
+func clusterDevicesSync(ctx context.Context, s *state.State) error {
+	devices, err := inventory.Collect()
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		// Load the previous allocations, keyed on instance ID and instance device name.
+		previous := map[int64]map[string]string{}
+
+		nodeDevices, err := tx.GetNodeDevices(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, nodeDevice := range nodeDevices {
+			if nodeDevice.NodeID != tx.GetNodeID() || nodeDevice.InstanceID == 0 {
+				continue
+			}
+
+			if previous[nodeDevice.InstanceID] == nil {
+				previous[nodeDevice.InstanceID] = map[string]string{}
+			}
+
+			previous[nodeDevice.InstanceID][nodeDevice.UsedByDevice] = nodeDevice.Type + "/" + nodeDevice.Address
+		}
+
+		var instances []db.InstanceArgs
+		filter := dbCluster.InstanceFilter{Node: &s.ServerName}
+		err = tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+			instances = append(instances, dbInst)
+			return nil
+		}, filter)
+		if err != nil {
+			return fmt.Errorf("Failed loading instances: %w", err)
+		}
+
+		sort.Slice(instances, func(i, j int) bool {
+			return instances[i].ID < instances[j].ID
+		})
+
+		allocated := make([]db.NodeDevice, len(devices))
+		available := make([]bool, len(devices))
+		for i := range devices {
+			allocated[i].ClusterDevice = devices[i]
+			available[i] = true
+		}
+
+		allocate := func(index int, instanceID int64, deviceName string) {
+			allocated[index].InstanceID = instanceID
+			allocated[index].UsedByDevice = deviceName
+			available[index] = false
+		}
+
+		for _, dbInst := range instances {
+			instanceID := int64(dbInst.ID)
+			expandedDevices := instancetype.ExpandInstanceDevices(dbInst.Devices, dbInst.Profiles).CloneNative()
+
+			// Keep the devices in use or previously allocated to the instance when they still match.
+			remaining := []inventory.Requirement{}
+			for _, req := range inventory.Requirements(dbInst.Type, expandedDevices) {
+				if !req.Exclusive {
+					remaining = append(remaining, req)
+					continue
+				}
+
+				hostName := dbInst.Config["volatile."+req.DeviceName+".host_name"]
+				preferred := -1
+				for i, device := range devices {
+					if !available[i] || !req.Matches(device) {
+						continue
+					}
+
+					if hostName != "" && device.Name == hostName {
+						preferred = i
+						break
+					}
+
+					if preferred < 0 && previous[instanceID][req.DeviceName] == device.Type+"/"+device.Address {
+						preferred = i
+					}
+				}
+
+				if preferred < 0 {
+					remaining = append(remaining, req)
+					continue
+				}
+
+				allocate(preferred, instanceID, req.DeviceName)
+			}
+
+			allocations, err := inventory.Assign(remaining, devices, available)
+			if err != nil {
+				logger.Warn("Instance devices can't be allocated", logger.Ctx{"project": dbInst.Project, "instance": dbInst.Name, "err": err})
+				continue
+			}
+
+			for deviceName, index := range allocations {
+				allocate(index, instanceID, deviceName)
+			}
+		}
+
+		return tx.ReplaceNodeDevices(ctx, tx.GetNodeID(), allocated)
+	})
+}
+
+// clusterDevicesFilterMembers returns the cluster members having free devices matching the devices the instance
+// needs passed through. Members without inventory data, for example because it wasn't synced yet, aren't filtered
+// out. Returns a not found error if no member has them.
+func clusterDevicesFilterMembers(ctx context.Context, tx *db.ClusterTx, members []db.NodeInfo, instType instancetype.Type, devices map[string]map[string]string) ([]db.NodeInfo, error) {
+	reqs := inventory.Requirements(instType, devices)
+	if len(reqs) == 0 {
+		return members, nil
+	}
+
+	nodeDevices, err := tx.GetNodeDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []db.NodeInfo{}
+	for _, member := range members {
+		memberDevices := []api.ClusterDevice{}
+		available := []bool{}
+		for _, nodeDevice := range nodeDevices {
+			if nodeDevice.NodeID != member.ID {
+				continue
+			}
+
+			memberDevices = append(memberDevices, nodeDevice.ClusterDevice)
+			available = append(available, nodeDevice.InstanceID == 0)
+		}
+
+		// Without inventory data, the member's devices are unknown and are checked on start instead.
+		if len(memberDevices) == 0 {
+			filtered = append(filtered, member)
+			continue
+		}
+
+		_, err := inventory.Assign(reqs, memberDevices, available)
+		if err != nil {
+			continue
+		}
+
+		filtered = append(filtered, member)
+	}
+
+	if len(filtered) == 0 {
+		return nil, api.StatusErrorf(http.StatusNotFound, "No cluster member has free devices matching the instance devices")
+	}
+
+	return filtered, nil
+}
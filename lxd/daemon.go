@@ -44,6 +44,7 @@ import (
 	"github.com/canonical/lxd/lxd/fsmonitor"
 	"github.com/canonical/lxd/lxd/identity"
 	"github.com/canonical/lxd/lxd/idmap"
+	"github.com/canonical/lxd/lxd/ingress"
 	"github.com/canonical/lxd/lxd/instance"
 	instanceDrivers "github.com/canonical/lxd/lxd/instance/drivers"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
@@ -84,6 +85,7 @@ type Daemon struct {
 	maas          *maas.Controller
 	bgp           *bgp.Server
 	dns           *dns.Server
+	ingress       *ingress.Server
 
 	// Event servers
 	devlxdEvents     *events.DevLXDServer
@@ -99,6 +101,9 @@ type Daemon struct {
 	taskPruneImages      *task.Task
 	taskClusterHeartbeat *task.Task
 
+	// Cluster device inventory refresh task, reset when local instances change
+	taskClusterDevicesSync *task.Task
+
 	// Stores startup time of daemon
 	startTime time.Time
 
@@ -182,6 +187,7 @@ func newDaemon(config *DaemonConfig, os *sys.OS) *Daemon {
 		events:         lxdEvents,
 		db:             &db.DB{},
 		http01Provider: acme.NewHTTP01Provider(),
+		ingress:        ingress.NewServer(),
 		os:             os,
 		setupChan:      make(chan struct{}),
 		waitReady:      cancel.New(context.Background()),
@@ -963,6 +969,13 @@ func (d *Daemon) init() error {
 		logger.Info(" - core scheduling: no")
 	}
 
+	d.os.CheckpointRestore = canUseCheckpointRestore()
+	if d.os.CheckpointRestore {
+		logger.Info(" - checkpoint/restore: yes")
+	} else {
+		logger.Info(" - checkpoint/restore: no")
+	}
+
 	d.os.UeventInjection = canUseUeventInjection()
 	if d.os.UeventInjection {
 		logger.Info(" - uevent injection: yes")
@@ -1141,6 +1154,15 @@ func (d *Daemon) init() error {
 				logger.Warn("Failed to mount devlxd", logger.Ctx{"err": err})
 			}
 		}
+
+		// Attempt to mount the VM forks tmpfs
+		forks := filepath.Join(d.os.VarDir, "virtual-machines-forks")
+		if !filesystem.IsMountPoint(forks) {
+			err = unix.Mount("tmpfs", forks, "tmpfs", 0, "size=100%,mode=0711")
+			if err != nil {
+				logger.Warn("Failed to mount VM forks", logger.Ctx{"err": err})
+			}
+		}
 	}
 
 	logger.Info("Loading daemon configuration")
@@ -1519,6 +1541,14 @@ func (d *Daemon) init() error {
 		logger.Info("Started DNS server")
 	}
 
+	// Serve the network ingresses, their listen addresses may be on managed networks.
+	if !d.os.MockMode {
+		err = networkIngressesStart(d)
+		if err != nil {
+			return err
+		}
+	}
+
 	metricsAddress := d.localConfig.MetricsAddress()
 	if metricsAddress != "" {
 		err = d.endpoints.UpMetrics(metricsAddress)
@@ -1694,6 +1724,9 @@ func (d *Daemon) init() error {
 		// Prune expired instance snapshots and take snapshot of instances (minutely check of configurable cron expression)
 		d.tasks.Add(pruneExpiredAndAutoCreateInstanceSnapshotsTask(d))
 
+		// Run the actions scheduled on instances (minutely check of configurable cron expressions)
+		d.tasks.Add(instanceScheduledActionsTask(d))
+
 		// Prune expired custom volume snapshots and take snapshots of custom volumes (minutely check of configurable cron expression)
 		d.tasks.Add(pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d))
 
@@ -1703,8 +1736,27 @@ func (d *Daemon) init() error {
 		// Auto-renew server certificate (daily)
 		d.tasks.Add(autoRenewCertificateTask(d))
 
+		// Renew network ingress certificates (daily)
+		d.tasks.Add(networkIngressCertificatesTask(d))
+
 		// Remove expired tokens (hourly)
 		d.tasks.Add(autoRemoveExpiredTokensTask(d))
+
+		// Check storage pool space usage against warning levels (every 5 minutes)
+		d.tasks.Add(storagePoolsCheckSpaceTask(d))
+
+		// Deduplicate storage pools (minutely check of configured deduplication schedules)
+		d.tasks.Add(storagePoolsDeduplicateTask(d))
+
+		// Refresh the cluster device inventory (every 10 minutes and when local instances change)
+		d.taskClusterDevicesSync = d.tasks.Add(clusterDevicesSyncTask(d))
+		d.internalListener.AddHandler("cluster-devices", d.clusterDevicesHandleEvent)
+
+		// Check the health of the local instances (every 10 seconds, configurable per instance)
+		d.tasks.Add(instanceHealthchecksTask(d))
+
+		// Record the resource usage history of the local instances (minutely)
+		d.tasks.Add(instanceStateHistoryTask(d))
 	}
 
 	// Start all background tasks
@@ -1843,6 +1895,7 @@ func (d *Daemon) Stop(ctx context.Context, sig os.Signal) error {
 			instancesShutdown(s, instances)
 
 			logger.Info("Stopping networks")
+			d.ingress.Stop()
 			networkShutdown(s)
 
 			// Unmount storage pools after instances stopped.
@@ -1919,6 +1972,7 @@ func (d *Daemon) Stop(ctx context.Context, sig os.Signal) error {
 
 		_ = unix.Unmount(shared.VarPath("devlxd"), unix.MNT_DETACH)
 		_ = unix.Unmount(shared.VarPath("shmounts"), unix.MNT_DETACH)
+		_ = unix.Unmount(shared.VarPath("virtual-machines-forks"), unix.MNT_DETACH)
 
 		logger.Info("Done unmounting temporary filesystems")
 	} else {
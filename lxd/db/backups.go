@@ -23,6 +23,7 @@ type InstanceBackup struct {
 	ExpiryDate           time.Time
 	InstanceOnly         bool
 	OptimizedStorage     bool
+	Stateful             bool
 	CompressionAlgorithm string
 }
 
@@ -38,6 +39,16 @@ type StoragePoolVolumeBackup struct {
 	CompressionAlgorithm string
 }
 
+// StoragePoolBucketBackup is a value object holding all db-related details about a storage bucket backup.
+type StoragePoolBucketBackup struct {
+	ID                   int
+	BucketID             int64
+	Name                 string
+	CreationDate         time.Time
+	ExpiryDate           time.Time
+	CompressionAlgorithm string
+}
+
 // Returns the ID of the instance backup with the given name.
 func (c *ClusterTx) getInstanceBackupID(ctx context.Context, name string) (int, error) {
 	q := "SELECT id FROM instances_backups WHERE name=?"
@@ -60,10 +71,12 @@ func (c *ClusterTx) GetInstanceBackup(ctx context.Context, projectName string, n
 
 	instanceOnlyInt := -1
 	optimizedStorageInt := -1
+	statefulInt := -1
 	q := `
 SELECT instances_backups.id, instances_backups.instance_id,
        instances_backups.creation_date, instances_backups.expiry_date,
-       instances_backups.container_only, instances_backups.optimized_storage
+       instances_backups.container_only, instances_backups.optimized_storage,
+       instances_backups.stateful
     FROM instances_backups
     JOIN instances ON instances.id=instances_backups.instance_id
     JOIN projects ON projects.id=instances.project_id
@@ -71,7 +84,7 @@ SELECT instances_backups.id, instances_backups.instance_id,
 `
 	arg1 := []any{projectName, name}
 	arg2 := []any{&args.ID, &args.InstanceID, &args.CreationDate,
-		&args.ExpiryDate, &instanceOnlyInt, &optimizedStorageInt}
+		&args.ExpiryDate, &instanceOnlyInt, &optimizedStorageInt, &statefulInt}
 
 	err := dbQueryRowScan(ctx, c, q, arg1, arg2)
 	if err != nil {
@@ -90,6 +103,10 @@ SELECT instances_backups.id, instances_backups.instance_id,
 		args.OptimizedStorage = true
 	}
 
+	if statefulInt == 1 {
+		args.Stateful = true
+	}
+
 	return args, nil
 }
 
@@ -100,10 +117,12 @@ func (c *ClusterTx) GetInstanceBackupWithID(ctx context.Context, backupID int) (
 
 	instanceOnlyInt := -1
 	optimizedStorageInt := -1
+	statefulInt := -1
 	q := `
 SELECT instances_backups.name, instances_backups.instance_id,
        instances_backups.creation_date, instances_backups.expiry_date,
-       instances_backups.container_only, instances_backups.optimized_storage
+       instances_backups.container_only, instances_backups.optimized_storage,
+       instances_backups.stateful
     FROM instances_backups
     JOIN instances ON instances.id=instances_backups.instance_id
     JOIN projects ON projects.id=instances.project_id
@@ -111,7 +130,7 @@ SELECT instances_backups.name, instances_backups.instance_id,
 `
 	arg1 := []any{backupID}
 	arg2 := []any{&args.Name, &args.InstanceID, &args.CreationDate,
-		&args.ExpiryDate, &instanceOnlyInt, &optimizedStorageInt}
+		&args.ExpiryDate, &instanceOnlyInt, &optimizedStorageInt, &statefulInt}
 
 	err := dbQueryRowScan(ctx, c, q, arg1, arg2)
 	if err != nil {
@@ -130,6 +149,10 @@ SELECT instances_backups.name, instances_backups.instance_id,
 		args.OptimizedStorage = true
 	}
 
+	if statefulInt == 1 {
+		args.Stateful = true
+	}
+
 	return args, nil
 }
 
@@ -174,7 +197,12 @@ func (c *ClusterTx) CreateInstanceBackup(ctx context.Context, args InstanceBacku
 		optimizedStorageInt = 1
 	}
 
-	str := "INSERT INTO instances_backups (instance_id, name, creation_date, expiry_date, container_only, optimized_storage) VALUES (?, ?, ?, ?, ?, ?)"
+	statefulInt := 0
+	if args.Stateful {
+		statefulInt = 1
+	}
+
+	str := "INSERT INTO instances_backups (instance_id, name, creation_date, expiry_date, container_only, optimized_storage, stateful) VALUES (?, ?, ?, ?, ?, ?, ?)"
 	stmt, err := c.tx.Prepare(str)
 	if err != nil {
 		return err
@@ -183,7 +211,7 @@ func (c *ClusterTx) CreateInstanceBackup(ctx context.Context, args InstanceBacku
 	defer func() { _ = stmt.Close() }()
 	result, err := stmt.Exec(args.InstanceID, args.Name,
 		args.CreationDate.Unix(), args.ExpiryDate.Unix(), instanceOnlyInt,
-		optimizedStorageInt)
+		optimizedStorageInt, statefulInt)
 	if err != nil {
 		return err
 	}
@@ -541,3 +569,153 @@ func (c *ClusterTx) RenameVolumeBackup(ctx context.Context, oldName, newName str
 
 	return nil
 }
+
+// GetExpiredStorageBucketBackups returns a list of expired storage bucket backups.
+func (c *ClusterTx) GetExpiredStorageBucketBackups(ctx context.Context) ([]StoragePoolBucketBackup, error) {
+	var backups []StoragePoolBucketBackup
+
+	q := `SELECT storage_buckets_backups.id, storage_buckets_backups.name, storage_buckets_backups.expiry_date, storage_buckets_backups.storage_bucket_id FROM storage_buckets_backups`
+
+	err := query.Scan(ctx, c.Tx(), q, func(scan func(dest ...any) error) error {
+		var b StoragePoolBucketBackup
+		var expiryTime sql.NullTime
+
+		err := scan(&b.ID, &b.Name, &expiryTime, &b.BucketID)
+		if err != nil {
+			return err
+		}
+
+		b.ExpiryDate = expiryTime.Time // Convert nulls to zero.
+
+		// Since zero time causes some issues due to timezones, we check the
+		// unix timestamp instead of IsZero().
+		if b.ExpiryDate.Unix() <= 0 {
+			// Backup doesn't expire
+			return nil
+		}
+
+		// Backup has expired
+		if time.Now().Unix()-b.ExpiryDate.Unix() >= 0 {
+			backups = append(backups, b)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return backups, nil
+}
+
+// GetStoragePoolBucketBackups returns a list of backups of the storage bucket with the given ID.
+func (c *ClusterTx) GetStoragePoolBucketBackups(ctx context.Context, bucketID int64) ([]StoragePoolBucketBackup, error) {
+	q := `
+	SELECT
+		backups.id,
+		backups.storage_bucket_id,
+		backups.name,
+		backups.creation_date,
+		backups.expiry_date
+	FROM storage_buckets_backups AS backups
+	WHERE backups.storage_bucket_id=?
+	ORDER BY backups.id
+	`
+
+	var backups []StoragePoolBucketBackup
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var b StoragePoolBucketBackup
+		var expiryTime sql.NullTime
+
+		err := scan(&b.ID, &b.BucketID, &b.Name, &b.CreationDate, &expiryTime)
+		if err != nil {
+			return err
+		}
+
+		b.ExpiryDate = expiryTime.Time // Convert nulls to zero.
+
+		backups = append(backups, b)
+
+		return nil
+	}, bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	return backups, nil
+}
+
+// GetStoragePoolBucketBackup returns the backup with the given name of the storage bucket with the given ID.
+func (c *ClusterTx) GetStoragePoolBucketBackup(ctx context.Context, bucketID int64, backupName string) (StoragePoolBucketBackup, error) {
+	args := StoragePoolBucketBackup{}
+	q := `
+SELECT
+	backups.id,
+	backups.storage_bucket_id,
+	backups.name,
+	backups.creation_date,
+	backups.expiry_date
+FROM storage_buckets_backups AS backups
+WHERE backups.storage_bucket_id=? AND backups.name=?
+`
+	var expiryTime sql.NullTime
+
+	arg1 := []any{bucketID, backupName}
+	outfmt := []any{&args.ID, &args.BucketID, &args.Name, &args.CreationDate, &expiryTime}
+
+	err := dbQueryRowScan(ctx, c, q, arg1, outfmt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return args, api.StatusErrorf(http.StatusNotFound, "Storage bucket backup not found")
+		}
+
+		return args, err
+	}
+
+	args.ExpiryDate = expiryTime.Time // Convert nulls to zero.
+
+	return args, nil
+}
+
+// CreateStoragePoolBucketBackup creates a new storage bucket backup.
+func (c *ClusterTx) CreateStoragePoolBucketBackup(ctx context.Context, args StoragePoolBucketBackup) error {
+	_, err := c.GetStoragePoolBucketBackup(ctx, args.BucketID, args.Name)
+	if err == nil {
+		return api.StatusErrorf(http.StatusConflict, "Backup for storage bucket %q already exists", args.Name)
+	}
+
+	str := "INSERT INTO storage_buckets_backups (storage_bucket_id, name, creation_date, expiry_date) VALUES (?, ?, ?, ?)"
+	result, err := c.tx.ExecContext(ctx, str, args.BucketID, args.Name, args.CreationDate.Unix(), args.ExpiryDate.Unix())
+	if err != nil {
+		return err
+	}
+
+	_, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("Error inserting %q into database", args.Name)
+	}
+
+	return nil
+}
+
+// DeleteStoragePoolBucketBackup removes the backup with the given name of the storage bucket with the given ID.
+func (c *ClusterTx) DeleteStoragePoolBucketBackup(ctx context.Context, bucketID int64, name string) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM storage_buckets_backups WHERE storage_bucket_id=? AND name=?", bucketID, name)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenameStoragePoolBucketBackup renames a backup of the storage bucket with the given ID from the given current
+// name to the new one.
+func (c *ClusterTx) RenameStoragePoolBucketBackup(ctx context.Context, bucketID int64, oldName string, newName string) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE storage_buckets_backups SET name = ? WHERE storage_bucket_id = ? AND name = ?", newName, bucketID, oldName)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
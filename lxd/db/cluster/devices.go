@@ -53,6 +53,9 @@ const (
 	TypeUnixHotplug = DeviceType(9)
 	TypeTPM         = DeviceType(10)
 	TypePCI         = DeviceType(11)
+	TypeSerial      = DeviceType(12)
+	TypeWatchdog    = DeviceType(13)
+	TypeSHM         = DeviceType(14)
 )
 
 func (t DeviceType) String() string {
@@ -81,6 +84,12 @@ func (t DeviceType) String() string {
 		return "tpm"
 	case TypePCI:
 		return "pci"
+	case TypeSerial:
+		return "serial"
+	case TypeWatchdog:
+		return "watchdog"
+	case TypeSHM:
+		return "shm"
 	}
 
 	return ""
@@ -113,6 +122,12 @@ func NewDeviceType(t string) (DeviceType, error) {
 		return TypeTPM, nil
 	case "pci":
 		return TypePCI, nil
+	case "serial":
+		return TypeSerial, nil
+	case "watchdog":
+		return TypeWatchdog, nil
+	case "shm":
+		return TypeSHM, nil
 	default:
 		return -1, fmt.Errorf("Invalid device type %q", t)
 	}
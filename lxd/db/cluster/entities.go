@@ -51,6 +51,7 @@ const (
 	entityTypeAuthGroup             int64 = 22
 	entityTypeIdentityProviderGroup int64 = 23
 	entityTypeIdentity              int64 = 24
+	entityTypeInstanceTemplate      int64 = 25
 )
 
 // Scan implements sql.Scanner for EntityType. This converts the integer value back into the correct entity.Type
@@ -124,6 +125,8 @@ func (e *EntityType) Scan(value any) error {
 		*e = EntityType(entity.TypeIdentityProviderGroup)
 	case entityTypeIdentity:
 		*e = EntityType(entity.TypeIdentity)
+	case entityTypeInstanceTemplate:
+		*e = EntityType(entity.TypeInstanceTemplate)
 	default:
 		return fmt.Errorf("Unknown entity type %d", entityTypeInt)
 	}
@@ -186,6 +189,8 @@ func (e EntityType) Value() (driver.Value, error) {
 		return entityTypeIdentityProviderGroup, nil
 	case EntityType(entity.TypeIdentity):
 		return entityTypeIdentity, nil
+	case EntityType(entity.TypeInstanceTemplate):
+		return entityTypeInstanceTemplate, nil
 	default:
 		return nil, fmt.Errorf("Unknown entity type %q", e)
 	}
@@ -298,6 +303,15 @@ var networkZoneEntityByID = fmt.Sprintf(`%s WHERE networks_zones.id = ?`, networ
 // networkZoneEntitiesByProjectName returns all entities of type entity.TypeNetworkZone in a particular project.
 var networkZoneEntitiesByProjectName = fmt.Sprintf(`%s WHERE projects.name = ?`, networkZoneEntities)
 
+// instanceTemplateEntities returns all entities of type entity.TypeInstanceTemplate.
+var instanceTemplateEntities = fmt.Sprintf(`SELECT %d, instances_templates.id, projects.name, '', json_array(instances_templates.name) FROM instances_templates JOIN projects ON instances_templates.project_id = projects.id`, entityTypeInstanceTemplate)
+
+// instanceTemplateEntityByID gets the entity of type entity.TypeInstanceTemplate with a particular ID.
+var instanceTemplateEntityByID = fmt.Sprintf(`%s WHERE instances_templates.id = ?`, instanceTemplateEntities)
+
+// instanceTemplateEntitiesByProjectName returns all entities of type entity.TypeInstanceTemplate in a particular project.
+var instanceTemplateEntitiesByProjectName = fmt.Sprintf(`%s WHERE projects.name = ?`, instanceTemplateEntities)
+
 // imageAliasEntities returns all entities of type entity.TypeImageAlias.
 var imageAliasEntities = fmt.Sprintf(`SELECT %d, images_aliases.id, projects.name, '', json_array(images_aliases.name) FROM images_aliases JOIN projects ON images_aliases.project_id = projects.id`, entityTypeImageAlias)
 
@@ -530,6 +544,7 @@ var entityStatementsAll = map[entity.Type]string{
 	entity.TypeAuthGroup:             authGroupEntities,
 	entity.TypeIdentityProviderGroup: identityProviderGroupEntities,
 	entity.TypeIdentity:              identityEntities,
+	entity.TypeInstanceTemplate:      instanceTemplateEntities,
 }
 
 // entityStatementsByID is a map of entity type to the statement which queries for all URL information for a single entity of that type with a given ID.
@@ -558,6 +573,7 @@ var entityStatementsByID = map[entity.Type]string{
 	entity.TypeAuthGroup:             authGroupEntityByID,
 	entity.TypeIdentityProviderGroup: identityProviderGroupEntityByID,
 	entity.TypeIdentity:              identityEntityByID,
+	entity.TypeInstanceTemplate:      instanceTemplateEntityByID,
 }
 
 // entityStatementsByProjectName is a map of entity type to the statement which queries for all URL information for all entities of that type within a given project.
@@ -578,6 +594,7 @@ var entityStatementsByProjectName = map[entity.Type]string{
 	entity.TypeStorageBucket:         storageBucketEntitiesByProjectName,
 	entity.TypeImageAlias:            imageAliasEntitiesByProjectName,
 	entity.TypeNetworkZone:           networkZoneEntitiesByProjectName,
+	entity.TypeInstanceTemplate:      instanceTemplateEntitiesByProjectName,
 }
 
 // EntityRef represents the expected format of entity URL queries.
@@ -973,6 +990,15 @@ WHERE projects.name = ?
 	AND '' = ? 
 	AND networks_zones.name = ?`
 
+// instanceTemplateIDFromURL gets the ID of an instanceTemplate from its URL.
+var instanceTemplateIDFromURL = `
+SELECT ?, instances_templates.id 
+FROM instances_templates 
+JOIN projects ON instances_templates.project_id = projects.id 
+WHERE projects.name = ? 
+	AND '' = ? 
+	AND instances_templates.name = ?`
+
 // imageAliasIDFromURL gets the ID of a imageAlias from its URL.
 var imageAliasIDFromURL = `
 SELECT ?, images_aliases.id 
@@ -1038,6 +1064,7 @@ var entityIDFromURLStatements = map[entity.Type]string{
 	entity.TypeAuthGroup:             authGroupIDFromURL,
 	entity.TypeIdentityProviderGroup: identityProviderGroupIDFromURL,
 	entity.TypeIdentity:              identityIDFromURL,
+	entity.TypeInstanceTemplate:      instanceTemplateIDFromURL,
 }
 
 // PopulateEntityReferencesFromURLs populates the values in the given map with entity references corresponding to the api.URL keys.
@@ -1216,6 +1243,7 @@ var entityDeletionTriggers = map[entity.Type]string{
 	entity.TypeAuthGroup:             authGroupDeletionTrigger,
 	entity.TypeIdentityProviderGroup: identityProviderGroupDeletionTrigger,
 	entity.TypeIdentity:              identityDeletionTrigger,
+	entity.TypeInstanceTemplate:      instanceTemplateDeletionTrigger,
 }
 
 // imageDeletionTrigger deletes any permissions or warnings associated with an image when it is deleted.
@@ -1542,3 +1570,18 @@ CREATE TRIGGER on_identity_delete
 		AND entity_id = OLD.id;
 	END
 `, entityTypeIdentity, entityTypeIdentity)
+
+// instanceTemplateDeletionTrigger deletes any permissions or warnings associated with an instance template when it is deleted.
+var instanceTemplateDeletionTrigger = fmt.Sprintf(`
+DROP TRIGGER IF EXISTS on_instance_template_delete;
+CREATE TRIGGER on_instance_template_delete
+	AFTER DELETE ON instances_templates
+	BEGIN
+	DELETE FROM auth_groups_permissions 
+		WHERE entity_type = %d 
+		AND entity_id = OLD.id;
+	DELETE FROM warnings
+		WHERE entity_type_code = %d
+		AND entity_id = OLD.id;
+	END
+`, entityTypeInstanceTemplate, entityTypeInstanceTemplate)
@@ -162,6 +162,7 @@ CREATE TABLE "instances_backups" (
     expiry_date DATETIME,
     container_only INTEGER NOT NULL default 0,
     optimized_storage INTEGER NOT NULL default 0,
+    stateful INTEGER NOT NULL DEFAULT 0,
     FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE CASCADE,
     UNIQUE (instance_id, name)
 );
@@ -242,6 +243,15 @@ CREATE TABLE "instances_snapshots_devices_config" (
     FOREIGN KEY (instance_snapshot_device_id) REFERENCES "instances_snapshots_devices" (id) ON DELETE CASCADE,
     UNIQUE (instance_snapshot_device_id, key)
 );
+CREATE TABLE "instances_templates" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    definition TEXT NOT NULL,
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
 CREATE TABLE "networks" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     project_id INTEGER NOT NULL,
@@ -299,6 +309,28 @@ CREATE TABLE "networks_forwards_config" (
 	UNIQUE (network_forward_id, key),
 	FOREIGN KEY (network_forward_id) REFERENCES "networks_forwards" (id) ON DELETE CASCADE
 );
+CREATE TABLE "networks_ingresses" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_id INTEGER NOT NULL,
+    node_id INTEGER NOT NULL,
+    hostname TEXT NOT NULL,
+    listen_address TEXT NOT NULL,
+    description TEXT NOT NULL,
+    routes TEXT NOT NULL,
+    certificate TEXT NOT NULL DEFAULT '',
+    private_key TEXT NOT NULL DEFAULT '',
+    UNIQUE (node_id, hostname),
+    FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE,
+    FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE
+);
+CREATE TABLE "networks_ingresses_config" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_ingress_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    UNIQUE (network_ingress_id, key),
+    FOREIGN KEY (network_ingress_id) REFERENCES "networks_ingresses" (id) ON DELETE CASCADE
+);
 CREATE TABLE "networks_load_balancers" (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 	network_id INTEGER NOT NULL,
@@ -413,6 +445,24 @@ CREATE TABLE "nodes_config" (
 	FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
 	UNIQUE (node_id, key)
 );
+CREATE TABLE "nodes_devices" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    node_id INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    address TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    vendor TEXT NOT NULL DEFAULT '',
+    vendor_id TEXT NOT NULL DEFAULT '',
+    product TEXT NOT NULL DEFAULT '',
+    product_id TEXT NOT NULL DEFAULT '',
+    serial TEXT NOT NULL DEFAULT '',
+    parent TEXT NOT NULL DEFAULT '',
+    instance_id INTEGER,
+    instance_device TEXT NOT NULL DEFAULT '',
+    UNIQUE (node_id, type, address),
+    FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
+    FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE SET NULL
+);
 CREATE TABLE nodes_failure_domains (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     name TEXT NOT NULL,
@@ -493,6 +543,15 @@ CREATE TABLE "storage_buckets" (
 	FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
 	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
 );
+CREATE TABLE "storage_buckets_backups" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    storage_bucket_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    creation_date DATETIME,
+    expiry_date DATETIME,
+    FOREIGN KEY (storage_bucket_id) REFERENCES "storage_buckets" (id) ON DELETE CASCADE,
+    UNIQUE (storage_bucket_id, name)
+);
 CREATE TABLE "storage_buckets_config" (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 	storage_bucket_id INTEGER NOT NULL,
@@ -662,5 +721,5 @@ CREATE TABLE "warnings" (
 );
 CREATE UNIQUE INDEX warnings_unique_node_id_project_id_entity_type_code_entity_id_type_code ON warnings(IFNULL(node_id, -1), IFNULL(project_id, -1), entity_type_code, entity_id, type_code);
 
-INSERT INTO schema (version, updated_at) VALUES (73, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (78, strftime("%s"))
 `
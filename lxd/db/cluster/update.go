@@ -110,6 +110,118 @@ var updates = map[int]schema.Update{
 	71: updateFromV70,
 	72: updateFromV71,
 	73: updateFromV72,
+	74: updateFromV73,
+	75: updateFromV74,
+	76: updateFromV75,
+	77: updateFromV76,
+	78: updateFromV77,
+}
+
+func updateFromV77(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `ALTER TABLE instances_backups ADD COLUMN stateful INTEGER NOT NULL DEFAULT 0;`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV76(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE "instances_templates" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    definition TEXT NOT NULL,
+    UNIQUE (project_id, name),
+    FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV75(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE "nodes_devices" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    node_id INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    address TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    vendor TEXT NOT NULL DEFAULT '',
+    vendor_id TEXT NOT NULL DEFAULT '',
+    product TEXT NOT NULL DEFAULT '',
+    product_id TEXT NOT NULL DEFAULT '',
+    serial TEXT NOT NULL DEFAULT '',
+    parent TEXT NOT NULL DEFAULT '',
+    instance_id INTEGER,
+    instance_device TEXT NOT NULL DEFAULT '',
+    UNIQUE (node_id, type, address),
+    FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
+    FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE SET NULL
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV74(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE "networks_ingresses" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_id INTEGER NOT NULL,
+    node_id INTEGER NOT NULL,
+    hostname TEXT NOT NULL,
+    listen_address TEXT NOT NULL,
+    description TEXT NOT NULL,
+    routes TEXT NOT NULL,
+    certificate TEXT NOT NULL DEFAULT '',
+    private_key TEXT NOT NULL DEFAULT '',
+    UNIQUE (node_id, hostname),
+    FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE,
+    FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE
+);
+CREATE TABLE "networks_ingresses_config" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_ingress_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    UNIQUE (network_ingress_id, key),
+    FOREIGN KEY (network_ingress_id) REFERENCES "networks_ingresses" (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV73(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE "storage_buckets_backups" (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    storage_bucket_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    creation_date DATETIME,
+    expiry_date DATETIME,
+    FOREIGN KEY (storage_bucket_id) REFERENCES "storage_buckets" (id) ON DELETE CASCADE,
+    UNIQUE (storage_bucket_id, name)
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func updateFromV72(ctx context.Context, tx *sql.Tx) error {
@@ -0,0 +1,156 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// instanceTemplateDefinition is the JSON encoded content of the definition column of the instances_templates table.
+type instanceTemplateDefinition struct {
+	Parameters map[string]api.InstanceTemplateParameter `json:"parameters"`
+	Instance   api.InstancesPost                        `json:"instance"`
+}
+
+// GetInstanceTemplateNames returns the names of the instance templates in the given project.
+func (c *ClusterTx) GetInstanceTemplateNames(ctx context.Context, projectName string) ([]string, error) {
+	q := `SELECT instances_templates.name FROM instances_templates
+		JOIN projects ON projects.id = instances_templates.project_id
+		WHERE projects.name = ?
+		ORDER BY instances_templates.name
+	`
+
+	templateNames := []string{}
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var templateName string
+
+		err := scan(&templateName)
+		if err != nil {
+			return err
+		}
+
+		templateNames = append(templateNames, templateName)
+
+		return nil
+	}, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	return templateNames, nil
+}
+
+// GetInstanceTemplate returns the ID and content of the instance template with the given name in the given project.
+func (c *ClusterTx) GetInstanceTemplate(ctx context.Context, projectName string, name string) (int64, *api.InstanceTemplate, error) {
+	var id = int64(-1)
+
+	template := api.InstanceTemplate{
+		Name:    name,
+		Project: projectName,
+	}
+
+	q := `
+		SELECT instances_templates.id, instances_templates.description, instances_templates.definition
+		FROM instances_templates
+		JOIN projects ON projects.id = instances_templates.project_id
+		WHERE projects.name = ? AND instances_templates.name = ?
+		LIMIT 1
+	`
+
+	var definition string
+
+	err := c.tx.QueryRowContext(ctx, q, projectName, name).Scan(&id, &template.Description, &definition)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return -1, nil, api.StatusErrorf(http.StatusNotFound, "Instance template not found")
+		}
+
+		return -1, nil, err
+	}
+
+	content := instanceTemplateDefinition{}
+	err = json.Unmarshal([]byte(definition), &content)
+	if err != nil {
+		return -1, nil, fmt.Errorf("Failed decoding instance template definition: %w", err)
+	}
+
+	template.Parameters = content.Parameters
+	template.Instance = content.Instance
+
+	if template.Parameters == nil {
+		template.Parameters = map[string]api.InstanceTemplateParameter{}
+	}
+
+	return id, &template, nil
+}
+
+// instanceTemplateDefinitionEncode returns the JSON encoded definition of the instance template.
+func instanceTemplateDefinitionEncode(put api.InstanceTemplatePut) (string, error) {
+	definition, err := json.Marshal(instanceTemplateDefinition{
+		Parameters: put.Parameters,
+		Instance:   put.Instance,
+	})
+	if err != nil {
+		return "", fmt.Errorf("Failed encoding instance template definition: %w", err)
+	}
+
+	return string(definition), nil
+}
+
+// CreateInstanceTemplate creates a new instance template in the given project.
+func (c *ClusterTx) CreateInstanceTemplate(ctx context.Context, projectName string, info api.InstanceTemplatesPost) (int64, error) {
+	definition, err := instanceTemplateDefinitionEncode(info.InstanceTemplatePut)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := c.tx.ExecContext(ctx, `
+		INSERT INTO instances_templates (project_id, name, description, definition)
+		VALUES ((SELECT id FROM projects WHERE name = ? LIMIT 1), ?, ?, ?)
+	`, projectName, info.Name, info.Description, definition)
+	if err != nil {
+		return -1, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// UpdateInstanceTemplate updates the instance template with the given ID.
+func (c *ClusterTx) UpdateInstanceTemplate(ctx context.Context, id int64, put api.InstanceTemplatePut) error {
+	definition, err := instanceTemplateDefinitionEncode(put)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.tx.ExecContext(ctx, "UPDATE instances_templates SET description=?, definition=? WHERE id=?", put.Description, definition, id)
+
+	return err
+}
+
+// RenameInstanceTemplate renames the instance template with the given ID.
+func (c *ClusterTx) RenameInstanceTemplate(ctx context.Context, id int64, newName string) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE instances_templates SET name=? WHERE id=?", newName, id)
+
+	return err
+}
+
+// DeleteInstanceTemplate deletes the instance template with the given ID.
+func (c *ClusterTx) DeleteInstanceTemplate(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM instances_templates WHERE id=?", id)
+
+	return err
+}
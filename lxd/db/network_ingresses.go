@@ -0,0 +1,347 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// CreateNetworkIngress creates a new Network Ingress on the current member.
+func (c *ClusterTx) CreateNetworkIngress(ctx context.Context, networkID int64, info *api.NetworkIngressesPost) (int64, error) {
+	var err error
+	var routesJSON []byte
+
+	if info.Routes != nil {
+		routesJSON, err = json.Marshal(info.Routes)
+		if err != nil {
+			return -1, fmt.Errorf("Failed marshalling routes: %w", err)
+		}
+	}
+
+	// Insert a new Network ingress record.
+	result, err := c.tx.ExecContext(ctx, `
+		INSERT INTO networks_ingresses
+		(network_id, node_id, hostname, listen_address, description, routes)
+		VALUES (?, ?, ?, ?, ?, ?)
+		`, networkID, c.nodeID, info.Hostname, info.ListenAddress, info.Description, string(routesJSON))
+	if err != nil {
+		return -1, err
+	}
+
+	ingressID, err := result.LastInsertId()
+	if err != nil {
+		return -1, err
+	}
+
+	// Save config.
+	err = networkIngressConfigAdd(c.tx, ingressID, info.Config)
+	if err != nil {
+		return -1, err
+	}
+
+	return ingressID, err
+}
+
+// networkIngressConfigAdd inserts Network ingress config keys.
+func networkIngressConfigAdd(tx *sql.Tx, ingressID int64, config map[string]string) error {
+	stmt, err := tx.Prepare(`
+	INSERT INTO networks_ingresses_config
+	(network_ingress_id, key, value)
+	VALUES(?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.Exec(ingressID, k, v)
+		if err != nil {
+			return fmt.Errorf("Failed inserting config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateNetworkIngress updates an existing Network Ingress.
+func (c *ClusterTx) UpdateNetworkIngress(ctx context.Context, networkID int64, ingressID int64, info api.NetworkIngressPut) error {
+	var err error
+	var routesJSON []byte
+
+	if info.Routes != nil {
+		routesJSON, err = json.Marshal(info.Routes)
+		if err != nil {
+			return fmt.Errorf("Failed marshalling routes: %w", err)
+		}
+	}
+
+	// Update existing Network ingress record.
+	res, err := c.tx.ExecContext(ctx, `
+		UPDATE networks_ingresses
+		SET description = ?, routes = ?
+		WHERE network_id = ? and id = ?
+		`, info.Description, string(routesJSON), networkID, ingressID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected <= 0 {
+		return api.StatusErrorf(http.StatusNotFound, "Network ingress not found")
+	}
+
+	// Save config.
+	_, err = c.tx.ExecContext(ctx, "DELETE FROM networks_ingresses_config WHERE network_ingress_id=?", ingressID)
+	if err != nil {
+		return err
+	}
+
+	err = networkIngressConfigAdd(c.tx, ingressID, info.Config)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkIngress deletes an existing Network Ingress.
+func (c *ClusterTx) DeleteNetworkIngress(ctx context.Context, networkID int64, ingressID int64) error {
+	// Delete existing Network ingress record.
+	res, err := c.tx.ExecContext(ctx, `
+			DELETE FROM networks_ingresses
+			WHERE network_id = ? and id = ?
+		`, networkID, ingressID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected <= 0 {
+		return api.StatusErrorf(http.StatusNotFound, "Network ingress not found")
+	}
+
+	return nil
+}
+
+// GetNetworkIngress returns the Network Ingress ID and info for the given network ID and host name.
+// If memberSpecific is true, then the search is restricted to ingresses that belong to this member.
+func (c *ClusterTx) GetNetworkIngress(ctx context.Context, networkID int64, memberSpecific bool, hostname string) (int64, *api.NetworkIngress, error) {
+	ingresses, err := c.GetNetworkIngresses(ctx, networkID, memberSpecific, hostname)
+	if (err == nil && len(ingresses) <= 0) || errors.Is(err, sql.ErrNoRows) {
+		return -1, nil, api.StatusErrorf(http.StatusNotFound, "Network ingress not found")
+	} else if err == nil && len(ingresses) > 1 {
+		return -1, nil, api.StatusErrorf(http.StatusConflict, "Network ingress found on more than one cluster member. Please target a specific member")
+	} else if err != nil {
+		return -1, nil, err
+	}
+
+	for ingressID, ingress := range ingresses {
+		return ingressID, ingress, nil // Only single ingress in map.
+	}
+
+	return -1, nil, fmt.Errorf("Unexpected ingress list size")
+}
+
+// networkIngressConfig populates the config map of the Network Ingress with the given ID.
+func networkIngressConfig(ctx context.Context, tx *ClusterTx, ingressID int64, ingress *api.NetworkIngress) error {
+	q := `
+	SELECT
+		key,
+		value
+	FROM networks_ingresses_config
+	WHERE network_ingress_id=?
+	`
+
+	ingress.Config = make(map[string]string)
+	return query.Scan(ctx, tx.Tx(), q, func(scan func(dest ...any) error) error {
+		var key, value string
+
+		err := scan(&key, &value)
+		if err != nil {
+			return err
+		}
+
+		_, found := ingress.Config[key]
+		if found {
+			return fmt.Errorf("Duplicate config row found for key %q for network ingress ID %d", key, ingressID)
+		}
+
+		ingress.Config[key] = value
+
+		return nil
+	}, ingressID)
+}
+
+// GetNetworkIngresses returns map of Network Ingresses for the given network ID keyed on Ingress ID.
+// If memberSpecific is true, then the search is restricted to ingresses that belong to this member.
+// Can optionally retrieve only specific network ingresses by host name.
+func (c *ClusterTx) GetNetworkIngresses(ctx context.Context, networkID int64, memberSpecific bool, hostnames ...string) (map[int64]*api.NetworkIngress, error) {
+	var q = &strings.Builder{}
+	args := []any{networkID}
+
+	q.WriteString(`
+	SELECT
+		networks_ingresses.id,
+		networks_ingresses.hostname,
+		networks_ingresses.listen_address,
+		networks_ingresses.description,
+		nodes.name,
+		networks_ingresses.routes
+	FROM networks_ingresses
+	JOIN nodes ON nodes.id = networks_ingresses.node_id
+	WHERE networks_ingresses.network_id = ?
+	`)
+
+	if memberSpecific {
+		q.WriteString("AND networks_ingresses.node_id = ? ")
+		args = append(args, c.nodeID)
+	}
+
+	if len(hostnames) > 0 {
+		q.WriteString(fmt.Sprintf("AND networks_ingresses.hostname IN %s ", query.Params(len(hostnames))))
+		for _, hostname := range hostnames {
+			args = append(args, hostname)
+		}
+	}
+
+	var err error
+	ingresses := make(map[int64]*api.NetworkIngress)
+
+	err = query.Scan(ctx, c.tx, q.String(), func(scan func(dest ...any) error) error {
+		var ingressID = int64(-1)
+		var routesJSON string
+		var ingress api.NetworkIngress
+
+		err := scan(&ingressID, &ingress.Hostname, &ingress.ListenAddress, &ingress.Description, &ingress.Location, &routesJSON)
+		if err != nil {
+			return err
+		}
+
+		ingress.Routes = []api.NetworkIngressRoute{}
+		if routesJSON != "" {
+			err = json.Unmarshal([]byte(routesJSON), &ingress.Routes)
+			if err != nil {
+				return fmt.Errorf("Failed unmarshalling routes: %w", err)
+			}
+		}
+
+		ingresses[ingressID] = &ingress
+
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Populate config.
+	for ingressID := range ingresses {
+		err = networkIngressConfig(ctx, c, ingressID, ingresses[ingressID])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ingresses, nil
+}
+
+// GetNetworkIngressNetworksOnMember returns the networks which have ingresses on this member.
+// Returns a map keyed on project name and network ID containing the network name.
+func (c *ClusterTx) GetNetworkIngressNetworksOnMember(ctx context.Context) (map[string]map[int64]string, error) {
+	q := `
+	SELECT DISTINCT
+		projects.name,
+		networks.id,
+		networks.name
+	FROM networks_ingresses
+	JOIN networks ON networks.id = networks_ingresses.network_id
+	JOIN projects ON projects.id = networks.project_id
+	WHERE networks_ingresses.node_id = ?
+	`
+
+	networks := make(map[string]map[int64]string)
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var projectName string
+		var networkID = int64(-1)
+		var networkName string
+
+		err := scan(&projectName, &networkID, &networkName)
+		if err != nil {
+			return err
+		}
+
+		if networks[projectName] == nil {
+			networks[projectName] = make(map[int64]string)
+		}
+
+		networks[projectName][networkID] = networkName
+
+		return nil
+	}, c.nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return networks, nil
+}
+
+// GetNetworkIngressCertificate returns the PEM encoded certificate and private key obtained for the
+// Network Ingress with the given ID. Both are empty if no certificate was obtained yet.
+func (c *ClusterTx) GetNetworkIngressCertificate(ctx context.Context, ingressID int64) (string, string, error) {
+	var certificate string
+	var privateKey string
+
+	err := c.tx.QueryRowContext(ctx, "SELECT certificate, private_key FROM networks_ingresses WHERE id = ?", ingressID).Scan(&certificate, &privateKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", api.StatusErrorf(http.StatusNotFound, "Network ingress not found")
+		}
+
+		return "", "", err
+	}
+
+	return certificate, privateKey, nil
+}
+
+// UpdateNetworkIngressCertificate stores the PEM encoded certificate and private key obtained for the
+// Network Ingress with the given ID.
+func (c *ClusterTx) UpdateNetworkIngressCertificate(ctx context.Context, ingressID int64, certificate string, privateKey string) error {
+	res, err := c.tx.ExecContext(ctx, "UPDATE networks_ingresses SET certificate = ?, private_key = ? WHERE id = ?", certificate, privateKey, ingressID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected <= 0 {
+		return api.StatusErrorf(http.StatusNotFound, "Network ingress not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// NodeDevice is a device of a cluster member that can be passed through to instances, along with its allocation.
+type NodeDevice struct {
+	api.ClusterDevice
+
+	NodeID int64
+
+	// InstanceID is the ID of the instance the device is allocated to, or zero if the device is free.
+	InstanceID int64
+}
+
+// GetNodeDevices returns the devices of all cluster members.
+func (c *ClusterTx) GetNodeDevices(ctx context.Context) ([]NodeDevice, error) {
+	q := `
+	SELECT nodes_devices.node_id, nodes.name, nodes_devices.type, nodes_devices.address, nodes_devices.name,
+		nodes_devices.vendor, nodes_devices.vendor_id, nodes_devices.product, nodes_devices.product_id,
+		nodes_devices.serial, nodes_devices.parent, nodes_devices.instance_device,
+		IFNULL(instances.id, 0), IFNULL(instances.name, ''), IFNULL(projects.name, '')
+	FROM nodes_devices
+	JOIN nodes ON nodes.id = nodes_devices.node_id
+	LEFT JOIN instances ON instances.id = nodes_devices.instance_id
+	LEFT JOIN projects ON projects.id = instances.project_id
+	ORDER BY nodes.name, nodes_devices.type, nodes_devices.address
+	`
+
+	devices := []NodeDevice{}
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var device NodeDevice
+		var instanceName, projectName string
+
+		err := scan(&device.NodeID, &device.Location, &device.Type, &device.Address, &device.Name,
+			&device.Vendor, &device.VendorID, &device.Product, &device.ProductID,
+			&device.Serial, &device.Parent, &device.UsedByDevice,
+			&device.InstanceID, &instanceName, &projectName)
+		if err != nil {
+			return err
+		}
+
+		if device.InstanceID != 0 {
+			device.UsedBy = api.NewURL().Path(version.APIVersion, "instances", instanceName).Project(projectName).String()
+		} else {
+			device.UsedByDevice = ""
+		}
+
+		devices = append(devices, device)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading cluster member devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// ReplaceNodeDevices replaces the devices of the given cluster member and their allocations.
+func (c *ClusterTx) ReplaceNodeDevices(ctx context.Context, nodeID int64, devices []NodeDevice) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM nodes_devices WHERE node_id = ?", nodeID)
+	if err != nil {
+		return fmt.Errorf("Failed deleting cluster member devices: %w", err)
+	}
+
+	stmt, err := c.tx.PrepareContext(ctx, `
+	INSERT INTO nodes_devices
+	(node_id, type, address, name, vendor, vendor_id, product, product_id, serial, parent, instance_id, instance_device)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	for _, device := range devices {
+		instanceID := sql.NullInt64{Int64: device.InstanceID, Valid: device.InstanceID != 0}
+
+		_, err = stmt.ExecContext(ctx, nodeID, device.Type, device.Address, device.Name,
+			device.Vendor, device.VendorID, device.Product, device.ProductID,
+			device.Serial, device.Parent, instanceID, device.UsedByDevice)
+		if err != nil {
+			return fmt.Errorf("Failed inserting cluster member device %q: %w", device.Address, err)
+		}
+	}
+
+	return nil
+}
+
+// AllocateNodeDevice allocates the free device of the given cluster member to the instance device.
+// Returns a conflict error if the device doesn't exist or is already allocated.
+func (c *ClusterTx) AllocateNodeDevice(ctx context.Context, nodeID int64, deviceType string, address string, instanceID int64, instanceDevice string) error {
+	result, err := c.tx.ExecContext(ctx, `
+	UPDATE nodes_devices SET instance_id = ?, instance_device = ?
+	WHERE node_id = ? AND type = ? AND address = ? AND instance_id IS NULL
+	`, instanceID, instanceDevice, nodeID, deviceType, address)
+	if err != nil {
+		return fmt.Errorf("Failed allocating cluster member device %q: %w", address, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n != 1 {
+		return api.StatusErrorf(http.StatusConflict, "Cluster member device %q isn't free", address)
+	}
+
+	return nil
+}
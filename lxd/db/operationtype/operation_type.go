@@ -76,6 +76,12 @@ const (
 	RenewServerCertificate
 	RemoveExpiredTokens
 	ClusterHeal
+	StorageBucketBackupCreate
+	StorageBucketBackupRemove
+	StorageBucketBackupRename
+	StorageBucketBackupRestore
+	StoragePoolDeduplicate
+	RenewNetworkIngressCertificates
 )
 
 // Description return a human-readable description of the operation type.
@@ -199,6 +205,18 @@ func (t Type) Description() string {
 		return "Remove expired tokens"
 	case ClusterHeal:
 		return "Healing cluster"
+	case StorageBucketBackupCreate:
+		return "Creating storage bucket backup"
+	case StorageBucketBackupRemove:
+		return "Deleting storage bucket backup"
+	case StorageBucketBackupRename:
+		return "Renaming storage bucket backup"
+	case StorageBucketBackupRestore:
+		return "Restoring storage bucket backup"
+	case StoragePoolDeduplicate:
+		return "Deduplicating storage pool"
+	case RenewNetworkIngressCertificates:
+		return "Renewing network ingress certificates"
 	default:
 		return "Executing operation"
 	}
@@ -280,6 +298,15 @@ func (t Type) Permission() (entity.Type, auth.Entitlement) {
 		return entity.TypeStorageVolume, auth.EntitlementCanManageBackups
 	case CustomVolumeBackupRestore:
 		return entity.TypeStorageVolume, auth.EntitlementCanEdit
+
+	case StorageBucketBackupCreate:
+		return entity.TypeStorageBucket, auth.EntitlementCanEdit
+	case StorageBucketBackupRemove:
+		return entity.TypeStorageBucket, auth.EntitlementCanEdit
+	case StorageBucketBackupRename:
+		return entity.TypeStorageBucket, auth.EntitlementCanEdit
+	case StorageBucketBackupRestore:
+		return entity.TypeStorageBucket, auth.EntitlementCanEdit
 	}
 
 	return "", ""
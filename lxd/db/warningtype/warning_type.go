@@ -58,6 +58,10 @@ const (
 	StoragePoolUnvailable
 	// UnableToUpdateClusterCertificate represents the unable to update cluster certificate warning.
 	UnableToUpdateClusterCertificate
+	// StoragePoolSpaceLow represents a storage pool whose used space reached one of its warning levels.
+	StoragePoolSpaceLow
+	// InstanceWatchdogTriggered represents the watchdog of an instance firing.
+	InstanceWatchdogTriggered
 )
 
 // TypeNames associates a warning code to its name.
@@ -88,6 +92,8 @@ var TypeNames = map[Type]string{
 	InstanceTypeNotOperational:             "Instance type not operational",
 	StoragePoolUnvailable:                  "Storage pool unavailable",
 	UnableToUpdateClusterCertificate:       "Unable to update cluster certificate",
+	StoragePoolSpaceLow:                    "Storage pool space usage above warning level",
+	InstanceWatchdogTriggered:              "Instance watchdog triggered",
 }
 
 // Severity returns the severity of the warning type.
@@ -145,6 +151,10 @@ func (t Type) Severity() Severity {
 		return SeverityHigh
 	case UnableToUpdateClusterCertificate:
 		return SeverityLow
+	case StoragePoolSpaceLow:
+		return SeverityModerate
+	case InstanceWatchdogTriggered:
+		return SeverityModerate
 	}
 
 	return SeverityLow
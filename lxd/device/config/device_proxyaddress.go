@@ -7,3 +7,10 @@ type ProxyAddress struct {
 	Address  string
 	Ports    []uint64
 }
+
+// ProxyHTTPRoute represents a route of a proxy device in HTTP mode.
+type ProxyHTTPRoute struct {
+	Host    string // Host the route applies to, empty for any host.
+	Path    string // Path prefix the route applies to.
+	Connect ProxyAddress
+}
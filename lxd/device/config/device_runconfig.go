@@ -50,6 +50,12 @@ type DiskLimits struct {
 	ReadIOps   int64
 	WriteBytes int64
 	WriteIOps  int64
+
+	// Burst limits, only supported for VMs.
+	ReadBytesBurst  int64
+	ReadIOpsBurst   int64
+	WriteBytesBurst int64
+	WriteIOpsBurst  int64
 }
 
 // RunConfig represents run-time config used for device setup/cleanup.
@@ -64,6 +70,9 @@ type RunConfig struct {
 	USBDevice        []USBDeviceItem  // USB device configuration settings.
 	TPMDevice        []RunConfigItem  // TPM device configuration settings.
 	PCIDevice        []RunConfigItem  // PCI device configuration settings.
+	SerialDevice     []RunConfigItem  // Serial device configuration settings.
+	WatchdogDevice   []RunConfigItem  // Watchdog device configuration settings.
+	SHMDevice        []RunConfigItem  // Shared memory device configuration settings.
 	Revert           revert.Hook      // Revert setup of device on post-setup error.
 }
 
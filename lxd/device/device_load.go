@@ -43,6 +43,8 @@ func newByType(state *state.State, projectName string, conf deviceConfig.Device)
 			dev = &nicSRIOV{}
 		case "ovn":
 			dev = &nicOVN{}
+		case "vdpa":
+			dev = &nicVDPA{}
 		}
 
 	case "infiniband":
@@ -81,6 +83,12 @@ func newByType(state *state.State, projectName string, conf deviceConfig.Device)
 		dev = &tpm{}
 	case "pci":
 		dev = &pci{}
+	case "serial":
+		dev = &serial{}
+	case "watchdog":
+		dev = &watchdog{}
+	case "shm":
+		dev = &shm{}
 	}
 
 	// Check a valid device type has been found.
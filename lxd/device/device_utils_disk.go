@@ -423,8 +423,9 @@ func DiskVMVirtfsProxyStop(pidPath string) error {
 // If the idmaps slice is supplied then the proxy process is run inside a user namespace using the supplied maps.
 // Returns UnsupportedError error if the host system or instance does not support virtiosfd, returns normal error
 // type if process cannot be started for other reasons.
+// If the cacheMode is supplied then it is passed to virtiofsd as its caching mode.
 // Returns revert function and listener file handle on success.
-func DiskVMVirtiofsdStart(execPath string, inst instance.Instance, socketPath string, pidPath string, logPath string, sharePath string, idmaps []idmap.IdmapEntry) (func(), net.Listener, error) {
+func DiskVMVirtiofsdStart(execPath string, inst instance.Instance, socketPath string, pidPath string, logPath string, sharePath string, idmaps []idmap.IdmapEntry, cacheMode string) (func(), net.Listener, error) {
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -499,6 +500,10 @@ func DiskVMVirtiofsdStart(execPath string, inst instance.Instance, socketPath st
 
 	// Start the virtiofsd process in non-daemon mode.
 	args := []string{"--fd=3", "-o", fmt.Sprintf("source=%s", sharePath)}
+	if cacheMode != "" {
+		args = append(args, "-o", fmt.Sprintf("cache=%s", cacheMode))
+	}
+
 	proc, err := subprocess.NewProcess(cmd, args, logPath, logPath)
 	if err != nil {
 		return nil, nil, err
@@ -525,6 +530,35 @@ func DiskVMVirtiofsdStart(execPath string, inst instance.Instance, socketPath st
 	return cleanup, listener, err
 }
 
+// DiskVMShareProtocol returns the protocol used to share the directory of the disk device with the VM.
+// This is "virtiofs" if its virtiofsd process is running, "9p" if only its virtfs-proxy-helper process is
+// running, or an empty string if the directory isn't shared.
+func DiskVMShareProtocol(devicesPath string, deviceName string) string {
+	processRunning := func(pidPath string) bool {
+		if !shared.PathExists(pidPath) {
+			return false
+		}
+
+		proc, err := subprocess.ImportProcess(pidPath)
+		if err != nil {
+			return false
+		}
+
+		_, err = proc.GetPid()
+		return err == nil
+	}
+
+	if processRunning(filepath.Join(devicesPath, fmt.Sprintf("virtio-fs.%s.pid", deviceName))) {
+		return "virtiofs"
+	}
+
+	if processRunning(filepath.Join(devicesPath, fmt.Sprintf("%s.pid", deviceName))) {
+		return "9p"
+	}
+
+	return ""
+}
+
 // DiskVMVirtiofsdStop stops an existing virtiofsd process and cleans up.
 func DiskVMVirtiofsdStop(socketPath string, pidPath string) error {
 	if shared.PathExists(pidPath) {
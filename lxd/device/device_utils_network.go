@@ -820,7 +820,7 @@ func networkSRIOVSetupVF(d deviceCommon, vfParent string, vfDevice string, vfID
 			return vfPCIDev, 0, fmt.Errorf("Failed getting IOMMU group for VF device %q: %w", vfPCIDev.SlotName, err)
 		}
 
-		if d.config["acceleration"] != "vdpa" {
+		if d.config["acceleration"] != "vdpa" && d.config["nictype"] != "vdpa" {
 			// Register VF device with vfio-pci driver so it can be passed to VM.
 			err = pcidev.DeviceDriverOverride(vfPCIDev, "vfio-pci")
 			if err != nil {
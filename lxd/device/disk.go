@@ -43,6 +43,10 @@ const diskSourceCloudInit = "cloud-init:config"
 // the QEMU driver.
 const DiskVirtiofsdSockMountOpt = "virtiofsdSock"
 
+// DiskVirtiofsDAXMountOpt indicates the mount option prefix used to provide the size in bytes of the virtio-fs
+// DAX window to the QEMU driver.
+const DiskVirtiofsDAXMountOpt = "virtiofsDAX"
+
 // DiskFileDescriptorMountPrefix indicates the mount dev path is using a file descriptor rather than a normal path.
 // The Mount.DevPath field will be expected to be in the format: "fd:<fdNum>:<devPath>".
 // It still includes the original dev path so that the instance driver can perform additional probing of the path
@@ -64,6 +68,26 @@ type diskBlockLimit struct {
 	readIops  int64
 	writeBps  int64
 	writeIops int64
+
+	// Burst limits, only supported for VMs.
+	readBpsBurst   int64
+	readIopsBurst  int64
+	writeBpsBurst  int64
+	writeIopsBurst int64
+}
+
+// diskLimitKeys are the keys of the disk I/O limits.
+var diskLimitKeys = []string{"limits.read", "limits.write", "limits.max", "limits.iops.read", "limits.iops.write", "limits.read.burst", "limits.write.burst", "limits.iops.read.burst", "limits.iops.write.burst"}
+
+// diskHasLimits returns whether the disk device has I/O limits set.
+func diskHasLimits(dev deviceConfig.Device) bool {
+	for _, key := range diskLimitKeys {
+		if dev[key] != "" {
+			return true
+		}
+	}
+
+	return false
 }
 
 // diskSourceNotFoundError error used to indicate source not found.
@@ -125,6 +149,11 @@ func (d *disk) CanHotPlug() bool {
 		return false
 	}
 
+	// Paths shared using 9p only can't be hot-plugged.
+	if d.config["share.protocol"] == "9p" {
+		return false
+	}
+
 	// Block disks can be hot-plugged into VMs.
 	return true
 }
@@ -245,6 +274,54 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		//  required: no
 		//  shortdesc: I/O limit in byte/s or IOPS for both read and write
 		"limits.max": validate.IsAny,
+		// lxdmeta:generate(entities=device-disk; group=device-conf; key=limits.iops.read)
+		// Can't be combined with an IOPS value in {config:option}`device-disk-device-conf:limits.read` or {config:option}`device-disk-device-conf:limits.max`.
+		// See also {ref}`storage-configure-io`.
+		// ---
+		//  type: integer
+		//  required: no
+		//  shortdesc: Read I/O limit in IOPS
+		"limits.iops.read": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=device-disk; group=device-conf; key=limits.iops.write)
+		// Can't be combined with an IOPS value in {config:option}`device-disk-device-conf:limits.write` or {config:option}`device-disk-device-conf:limits.max`.
+		// See also {ref}`storage-configure-io`.
+		// ---
+		//  type: integer
+		//  required: no
+		//  shortdesc: Write I/O limit in IOPS
+		"limits.iops.write": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=device-disk; group=device-conf; key=limits.read.burst)
+		// The disk can read at this rate for up to one second before being throttled to the read limit in byte/s.
+		// This option is supported only for virtual machines.
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: Read burst limit in byte/s
+		"limits.read.burst": validate.Optional(validate.IsSize),
+		// lxdmeta:generate(entities=device-disk; group=device-conf; key=limits.write.burst)
+		// The disk can write at this rate for up to one second before being throttled to the write limit in byte/s.
+		// This option is supported only for virtual machines.
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: Write burst limit in byte/s
+		"limits.write.burst": validate.Optional(validate.IsSize),
+		// lxdmeta:generate(entities=device-disk; group=device-conf; key=limits.iops.read.burst)
+		// The disk can read at this rate for up to one second before being throttled to the read limit in IOPS.
+		// This option is supported only for virtual machines.
+		// ---
+		//  type: integer
+		//  required: no
+		//  shortdesc: Read burst limit in IOPS
+		"limits.iops.read.burst": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=device-disk; group=device-conf; key=limits.iops.write.burst)
+		// The disk can write at this rate for up to one second before being throttled to the write limit in IOPS.
+		// This option is supported only for virtual machines.
+		// ---
+		//  type: integer
+		//  required: no
+		//  shortdesc: Write burst limit in IOPS
+		"limits.iops.write.burst": validate.Optional(validate.IsUint32),
 		// lxdmeta:generate(entities=device-disk; group=device-conf; key=size)
 		// This option is supported only for the rootfs (`/`).
 		//
@@ -322,6 +399,7 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		"path": validate.IsAny,
 		// lxdmeta:generate(entities=device-disk; group=device-conf; key=io.cache)
 		// Possible values are `none`, `writeback`, or `unsafe`.
+		// For directories shared using `virtiofs`, they map to the `never`, `auto` and `always` caching modes of `virtiofsd`.
 		// ---
 		//  type: string
 		//  defaultdesc: `none`
@@ -330,14 +408,33 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		//  shortdesc: Caching mode for the device
 		"io.cache": validate.Optional(validate.IsOneOf("none", "writeback", "unsafe")),
 		// lxdmeta:generate(entities=device-disk; group=device-conf; key=io.bus)
-		// Possible values are `virtio-scsi` or `nvme`.
+		// Possible values are `virtio-scsi`, `virtio-blk` or `nvme`.
 		// ---
 		//  type: string
 		//  defaultdesc: `virtio-scsi`
 		//  required: no
 		//  condition: virtual machine
 		//  shortdesc: Bus for the device
-		"io.bus": validate.Optional(validate.IsOneOf("virtio-scsi", "nvme")),
+		"io.bus": validate.Optional(validate.IsOneOf("virtio-scsi", "virtio-blk", "nvme")),
+		// lxdmeta:generate(entities=device-disk; group=device-conf; key=share.protocol)
+		// Possible values are `auto` (use `virtiofs` and fall back to `9p` if it isn't available), `virtiofs`, or `9p`.
+		// Only `virtiofs` shares can be added to a running virtual machine.
+		// ---
+		//  type: string
+		//  defaultdesc: `auto`
+		//  required: no
+		//  condition: virtual machine
+		//  shortdesc: Protocol used to share a directory
+		"share.protocol": validate.Optional(validate.IsOneOf("auto", "virtiofs", "9p")),
+		// lxdmeta:generate(entities=device-disk; group=device-conf; key=virtiofs.dax)
+		// The guest maps the shared files in a window of this size, which avoids copying them into its page cache.
+		// Requires QEMU support for virtio-fs DAX.
+		// ---
+		//  type: string
+		//  required: no
+		//  condition: virtual machine
+		//  shortdesc: Size of the virtio-fs DAX window
+		"virtiofs.dax": validate.Optional(validate.IsSize),
 	}
 
 	err := d.config.Validate(rules)
@@ -353,6 +450,14 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("IO cache configuration cannot be applied to containers")
 	}
 
+	if instConf.Type() == instancetype.Container && (d.config["share.protocol"] != "" || d.config["virtiofs.dax"] != "") {
+		return fmt.Errorf("Share protocol configuration cannot be applied to containers")
+	}
+
+	if d.config["virtiofs.dax"] != "" && d.config["share.protocol"] == "9p" {
+		return fmt.Errorf(`The "virtiofs.dax" property cannot be used with the "9p" share protocol`)
+	}
+
 	if d.config["required"] != "" && d.config["optional"] != "" {
 		return fmt.Errorf(`Cannot use both "required" and deprecated "optional" properties at the same time`)
 	}
@@ -385,6 +490,18 @@ func (d *disk) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("Recursive read-only bind-mounts aren't currently supported by the kernel")
 	}
 
+	// Check the dedicated IOPS and burst limits are consistent with the other limits.
+	if d.config["limits.iops.read"] != "" || d.config["limits.iops.write"] != "" || d.config["limits.read.burst"] != "" || d.config["limits.write.burst"] != "" || d.config["limits.iops.read.burst"] != "" || d.config["limits.iops.write.burst"] != "" {
+		limit, err := d.parseLimit(d.config)
+		if err != nil {
+			return fmt.Errorf("Invalid I/O limits: %w", err)
+		}
+
+		if instConf.Type() == instancetype.Container && (limit.readBpsBurst > 0 || limit.readIopsBurst > 0 || limit.writeBpsBurst > 0 || limit.writeIopsBurst > 0) {
+			return fmt.Errorf("Burst I/O limits are only supported for virtual machines")
+		}
+	}
+
 	// Check ceph options are only used when ceph or cephfs type source is specified.
 	if !(d.sourceIsCeph() || d.sourceIsCephFs()) && (d.config["ceph.cluster_name"] != "" || d.config["ceph.user_name"] != "") {
 		return fmt.Errorf("Invalid options ceph.cluster_name/ceph.user_name for source %q", d.config["source"])
@@ -610,7 +727,7 @@ func (d *disk) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.max", "limits.read", "limits.write", "size", "size.state"}
+	return append([]string{"size", "size.state"}, diskLimitKeys...)
 }
 
 // Register calls mount for the disk volume (which should already be mounted) to reinitialise the reference counter
@@ -701,7 +818,7 @@ func (d *disk) startContainer() (*deviceConfig.RunConfig, error) {
 	runConf.PostHooks = append(runConf.PostHooks, func() error {
 		runConf := deviceConfig.RunConfig{}
 
-		err := d.generateLimits(&runConf)
+		err := d.generateLimits(&runConf, false)
 		if err != nil {
 			return err
 		}
@@ -908,19 +1025,14 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 
 	// Add I/O limits if set.
 	var diskLimits *deviceConfig.DiskLimits
-	if d.config["limits.read"] != "" || d.config["limits.write"] != "" || d.config["limits.max"] != "" {
+	if diskHasLimits(d.config) {
 		// Parse the limits into usable values.
-		readBps, readIops, writeBps, writeIops, err := d.parseLimit(d.config)
+		limit, err := d.parseLimit(d.config)
 		if err != nil {
 			return nil, err
 		}
 
-		diskLimits = &deviceConfig.DiskLimits{
-			ReadBytes:  readBps,
-			ReadIOps:   readIops,
-			WriteBytes: writeBps,
-			WriteIOps:  writeIops,
-		}
+		diskLimits = limit.diskLimits()
 	}
 
 	if instancetype.IsRootDiskDevice(d.config) {
@@ -1110,17 +1222,47 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 					rawIDMaps = diskAddRootUserNSEntry(rawIDMaps, 65534)
 				}
 
+				shareProtocol := d.config["share.protocol"]
+				if shareProtocol == "" {
+					shareProtocol = "auto"
+				}
+
 				// Start virtiofsd for virtio-fs share. The lxd-agent prefers to use this over the
 				// virtfs-proxy-helper 9p share. The 9p share will only be used as a fallback.
 				err = func() error {
+					if shareProtocol == "9p" {
+						return nil
+					}
+
 					sockPath, pidPath := d.vmVirtiofsdPaths()
 					logPath := filepath.Join(d.inst.LogPath(), fmt.Sprintf("disk.%s.log", d.name))
 					_ = os.Remove(logPath) // Remove old log if needed.
 
-					revertFunc, unixListener, err := DiskVMVirtiofsdStart(d.state.OS.ExecPath, d.inst, sockPath, pidPath, logPath, mount.DevPath, rawIDMaps)
+					// Map the disk caching mode to the virtiofsd one.
+					cacheMode := ""
+					switch d.config["io.cache"] {
+					case "none":
+						cacheMode = "never"
+					case "writeback":
+						cacheMode = "auto"
+					case "unsafe":
+						cacheMode = "always"
+					}
+
+					revertFunc, unixListener, err := DiskVMVirtiofsdStart(d.state.OS.ExecPath, d.inst, sockPath, pidPath, logPath, mount.DevPath, rawIDMaps, cacheMode)
 					if err != nil {
 						var errUnsupported UnsupportedError
 						if errors.As(err, &errUnsupported) {
+							// Without 9p fallback the share can't be added at all, so report why.
+							if shareProtocol == "virtiofs" {
+								return fmt.Errorf("Unable to use virtio-fs: %w", err)
+							}
+
+							// The 9p share can't be hot-plugged so there is no fallback for running instances.
+							if d.inst.IsRunning() {
+								return fmt.Errorf("Unable to use virtio-fs, which is required to add a directory share to a running instance: %w", err)
+							}
+
 							d.logger.Warn("Unable to use virtio-fs for device, using 9p as a fallback", logger.Ctx{"err": errUnsupported})
 
 							if errUnsupported == ErrMissingVirtiofsd {
@@ -1155,6 +1297,15 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 					// QEMU driver also setup the virtio-fs share.
 					mount.Opts = append(mount.Opts, fmt.Sprintf("%s=%s", DiskVirtiofsdSockMountOpt, sockPath))
 
+					if d.config["virtiofs.dax"] != "" {
+						daxSize, err := units.ParseByteSizeString(d.config["virtiofs.dax"])
+						if err != nil {
+							return err
+						}
+
+						mount.Opts = append(mount.Opts, fmt.Sprintf("%s=%d", DiskVirtiofsDAXMountOpt, daxSize))
+					}
+
 					return nil
 				}()
 				if err != nil {
@@ -1162,7 +1313,10 @@ func (d *disk) startVM() (*deviceConfig.RunConfig, error) {
 				}
 
 				// We can't hotplug 9p shares, so only do 9p for stopped instances.
-				if !d.inst.IsRunning() {
+				if shareProtocol == "virtiofs" {
+					// Indicate to the QEMU driver that there is no 9p share.
+					mount.DevPath = ""
+				} else if !d.inst.IsRunning() {
 					// Start virtfs-proxy-helper for 9p share (this will rewrite mount.DevPath with
 					// socket FD number so must come after starting virtiofsd).
 					err = func() error {
@@ -1292,7 +1446,8 @@ func (d *disk) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 		runConf := deviceConfig.RunConfig{}
 
 		if d.inst.Type() == instancetype.Container {
-			err := d.generateLimits(&runConf)
+			// Reset the limits which were removed if the disk had limits, as the cgroup keeps them otherwise.
+			err := d.generateLimits(&runConf, diskHasLimits(oldDevices[d.name]))
 			if err != nil {
 				return err
 			}
@@ -1300,23 +1455,16 @@ func (d *disk) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 
 		if d.inst.Type() == instancetype.VM {
 			// Parse the limits into usable values.
-			readBps, readIops, writeBps, writeIops, err := d.parseLimit(d.config)
+			limit, err := d.parseLimit(d.config)
 			if err != nil {
 				return err
 			}
 
-			// Apply the limits to a minimal mount entry.
-			diskLimits := &deviceConfig.DiskLimits{
-				ReadBytes:  readBps,
-				ReadIOps:   readIops,
-				WriteBytes: writeBps,
-				WriteIOps:  writeIops,
-			}
-
+			// Apply the limits to a minimal mount entry, this replaces all the previous limits.
 			runConf.Mounts = []deviceConfig.MountEntryItem{
 				{
 					DevName: d.name,
-					Limits:  diskLimits,
+					Limits:  limit.diskLimits(),
 				},
 			}
 		}
@@ -1397,7 +1545,9 @@ func (d *disk) applyQuota(remount bool) error {
 }
 
 // generateLimits adds a set of cgroup rules to apply specified limits to the supplied RunConfig.
-func (d *disk) generateLimits(runConf *deviceConfig.RunConfig) error {
+// If reset is true, rules removing the limits of the block devices without limits are also added, which is
+// needed when updating the limits of a running container.
+func (d *disk) generateLimits(runConf *deviceConfig.RunConfig, reset bool) error {
 	// Disk throttle limits.
 	hasDiskLimits := false
 	for _, dev := range d.inst.ExpandedDevices() {
@@ -1405,12 +1555,17 @@ func (d *disk) generateLimits(runConf *deviceConfig.RunConfig) error {
 			continue
 		}
 
-		if dev["limits.read"] != "" || dev["limits.write"] != "" || dev["limits.max"] != "" {
+		if diskHasLimits(dev) {
 			hasDiskLimits = true
 		}
 	}
 
-	if hasDiskLimits {
+	// Removing limits is only possible if the controller is available.
+	if reset && !hasDiskLimits && !d.state.OS.CGInfo.Supports(cgroup.Blkio, nil) {
+		return nil
+	}
+
+	if hasDiskLimits || reset {
 		if !d.state.OS.CGInfo.Supports(cgroup.Blkio, nil) {
 			return fmt.Errorf("Cannot apply disk limits as blkio cgroup controller is missing")
 		}
@@ -1426,28 +1581,29 @@ func (d *disk) generateLimits(runConf *deviceConfig.RunConfig) error {
 		}
 
 		for block, limit := range diskLimits {
-			if limit.readBps > 0 {
+			// A zero limit removes any previous limit when resetting.
+			if limit.readBps > 0 || reset {
 				err = cg.SetBlkioLimit(block, "read", "bps", limit.readBps)
 				if err != nil {
 					return err
 				}
 			}
 
-			if limit.readIops > 0 {
+			if limit.readIops > 0 || reset {
 				err = cg.SetBlkioLimit(block, "read", "iops", limit.readIops)
 				if err != nil {
 					return err
 				}
 			}
 
-			if limit.writeBps > 0 {
+			if limit.writeBps > 0 || reset {
 				err = cg.SetBlkioLimit(block, "write", "bps", limit.writeBps)
 				if err != nil {
 					return err
 				}
 			}
 
-			if limit.writeIops > 0 {
+			if limit.writeIops > 0 || reset {
 				err = cg.SetBlkioLimit(block, "write", "iops", limit.writeIops)
 				if err != nil {
 					return err
@@ -2053,7 +2209,7 @@ func (d *disk) getDiskLimits() (map[string]diskBlockLimit, error) {
 		}
 
 		// Parse the user input
-		readBps, readIops, writeBps, writeIops, err := d.parseLimit(dev)
+		limit, err := d.parseLimit(dev)
 		if err != nil {
 			return nil, err
 		}
@@ -2076,7 +2232,7 @@ func (d *disk) getDiskLimits() (map[string]diskBlockLimit, error) {
 		// Get the backing block devices (major:minor)
 		blocks, err := d.getParentBlocks(source)
 		if err != nil {
-			if readBps == 0 && readIops == 0 && writeBps == 0 && writeIops == 0 {
+			if limit.readBps == 0 && limit.readIops == 0 && limit.writeBps == 0 && limit.writeIops == 0 {
 				// If the device doesn't exist, there is no limit to clear so ignore the failure
 				continue
 			} else {
@@ -2084,7 +2240,7 @@ func (d *disk) getDiskLimits() (map[string]diskBlockLimit, error) {
 			}
 		}
 
-		device := diskBlockLimit{readBps: readBps, readIops: readIops, writeBps: writeBps, writeIops: writeIops}
+		device := diskBlockLimit{readBps: limit.readBps, readIops: limit.readIops, writeBps: limit.writeBps, writeIops: limit.writeIops}
 		for _, block := range blocks {
 			blockStr := ""
 
@@ -2163,7 +2319,10 @@ func (d *disk) getDiskLimits() (map[string]diskBlockLimit, error) {
 }
 
 // parseLimit parses the disk configuration for its I/O limits and returns the I/O bytes/iops limits.
-func (d *disk) parseLimit(dev deviceConfig.Device) (readBps int64, readIops int64, writeBps int64, writeIops int64, err error) {
+func (d *disk) parseLimit(dev deviceConfig.Device) (diskBlockLimit, error) {
+	var limit diskBlockLimit
+	var err error
+
 	readSpeed := dev["limits.read"]
 	writeSpeed := dev["limits.write"]
 
@@ -2194,19 +2353,123 @@ func (d *disk) parseLimit(dev deviceConfig.Device) (readBps int64, readIops int6
 		return bps, iops, nil
 	}
 
+	// parseIops parses a dedicated IOPS limit.
+	parseIops := func(key string) (int64, error) {
+		if dev[key] == "" {
+			return 0, nil
+		}
+
+		iops, err := strconv.ParseInt(dev[key], 10, 64)
+		if err != nil {
+			return -1, fmt.Errorf("Invalid value for %q: %w", key, err)
+		}
+
+		return iops, nil
+	}
+
+	// parseBytes parses a dedicated byte/s limit.
+	parseBytes := func(key string) (int64, error) {
+		if dev[key] == "" {
+			return 0, nil
+		}
+
+		bps, err := units.ParseByteSizeString(dev[key])
+		if err != nil {
+			return -1, fmt.Errorf("Invalid value for %q: %w", key, err)
+		}
+
+		return bps, nil
+	}
+
 	// Process reads.
-	readBps, readIops, err = parseValue(readSpeed)
+	limit.readBps, limit.readIops, err = parseValue(readSpeed)
 	if err != nil {
-		return -1, -1, -1, -1, err
+		return diskBlockLimit{}, err
 	}
 
 	// Process writes.
-	writeBps, writeIops, err = parseValue(writeSpeed)
+	limit.writeBps, limit.writeIops, err = parseValue(writeSpeed)
 	if err != nil {
-		return -1, -1, -1, -1, err
+		return diskBlockLimit{}, err
+	}
+
+	// Process the dedicated IOPS limits.
+	for _, l := range []struct {
+		key   string
+		value *int64
+	}{
+		{key: "limits.iops.read", value: &limit.readIops},
+		{key: "limits.iops.write", value: &limit.writeIops},
+	} {
+		iops, err := parseIops(l.key)
+		if err != nil {
+			return diskBlockLimit{}, err
+		}
+
+		if iops == 0 {
+			continue
+		}
+
+		if *l.value > 0 {
+			return diskBlockLimit{}, fmt.Errorf("%q can't be used with an IOPS value in %q or %q", l.key, "limits."+strings.TrimPrefix(l.key, "limits.iops."), "limits.max")
+		}
+
+		*l.value = iops
+	}
+
+	// Process the burst limits.
+	for _, l := range []struct {
+		key   string
+		iops  bool
+		base  int64
+		value *int64
+	}{
+		{key: "limits.read.burst", base: limit.readBps, value: &limit.readBpsBurst},
+		{key: "limits.write.burst", base: limit.writeBps, value: &limit.writeBpsBurst},
+		{key: "limits.iops.read.burst", iops: true, base: limit.readIops, value: &limit.readIopsBurst},
+		{key: "limits.iops.write.burst", iops: true, base: limit.writeIops, value: &limit.writeIopsBurst},
+	} {
+		var burst int64
+		if l.iops {
+			burst, err = parseIops(l.key)
+		} else {
+			burst, err = parseBytes(l.key)
+		}
+
+		if err != nil {
+			return diskBlockLimit{}, err
+		}
+
+		if burst == 0 {
+			continue
+		}
+
+		if l.base == 0 {
+			return diskBlockLimit{}, fmt.Errorf("%q requires the matching limit to be set", l.key)
+		}
+
+		if burst < l.base {
+			return diskBlockLimit{}, fmt.Errorf("%q can't be lower than the matching limit", l.key)
+		}
+
+		*l.value = burst
 	}
 
-	return readBps, readIops, writeBps, writeIops, nil
+	return limit, nil
+}
+
+// diskLimits returns the limits in the form used by the instance drivers.
+func (l diskBlockLimit) diskLimits() *deviceConfig.DiskLimits {
+	return &deviceConfig.DiskLimits{
+		ReadBytes:       l.readBps,
+		ReadIOps:        l.readIops,
+		WriteBytes:      l.writeBps,
+		WriteIOps:       l.writeIops,
+		ReadBytesBurst:  l.readBpsBurst,
+		ReadIOpsBurst:   l.readIopsBurst,
+		WriteBytesBurst: l.writeBpsBurst,
+		WriteIOpsBurst:  l.writeIopsBurst,
+	}
 }
 
 func (d *disk) getParentBlocks(path string) ([]string, error) {
@@ -31,7 +31,7 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  managed: no
 		//  shortdesc: Name of the interface inside the instance
 
-		// lxdmeta:generate(entities=device-nic-{ipvlan+p2p+routed}; group=device-conf; key=name)
+		// lxdmeta:generate(entities=device-nic-{ipvlan+p2p+routed+vdpa}; group=device-conf; key=name)
 		//
 		// ---
 		//  type: string
@@ -58,6 +58,14 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		// ---
 		//  type: string
 		//  shortdesc: Name of the host device to join the instance to
+
+		// lxdmeta:generate(entities=device-nic-vdpa; group=device-conf; key=parent)
+		// This can be either an SR-IOV capable network interface, from which a virtual function is allocated,
+		// or a vDPA management device (for example, `vdpasim_net` or `pci/0000:01:00.2`).
+		// ---
+		//  type: string
+		//  required: yes
+		//  shortdesc: Name of the host device to allocate the vDPA device from
 		"parent": validate.IsAny,
 		// lxdmeta:generate(entities=device-nic-{bridged+macvlan+sriov+physical}; group=device-conf; key=network)
 		// You can specify this option instead of specifying the `nictype` directly.
@@ -90,6 +98,13 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  managed: yes
 		//  shortdesc: MTU of the new interface
 
+		// lxdmeta:generate(entities=device-nic-vdpa; group=device-conf; key=mtu)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: vDPA device default
+		//  shortdesc: MTU of the new interface
+
 		// lxdmeta:generate(entities=device-nic-physical; group=device-conf; key=mtu)
 		//
 		// ---
@@ -133,6 +148,12 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  managed: no
 		//  shortdesc: VLAN ID to attach to
 
+		// lxdmeta:generate(entities=device-nic-vdpa; group=device-conf; key=vlan)
+		// Only supported when the parent is an SR-IOV capable network interface.
+		// ---
+		//  type: integer
+		//  shortdesc: VLAN ID to attach to
+
 		// lxdmeta:generate(entities=device-nic-ovn; group=device-conf; key=vlan)
 		// See also {config:option}`device-nic-ovn-device-conf:nested`.
 		// ---
@@ -169,7 +190,7 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  managed: no
 		//  shortdesc: MAC address of the new interface
 
-		// lxdmeta:generate(entities=device-nic-{ipvlan+p2p+routed}; group=device-conf; key=hwaddr)
+		// lxdmeta:generate(entities=device-nic-{ipvlan+p2p+routed+vdpa}; group=device-conf; key=hwaddr)
 		//
 		// ---
 		//  type: string
@@ -430,7 +451,7 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  managed: no
 		//  shortdesc: Boot priority for VMs
 
-		// lxdmeta:generate(entities=device-nic-p2p; group=device-conf; key=boot.priority)
+		// lxdmeta:generate(entities=device-nic-{p2p+vdpa}; group=device-conf; key=boot.priority)
 		// A higher value for this option means that the VM boots first.
 		// ---
 		//  type: integer
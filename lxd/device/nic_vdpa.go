@@ -0,0 +1,261 @@
+package device
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/ip"
+	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/revert"
+)
+
+type nicVDPA struct {
+	deviceCommon
+}
+
+// CanHotPlug returns whether the device can be managed whilst the instance is running. Returns true.
+func (d *nicVDPA) CanHotPlug() bool {
+	return true
+}
+
+// validateConfig checks the supplied config for correctness.
+func (d *nicVDPA) validateConfig(instConf instance.ConfigReader) error {
+	if !instanceSupported(instConf.Type(), instancetype.VM) {
+		return ErrUnsupportedDevType
+	}
+
+	requiredFields := []string{
+		"parent",
+	}
+
+	optionalFields := []string{
+		"name",
+		"hwaddr",
+		"mtu",
+		"vlan",
+		"boot.priority",
+	}
+
+	err := d.config.Validate(nicValidationRules(requiredFields, optionalFields, instConf))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateEnvironment checks the runtime environment for correctness.
+func (d *nicVDPA) validateEnvironment() error {
+	if shared.IsTrue(d.inst.ExpandedConfig()["migration.stateful"]) {
+		return fmt.Errorf("Network vDPA devices cannot be used when migration.stateful is enabled")
+	}
+
+	// When the parent isn't a network interface, it must be a vDPA management device.
+	if !network.InterfaceExists(d.config["parent"]) {
+		if d.config["vlan"] != "" {
+			return fmt.Errorf("The %q property can only be used when the parent is an SR-IOV network interface", "vlan")
+		}
+
+		_, err := d.managementDevice()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// managementDevice returns the vDPA management device referenced by the parent property.
+// The parent can be specified either as "<bus>/<device>" or as the device name alone.
+func (d *nicVDPA) managementDevice() (*ip.MgmtVDPADev, error) {
+	mgmtDevs, err := ip.ListVDPAMgmtDevices()
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing vDPA management devices: %w", err)
+	}
+
+	busName, devName, found := strings.Cut(d.config["parent"], "/")
+	if !found {
+		busName, devName = "", busName
+	}
+
+	for _, mgmtDev := range mgmtDevs {
+		if mgmtDev.DevName == devName && (!found || mgmtDev.BusName == busName) {
+			return mgmtDev, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Parent device %q is neither a network interface nor a vDPA management device", d.config["parent"])
+}
+
+// Start is run when the device is added to the instance.
+func (d *nicVDPA) Start() (*deviceConfig.RunConfig, error) {
+	err := util.LoadModule("vdpa")
+	if err != nil {
+		return nil, fmt.Errorf("Error loading %q module: %w", "vdpa", err)
+	}
+
+	err = util.LoadModule("vhost_vdpa")
+	if err != nil {
+		return nil, fmt.Errorf("Error loading %q module: %w", "vhost_vdpa", err)
+	}
+
+	err = d.validateEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	saveData := make(map[string]string)
+
+	var hwaddr net.HardwareAddr
+	if d.config["hwaddr"] != "" {
+		hwaddr, err = net.ParseMAC(d.config["hwaddr"])
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing MAC address %q: %w", d.config["hwaddr"], err)
+		}
+	}
+
+	var mtu uint64
+	if d.config["mtu"] != "" {
+		mtu, err = strconv.ParseUint(d.config["mtu"], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid MTU specified %q: %w", d.config["mtu"], err)
+		}
+	}
+
+	var vDPADevice *ip.VDPADev
+	runConf := deviceConfig.RunConfig{}
+
+	if network.InterfaceExists(d.config["parent"]) {
+		// Find free VF exclusively.
+		network.SRIOVVirtualFunctionMutex.Lock()
+		vfDev, vfID, err := network.SRIOVFindFreeVirtualFunction(d.state, d.config["parent"])
+		if err != nil {
+			network.SRIOVVirtualFunctionMutex.Unlock()
+			return nil, err
+		}
+
+		// Claim the SR-IOV virtual function (VF) on the parent (PF) and get the PCI information.
+		vfPCIDev, pciIOMMUGroup, err := networkSRIOVSetupVF(d.deviceCommon, d.config["parent"], vfDev, vfID, false, saveData)
+		if err != nil {
+			network.SRIOVVirtualFunctionMutex.Unlock()
+			return nil, err
+		}
+
+		revert.Add(func() {
+			_ = networkSRIOVRestoreVF(d.deviceCommon, false, saveData)
+		})
+
+		// Create the vDPA device on the VF.
+		vDPADevice, err = ip.CreateVDPADevice(&ip.MgmtVDPADev{BusName: "pci", DevName: vfPCIDev.SlotName}, hwaddr, uint16(mtu), saveData)
+		if err != nil {
+			network.SRIOVVirtualFunctionMutex.Unlock()
+			return nil, err
+		}
+
+		network.SRIOVVirtualFunctionMutex.Unlock()
+
+		runConf.NetworkInterface = append(runConf.NetworkInterface,
+			[]deviceConfig.RunConfigItem{
+				{Key: "pciSlotName", Value: vfPCIDev.SlotName},
+				{Key: "pciIOMMUGroup", Value: fmt.Sprintf("%d", pciIOMMUGroup)},
+			}...)
+	} else {
+		mgmtDev, err := d.managementDevice()
+		if err != nil {
+			return nil, err
+		}
+
+		vDPADevice, err = ip.CreateVDPADevice(mgmtDev, hwaddr, uint16(mtu), saveData)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	revert.Add(func() { _ = ip.DeleteVDPADevice(vDPADevice.Name) })
+
+	err = d.volatileSet(saveData)
+	if err != nil {
+		return nil, err
+	}
+
+	runConf.NetworkInterface = append(runConf.NetworkInterface,
+		[]deviceConfig.RunConfigItem{
+			{Key: "devName", Value: d.name},
+			{Key: "name", Value: d.config["name"]},
+			{Key: "hwaddr", Value: d.config["hwaddr"]},
+			{Key: "mtu", Value: d.config["mtu"]},
+			{Key: "maxVQP", Value: fmt.Sprintf("%d", vDPADevice.MaxVQs/2)},
+			{Key: "vDPADevName", Value: vDPADevice.Name},
+			{Key: "vhostVDPAPath", Value: vDPADevice.VhostVDPA.Path},
+		}...)
+
+	revert.Success()
+	return &runConf, nil
+}
+
+// Stop is run when the device is removed from the instance.
+func (d *nicVDPA) Stop() (*deviceConfig.RunConfig, error) {
+	runConf := deviceConfig.RunConfig{
+		PostHooks: []func() error{d.postStop},
+	}
+
+	return &runConf, nil
+}
+
+// postStop is run after the device is removed from the instance.
+func (d *nicVDPA) postStop() error {
+	defer func() {
+		_ = d.volatileSet(map[string]string{
+			"host_name":                "",
+			"last_state.hwaddr":        "",
+			"last_state.mtu":           "",
+			"last_state.created":       "",
+			"last_state.vdpa.name":     "",
+			"last_state.vf.parent":     "",
+			"last_state.vf.id":         "",
+			"last_state.vf.hwaddr":     "",
+			"last_state.vf.vlan":       "",
+			"last_state.vf.spoofcheck": "",
+			"last_state.pci.driver":    "",
+		})
+	}()
+
+	v := d.volatileGet()
+	errs := []error{}
+
+	// Delete the vDPA device.
+	if v["last_state.vdpa.name"] != "" {
+		err := ip.DeleteVDPADevice(v["last_state.vdpa.name"])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Failed deleting vDPA device %q: %w", v["last_state.vdpa.name"], err))
+		}
+	}
+
+	// Restore the VF if the vDPA device was created on one, even if the vDPA device couldn't be deleted.
+	if v["last_state.vf.parent"] != "" {
+		network.SRIOVVirtualFunctionMutex.Lock()
+		err := networkSRIOVRestoreVF(d.deviceCommon, false, v)
+		network.SRIOVVirtualFunctionMutex.Unlock()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Failed restoring VF: %w", err))
+		}
+	}
+
+	if len(errs) == 1 {
+		return errs[0]
+	} else if len(errs) > 1 {
+		return fmt.Errorf("Failed stopping vDPA device: %v", errs)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package device
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"os"
@@ -34,6 +35,50 @@ import (
 	"github.com/canonical/lxd/shared/validate"
 )
 
+// ProxyRedactDevices removes the TLS private keys of the proxy devices from devices, so that they aren't
+// returned to API clients.
+func ProxyRedactDevices(devices map[string]map[string]string) {
+	for devName, dev := range devices {
+		if dev["type"] != "proxy" || dev["tls.key"] == "" {
+			continue
+		}
+
+		redacted := make(map[string]string, len(dev))
+		for k, v := range dev {
+			if k != "tls.key" {
+				redacted[k] = v
+			}
+		}
+
+		devices[devName] = redacted
+	}
+}
+
+// ProxyRestoreRedactedKeys sets the TLS private key of the proxy devices in newDevices that don't specify one to
+// the key of the matching device in oldDevices, as long as their certificate is unchanged. This allows clients to
+// send back the devices as returned by ProxyRedactDevices.
+func ProxyRestoreRedactedKeys(oldDevices map[string]map[string]string, newDevices map[string]map[string]string) {
+	for devName, dev := range newDevices {
+		oldDev, ok := oldDevices[devName]
+		if !ok || dev["type"] != "proxy" || oldDev["type"] != "proxy" {
+			continue
+		}
+
+		_, ok = dev["tls.key"]
+		if ok || oldDev["tls.key"] == "" || dev["tls.cert"] != oldDev["tls.cert"] {
+			continue
+		}
+
+		restored := make(map[string]string, len(dev)+1)
+		for k, v := range dev {
+			restored[k] = v
+		}
+
+		restored["tls.key"] = oldDev["tls.key"]
+		newDevices[devName] = restored
+	}
+}
+
 type proxy struct {
 	deviceCommon
 }
@@ -51,6 +96,10 @@ type proxyProcInfo struct {
 	securityUID    string
 	securityGID    string
 	proxyProtocol  string
+	tlsCertFd      string
+	tlsKeyFd       string
+	proxyMode      string
+	httpRoutes     string
 	inheritFds     []*os.File
 }
 
@@ -159,6 +208,43 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		//  required: no
 		//  shortdesc: Whether to use the HAProxy PROXY protocol
 		"proxy_protocol": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=device-proxy; group=device-conf; key=proxy_mode)
+		// Possible values are `raw` and `http`.
+		// In `http` mode, requests are routed to the `connect` address or to one of the {config:option}`device-proxy-device-conf:http.routes`.
+		// See {ref}`devices-proxy-http` for more information.
+		// ---
+		//  type: string
+		//  defaultdesc: `raw`
+		//  required: no
+		//  shortdesc: Whether to forward raw connections or route HTTP requests
+		"proxy_mode": validate.Optional(validate.IsOneOf("raw", "http")),
+		// lxdmeta:generate(entities=device-proxy; group=device-conf; key=http.routes)
+		// Specify a comma-separated list of routes in the form `<host>[/<path>]=<connect address>`, for example `app1.example.com=tcp:127.0.0.1:8001,example.com/api=tcp:127.0.0.1:8002`.
+		// Omit the host to match any host.
+		// Requests that don't match any route are sent to the `connect` address.
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: Routes of the HTTP requests to other addresses
+		"http.routes": validate.Optional(func(value string) error {
+			_, err := network.ProxyParseHTTPRoutes(value)
+			return err
+		}),
+		// lxdmeta:generate(entities=device-proxy; group=device-conf; key=tls.cert)
+		// Specify the certificate in PEM format.
+		// If set, the proxy terminates TLS on the listen address, using {config:option}`device-proxy-device-conf:tls.key` as the private key.
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: Certificate used to terminate TLS
+		"tls.cert": validate.Optional(validate.IsX509Certificate),
+		// lxdmeta:generate(entities=device-proxy; group=device-conf; key=tls.key)
+		// Specify the private key in PEM format.
+		// ---
+		//  type: string
+		//  required: no
+		//  shortdesc: Private key used to terminate TLS
+		"tls.key": validate.Optional(validate.IsNotEmpty),
 	}
 
 	err := d.config.Validate(rules)
@@ -194,6 +280,37 @@ func (d *proxy) validateConfig(instConf instance.ConfigReader) error {
 		return fmt.Errorf("The PROXY header can only be sent to tcp servers in non-nat mode")
 	}
 
+	if d.config["tls.cert"] != "" || d.config["tls.key"] != "" {
+		if d.config["tls.cert"] == "" || d.config["tls.key"] == "" {
+			return fmt.Errorf("Both tls.cert and tls.key must be set to terminate TLS")
+		}
+
+		_, err := tls.X509KeyPair([]byte(d.config["tls.cert"]), []byte(d.config["tls.key"]))
+		if err != nil {
+			return fmt.Errorf("Invalid TLS certificate and key: %w", err)
+		}
+
+		if listenAddr.ConnType != "tcp" || shared.IsTrue(d.config["nat"]) {
+			return fmt.Errorf("TLS can only be terminated on tcp listeners in non-nat mode")
+		}
+	}
+
+	if d.config["proxy_mode"] == "http" {
+		if listenAddr.ConnType != "tcp" || connectAddr.ConnType != "tcp" || shared.IsTrue(d.config["nat"]) {
+			return fmt.Errorf("HTTP mode is only supported between tcp addresses in non-nat mode")
+		}
+
+		if len(connectAddr.Ports) != 1 {
+			return fmt.Errorf("HTTP mode requires a single connect port")
+		}
+
+		if shared.IsTrue(d.config["proxy_protocol"]) {
+			return fmt.Errorf("The PROXY header can't be used in HTTP mode, the client address is sent in the X-Forwarded-For header instead")
+		}
+	} else if d.config["http.routes"] != "" {
+		return fmt.Errorf("HTTP routes can only be used when proxy_mode is set to http")
+	}
+
 	if (!strings.HasPrefix(d.config["listen"], "unix:") || strings.HasPrefix(d.config["listen"], "unix:@")) &&
 		(d.config["uid"] != "" || d.config["gid"] != "" || d.config["mode"] != "") {
 		return fmt.Errorf("Only proxy devices for non-abstract unix sockets can carry uid, gid, or mode properties")
@@ -333,6 +450,10 @@ func (d *proxy) Start() (*deviceConfig.RunConfig, error) {
 				proxyValues.securityGID,
 				proxyValues.securityUID,
 				proxyValues.proxyProtocol,
+				proxyValues.tlsCertFd,
+				proxyValues.tlsKeyFd,
+				proxyValues.proxyMode,
+				proxyValues.httpRoutes,
 			}
 
 			p, err := subprocess.NewProcess(command, forkproxyargs, logPath, logPath)
@@ -629,6 +750,28 @@ func (d *proxy) setupProxyProcInfo() (*proxyProcInfo, error) {
 		listenAddrMode = d.config["mode"]
 	}
 
+	// Pass the TLS certificate and key as file descriptors as forkproxy can't read them from the host
+	// once it attached to the namespaces of the instance.
+	var tlsCertFd, tlsKeyFd string
+	if d.config["tls.cert"] != "" {
+		certFile, err := linux.CreateMemfd([]byte(d.config["tls.cert"]))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to pass TLS certificate: %w", err)
+		}
+
+		inheritFd = append(inheritFd, certFile)
+		tlsCertFd = fmt.Sprintf("%d", 2+len(inheritFd))
+
+		keyFile, err := linux.CreateMemfd([]byte(d.config["tls.key"]))
+		if err != nil {
+			_ = certFile.Close()
+			return nil, fmt.Errorf("Failed to pass TLS key: %w", err)
+		}
+
+		inheritFd = append(inheritFd, keyFile)
+		tlsKeyFd = fmt.Sprintf("%d", 2+len(inheritFd))
+	}
+
 	p := &proxyProcInfo{
 		listenPid:      listenPid,
 		listenPidFd:    listenPidFd,
@@ -642,6 +785,10 @@ func (d *proxy) setupProxyProcInfo() (*proxyProcInfo, error) {
 		securityGID:    d.config["security.gid"],
 		securityUID:    d.config["security.uid"],
 		proxyProtocol:  d.config["proxy_protocol"],
+		tlsCertFd:      tlsCertFd,
+		tlsKeyFd:       tlsKeyFd,
+		proxyMode:      d.config["proxy_mode"],
+		httpRoutes:     d.config["http.routes"],
 		inheritFds:     inheritFd,
 	}
 
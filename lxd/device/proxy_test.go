@@ -0,0 +1,50 @@
+package device
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyRedactDevices(t *testing.T) {
+	stored := map[string]string{"type": "proxy", "tls.cert": "cert", "tls.key": "key"}
+	devices := map[string]map[string]string{
+		"web":   stored,
+		"plain": {"type": "proxy", "listen": "tcp:0.0.0.0:80"},
+		"eth0":  {"type": "nic", "tls.key": "not-a-proxy"},
+	}
+
+	ProxyRedactDevices(devices)
+
+	assert.Equal(t, map[string]string{"type": "proxy", "tls.cert": "cert"}, devices["web"])
+	assert.Equal(t, map[string]string{"type": "proxy", "listen": "tcp:0.0.0.0:80"}, devices["plain"])
+	assert.Equal(t, "not-a-proxy", devices["eth0"]["tls.key"])
+
+	// The original device config must be left untouched.
+	assert.Equal(t, "key", stored["tls.key"])
+}
+
+func TestProxyRestoreRedactedKeys(t *testing.T) {
+	oldDevices := map[string]map[string]string{
+		"web":     {"type": "proxy", "tls.cert": "cert", "tls.key": "key"},
+		"renewed": {"type": "proxy", "tls.cert": "cert", "tls.key": "key"},
+		"changed": {"type": "proxy", "tls.cert": "cert", "tls.key": "key"},
+		"cleared": {"type": "proxy", "tls.cert": "cert", "tls.key": "key"},
+	}
+
+	newDevices := map[string]map[string]string{
+		"web":     {"type": "proxy", "tls.cert": "cert"},
+		"renewed": {"type": "proxy", "tls.cert": "new-cert"},
+		"changed": {"type": "proxy", "tls.cert": "cert", "tls.key": "new-key"},
+		"cleared": {"type": "proxy", "tls.cert": "cert", "tls.key": ""},
+		"new":     {"type": "proxy", "tls.cert": "cert"},
+	}
+
+	ProxyRestoreRedactedKeys(oldDevices, newDevices)
+
+	assert.Equal(t, "key", newDevices["web"]["tls.key"])
+	assert.NotContains(t, newDevices["renewed"], "tls.key")
+	assert.Equal(t, "new-key", newDevices["changed"]["tls.key"])
+	assert.Equal(t, "", newDevices["cleared"]["tls.key"])
+	assert.NotContains(t, newDevices["new"], "tls.key")
+}
@@ -0,0 +1,463 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/storage/filesystem"
+	"github.com/canonical/lxd/lxd/subprocess"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/osarch"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/termios"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// Serial port bus types.
+const (
+	serialBusVirtio = "virtio"
+	serialBusISA    = "isa"
+)
+
+type serial struct {
+	deviceCommon
+}
+
+// CanMigrate returns whether the device can be migrated to any other cluster member.
+func (d *serial) CanMigrate() bool {
+	return true
+}
+
+// CanHotPlug returns whether the device can be managed whilst the instance is running.
+// Only virtio serial ports can be added to or removed from a running VM.
+func (d *serial) CanHotPlug() bool {
+	if d.inst.Type() == instancetype.VM {
+		return d.bus() == serialBusVirtio
+	}
+
+	return true
+}
+
+// bus returns the bus the serial port is attached to in a VM.
+func (d *serial) bus() string {
+	if d.config["bus"] == "" {
+		return serialBusVirtio
+	}
+
+	return d.config["bus"]
+}
+
+// validateConfig checks the supplied config for correctness.
+func (d *serial) validateConfig(instConf instance.ConfigReader) error {
+	if !instanceSupported(instConf.Type(), instancetype.Container, instancetype.VM) {
+		return ErrUnsupportedDevType
+	}
+
+	rules := map[string]func(string) error{
+		// lxdmeta:generate(entities=device-serial; group=device-conf; key=listen)
+		// Use `unix:<name>` for a Unix socket named `serial.<name>.sock` in the devices directory of the instance or `tcp:<address>:<port>` for a TCP listener.
+		// If not set, the port is exposed as a Unix socket named after the device.
+		// See {ref}`devices-serial-access` for more information.
+		// ---
+		//  type: string
+		//  shortdesc: Address on the host that the serial port is exposed on
+		"listen": validate.Optional(func(value string) error {
+			_, _, err := serialParseListen(value, d.name)
+			return err
+		}),
+	}
+
+	// lxdmeta:generate(entities=device-serial; group=device-conf; key=path)
+	// For example: `/dev/ttyS1`
+	// ---
+	//  type: string
+	//  required: for containers
+	//  condition: containers
+	//  shortdesc: Path inside the container
+
+	// lxdmeta:generate(entities=device-serial; group=device-conf; key=uid)
+	//
+	// ---
+	//  type: integer
+	//  defaultdesc: `0`
+	//  condition: containers
+	//  shortdesc: UID of the device owner in the container
+
+	// lxdmeta:generate(entities=device-serial; group=device-conf; key=gid)
+	//
+	// ---
+	//  type: integer
+	//  defaultdesc: `0`
+	//  condition: containers
+	//  shortdesc: GID of the device owner in the container
+
+	// lxdmeta:generate(entities=device-serial; group=device-conf; key=mode)
+	//
+	// ---
+	//  type: integer
+	//  defaultdesc: `0660`
+	//  condition: containers
+	//  shortdesc: Mode of the device in the container
+
+	// lxdmeta:generate(entities=device-serial; group=device-conf; key=bus)
+	// Possible values are `virtio` for a virtio serial port and `isa` for an emulated 16550A UART (x86_64 only).
+	// ISA serial ports can't be added to or removed from a running VM.
+	// ---
+	//  type: string
+	//  defaultdesc: `virtio`
+	//  condition: virtual machine
+	//  shortdesc: Bus the serial port is attached to
+
+	// lxdmeta:generate(entities=device-serial; group=device-conf; key=name)
+	// The guest can access the port through `/dev/virtio-ports/<name>`.
+	// ---
+	//  type: string
+	//  defaultdesc: device name
+	//  condition: virtual machine with `bus` set to `virtio`
+	//  shortdesc: Name of the virtio serial port in the VM
+	if instConf.Type() == instancetype.Container {
+		rules["path"] = validate.IsNotEmpty
+		rules["uid"] = unixValidUserID
+		rules["gid"] = unixValidUserID
+		rules["mode"] = unixValidOctalFileMode
+	} else {
+		rules["bus"] = validate.Optional(validate.IsOneOf(serialBusVirtio, serialBusISA))
+		rules["name"] = validate.Optional(validate.IsNotEmpty)
+	}
+
+	err := d.config.Validate(rules)
+	if err != nil {
+		return fmt.Errorf("Failed to validate config: %w", err)
+	}
+
+	// Each Unix socket belongs to a single device, as starting the device replaces any stale socket.
+	network, socketName, _ := serialParseListen(d.config["listen"], d.name)
+	if network == "unix" {
+		for devName, devConfig := range instConf.ExpandedDevices() {
+			if devName == d.name || devConfig["type"] != "serial" {
+				continue
+			}
+
+			otherNetwork, otherSocketName, _ := serialParseListen(devConfig["listen"], devName)
+			if otherNetwork == "unix" && otherSocketName == socketName {
+				return fmt.Errorf("Unix socket %q is already used by serial device %q", socketName, devName)
+			}
+		}
+	}
+
+	if instConf.Type() == instancetype.VM && d.bus() == serialBusISA {
+		if instConf.Architecture() != osarch.ARCH_64BIT_INTEL_X86 {
+			return fmt.Errorf("ISA serial ports are only supported on x86_64")
+		}
+
+		if d.config["name"] != "" {
+			return fmt.Errorf("The name property is only supported for virtio serial ports")
+		}
+	}
+
+	return nil
+}
+
+// serialParseListen parses a serial port listen address and returns its network and address.
+// For Unix sockets, the address is the name of the socket, which defaults to the device name.
+func serialParseListen(listen string, devName string) (string, string, error) {
+	if listen == "" {
+		return "unix", devName, nil
+	}
+
+	network, address, found := strings.Cut(listen, ":")
+	if !found || address == "" {
+		return "", "", fmt.Errorf("Invalid listen address %q, must be unix:<name> or tcp:<address>:<port>", listen)
+	}
+
+	switch network {
+	case "unix":
+		// The socket is always created in the devices directory of the instance.
+		if strings.Contains(address, "/") || address == "." || address == ".." {
+			return "", "", fmt.Errorf("Unix socket name %q must not be a path", address)
+		}
+
+	case "tcp":
+		_, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return "", "", fmt.Errorf("Invalid TCP listen address %q: %w", address, err)
+		}
+
+		err = validate.IsNetworkPort(port)
+		if err != nil {
+			return "", "", fmt.Errorf("Invalid TCP listen port %q: %w", port, err)
+		}
+
+	default:
+		return "", "", fmt.Errorf("Invalid listen address %q, must be unix:<name> or tcp:<address>:<port>", listen)
+	}
+
+	return network, address, nil
+}
+
+// serialListenAddress returns the network and address the serial port of the device is exposed on.
+func serialListenAddress(inst instance.Instance, devName string, devConfig deviceConfig.Device) (string, string, error) {
+	network, address, err := serialParseListen(devConfig["listen"], devName)
+	if err != nil {
+		return "", "", err
+	}
+
+	if network == "unix" {
+		address = filepath.Join(inst.DevicesPath(), fmt.Sprintf("serial.%s.sock", filesystem.PathNameEncode(address)))
+	}
+
+	return network, address, nil
+}
+
+// SerialConsoleAddress returns the network and address the serial device devName of the instance is exposed on.
+func SerialConsoleAddress(inst instance.Instance, devName string) (string, string, error) {
+	devConfig, ok := inst.ExpandedDevices()[devName]
+	if !ok || devConfig["type"] != "serial" {
+		return "", "", api.StatusErrorf(http.StatusNotFound, "Serial device %q not found", devName)
+	}
+
+	return serialListenAddress(inst, devName, devConfig)
+}
+
+// logPath returns the path of the file the output of the serial port is logged to.
+func (d *serial) logPath() string {
+	return filepath.Join(d.inst.LogPath(), fmt.Sprintf("serial.%s.log", d.name))
+}
+
+// removeSocket removes the Unix socket of the device, making sure not to remove any other kind of file.
+func (d *serial) removeSocket() error {
+	network, address, err := serialListenAddress(d.inst, d.name, d.config)
+	if err != nil || network != "unix" {
+		return err
+	}
+
+	info, err := os.Lstat(address)
+	if err == nil && info.Mode()&fs.ModeSocket != 0 {
+		err = os.Remove(address)
+	}
+
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("Failed to remove serial port socket %q: %w", address, err)
+	}
+
+	return nil
+}
+
+// Start is run when the device is added to the instance.
+func (d *serial) Start() (*deviceConfig.RunConfig, error) {
+	// Create the devices directory if missing.
+	if !shared.PathExists(d.inst.DevicesPath()) {
+		err := os.Mkdir(d.inst.DevicesPath(), 0711)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Remove the socket left behind if the device wasn't stopped cleanly.
+	err := d.removeSocket()
+	if err != nil {
+		return nil, err
+	}
+
+	if d.inst.Type() == instancetype.VM {
+		return d.startVM()
+	}
+
+	return d.startContainer()
+}
+
+func (d *serial) startContainer() (*deviceConfig.RunConfig, error) {
+	network, address, err := serialListenAddress(d.inst, d.name, d.config)
+	if err != nil {
+		return nil, err
+	}
+
+	uid, gid := 0, 0
+	if d.config["uid"] != "" {
+		uid, err = strconv.Atoi(d.config["uid"])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid uid %q: %w", d.config["uid"], err)
+		}
+	}
+
+	if d.config["gid"] != "" {
+		gid, err = strconv.Atoi(d.config["gid"])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid gid %q: %w", d.config["gid"], err)
+		}
+	}
+
+	mode := os.FileMode(unixDefaultMode)
+	if d.config["mode"] != "" {
+		modeOct, err := unixDeviceModeOct(d.config["mode"])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid mode %q: %w", d.config["mode"], err)
+		}
+
+		mode = os.FileMode(modeOct)
+	}
+
+	// The pty node is bind-mounted into the container as a pts node only works within its devpts instance.
+	// Its ownership is shifted into the container's idmap when mounted.
+	ptx, pty, err := shared.OpenPty(int64(uid), int64(gid))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to allocate pty: %w", err)
+	}
+
+	defer func() {
+		_ = ptx.Close()
+		_ = pty.Close()
+	}()
+
+	// Start like a raw serial line, the software in the container configures it as needed.
+	_, err = termios.MakeRaw(int(pty.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to configure pty: %w", err)
+	}
+
+	err = pty.Chmod(mode)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to set pty mode: %w", err)
+	}
+
+	var stat unix.Stat_t
+	err = unix.Fstat(int(pty.Fd()), &stat)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to stat pty: %w", err)
+	}
+
+	// Bridge the pty to the listen address and log its output.
+	proc, err := subprocess.NewProcess(d.state.OS.ExecPath, []string{"forkserial", fmt.Sprintf("%s:%s", network, address), d.logPath()}, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create new process: %w", err)
+	}
+
+	err = proc.StartWithFiles(context.Background(), []*os.File{ptx, pty})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to start serial port for device %q: %w", d.name, err)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	revert.Add(func() { _ = proc.Stop() })
+
+	pidPath := filepath.Join(d.inst.DevicesPath(), fmt.Sprintf("%s.pid", d.name))
+
+	err = proc.Save(pidPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to save forkserial state for device %q: %w", d.name, err)
+	}
+
+	// Record the pty number so that the device can be removed from the container once the pty is gone.
+	devNum := fmt.Sprintf("%d:%d", unix.Major(stat.Rdev), unix.Minor(stat.Rdev))
+	err = d.volatileSet(map[string]string{"last_state.pty": devNum})
+	if err != nil {
+		return nil, err
+	}
+
+	runConf := deviceConfig.RunConfig{
+		Mounts: []deviceConfig.MountEntryItem{{
+			DevPath:    pty.Name(),
+			TargetPath: strings.TrimPrefix(d.config["path"], "/"),
+			FSType:     "none",
+			Opts:       []string{"bind", "create=file"},
+			OwnerShift: deviceConfig.MountOwnerShiftStatic,
+		}},
+		CGroups: []deviceConfig.RunConfigItem{{
+			Key:   "devices.allow",
+			Value: fmt.Sprintf("c %s rwm", devNum),
+		}},
+	}
+
+	revert.Success()
+
+	return &runConf, nil
+}
+
+func (d *serial) startVM() (*deviceConfig.RunConfig, error) {
+	network, address, err := serialListenAddress(d.inst, d.name, d.config)
+	if err != nil {
+		return nil, err
+	}
+
+	portName := d.config["name"]
+	if portName == "" {
+		portName = d.name
+	}
+
+	runConf := deviceConfig.RunConfig{
+		SerialDevice: []deviceConfig.RunConfigItem{
+			{Key: "devName", Value: d.name},
+			{Key: "bus", Value: d.bus()},
+			{Key: "name", Value: portName},
+			{Key: "network", Value: network},
+			{Key: "address", Value: address},
+			{Key: "logPath", Value: d.logPath()},
+		},
+	}
+
+	return &runConf, nil
+}
+
+// Stop is run when the device is removed from the instance.
+func (d *serial) Stop() (*deviceConfig.RunConfig, error) {
+	runConf := deviceConfig.RunConfig{}
+
+	if d.inst.Type() == instancetype.Container {
+		pidPath := filepath.Join(d.inst.DevicesPath(), fmt.Sprintf("%s.pid", d.name))
+
+		defer func() { _ = os.Remove(pidPath) }()
+
+		if shared.PathExists(pidPath) {
+			proc, err := subprocess.ImportProcess(pidPath)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to import process %q: %w", pidPath, err)
+			}
+
+			err = proc.Stop()
+			if err != nil && err != subprocess.ErrNotRunning {
+				return nil, fmt.Errorf("Failed to stop imported process %q: %w", pidPath, err)
+			}
+		}
+
+		runConf.Mounts = append(runConf.Mounts, deviceConfig.MountEntryItem{
+			TargetPath: strings.TrimPrefix(d.config["path"], "/"),
+		})
+
+		devNum := d.volatileGet()["last_state.pty"]
+		if devNum != "" {
+			runConf.CGroups = append(runConf.CGroups, deviceConfig.RunConfigItem{
+				Key:   "devices.deny",
+				Value: fmt.Sprintf("c %s rwm", devNum),
+			})
+
+			err := d.volatileSet(map[string]string{"last_state.pty": ""})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Remove the Unix socket left behind by the listener.
+	err := d.removeSocket()
+	if err != nil {
+		return nil, err
+	}
+
+	return &runConf, nil
+}
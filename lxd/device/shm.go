@@ -0,0 +1,277 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"strings"
+
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/locking"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/storage/filesystem"
+	"github.com/canonical/lxd/shared/osarch"
+	"github.com/canonical/lxd/shared/units"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// shmHostPath is the directory on the host holding the shared memory objects.
+// Each object is a directory containing the memory file and a marker file for each device using it.
+const shmHostPath = "/dev/shm/lxd-shm"
+
+type shm struct {
+	deviceCommon
+}
+
+// CanHotPlug returns whether the device can be managed whilst the instance is running.
+// Only containers support hotplug, QEMU can't open the memory file once it dropped its privileges.
+func (d *shm) CanHotPlug() bool {
+	return d.inst.Type() == instancetype.Container
+}
+
+// validateConfig checks the supplied config for correctness.
+func (d *shm) validateConfig(instConf instance.ConfigReader) error {
+	if !instanceSupported(instConf.Type(), instancetype.Container, instancetype.VM) {
+		return ErrUnsupportedDevType
+	}
+
+	rules := map[string]func(string) error{
+		// lxdmeta:generate(entities=device-shm; group=device-conf; key=name)
+		// All `shm` devices using the same name in a project share the same memory region.
+		// ---
+		//  type: string
+		//  required: yes
+		//  shortdesc: Name of the shared memory object on the host
+		"name": validate.IsHostname,
+
+		// lxdmeta:generate(entities=device-shm; group=device-conf; key=size)
+		// The size must be a power of two, for example `16MiB`.
+		// All devices using the same shared memory object must use the same size.
+		// ---
+		//  type: string
+		//  required: yes
+		//  shortdesc: Size of the shared memory object
+		"size": func(value string) error {
+			size, err := units.ParseByteSizeString(value)
+			if err != nil {
+				return err
+			}
+
+			if size < 4096 || bits.OnesCount64(uint64(size)) != 1 {
+				return fmt.Errorf("Size must be a power of two and at least 4KiB")
+			}
+
+			return nil
+		},
+	}
+
+	// lxdmeta:generate(entities=device-shm; group=device-conf; key=path)
+	// For example: `/dev/lxd-shm`
+	//
+	// The path can't be located in `/dev/shm` as the init system of the container mounts over it.
+	// ---
+	//  type: string
+	//  required: for containers
+	//  condition: containers
+	//  shortdesc: Path of the shared memory file inside the container
+	if instConf.Type() == instancetype.Container {
+		rules["path"] = func(value string) error {
+			err := validate.IsAbsFilePath(value)
+			if err != nil {
+				return err
+			}
+
+			cleanPath := filepath.Clean(value)
+			if cleanPath == "/dev/shm" || strings.HasPrefix(cleanPath, "/dev/shm/") {
+				return fmt.Errorf("Path can't be located in /dev/shm")
+			}
+
+			return nil
+		}
+	}
+
+	err := d.config.Validate(rules)
+	if err != nil {
+		return fmt.Errorf("Failed to validate config: %w", err)
+	}
+
+	if instConf.Type() == instancetype.VM && instConf.Architecture() == osarch.ARCH_64BIT_S390_BIG_ENDIAN {
+		return fmt.Errorf("Shared memory devices aren't supported on s390x")
+	}
+
+	return nil
+}
+
+// objectPath returns the path of the directory of the shared memory object on the host.
+func (d *shm) objectPath() string {
+	return filepath.Join(shmHostPath, project.Instance(d.inst.Project().Name, d.config["name"]))
+}
+
+// memoryPath returns the path of the file backing the shared memory object on the host.
+func (d *shm) memoryPath() string {
+	return filepath.Join(d.objectPath(), "memory")
+}
+
+// userPath returns the path of the marker file recording that the device uses the shared memory object.
+func (d *shm) userPath() string {
+	return filepath.Join(d.objectPath(), "users", fmt.Sprintf("%s.%s", project.Instance(d.inst.Project().Name, d.inst.Name()), filesystem.PathNameEncode(d.name)))
+}
+
+// lockName returns the name of the lock serializing access to the shared memory object.
+func (d *shm) lockName() string {
+	return fmt.Sprintf("shm_%s", project.Instance(d.inst.Project().Name, d.config["name"]))
+}
+
+// acquire creates the shared memory object if needed and registers the device as one of its users.
+func (d *shm) acquire() error {
+	size, err := units.ParseByteSizeString(d.config["size"])
+	if err != nil {
+		return err
+	}
+
+	unlock, err := locking.Lock(context.Background(), d.lockName())
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	// Only root may browse the shared memory objects, access is granted through the mounts and QEMU.
+	err = os.MkdirAll(shmHostPath, 0700)
+	if err != nil {
+		return fmt.Errorf("Failed to create %q: %w", shmHostPath, err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(d.userPath()), 0700)
+	if err != nil {
+		return fmt.Errorf("Failed to create shared memory object %q: %w", d.config["name"], err)
+	}
+
+	memoryPath := d.memoryPath()
+
+	info, err := os.Stat(memoryPath)
+	if err == nil {
+		if info.Size() != size {
+			return fmt.Errorf("Shared memory object %q already exists with a size of %d bytes", d.config["name"], info.Size())
+		}
+	} else if errors.Is(err, fs.ErrNotExist) {
+		f, err := os.OpenFile(memoryPath, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return fmt.Errorf("Failed to create shared memory object %q: %w", d.config["name"], err)
+		}
+
+		defer func() { _ = f.Close() }()
+
+		err = f.Truncate(size)
+		if err != nil {
+			_ = os.Remove(memoryPath)
+			return fmt.Errorf("Failed to resize shared memory object %q: %w", d.config["name"], err)
+		}
+
+		// Unprivileged containers must be able to use the memory, set the mode regardless of the umask.
+		err = f.Chmod(0666)
+		if err != nil {
+			_ = os.Remove(memoryPath)
+			return fmt.Errorf("Failed to set permissions of shared memory object %q: %w", d.config["name"], err)
+		}
+	} else {
+		return err
+	}
+
+	err = os.WriteFile(d.userPath(), nil, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to register user of shared memory object %q: %w", d.config["name"], err)
+	}
+
+	return nil
+}
+
+// release unregisters the device as a user of the shared memory object and removes the object
+// once the last user is gone.
+func (d *shm) release() error {
+	unlock, err := locking.Lock(context.Background(), d.lockName())
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	err = os.Remove(d.userPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("Failed to unregister user of shared memory object %q: %w", d.config["name"], err)
+	}
+
+	users, err := os.ReadDir(filepath.Dir(d.userPath()))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if len(users) > 0 {
+		return nil
+	}
+
+	err = os.RemoveAll(d.objectPath())
+	if err != nil {
+		return fmt.Errorf("Failed to remove shared memory object %q: %w", d.config["name"], err)
+	}
+
+	return nil
+}
+
+// Start is run when the device is added to the instance.
+func (d *shm) Start() (*deviceConfig.RunConfig, error) {
+	err := d.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	runConf := deviceConfig.RunConfig{}
+
+	if d.inst.Type() == instancetype.VM {
+		size, err := units.ParseByteSizeString(d.config["size"])
+		if err != nil {
+			return nil, err
+		}
+
+		runConf.SHMDevice = []deviceConfig.RunConfigItem{
+			{Key: "devName", Value: d.name},
+			{Key: "path", Value: d.memoryPath()},
+			{Key: "size", Value: fmt.Sprintf("%d", size)},
+		}
+
+		return &runConf, nil
+	}
+
+	runConf.Mounts = []deviceConfig.MountEntryItem{{
+		DevName:    d.name,
+		DevPath:    d.memoryPath(),
+		TargetPath: strings.TrimPrefix(d.config["path"], "/"),
+		FSType:     "none",
+		Opts:       []string{"bind", "create=file"},
+		OwnerShift: deviceConfig.MountOwnerShiftNone,
+	}}
+
+	return &runConf, nil
+}
+
+// Stop is run when the device is removed from the instance.
+func (d *shm) Stop() (*deviceConfig.RunConfig, error) {
+	runConf := deviceConfig.RunConfig{
+		PostHooks: []func() error{d.release},
+	}
+
+	// Request an unmount of the file inside the container.
+	if d.inst.Type() == instancetype.Container {
+		runConf.Mounts = []deviceConfig.MountEntryItem{{
+			TargetPath: strings.TrimPrefix(d.config["path"], "/"),
+		}}
+	}
+
+	return &runConf, nil
+}
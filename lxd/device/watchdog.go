@@ -0,0 +1,94 @@
+package device
+
+import (
+	"fmt"
+
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/shared/osarch"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+type watchdog struct {
+	deviceCommon
+}
+
+// CanMigrate returns whether the device can be migrated to any other cluster member.
+func (d *watchdog) CanMigrate() bool {
+	return true
+}
+
+// validateConfig checks the supplied config for correctness.
+func (d *watchdog) validateConfig(instConf instance.ConfigReader) error {
+	if !instanceSupported(instConf.Type(), instancetype.VM) {
+		return ErrUnsupportedDevType
+	}
+
+	rules := map[string]func(string) error{
+		// lxdmeta:generate(entities=device-watchdog; group=device-conf; key=model)
+		// Possible values are `i6300esb` (PCI device) and `ib700` (ISA device, x86_64 only).
+		// ---
+		//  type: string
+		//  defaultdesc: `i6300esb`
+		//  shortdesc: Model of the emulated watchdog
+		"model": validate.Optional(validate.IsOneOf("i6300esb", "ib700")),
+
+		// lxdmeta:generate(entities=device-watchdog; group=device-conf; key=action)
+		// Possible values are `reset`, `poweroff`, `pause` and `none`.
+		// See {ref}`devices-watchdog-recovery` for how LXD can restart the instance instead.
+		// ---
+		//  type: string
+		//  defaultdesc: `reset`
+		//  shortdesc: Action to take when the watchdog fires
+		"action": validate.Optional(validate.IsOneOf("reset", "poweroff", "pause", "none")),
+	}
+
+	err := d.config.Validate(rules)
+	if err != nil {
+		return fmt.Errorf("Failed to validate config: %w", err)
+	}
+
+	switch d.config["model"] {
+	case "ib700":
+		if instConf.Architecture() != osarch.ARCH_64BIT_INTEL_X86 {
+			return fmt.Errorf("The ib700 watchdog is only supported on x86_64")
+		}
+
+	default:
+		if instConf.Architecture() == osarch.ARCH_64BIT_S390_BIG_ENDIAN {
+			return fmt.Errorf("The i6300esb watchdog isn't supported on s390x")
+		}
+	}
+
+	// The watchdog action applies to the whole VM, so only allow a single watchdog.
+	for devName, devConfig := range instConf.ExpandedDevices() {
+		if devName != d.name && devConfig["type"] == "watchdog" {
+			return fmt.Errorf("Only one watchdog device is supported, found %q already", devName)
+		}
+	}
+
+	return nil
+}
+
+// Start is run when the device is added to the instance.
+func (d *watchdog) Start() (*deviceConfig.RunConfig, error) {
+	model := d.config["model"]
+	if model == "" {
+		model = "i6300esb"
+	}
+
+	runConf := deviceConfig.RunConfig{
+		WatchdogDevice: []deviceConfig.RunConfigItem{
+			{Key: "devName", Value: d.name},
+			{Key: "model", Value: model},
+		},
+	}
+
+	return &runConf, nil
+}
+
+// Stop is run when the device is removed from the instance.
+func (d *watchdog) Stop() (*deviceConfig.RunConfig, error) {
+	return nil, nil
+}
@@ -0,0 +1,353 @@
+package ingress
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// Target health states.
+const (
+	targetStatusUnknown = "unknown"
+	targetStatusOnline  = "online"
+	targetStatusOffline = "offline"
+)
+
+// Ingress represents a network ingress served by the server.
+type Ingress struct {
+	Project       string
+	Network       string
+	Hostname      string
+	ListenAddress string
+	Config        map[string]string
+	Routes        []api.NetworkIngressRoute
+
+	// Certificate served over HTTPS, nil if HTTPS isn't available for the ingress.
+	Certificate *tls.Certificate
+}
+
+// ingress is the runtime state of a served network ingress.
+type ingress struct {
+	info        Ingress
+	certificate atomic.Pointer[tls.Certificate]
+
+	routes    []*route  // Sorted from the longest to the shortest path.
+	targets   []*target // Targets shared by the routes.
+	transport *http.Transport
+
+	cancel context.CancelFunc
+
+	requests atomic.Int64
+	errors   atomic.Int64
+}
+
+// route is a path prefix with the targets its requests are shared between.
+type route struct {
+	path    string
+	targets []*target
+	next    atomic.Uint64
+}
+
+// target is a backend of the ingress.
+type target struct {
+	address  string
+	proxy    *httputil.ReverseProxy
+	requests atomic.Int64
+
+	mu        sync.Mutex
+	status    string
+	lastCheck time.Time
+	lastError string
+}
+
+// newIngress prepares the runtime state of the ingress, health checks aren't started yet.
+func newIngress(info Ingress) *ingress {
+	i := &ingress{
+		info: info,
+		transport: &http.Transport{
+			// Never use the proxy configured through the environment to reach the instances.
+			Proxy:                 nil,
+			DialContext:           (&net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+			MaxIdleConnsPerHost:   16,
+			IdleConnTimeout:       90 * time.Second,
+			ResponseHeaderTimeout: 5 * time.Minute,
+		},
+	}
+
+	i.certificate.Store(info.Certificate)
+
+	targets := map[string]*target{}
+	routes := map[string]*route{}
+
+	for _, r := range info.Routes {
+		address := net.JoinHostPort(r.TargetAddress, r.TargetPort)
+
+		t, found := targets[address]
+		if !found {
+			t = i.newTarget(address)
+			targets[address] = t
+			i.targets = append(i.targets, t)
+		}
+
+		rt, found := routes[r.Path]
+		if !found {
+			rt = &route{path: r.Path}
+			routes[r.Path] = rt
+			i.routes = append(i.routes, rt)
+		}
+
+		rt.targets = append(rt.targets, t)
+	}
+
+	sort.SliceStable(i.routes, func(a, b int) bool {
+		return len(i.routes[a].path) > len(i.routes[b].path)
+	})
+
+	return i
+}
+
+// newTarget returns a target proxying the requests to the address.
+func (i *ingress) newTarget(address string) *target {
+	t := &target{address: address, status: targetStatusUnknown}
+	u := &url.URL{Scheme: "http", Host: address}
+
+	t.proxy = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(u)
+			r.Out.Host = r.In.Host // Keep the requested host for virtual hosting.
+			r.SetXForwarded()
+		},
+		Transport: i.transport,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.Debug("Failed proxying ingress request", logger.Ctx{"hostname": i.info.Hostname, "target": address, "err": err})
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+
+	return t
+}
+
+// start starts the health checks of the targets.
+func (i *ingress) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	i.cancel = cancel
+
+	interval := 10 * time.Second
+	if i.info.Config["healthcheck.interval"] != "" {
+		seconds, err := strconv.Atoi(i.info.Config["healthcheck.interval"])
+		if err == nil && seconds > 0 {
+			interval = time.Duration(seconds) * time.Second
+		}
+	}
+
+	for _, t := range i.targets {
+		go i.healthCheck(ctx, t, interval)
+	}
+}
+
+// stop stops the health checks and closes the connections to the targets.
+func (i *ingress) stop() {
+	if i.cancel != nil {
+		i.cancel()
+	}
+
+	i.transport.CloseIdleConnections()
+}
+
+// healthCheck checks the health of the target until the context is cancelled.
+func (i *ingress) healthCheck(ctx context.Context, t *target, interval time.Duration) {
+	timeout := min(interval, 5*time.Second)
+
+	client := &http.Client{
+		Transport: i.transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	for {
+		err := i.check(ctx, client, t, timeout)
+
+		t.mu.Lock()
+		previousStatus := t.status
+		t.lastCheck = time.Now()
+		if err != nil {
+			t.status = targetStatusOffline
+			t.lastError = err.Error()
+		} else {
+			t.status = targetStatusOnline
+			t.lastError = ""
+		}
+
+		status := t.status
+		t.mu.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if status != previousStatus {
+			logger.Info("Network ingress target health changed", logger.Ctx{"hostname": i.info.Hostname, "target": t.address, "status": status, "err": err})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// check runs a single health check of the target.
+// Without a health check path, the target is online when it accepts TCP connections.
+func (i *ingress) check(ctx context.Context, client *http.Client, t *target, timeout time.Duration) error {
+	path := i.info.Config["healthcheck.path"]
+	if path == "" {
+		dialer := net.Dialer{Timeout: timeout}
+
+		conn, err := dialer.DialContext(ctx, "tcp", t.address)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+t.address+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Host = i.info.Hostname
+	req.Header.Set("User-Agent", "LXD ingress health check")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("Unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// healthy returns whether the target can receive requests.
+// Targets are considered healthy until their first health check completes.
+func (t *target) healthy() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.status != targetStatusOffline
+}
+
+// match returns the route with the longest path prefix matching the path.
+func (i *ingress) match(path string) *route {
+	for _, rt := range i.routes {
+		if rt.path == "/" || path == rt.path || strings.HasPrefix(path, rt.path+"/") {
+			return rt
+		}
+	}
+
+	return nil
+}
+
+// pick returns the next healthy target of the route in a round-robin fashion.
+func (rt *route) pick() *target {
+	count := uint64(len(rt.targets))
+	start := rt.next.Add(1)
+
+	for n := uint64(0); n < count; n++ {
+		t := rt.targets[(start+n)%count]
+		if t.healthy() {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// redirect returns whether plain HTTP requests are redirected to HTTPS.
+func (i *ingress) redirect() bool {
+	return i.certificate.Load() != nil && shared.IsTrueOrEmpty(i.info.Config["http.redirect"])
+}
+
+// ServeHTTP proxies the request to a target of the matching route.
+func (i *ingress) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	i.requests.Add(1)
+
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	defer func() {
+		if rec.status >= http.StatusInternalServerError {
+			i.errors.Add(1)
+		}
+	}()
+
+	rt := i.match(r.URL.Path)
+	if rt == nil {
+		http.Error(rec, "No route for the requested path", http.StatusNotFound)
+		return
+	}
+
+	t := rt.pick()
+	if t == nil {
+		http.Error(rec, "No healthy target available", http.StatusServiceUnavailable)
+		return
+	}
+
+	t.requests.Add(1)
+	t.proxy.ServeHTTP(rec, r)
+}
+
+// state returns the state of the ingress targets.
+func (i *ingress) state() []api.NetworkIngressStateTarget {
+	targets := make([]api.NetworkIngressStateTarget, 0, len(i.targets))
+	for _, t := range i.targets {
+		t.mu.Lock()
+		targets = append(targets, api.NetworkIngressStateTarget{
+			Address:   t.address,
+			Status:    t.status,
+			LastCheck: t.lastCheck,
+			Error:     t.lastError,
+			Requests:  t.requests.Load(),
+		})
+		t.mu.Unlock()
+	}
+
+	return targets
+}
+
+// statusRecorder records the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code before writing it.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the underlying response writer, used by http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,172 @@
+package ingress
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestValidateHostname(t *testing.T) {
+	tests := []struct {
+		hostname string
+		valid    bool
+	}{
+		{hostname: "www.example.net", valid: true},
+		{hostname: "example", valid: true},
+		{hostname: "", valid: false},
+		{hostname: "192.0.2.1", valid: false},
+		{hostname: "www..example.net", valid: false},
+		{hostname: "-www.example.net", valid: false},
+		{hostname: "www_1.example.net", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hostname, func(t *testing.T) {
+			err := ValidateHostname(tt.hostname)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateRoutes(t *testing.T) {
+	route := api.NetworkIngressRoute{Path: "/api", TargetAddress: "198.51.100.2", TargetPort: "8080"}
+
+	assert.NoError(t, ValidateRoutes([]api.NetworkIngressRoute{route}))
+
+	// Duplicates are rejected, even with a different description.
+	duplicate := route
+	duplicate.Description = "duplicate"
+	assert.Error(t, ValidateRoutes([]api.NetworkIngressRoute{route, duplicate}))
+
+	invalid := []api.NetworkIngressRoute{
+		{Path: "api", TargetAddress: "198.51.100.2", TargetPort: "80"},
+		{Path: "/api/../admin", TargetAddress: "198.51.100.2", TargetPort: "80"},
+		{Path: "/api?x=1", TargetAddress: "198.51.100.2", TargetPort: "80"},
+		{Path: "/", TargetAddress: "example.net", TargetPort: "80"},
+		{Path: "/", TargetAddress: "198.51.100.2", TargetPort: "0"},
+		{Path: "/", TargetAddress: "198.51.100.2", TargetPort: "65536"},
+	}
+
+	for _, r := range invalid {
+		assert.Error(t, ValidateRoutes([]api.NetworkIngressRoute{r}), "%+v", r)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	assert.NoError(t, ValidateConfig(map[string]string{"tls.acme": "true", "healthcheck.path": "/healthz", "user.foo": "bar"}))
+	assert.Error(t, ValidateConfig(map[string]string{"foo": "bar"}))
+	assert.Error(t, ValidateConfig(map[string]string{"healthcheck.path": "healthz"}))
+	assert.Error(t, ValidateConfig(map[string]string{"healthcheck.interval": "0"}))
+	assert.Error(t, ValidateConfig(map[string]string{"tls.key": "key"}))
+}
+
+func TestIngressMatch(t *testing.T) {
+	i := newIngress(Ingress{
+		Hostname: "www.example.net",
+		Routes: []api.NetworkIngressRoute{
+			{Path: "/", TargetAddress: "198.51.100.1", TargetPort: "80"},
+			{Path: "/api", TargetAddress: "198.51.100.2", TargetPort: "80"},
+			{Path: "/api/v2", TargetAddress: "198.51.100.3", TargetPort: "80"},
+			{Path: "/api/v2", TargetAddress: "198.51.100.4", TargetPort: "80"},
+		},
+	})
+
+	tests := map[string]string{
+		"/":            "/",
+		"/apis":        "/",
+		"/api":         "/api",
+		"/api/users":   "/api",
+		"/api/v2":      "/api/v2",
+		"/api/v2/test": "/api/v2",
+	}
+
+	for path, expected := range tests {
+		rt := i.match(path)
+		require.NotNil(t, rt, path)
+		assert.Equal(t, expected, rt.path, path)
+	}
+
+	// Requests are shared between the targets of the route.
+	rt := i.match("/api/v2")
+	first := rt.pick()
+	second := rt.pick()
+	assert.NotEqual(t, first.address, second.address)
+
+	// Unhealthy targets are skipped.
+	first.status = targetStatusOffline
+	assert.Equal(t, second, rt.pick())
+	assert.Equal(t, second, rt.pick())
+
+	second.status = targetStatusOffline
+	assert.Nil(t, rt.pick())
+}
+
+func TestIngressServeHTTP(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = io.WriteString(w, r.Host+" "+r.URL.Path+" "+r.Header.Get("X-Forwarded-Host"))
+	}))
+	defer backend.Close()
+
+	host, port, err := net.SplitHostPort(backend.Listener.Addr().String())
+	require.NoError(t, err)
+
+	i := newIngress(Ingress{
+		Hostname: "www.example.net",
+		Routes:   []api.NetworkIngressRoute{{Path: "/app", TargetAddress: host, TargetPort: port}},
+	})
+	defer i.stop()
+
+	// Matching request, the host is kept.
+	rec := httptest.NewRecorder()
+	i.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://www.example.net/app/page", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "www.example.net /app/page www.example.net", rec.Body.String())
+
+	// No matching route.
+	rec = httptest.NewRecorder()
+	i.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://www.example.net/other", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Only server errors are counted as errors.
+	assert.Equal(t, int64(2), i.requests.Load())
+	assert.Equal(t, int64(0), i.errors.Load())
+
+	// Unhealthy target.
+	i.targets[0].status = targetStatusOffline
+	rec = httptest.NewRecorder()
+	i.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://www.example.net/app", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, int64(1), i.errors.Load())
+
+	states := i.state()
+	require.Len(t, states, 1)
+	assert.Equal(t, int64(1), states[0].Requests)
+	assert.Equal(t, targetStatusOffline, states[0].Status)
+}
+
+func TestServerRenameNetwork(t *testing.T) {
+	s := NewServer()
+	s.ingresses["www.example.net"] = newIngress(Ingress{Project: "default", Network: "lxdbr0", Hostname: "www.example.net"})
+	s.ingresses["www.example.org"] = newIngress(Ingress{Project: "other", Network: "lxdbr0", Hostname: "www.example.org"})
+
+	s.RenameNetwork("default", "lxdbr0", "lxdbr1")
+
+	assert.Equal(t, "lxdbr1", s.ingresses["www.example.net"].info.Network)
+	assert.Equal(t, "lxdbr0", s.ingresses["www.example.org"].info.Network)
+}
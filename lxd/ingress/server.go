@@ -0,0 +1,447 @@
+package ingress
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// HTTP and HTTPS ports the ingresses listen on.
+const (
+	httpPort  = 80
+	httpsPort = 443
+)
+
+// acmeChallengePath is the path prefix under which HTTP-01 challenges are requested.
+const acmeChallengePath = "/.well-known/acme-challenge/"
+
+// Server represents the reverse proxy serving the network ingresses of the local member.
+// It also implements the HTTP-01 challenge provider used to obtain their certificates.
+type Server struct {
+	ingresses  map[string]*ingress  // Keyed on host name.
+	listeners  map[string]*listener // Keyed on listen address.
+	challenges map[string]string    // Key authorizations keyed on token.
+	certErrors map[string]string    // Last certificate error keyed on host name.
+
+	mu sync.RWMutex
+}
+
+// listener holds the HTTP and HTTPS servers of a listen address.
+type listener struct {
+	http  *http.Server
+	https *http.Server
+}
+
+// NewServer returns a new server instance.
+func NewServer() *Server {
+	return &Server{
+		ingresses:  map[string]*ingress{},
+		listeners:  map[string]*listener{},
+		challenges: map[string]string{},
+		certErrors: map[string]string{},
+	}
+}
+
+// Set starts serving the ingress or updates it if its host name is already served.
+// The request and health state is kept when only the certificate changes.
+func (s *Server) Set(info Ingress) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.ingresses[info.Hostname]
+	if old != nil && sameIngress(old.info, info) {
+		old.info.Certificate = info.Certificate
+		old.certificate.Store(info.Certificate)
+		return nil
+	}
+
+	i := newIngress(info)
+	s.ingresses[info.Hostname] = i
+
+	err := s.updateListeners()
+	if err != nil {
+		// Restore the previous state.
+		if old != nil {
+			s.ingresses[info.Hostname] = old
+		} else {
+			delete(s.ingresses, info.Hostname)
+		}
+
+		_ = s.updateListeners()
+
+		return err
+	}
+
+	if old != nil {
+		i.requests.Store(old.requests.Load())
+		i.errors.Store(old.errors.Load())
+		old.stop()
+	}
+
+	i.start()
+
+	return nil
+}
+
+// Remove stops serving the ingress with the given host name.
+func (s *Server) Remove(hostname string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.ingresses[hostname]
+	if i == nil {
+		return
+	}
+
+	delete(s.ingresses, hostname)
+	delete(s.certErrors, hostname)
+	i.stop()
+
+	_ = s.updateListeners()
+}
+
+// RemoveNetwork stops serving all the ingresses of the network.
+func (s *Server) RemoveNetwork(projectName string, networkName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hostname, i := range s.ingresses {
+		if i.info.Project != projectName || i.info.Network != networkName {
+			continue
+		}
+
+		delete(s.ingresses, hostname)
+		delete(s.certErrors, hostname)
+		i.stop()
+	}
+
+	_ = s.updateListeners()
+}
+
+// RenameNetwork updates the ingresses of the network after it has been renamed.
+func (s *Server) RenameNetwork(projectName string, oldName string, newName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, i := range s.ingresses {
+		if i.info.Project == projectName && i.info.Network == oldName {
+			i.info.Network = newName
+		}
+	}
+}
+
+// Stop stops serving all the ingresses.
+func (s *Server) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hostname, i := range s.ingresses {
+		delete(s.ingresses, hostname)
+		i.stop()
+	}
+
+	_ = s.updateListeners()
+}
+
+// SetCertificateError records the error encountered when obtaining the certificate of the ingress.
+// A nil error clears the previous error.
+func (s *Server) SetCertificateError(hostname string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		delete(s.certErrors, hostname)
+		return
+	}
+
+	s.certErrors[hostname] = err.Error()
+}
+
+// State returns the state of the ingress with the given host name.
+// Returns nil if the ingress isn't served.
+func (s *Server) State(hostname string) *api.NetworkIngressState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.ingresses[hostname]
+	if i == nil {
+		return nil
+	}
+
+	state := &api.NetworkIngressState{
+		Requests: i.requests.Load(),
+		Errors:   i.errors.Load(),
+		Targets:  i.state(),
+	}
+
+	state.Certificate.Source = "none"
+	state.Certificate.Status = "none"
+
+	if shared.IsTrue(i.info.Config["tls.acme"]) {
+		state.Certificate.Source = "acme"
+		state.Certificate.Status = "pending"
+		state.Certificate.Error = s.certErrors[hostname]
+		if state.Certificate.Error != "" {
+			state.Certificate.Status = "failed"
+		}
+	} else if i.info.Config["tls.certificate"] != "" {
+		state.Certificate.Source = "manual"
+	}
+
+	cert := i.certificate.Load()
+	if cert != nil {
+		state.Certificate.Status = "valid"
+
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err == nil {
+			state.Certificate.ExpiresAt = leaf.NotAfter
+		}
+	}
+
+	return state
+}
+
+// Metrics returns the request and health metrics of the ingresses of the project.
+// Returns nil if the project has no ingresses.
+func (s *Server) Metrics(projectName string) *metrics.MetricSet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out *metrics.MetricSet
+
+	for hostname, i := range s.ingresses {
+		if i.info.Project != projectName {
+			continue
+		}
+
+		if out == nil {
+			out = metrics.NewMetricSet(nil)
+		}
+
+		labels := map[string]string{"project": i.info.Project, "network": i.info.Network, "hostname": hostname}
+
+		out.AddSamples(metrics.NetworkIngressRequestsTotal, metrics.Sample{Labels: labels, Value: float64(i.requests.Load())})
+		out.AddSamples(metrics.NetworkIngressErrorsTotal, metrics.Sample{Labels: labels, Value: float64(i.errors.Load())})
+
+		for _, t := range i.state() {
+			targetLabels := map[string]string{"project": i.info.Project, "network": i.info.Network, "hostname": hostname, "target": t.Address}
+
+			up := 0.0
+			if t.Status == targetStatusOnline {
+				up = 1
+			}
+
+			out.AddSamples(metrics.NetworkIngressTargetUp, metrics.Sample{Labels: targetLabels, Value: up})
+			out.AddSamples(metrics.NetworkIngressTargetRequestsTotal, metrics.Sample{Labels: targetLabels, Value: float64(t.Requests)})
+		}
+	}
+
+	return out
+}
+
+// Present makes the key authorization of the HTTP-01 challenge available.
+func (s *Server) Present(domain string, token string, keyAuth string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.challenges[token] = keyAuth
+
+	return nil
+}
+
+// CleanUp removes the key authorization of the HTTP-01 challenge.
+func (s *Server) CleanUp(domain string, token string, keyAuth string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.challenges, token)
+
+	return nil
+}
+
+// updateListeners starts the listeners needed by the ingresses and stops the unused ones.
+// Must be called with the lock held.
+func (s *Server) updateListeners() error {
+	needed := map[string]bool{}
+	for _, i := range s.ingresses {
+		needed[i.info.ListenAddress] = true
+	}
+
+	for address, l := range s.listeners {
+		if needed[address] {
+			continue
+		}
+
+		_ = l.http.Close()
+		_ = l.https.Close()
+		delete(s.listeners, address)
+	}
+
+	for address := range needed {
+		if s.listeners[address] != nil {
+			continue
+		}
+
+		l, err := s.listen(address)
+		if err != nil {
+			return err
+		}
+
+		s.listeners[address] = l
+	}
+
+	return nil
+}
+
+// listen starts the HTTP and HTTPS servers on the listen address.
+func (s *Server) listen(address string) (*listener, error) {
+	httpListener, err := net.Listen("tcp", net.JoinHostPort(address, fmt.Sprintf("%d", httpPort)))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to listen on %q: %w", address, err)
+	}
+
+	httpsListener, err := net.Listen("tcp", net.JoinHostPort(address, fmt.Sprintf("%d", httpsPort)))
+	if err != nil {
+		_ = httpListener.Close()
+		return nil, fmt.Errorf("Failed to listen on %q: %w", address, err)
+	}
+
+	l := &listener{
+		http: &http.Server{
+			Handler:           s.httpHandler(address),
+			ReadHeaderTimeout: 30 * time.Second,
+		},
+		https: &http.Server{
+			Handler:           s.httpsHandler(address),
+			ReadHeaderTimeout: 30 * time.Second,
+			TLSConfig: &tls.Config{
+				MinVersion:     tls.VersionTLS12,
+				GetCertificate: s.certificateGetter(address),
+			},
+		},
+	}
+
+	go func() {
+		err := l.http.Serve(httpListener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Network ingress HTTP listener failed", logger.Ctx{"address": address, "err": err})
+		}
+	}()
+
+	go func() {
+		err := l.https.ServeTLS(httpsListener, "", "")
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Network ingress HTTPS listener failed", logger.Ctx{"address": address, "err": err})
+		}
+	}()
+
+	return l, nil
+}
+
+// lookup returns the ingress serving the host on the listen address.
+func (s *Server) lookup(address string, host string) *ingress {
+	hostname, _, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname = host
+	}
+
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.ingresses[hostname]
+	if i == nil || i.info.ListenAddress != address {
+		return nil
+	}
+
+	return i
+}
+
+// httpHandler returns the handler of the plain HTTP server on the listen address.
+// It answers the HTTP-01 challenges and redirects to HTTPS once a certificate is available.
+func (s *Server) httpHandler(address string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, isChallenge := strings.CutPrefix(r.URL.Path, acmeChallengePath)
+		if isChallenge {
+			s.mu.RLock()
+			keyAuth, found := s.challenges[token]
+			s.mu.RUnlock()
+
+			if !found {
+				http.NotFound(w, r)
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte(keyAuth))
+			return
+		}
+
+		i := s.lookup(address, r.Host)
+		if i == nil {
+			http.Error(w, "Unknown host", http.StatusNotFound)
+			return
+		}
+
+		if i.redirect() {
+			u := url.URL{Scheme: "https", Host: i.info.Hostname, Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery}
+			http.Redirect(w, r, u.String(), http.StatusPermanentRedirect)
+			return
+		}
+
+		i.ServeHTTP(w, r)
+	})
+}
+
+// httpsHandler returns the handler of the HTTPS server on the listen address.
+func (s *Server) httpsHandler(address string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := s.lookup(address, r.Host)
+		if i == nil || i.certificate.Load() == nil {
+			http.Error(w, "Unknown host", http.StatusNotFound)
+			return
+		}
+
+		i.ServeHTTP(w, r)
+	})
+}
+
+// certificateGetter returns the function selecting the certificate from the TLS server name.
+func (s *Server) certificateGetter(address string) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		i := s.lookup(address, hello.ServerName)
+		if i == nil {
+			return nil, fmt.Errorf("Unknown server name %q", hello.ServerName)
+		}
+
+		cert := i.certificate.Load()
+		if cert == nil {
+			return nil, fmt.Errorf("No certificate available for %q", hello.ServerName)
+		}
+
+		return cert, nil
+	}
+}
+
+// sameIngress returns whether both ingresses are served the same way, ignoring their certificates.
+func sameIngress(a Ingress, b Ingress) bool {
+	return a.Project == b.Project &&
+		a.Network == b.Network &&
+		a.ListenAddress == b.ListenAddress &&
+		reflect.DeepEqual(a.Config, b.Config) &&
+		reflect.DeepEqual(a.Routes, b.Routes)
+}
@@ -0,0 +1,176 @@
+package ingress
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// ValidateHostname checks that the host name can be served by an ingress.
+func ValidateHostname(hostname string) error {
+	if hostname == "" {
+		return fmt.Errorf("Host name is required")
+	}
+
+	if len(hostname) > 253 {
+		return fmt.Errorf("Host name must be at most 253 characters long")
+	}
+
+	if net.ParseIP(hostname) != nil {
+		return fmt.Errorf("Host name can't be an IP address")
+	}
+
+	for _, label := range strings.Split(hostname, ".") {
+		err := validate.IsHostname(label)
+		if err != nil {
+			return fmt.Errorf("Invalid host name %q: %w", hostname, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateConfig checks the configuration of an ingress.
+func ValidateConfig(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=network-ingress; group=ingress-conf; key=tls.acme)
+		// The certificate is obtained from the ACME server configured with {config:option}`server-acme:acme.ca_url`,
+		// using {config:option}`server-acme:acme.email`. The HTTP-01 challenges are answered on port 80 of the listen address.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to obtain the certificate through ACME
+		"tls.acme": validate.Optional(validate.IsBool),
+
+		// lxdmeta:generate(entities=network-ingress; group=ingress-conf; key=tls.certificate)
+		// Can't be combined with {config:option}`network-ingress-ingress-conf:tls.acme`.
+		// ---
+		//  type: string
+		//  shortdesc: PEM encoded certificate to serve
+		"tls.certificate": validate.Optional(validate.IsX509Certificate),
+
+		// lxdmeta:generate(entities=network-ingress; group=ingress-conf; key=tls.key)
+		// The key is never returned by the API.
+		// When updating the ingress, the existing key is kept if the key is omitted and the certificate is unchanged.
+		// ---
+		//  type: string
+		//  shortdesc: PEM encoded private key of the certificate
+		"tls.key": validate.IsAny,
+
+		// lxdmeta:generate(entities=network-ingress; group=ingress-conf; key=http.redirect)
+		// Requests to the ACME challenges are never redirected.
+		// ---
+		//  type: bool
+		//  defaultdesc: `true`
+		//  shortdesc: Whether to redirect HTTP requests to HTTPS once a certificate is available
+		"http.redirect": validate.Optional(validate.IsBool),
+
+		// lxdmeta:generate(entities=network-ingress; group=ingress-conf; key=healthcheck.path)
+		// When set, a target is healthy if a `GET` request to this path returns a status code below 400.
+		// Otherwise, a target is healthy if it accepts TCP connections.
+		// ---
+		//  type: string
+		//  shortdesc: Path of the HTTP health check
+		"healthcheck.path": validate.Optional(func(value string) error {
+			if !strings.HasPrefix(value, "/") {
+				return fmt.Errorf("Health check path must start with /")
+			}
+
+			if strings.ContainsAny(value, " #") {
+				return fmt.Errorf("Health check path can't contain spaces or fragments")
+			}
+
+			return nil
+		}),
+
+		// lxdmeta:generate(entities=network-ingress; group=ingress-conf; key=healthcheck.interval)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `10`
+		//  shortdesc: Interval between health checks in seconds
+		"healthcheck.interval": validate.Optional(validate.IsInRange(1, 3600)),
+	}
+
+	for key, value := range config {
+		// lxdmeta:generate(entities=network-ingress; group=ingress-conf; key=user.*)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: User-provided free-form key/value pairs
+		if strings.HasPrefix(key, "user.") {
+			continue
+		}
+
+		validator, found := rules[key]
+		if !found {
+			return fmt.Errorf("Invalid option %q", key)
+		}
+
+		err := validator(value)
+		if err != nil {
+			return fmt.Errorf("Invalid value for option %q: %w", key, err)
+		}
+	}
+
+	if (config["tls.certificate"] == "") != (config["tls.key"] == "") {
+		return fmt.Errorf("Both %q and %q must be set", "tls.certificate", "tls.key")
+	}
+
+	if config["tls.certificate"] != "" {
+		if shared.IsTrue(config["tls.acme"]) {
+			return fmt.Errorf("%q can't be used with %q", "tls.certificate", "tls.acme")
+		}
+
+		_, err := tls.X509KeyPair([]byte(config["tls.certificate"]), []byte(config["tls.key"]))
+		if err != nil {
+			return fmt.Errorf("Invalid TLS certificate and key: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateRoutes checks the routes of an ingress. The routes must be normalised.
+func ValidateRoutes(routes []api.NetworkIngressRoute) error {
+	seen := map[api.NetworkIngressRoute]bool{}
+
+	for _, r := range routes {
+		if !strings.HasPrefix(r.Path, "/") || strings.ContainsAny(r.Path, " ?#") {
+			return fmt.Errorf("Invalid route path %q", r.Path)
+		}
+
+		for _, segment := range strings.Split(r.Path, "/") {
+			if segment == "." || segment == ".." {
+				return fmt.Errorf("Invalid route path %q", r.Path)
+			}
+		}
+
+		if net.ParseIP(r.TargetAddress) == nil {
+			return fmt.Errorf("Invalid route target address %q", r.TargetAddress)
+		}
+
+		err := validate.IsNetworkPort(r.TargetPort)
+		if err != nil {
+			return fmt.Errorf("Invalid route target port %q: %w", r.TargetPort, err)
+		}
+
+		if r.TargetPort == "0" {
+			return fmt.Errorf("Invalid route target port %q", r.TargetPort)
+		}
+
+		key := api.NetworkIngressRoute{Path: r.Path, TargetAddress: r.TargetAddress, TargetPort: r.TargetPort}
+		if seen[key] {
+			return fmt.Errorf("Duplicate route for path %q to %q", r.Path, net.JoinHostPort(r.TargetAddress, r.TargetPort))
+		}
+
+		seen[key] = true
+	}
+
+	return nil
+}
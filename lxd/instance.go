@@ -10,10 +10,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db"
 	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/device"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/instance/operationlock"
@@ -25,6 +27,7 @@ import (
 	"github.com/canonical/lxd/lxd/task"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/revert"
 )
@@ -766,3 +769,77 @@ func instanceOperationLock(ctx context.Context, projectName string, instanceName
 
 	return locking.Lock(ctx, fmt.Sprintf("InstanceOperation_%s", project.Instance(projectName, instanceName)))
 }
+
+// instanceRedact removes the secrets of the devices, such as the TLS private keys of proxy devices, from a
+// rendered instance or instance snapshot before it's returned to API clients.
+func instanceRedact(render any) {
+	switch render := render.(type) {
+	case *api.Instance:
+		device.ProxyRedactDevices(render.Devices)
+		device.ProxyRedactDevices(render.ExpandedDevices)
+	case *api.InstanceFull:
+		device.ProxyRedactDevices(render.Devices)
+		device.ProxyRedactDevices(render.ExpandedDevices)
+		for i := range render.Snapshots {
+			device.ProxyRedactDevices(render.Snapshots[i].Devices)
+			device.ProxyRedactDevices(render.Snapshots[i].ExpandedDevices)
+		}
+
+	case *api.InstanceSnapshot:
+		device.ProxyRedactDevices(render.Devices)
+		device.ProxyRedactDevices(render.ExpandedDevices)
+	}
+}
+
+// instanceConfigKeyRunsCommand returns whether the config key sets a command run inside the instance.
+func instanceConfigKeyRunsCommand(key string) bool {
+	if key == "healthcheck.command" {
+		return true
+	}
+
+	return strings.HasPrefix(key, "schedule.") && strings.HasSuffix(key, ".command") && strings.Count(key, ".") == 2
+}
+
+// instanceCommandEnvironment returns the environment of the commands LXD runs inside the instance as configured
+// by its health check and scheduled action keys.
+func instanceCommandEnvironment() map[string]string {
+	return map[string]string{
+		"PATH": "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		"HOME": "/root",
+		"LANG": "C.UTF-8",
+	}
+}
+
+// instanceCommandConfigCheckAccess checks that the requestor has the given entitlement on the entity when the new
+// config sets or changes a command run inside the instance, as this grants the same access as running commands in it.
+func instanceCommandConfigCheckAccess(s *state.State, r *http.Request, entityURL *api.URL, entitlement auth.Entitlement, oldConfig map[string]string, newConfig map[string]string) error {
+	for key, value := range newConfig {
+		if !instanceConfigKeyRunsCommand(key) || value == "" || value == oldConfig[key] {
+			continue
+		}
+
+		err := s.Authorizer.CheckPermission(r.Context(), r, entityURL, entitlement)
+		if err != nil && auth.IsDeniedError(err) {
+			return api.StatusErrorf(http.StatusForbidden, "Setting %q requires permission to run commands in the instance", key)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// instanceSessionRecordingConfigCheckAccess checks that the requestor can edit the project when the new config
+// changes security.session_recording, so that users who are recorded can't switch the recording off.
+func instanceSessionRecordingConfigCheckAccess(s *state.State, r *http.Request, projectName string, oldConfig map[string]string, newConfig map[string]string) error {
+	if shared.IsTrue(oldConfig["security.session_recording"]) == shared.IsTrue(newConfig["security.session_recording"]) {
+		return nil
+	}
+
+	err := s.Authorizer.CheckPermission(r.Context(), r, entity.ProjectURL(projectName), auth.EntitlementCanEdit)
+	if err != nil && auth.IsDeniedError(err) {
+		return api.StatusErrorf(http.StatusForbidden, "Changing %q requires permission to edit the project", "security.session_recording")
+	}
+
+	return err
+}
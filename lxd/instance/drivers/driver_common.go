@@ -604,6 +604,69 @@ func (d *common) restartCommon(inst instance.Instance, timeout time.Duration) er
 	return nil
 }
 
+// restartPolicyApply starts the instance again in the background after it stopped on its own, if its restart
+// policy requires it. The failed argument indicates whether the instance stopped unexpectedly.
+func (d *common) restartPolicyApply(inst instance.Instance, failed bool) {
+	policy := d.expandedConfig["boot.restart_policy"]
+	if policy != "always" && (policy != "on-failure" || !failed) {
+		return
+	}
+
+	// Consider the instance recovered once it ran long enough since its last start.
+	attempts, _ := strconv.ParseUint(d.localConfig["volatile.restart_policy.attempts"], 10, 32)
+	if time.Since(d.lastUsedDate) > 10*time.Minute {
+		attempts = 0
+	}
+
+	maxAttempts := uint64(3)
+	if d.expandedConfig["boot.restart_policy.max_attempts"] != "" {
+		maxAttempts, _ = strconv.ParseUint(d.expandedConfig["boot.restart_policy.max_attempts"], 10, 32)
+	}
+
+	if maxAttempts > 0 && attempts >= maxAttempts {
+		d.logger.Warn("Not restarting instance, maximum number of restart attempts reached", logger.Ctx{"attempts": attempts})
+		return
+	}
+
+	delay := uint64(5)
+	if d.expandedConfig["boot.restart_policy.delay"] != "" {
+		delay, _ = strconv.ParseUint(d.expandedConfig["boot.restart_policy.delay"], 10, 32)
+	}
+
+	// Double the delay on each consecutive attempt, up to 5 minutes.
+	backoff := time.Duration(delay) * time.Second
+	for i := uint64(0); i < attempts && backoff < 5*time.Minute; i++ {
+		backoff *= 2
+	}
+
+	backoff = min(backoff, 5*time.Minute)
+
+	attempts++
+	err := d.VolatileSet(map[string]string{"volatile.restart_policy.attempts": strconv.FormatUint(attempts, 10)})
+	if err != nil {
+		d.logger.Warn("Failed recording restart attempt", logger.Ctx{"err": err})
+	}
+
+	d.logger.Info("Restarting instance following restart policy", logger.Ctx{"policy": policy, "attempt": attempts, "delay": backoff})
+
+	go func() {
+		time.Sleep(backoff)
+
+		// Don't interfere with the instance being started in the meantime.
+		if inst.IsRunning() {
+			return
+		}
+
+		err := inst.Start(false)
+		if err != nil {
+			d.logger.Error("Failed restarting instance following restart policy", logger.Ctx{"err": err})
+			return
+		}
+
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceRestarted.Event(d, logger.Ctx{"reason": "restart-policy", "attempt": attempts}))
+	}()
+}
+
 // rebuildCommon handles the common part of instance rebuilds.
 func (d *common) rebuildCommon(inst instance.Instance, img *api.Image, op *operations.Operation) error {
 	instLocalConfig := d.localConfig
@@ -1125,12 +1188,21 @@ func (d *common) canMigrate(inst instance.Instance) (migrate bool, live bool) {
 }
 
 // recordLastState records last power and used time into local config and database config.
+// The health status of the previous run is cleared as the health checks start afresh.
 func (d *common) recordLastState() error {
 	var err error
 
+	if d.localConfig["volatile.last_state.health"] != "" {
+		err = d.VolatileSet(map[string]string{"volatile.last_state.health": ""})
+		if err != nil {
+			return fmt.Errorf("Error clearing instance health status: %w", err)
+		}
+	}
+
 	// Record power state.
 	d.localConfig["volatile.last_state.power"] = instance.PowerStateRunning
 	d.expandedConfig["volatile.last_state.power"] = instance.PowerStateRunning
+	d.lastUsedDate = time.Now().UTC()
 
 	// Database updates
 	return d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -1142,7 +1214,7 @@ func (d *common) recordLastState() error {
 		}
 
 		// Update time instance last started time.
-		err = tx.UpdateInstanceLastUsedDate(d.id, time.Now().UTC())
+		err = tx.UpdateInstanceLastUsedDate(d.id, d.lastUsedDate)
 		if err != nil {
 			err = fmt.Errorf("Error updating instance last used: %w", err)
 			return err
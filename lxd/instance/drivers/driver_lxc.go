@@ -73,6 +73,7 @@ import (
 	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/termios"
 	"github.com/canonical/lxd/shared/units"
+	"github.com/canonical/lxd/shared/version"
 	"github.com/canonical/lxd/shared/ws"
 )
 
@@ -2384,6 +2385,20 @@ func (d *lxc) Start(stateful bool) error {
 			return err
 		}
 
+		// Check that the checkpoint can be restored on this host before starting anything.
+		_, err = d.mount()
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+
+		err = d.checkpointCheckRestore(d.StatePath())
+		_ = d.unmount()
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+
 		d.logger.Info("Restoring stateful checkpoint")
 
 		criuMigrationArgs := instance.CriuMigrationArgs{
@@ -2649,11 +2664,18 @@ func (d *lxc) Stop(stateful bool) error {
 
 	// Handle stateful stop
 	if stateful {
+		criuVersion, err := d.checkpointCheck()
+		if err != nil {
+			err = fmt.Errorf("Unable to stop the instance statefully: %w", err)
+			op.Done(err)
+			return err
+		}
+
 		// Cleanup any existing state
 		stateDir := d.StatePath()
 		_ = os.RemoveAll(stateDir)
 
-		err := os.MkdirAll(stateDir, 0700)
+		err = os.MkdirAll(stateDir, 0700)
 		if err != nil {
 			op.Done(err)
 			return err
@@ -2676,6 +2698,12 @@ func (d *lxc) Stop(stateful bool) error {
 			return err
 		}
 
+		err = d.checkpointWriteInfo(stateDir, criuVersion)
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+
 		err = op.Wait(context.Background())
 		if err != nil && d.IsRunning() {
 			return err
@@ -2922,8 +2950,9 @@ func (d *lxc) onStop(args map[string]string) error {
 
 	// Record power state.
 	err = d.VolatileSet(map[string]string{
-		"volatile.last_state.power": instance.PowerStateStopped,
-		"volatile.last_state.ready": "false",
+		"volatile.last_state.power":  instance.PowerStateStopped,
+		"volatile.last_state.ready":  "false",
+		"volatile.last_state.health": "",
 	})
 	if err != nil {
 		// Don't return an error here as we still want to cleanup the instance even if DB not available.
@@ -3026,6 +3055,10 @@ func (d *lxc) onStop(args map[string]string) error {
 				op.Done(fmt.Errorf("Failed deleting ephemeral instance: %w", err))
 				return
 			}
+		} else if op.GetInstanceInitiated() {
+			// The exit status of the container's init isn't available here, so any stop that wasn't
+			// requested through LXD is considered a failure.
+			d.restartPolicyApply(d, true)
 		}
 	}(d, target, op)
 
@@ -3346,6 +3379,10 @@ func (d *lxc) renderState(statusCode api.StatusCode, hostInterfaces []net.Interf
 
 	status.Disk = d.diskState()
 
+	if d.isRunningStatusCode(statusCode) {
+		d.diskCountersState(status.Disk)
+	}
+
 	d.release()
 
 	return &status, nil
@@ -3365,76 +3402,102 @@ func (d *lxc) snapshot(name string, expiry time.Time, stateful bool) error {
 			return fmt.Errorf("Unable to create a stateful snapshot. The instance isn't running")
 		}
 
-		_, err := exec.LookPath("criu")
+		err := d.CheckpointState()
 		if err != nil {
-			return fmt.Errorf("Unable to create a stateful snapshot. CRIU isn't installed")
+			return fmt.Errorf("Unable to create a stateful snapshot: %w", err)
 		}
 
-		// Cleanup any existing state
-		stateDir := d.StatePath()
-		_ = os.RemoveAll(stateDir)
+		// Make sure we don't keep state around after the snapshot has been made.
+		defer func() { _ = os.RemoveAll(d.StatePath()) }()
+	}
 
-		// Create the state path and make sure we don't keep state around after the snapshot has been made.
-		err = os.MkdirAll(stateDir, 0700)
-		if err != nil {
-			return err
-		}
+	// Wait for any file operations to complete to have a more consistent snapshot.
+	d.stopForkfile(false)
 
-		defer func() { _ = os.RemoveAll(stateDir) }()
+	return d.snapshotCommon(d, name, expiry, stateful)
+}
 
-		// Release liblxc container once done.
-		defer func() {
-			d.release()
-		}()
+// CheckpointState dumps the running state of the container into its state path, leaving the container running.
+func (d *lxc) CheckpointState() error {
+	if !d.IsRunning() {
+		return fmt.Errorf("The instance isn't running")
+	}
 
-		// Load the go-lxc struct
-		if d.expandedConfig["raw.lxc"] != "" {
-			cc, err := d.initLXC(true)
-			if err != nil {
-				return err
-			}
+	criuVersion, err := d.checkpointCheck()
+	if err != nil {
+		return err
+	}
 
-			err = d.loadRawLXCConfig(cc)
-			if err != nil {
-				return err
-			}
-		} else {
-			_, err = d.initLXC(false)
-			if err != nil {
-				return err
-			}
-		}
+	// Cleanup any existing state
+	stateDir := d.StatePath()
+	_ = os.RemoveAll(stateDir)
 
-		/* TODO: ideally we would freeze here and unfreeze below after
-		 * we've copied the filesystem, to make sure there are no
-		 * changes by the container while snapshotting. Unfortunately
-		 * there is abug in CRIU where it doesn't leave the container
-		 * in the same state it found it w.r.t. freezing, i.e. CRIU
-		 * freezes too, and then /always/ thaws, even if the container
-		 * was frozen. Until that's fixed, all calls to Unfreeze()
-		 * after snapshotting will fail.
-		 */
-		criuMigrationArgs := instance.CriuMigrationArgs{
-			Cmd:          liblxc.MIGRATE_DUMP,
-			StateDir:     stateDir,
-			Function:     "snapshot",
-			Stop:         false,
-			ActionScript: false,
-			DumpDir:      "",
-			PreDumpDir:   "",
+	err = os.MkdirAll(stateDir, 0700)
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	revert.Add(func() { _ = os.RemoveAll(stateDir) })
+
+	// Release liblxc container once done.
+	defer func() {
+		d.release()
+	}()
+
+	// Load the go-lxc struct
+	if d.expandedConfig["raw.lxc"] != "" {
+		cc, err := d.initLXC(true)
+		if err != nil {
+			return err
 		}
 
-		// Dump the state.
-		err = d.migrate(&criuMigrationArgs)
+		err = d.loadRawLXCConfig(cc)
+		if err != nil {
+			return err
+		}
+	} else {
+		_, err = d.initLXC(false)
 		if err != nil {
-			return fmt.Errorf("Failed taking stateful checkpoint: %w", err)
+			return err
 		}
 	}
 
-	// Wait for any file operations to complete to have a more consistent snapshot.
-	d.stopForkfile(false)
+	/* TODO: ideally we would freeze here and unfreeze below after
+	 * we've copied the filesystem, to make sure there are no
+	 * changes by the container while snapshotting. Unfortunately
+	 * there is abug in CRIU where it doesn't leave the container
+	 * in the same state it found it w.r.t. freezing, i.e. CRIU
+	 * freezes too, and then /always/ thaws, even if the container
+	 * was frozen. Until that's fixed, all calls to Unfreeze()
+	 * after snapshotting will fail.
+	 */
+	criuMigrationArgs := instance.CriuMigrationArgs{
+		Cmd:          liblxc.MIGRATE_DUMP,
+		StateDir:     stateDir,
+		Function:     "snapshot",
+		Stop:         false,
+		ActionScript: false,
+		DumpDir:      "",
+		PreDumpDir:   "",
+	}
 
-	return d.snapshotCommon(d, name, expiry, stateful)
+	// Dump the state.
+	err = d.migrate(&criuMigrationArgs)
+	if err != nil {
+		return fmt.Errorf("Failed taking stateful checkpoint: %w", err)
+	}
+
+	err = d.checkpointWriteInfo(stateDir, criuVersion)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+
+	return nil
 }
 
 // Snapshot takes a new snapshot.
@@ -3460,6 +3523,16 @@ func (d *lxc) Restore(sourceContainer instance.Instance, stateful bool) error {
 
 	defer op.Done(nil)
 
+	// Check that the state can be restored before stopping the container.
+	if stateful {
+		_, err := d.checkpointCheck()
+		if err != nil {
+			err = fmt.Errorf("Failed to restore container state: %w", err)
+			op.Done(err)
+			return err
+		}
+	}
+
 	// Stop the container.
 	wasRunning := d.IsRunning()
 	if wasRunning {
@@ -3595,6 +3668,12 @@ func (d *lxc) Restore(sourceContainer instance.Instance, stateful bool) error {
 			return err
 		}
 
+		err = d.checkpointCheckRestore(d.StatePath())
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+
 		d.logger.Debug("Performing stateful restore", ctxMap)
 		d.stateful = true
 
@@ -3608,21 +3687,16 @@ func (d *lxc) Restore(sourceContainer instance.Instance, stateful bool) error {
 			PreDumpDir:   "",
 		}
 
-		// Checkpoint.
+		// Restore the checkpoint.
 		err = d.migrate(&criuMigrationArgs)
 		if err != nil {
 			op.Done(err)
-			return fmt.Errorf("Failed taking stateful checkpoint: %w", err)
+			return fmt.Errorf("Failed restoring stateful checkpoint: %w", err)
 		}
 
 		// Remove the state from the parent container; we only keep this in snapshots.
-		err2 := os.RemoveAll(d.StatePath())
-		if err2 != nil && !os.IsNotExist(err) {
-			op.Done(err)
-			return err
-		}
-
-		if err != nil {
+		err = os.RemoveAll(d.StatePath())
+		if err != nil && !os.IsNotExist(err) {
 			op.Done(err)
 			return err
 		}
@@ -5146,6 +5220,116 @@ func getCRIULogErrors(imagesDir string, method string) (string, error) {
 	return strings.Join(ret, "\n"), nil
 }
 
+// criuStateInfoFile is the name of the file recording the host a CRIU dump was taken on.
+const criuStateInfoFile = "lxd-criu.yaml"
+
+// criuStateInfo describes the host a CRIU dump was taken on.
+type criuStateInfo struct {
+	Architecture  string `yaml:"architecture"`
+	KernelVersion string `yaml:"kernel_version"`
+	CRIUVersion   string `yaml:"criu_version"`
+}
+
+// getCRIUVersion returns the version of the installed CRIU.
+func getCRIUVersion() (*version.DottedVersion, error) {
+	_, err := exec.LookPath("criu")
+	if err != nil {
+		return nil, fmt.Errorf("CRIU isn't installed")
+	}
+
+	out, err := shared.RunCommand("criu", "--version")
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting CRIU version: %w", err)
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		value, found := strings.CutPrefix(strings.TrimSpace(line), "Version:")
+		if found {
+			return version.Parse(strings.TrimSpace(value))
+		}
+	}
+
+	return nil, fmt.Errorf("Failed parsing CRIU version from %q", strings.TrimSpace(out))
+}
+
+// checkpointCheck checks that the host is able to checkpoint and restore containers and returns the CRIU version.
+func (d *lxc) checkpointCheck() (*version.DottedVersion, error) {
+	if !d.state.OS.CheckpointRestore {
+		return nil, fmt.Errorf("The kernel doesn't support checkpoint/restore")
+	}
+
+	return getCRIUVersion()
+}
+
+// checkpointWriteInfo records the host the CRIU dump in stateDir was taken on.
+func (d *lxc) checkpointWriteInfo(stateDir string, criuVersion *version.DottedVersion) error {
+	arch, err := osarch.ArchitectureGetLocal()
+	if err != nil {
+		return err
+	}
+
+	info := criuStateInfo{
+		Architecture:  arch,
+		KernelVersion: d.state.OS.KernelVersion.String(),
+		CRIUVersion:   criuVersion.String(),
+	}
+
+	data, err := yaml.Marshal(&info)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(stateDir, criuStateInfoFile), data, 0600)
+}
+
+// checkpointCheckRestore checks that the CRIU dump in stateDir can be restored on this host.
+func (d *lxc) checkpointCheckRestore(stateDir string) error {
+	criuVersion, err := d.checkpointCheck()
+	if err != nil {
+		return fmt.Errorf("Failed to restore container state: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(stateDir, criuStateInfoFile))
+	if err != nil {
+		// Dumps taken by older LXD versions don't record the host they were taken on.
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("Failed reading container state information: %w", err)
+	}
+
+	info := criuStateInfo{}
+	err = yaml.Unmarshal(data, &info)
+	if err != nil {
+		return fmt.Errorf("Failed parsing container state information: %w", err)
+	}
+
+	arch, err := osarch.ArchitectureGetLocal()
+	if err != nil {
+		return err
+	}
+
+	if info.Architecture != arch {
+		return fmt.Errorf("Container state was taken on a %q host and can't be restored on a %q host", info.Architecture, arch)
+	}
+
+	dumpCRIUVersion, err := version.Parse(info.CRIUVersion)
+	if err != nil {
+		return fmt.Errorf("Failed parsing CRIU version of container state: %w", err)
+	}
+
+	if criuVersion.Compare(dumpCRIUVersion) < 0 {
+		return fmt.Errorf("Container state was taken with CRIU %s and can't be restored with the older CRIU %s", dumpCRIUVersion, criuVersion)
+	}
+
+	if info.KernelVersion != d.state.OS.KernelVersion.String() {
+		d.logger.Warn("Restoring container state taken on a different kernel", logger.Ctx{"stateKernel": info.KernelVersion, "kernel": d.state.OS.KernelVersion.String()})
+	}
+
+	return nil
+}
+
 // Check if CRIU supports pre-dumping and number of pre-dump iterations.
 func (d *lxc) migrationSendCheckForPreDumpSupport() (bool, int) {
 	// Check if this architecture/kernel/criu combination supports pre-copy dirty memory tracking feature.
@@ -7294,6 +7478,95 @@ func (d *lxc) diskState() map[string]api.InstanceStateDisk {
 	return disk
 }
 
+// diskCountersState adds the I/O counters of the host block devices backing the disks to the disk state.
+func (d *lxc) diskCountersState(disk map[string]api.InstanceStateDisk) {
+	cc, err := d.initLXC(false)
+	if err != nil {
+		return
+	}
+
+	cg, err := d.cgroup(cc, true)
+	if err != nil {
+		return
+	}
+
+	ioStats, err := cg.GetIOStats()
+	if err != nil {
+		if !errors.Is(err, cgroup.ErrControllerMissing) {
+			d.logger.Warn("Failed to get disk stats", logger.Ctx{"err": err})
+		}
+
+		return
+	}
+
+	for _, dev := range d.expandedDevices.Sorted() {
+		if dev.Config["type"] != "disk" || dev.Config["path"] == "" {
+			continue
+		}
+
+		var sourcePath string
+		if dev.Config["pool"] != "" {
+			volName := project.Instance(d.project.Name, d.name)
+			volType := storageDrivers.VolumeTypeContainer
+			if dev.Config["source"] != "" {
+				volName = project.StorageVolume(d.project.Name, dev.Config["source"])
+				volType = storageDrivers.VolumeTypeCustom
+			}
+
+			sourcePath = storageDrivers.GetVolumeMountPath(dev.Config["pool"], volType, volName)
+		} else {
+			sourcePath = shared.HostPath(dev.Config["source"])
+		}
+
+		// Disks not backed by a host block device, such as those on ZFS datasets, have no counters.
+		blockDevName, err := hostBlockDeviceName(sourcePath)
+		if err != nil {
+			continue
+		}
+
+		stats := ioStats[blockDevName]
+		if stats == nil {
+			continue
+		}
+
+		state := disk[dev.Name]
+		state.Counters = &api.InstanceStateDiskCounters{
+			BytesRead:       int64(stats.ReadBytes),
+			BytesWritten:    int64(stats.WrittenBytes),
+			ReadsCompleted:  int64(stats.ReadsCompleted),
+			WritesCompleted: int64(stats.WritesCompleted),
+		}
+
+		disk[dev.Name] = state
+	}
+}
+
+// hostBlockDeviceName returns the name of the host block device backing the given path, as listed in
+// /proc/partitions. Partitions are resolved to their parent disk as I/O is accounted on the whole disk.
+func hostBlockDeviceName(path string) (string, error) {
+	var stat unix.Stat_t
+	err := unix.Stat(path, &stat)
+	if err != nil {
+		return "", err
+	}
+
+	devID := uint64(stat.Dev)
+	if stat.Mode&unix.S_IFMT == unix.S_IFBLK {
+		devID = uint64(stat.Rdev)
+	}
+
+	sysPath, err := filepath.EvalSymlinks(fmt.Sprintf("/sys/dev/block/%d:%d", unix.Major(devID), unix.Minor(devID)))
+	if err != nil {
+		return "", err
+	}
+
+	if shared.PathExists(filepath.Join(sysPath, "partition")) {
+		sysPath = filepath.Dir(sysPath)
+	}
+
+	return filepath.Base(sysPath), nil
+}
+
 func (d *lxc) memoryState() api.InstanceStateMemory {
 	memory := api.InstanceStateMemory{}
 
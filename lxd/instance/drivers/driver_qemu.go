@@ -102,6 +102,9 @@ const qemuNetDevIDPrefix = "lxd_"
 // qemuBlockDevIDPrefix used as part of the name given QEMU blockdevs generated from user added devices.
 const qemuBlockDevIDPrefix = "lxd_"
 
+// qemuIOThreadPrefix used as part of the name given QEMU I/O thread objects.
+const qemuIOThreadPrefix = "iothread"
+
 // qemuMigrationNBDExportName is the name of the disk device export by the migration NBD server.
 const qemuMigrationNBDExportName = "lxd_root"
 
@@ -136,6 +139,10 @@ var vmLegacyFirmwares = []vmFirmware{
 	{code: "OVMF_CODE.CSM.fd", vars: "OVMF_VARS.CSM.fd"},
 }
 
+// Single-image firmwares for SEV-SNP and TDX guests, which can't use pflash firmware and NVRAM.
+var vmSEVSNPFirmwares = []string{"OVMF.amdsev.fd", "AMDSEV.fd"}
+var vmTDXFirmwares = []string{"OVMF.inteltdx.fd", "OVMF.tdx.fd"}
+
 // qemuSparseUSBPorts is the amount of sparse USB ports for VMs.
 // 4 are reserved, and the other 4 can be used for any USB device.
 const qemuSparseUSBPorts = 8
@@ -405,7 +412,7 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]any)
 	state := d.state
 
 	return func(event string, data map[string]any) {
-		if !shared.ValueInSlice(event, []string{qmp.EventVMShutdown, qmp.EventAgentStarted}) {
+		if !shared.ValueInSlice(event, []string{qmp.EventVMShutdown, qmp.EventAgentStarted, qmp.EventWatchdog}) {
 			return // Don't bother loading the instance from DB if we aren't going to handle the event.
 		}
 
@@ -455,15 +462,50 @@ func (d *qemu) getMonitorEventHandler() func(event string, data map[string]any)
 				d.logger.Debug("Instance stopped", logger.Ctx{"target": target, "reason": data["reason"]})
 			}
 
-			err = d.onStop(target)
+			// A guest panic or QEMU going away is considered a failure for the restart policy.
+			failed := entry == "guest-panic" || entry == qmp.EventVMShutdownReasonDisconnect
+
+			err = d.onStop(target, failed)
 			if err != nil {
 				d.logger.Error("Failed to cleanly stop instance", logger.Ctx{"err": err})
 				return
 			}
+		} else if event == qmp.EventWatchdog {
+			action, _ := data["action"].(string)
+			d.onWatchdog(action)
 		}
 	}
 }
 
+// onWatchdog records that the watchdog of the instance fired and restarts the instance if requested.
+func (d *qemu) onWatchdog(action string) {
+	d.logger.Warn("Instance watchdog triggered", logger.Ctx{"action": action})
+
+	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceWatchdogTriggered.Event(d, logger.Ctx{"action": action}))
+
+	err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarning(ctx, d.node, d.project.Name, entity.TypeInstance, d.ID(), warningtype.InstanceWatchdogTriggered, fmt.Sprintf("Watchdog action %q", action))
+	})
+	if err != nil {
+		d.logger.Warn("Failed to record watchdog warning", logger.Ctx{"err": err})
+	}
+
+	// A guest reset is handled as a reboot once QEMU reports the shutdown.
+	if action == "reset" || d.expandedConfig["boot.recovery"] != "restart" {
+		return
+	}
+
+	d.logger.Info("Restarting instance following watchdog recovery policy")
+
+	// Restart in the background as stopping the instance waits on the monitor that is running this handler.
+	go func() {
+		err := d.Restart(0)
+		if err != nil {
+			d.logger.Error("Failed to restart instance after watchdog triggered", logger.Ctx{"err": err})
+		}
+	}()
+}
+
 // mount the instance's config volume if needed.
 func (d *qemu) mount() (*storagePools.MountInfo, error) {
 	var pool storagePools.Pool
@@ -605,7 +647,8 @@ func (d *qemu) pidWait(timeout time.Duration) bool {
 }
 
 // onStop is run when the instance stops.
-func (d *qemu) onStop(target string) error {
+// The failed argument indicates whether the instance stopped unexpectedly.
+func (d *qemu) onStop(target string, failed bool) error {
 	d.logger.Debug("onStop hook started", logger.Ctx{"target": target})
 	defer d.logger.Debug("onStop hook finished", logger.Ctx{"target": target})
 
@@ -631,8 +674,9 @@ func (d *qemu) onStop(target string) error {
 
 	// Record power state.
 	err = d.VolatileSet(map[string]string{
-		"volatile.last_state.power": instance.PowerStateStopped,
-		"volatile.last_state.ready": "false",
+		"volatile.last_state.power":  instance.PowerStateStopped,
+		"volatile.last_state.ready":  "false",
+		"volatile.last_state.health": "",
 	})
 	if err != nil {
 		// Don't return an error here as we still want to cleanup the instance even if DB not available.
@@ -644,6 +688,10 @@ func (d *qemu) onStop(target string) error {
 	_ = os.Remove(d.pidFilePath())
 	_ = os.Remove(d.monitorPath())
 
+	// Release the fork memory.
+	d.forkBaseRelease()
+	_ = os.Remove(d.forkMemoryPath())
+
 	// Stop the storage for the instance.
 	err = d.unmount()
 	if err != nil && !errors.Is(err, storageDrivers.ErrInUse) {
@@ -682,6 +730,8 @@ func (d *qemu) onStop(target string) error {
 			op.Done(err)
 			return err
 		}
+	} else if op.GetInstanceInitiated() {
+		d.restartPolicyApply(d, failed)
 	}
 
 	return nil
@@ -1093,6 +1143,57 @@ func (d *qemu) validateStartup(stateful bool, statusCode api.StatusCode) error {
 		return fmt.Errorf("Stateful start requires migration.stateful to be set to true")
 	}
 
+	// Forking relies on stateful migration and on a single file backed memory object.
+	if shared.IsTrue(d.expandedConfig["migration.fork"]) {
+		if shared.IsFalseOrEmpty(d.expandedConfig["migration.stateful"]) {
+			return fmt.Errorf("migration.fork requires migration.stateful to be set to true")
+		}
+
+		if d.architecture != osarch.ARCH_64BIT_INTEL_X86 {
+			return fmt.Errorf("migration.fork is only supported on x86_64")
+		}
+
+		if shared.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
+			return fmt.Errorf("migration.fork can't be used with limits.memory.hugepages")
+		}
+
+		if shared.IsTrue(d.expandedConfig["security.sev"]) || shared.IsTrue(d.expandedConfig["security.tdx"]) {
+			return fmt.Errorf("migration.fork can't be used with security.sev or security.tdx")
+		}
+	}
+
+	err = d.validateCPUPinStrategy()
+	if err != nil {
+		return err
+	}
+
+	// Only one confidential computing technology can be used at a time.
+	if shared.IsTrue(d.expandedConfig["security.tdx"]) && shared.IsTrue(d.expandedConfig["security.sev"]) {
+		return fmt.Errorf("security.tdx can't be used with security.sev")
+	}
+
+	if shared.IsTrue(d.expandedConfig["security.sev.snp"]) {
+		if shared.IsFalseOrEmpty(d.expandedConfig["security.sev"]) {
+			return fmt.Errorf("security.sev.snp requires security.sev to be set to true")
+		}
+
+		if d.expandedConfig["security.sev.session.dh"] != "" || d.expandedConfig["security.sev.session.data"] != "" {
+			return fmt.Errorf("security.sev.session.dh and security.sev.session.data can't be used with security.sev.snp")
+		}
+	}
+
+	// SEV-SNP and TDX guests boot from a single-image firmware without any UEFI variables store.
+	confidentialType := d.confidentialType()
+	if confidentialType == "sev-snp" || confidentialType == "tdx" {
+		if shared.IsTrue(d.expandedConfig["security.csm"]) {
+			return fmt.Errorf("CSM can't be enabled with %s. Please set security.csm=false on the instance", confidentialType)
+		}
+
+		if shared.IsTrueOrEmpty(d.expandedConfig["security.secureboot"]) {
+			return fmt.Errorf("Secure boot can't be enabled with %s. Please set security.secureboot=false on the instance", confidentialType)
+		}
+	}
+
 	return nil
 }
 
@@ -1143,6 +1244,20 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		}
 	}
 
+	// Check whether the instance is being started from a fork base.
+	forkBaseID := d.localConfig["volatile.fork.restore"]
+	if forkBaseID != "" {
+		if stateful {
+			return fmt.Errorf("Stateful start isn't possible while the instance is pending a fork restore")
+		}
+
+		if !shared.PathExists(forkBasePath(forkBaseID)) {
+			// Clear the fork base so that the next start boots normally.
+			_ = d.VolatileSet(map[string]string{"volatile.fork.restore": ""})
+			return fmt.Errorf("Fork base %q is no longer available", forkBaseID)
+		}
+	}
+
 	// Setup a new operation if needed.
 	if op == nil {
 		op, err = operationlock.CreateWaitGet(d.Project().Name, d.Name(), operationlock.ActionStart, []operationlock.Action{operationlock.ActionRestart, operationlock.ActionRestore}, false, false)
@@ -1386,7 +1501,7 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 	// This is used by the lxd-agent in preference to 9p (due to its improved performance) and in scenarios
 	// where 9p isn't available in the VM guest OS.
 	configSockPath, configPIDPath := d.configVirtiofsdPaths()
-	revertFunc, unixListener, err := device.DiskVMVirtiofsdStart(d.state.OS.ExecPath, d, configSockPath, configPIDPath, "", configMntPath, nil)
+	revertFunc, unixListener, err := device.DiskVMVirtiofsdStart(d.state.OS.ExecPath, d, configSockPath, configPIDPath, "", configMntPath, nil, "")
 	if err != nil {
 		var errUnsupported device.UnsupportedError
 		if !errors.As(err, &errUnsupported) {
@@ -1529,11 +1644,22 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		}
 	}
 
+	// If starting from a fork base, its device state is loaded once the devices are setup.
+	if forkBaseID != "" {
+		qemuCmd = append(qemuCmd, "-incoming", "defer")
+	}
+
 	// SMBIOS only on x86_64 and aarch64.
 	if d.architectureSupportsUEFI(d.architecture) {
 		qemuCmd = append(qemuCmd, "-smbios", "type=2,manufacturer=Canonical Ltd.,product=LXD")
 	}
 
+	err = d.forkPrepareFiles(forkBaseID)
+	if err != nil {
+		op.Done(err)
+		return fmt.Errorf("Failed preparing fork memory files: %w", err)
+	}
+
 	// Attempt to drop privileges (doesn't work when restoring state).
 	if !stateful && d.state.OS.UnprivUser != "" {
 		qemuCmd = append(qemuCmd, "-runas", d.state.OS.UnprivUser)
@@ -1683,8 +1809,9 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 	// We need to hotplug vCPUs now if:
 	// - architecture supports hotplug
 	// - no explicit vCPU pinning was specified (cpuInfo.vcpus == nil)
+	// - no explicit guest NUMA layout was specified (cpuInfo.numa == nil)
 	// - we have more than one vCPU set
-	if d.architectureSupportsCPUHotplug() && cpuInfo.vcpus == nil && cpuInfo.cores > 1 {
+	if d.architectureSupportsCPUHotplug() && cpuInfo.vcpus == nil && cpuInfo.numa == nil && cpuInfo.cores > 1 {
 		// Setup CPUs and core scheduling for hotpluggable CPU systems.
 		err := d.setCPUs(cpuInfo.cores)
 		if err != nil {
@@ -1743,6 +1870,11 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		"panic":    "pause",    // Pause on panics to allow investigation.
 	}
 
+	watchdogAction := d.watchdogAction()
+	if watchdogAction != "" {
+		actions["watchdog"] = watchdogAction
+	}
+
 	err = monitor.SetAction(actions)
 	if err != nil {
 		op.Done(err)
@@ -1756,6 +1888,21 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 			op.Done(err)
 			return err
 		}
+	} else if forkBaseID != "" {
+		err = d.forkRestoreState(monitor, forkBaseID)
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+	}
+
+	// Record the confidential computing state, including the launch measurement, before the guest runs.
+	if !stateful && forkBaseID == "" {
+		err = d.recordConfidentialState(monitor)
+		if err != nil {
+			op.Done(err)
+			return fmt.Errorf("Failed recording confidential computing state: %w", err)
+		}
 	}
 
 	// Start the VM.
@@ -1781,6 +1928,15 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		}
 	}
 
+	// Finish handling fork start.
+	if forkBaseID != "" {
+		err = d.forkRestoreFinish()
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+	}
+
 	// Record last start state.
 	err = d.recordLastState()
 	if err != nil {
@@ -1801,6 +1957,10 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 	}
 
 	if op.Action() == "start" {
+		// Only a start requested by the user clears the watchdog warning, a restart from the recovery
+		// policy keeps it.
+		_ = warnings.ResolveWarningsByNodeAndProjectAndTypeAndEntity(d.state.DB.Cluster, d.node, d.project.Name, warningtype.InstanceWatchdogTriggered, entity.TypeInstance, d.ID())
+
 		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceStarted.Event(d, nil))
 	}
 
@@ -1881,21 +2041,157 @@ func (d *qemu) setupSEV(fdFiles *[]*os.File) (*qemuSevOpts, error) {
 		sevOpts.sessionDataFD = fmt.Sprintf("/proc/self/fd/%d", sessionDataFD)
 	}
 
-	if shared.IsTrue(d.expandedConfig["security.sev.policy.es"]) {
+	if shared.IsTrue(d.expandedConfig["security.sev.snp"]) {
+		_, sevSNP := info.Features["sev-snp"]
+		if !sevSNP {
+			return nil, errors.New("AMD SEV-SNP is not supported by the host")
+		}
+
+		sevOpts.snp = true
+	} else if shared.IsTrue(d.expandedConfig["security.sev.policy.es"]) {
 		_, sevES := info.Features["sev-es"]
 		if !sevES {
 			return nil, errors.New("AMD SEV-ES is not supported by the host")
 		}
+	}
+
+	sevOpts.policy = d.sevPolicy()
 
+	return sevOpts, nil
+}
+
+// sevPolicy returns the guest policy bit mask matching the SEV mode of the VM.
+func (d *qemu) sevPolicy() string {
+	if shared.IsTrue(d.expandedConfig["security.sev.snp"]) {
+		// The SEV-SNP guest policy has a different layout than the SEV one. '0x30000' only sets the
+		// mandatory reserved bit and allows SMT, see chapter 4.3 of the SEV-SNP firmware ABI specification:
+		// https://www.amd.com/system/files/TechDocs/56860.pdf
+		return "0x30000"
+	}
+
+	if shared.IsTrue(d.expandedConfig["security.sev.policy.es"]) {
 		// This bit mask is used to specify a guest policy. '0x5' is for SEV-ES. The details of the available policies can be found in the link below (see chapter 3)
 		// https://www.amd.com/system/files/TechDocs/55766_SEV-KM_API_Specification.pdf
-		sevOpts.policy = "0x5"
-	} else {
-		// '0x1' is for a regular SEV policy.
-		sevOpts.policy = "0x1"
+		return "0x5"
 	}
 
-	return sevOpts, nil
+	// '0x1' is for a regular SEV policy.
+	return "0x1"
+}
+
+// confidentialType returns the confidential computing technology used by the VM (sev, sev-es, sev-snp or tdx).
+// Returns an empty string if the VM doesn't use confidential computing.
+func (d *qemu) confidentialType() string {
+	if shared.IsTrue(d.expandedConfig["security.tdx"]) {
+		return "tdx"
+	}
+
+	if shared.IsFalseOrEmpty(d.expandedConfig["security.sev"]) {
+		return ""
+	}
+
+	if shared.IsTrue(d.expandedConfig["security.sev.snp"]) {
+		return "sev-snp"
+	}
+
+	if shared.IsTrue(d.expandedConfig["security.sev.policy.es"]) {
+		return "sev-es"
+	}
+
+	return "sev"
+}
+
+// confidentialFirmwares returns the single-image firmwares that can be used by the VM's confidential computing
+// technology. Returns nil if the VM uses the regular UEFI firmware with its NVRAM.
+func (d *qemu) confidentialFirmwares() []string {
+	switch d.confidentialType() {
+	case "sev-snp":
+		return vmSEVSNPFirmwares
+	case "tdx":
+		return vmTDXFirmwares
+	}
+
+	return nil
+}
+
+// confidentialStatePath returns the path of the file recording the confidential computing state of the VM.
+func (d *qemu) confidentialStatePath() string {
+	return filepath.Join(d.LogPath(), "confidential.json")
+}
+
+// recordConfidentialState records the confidential computing state of the VM so that it can be retrieved by
+// the guest owner for attestation. The SEV and SEV-ES launch measurement must be queried before the guest is
+// first started as QEMU doesn't provide it afterwards.
+func (d *qemu) recordConfidentialState(monitor *qmp.Monitor) error {
+	statePath := d.confidentialStatePath()
+
+	err := os.Remove(statePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	confidentialType := d.confidentialType()
+	if confidentialType == "" {
+		return nil
+	}
+
+	state := api.InstanceStateConfidential{
+		Type: confidentialType,
+	}
+
+	if confidentialType != "tdx" {
+		state.Policy = d.sevPolicy()
+	}
+
+	if confidentialType == "sev" || confidentialType == "sev-es" {
+		state.LaunchMeasurement, err = monitor.SEVLaunchMeasurement()
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(statePath, data, 0600)
+}
+
+// confidentialState returns the confidential computing state recorded when the VM was started.
+// Returns nil if the VM doesn't use confidential computing.
+func (d *qemu) confidentialState() (*api.InstanceStateConfidential, error) {
+	data, err := os.ReadFile(d.confidentialStatePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	state := &api.InstanceStateConfidential{}
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// setupTDX checks that the host supports Intel TDX guests.
+func (d *qemu) setupTDX() error {
+	if d.architecture != osarch.ARCH_64BIT_INTEL_X86 {
+		return errors.New("Intel TDX support is only available on x86_64 systems")
+	}
+
+	info := DriverStatuses()[instancetype.VM].Info
+	_, tdxFound := info.Features["tdx"]
+	if !tdxFound {
+		return errors.New("Intel TDX is not supported by the host")
+	}
+
+	return nil
 }
 
 // getAgentConnectionInfo returns the connection info the lxd-agent needs to connect to the LXD
@@ -2152,12 +2448,25 @@ func (d *qemu) deviceStart(dev device.Device, instanceRunning bool) (*deviceConf
 				}
 			}
 
+			if len(runConf.SerialDevice) > 0 {
+				err = d.deviceAttachSerial(runConf.SerialDevice)
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			// If running, run post start hooks now (if not running LXD will run them
 			// once the instance is started).
 			err = d.runHooks(runConf.PostHooks)
 			if err != nil {
 				return nil, err
 			}
+
+			// Hotplugging can create QEMU threads which aren't pinned yet.
+			err = d.repinEmulatorThreads()
+			if err != nil {
+				l.Warn("Failed pinning QEMU emulator threads", logger.Ctx{"err": err})
+			}
 		}
 	}
 
@@ -2176,6 +2485,22 @@ func (d *qemu) deviceAttachPath(deviceName string, configCopy map[string]string,
 		return fmt.Errorf("Virtiofsd isn't running")
 	}
 
+	// Check if the disk device has provided a DAX window size.
+	var virtiofsDAXSize string
+	for _, opt := range mount.Opts {
+		if strings.HasPrefix(opt, fmt.Sprintf("%s=", device.DiskVirtiofsDAXMountOpt)) {
+			parts := strings.SplitN(opt, "=", 2)
+			virtiofsDAXSize = parts[1]
+		}
+	}
+
+	if virtiofsDAXSize != "" {
+		err := d.checkVirtiofsDAX()
+		if err != nil {
+			return err
+		}
+	}
+
 	reverter := revert.New()
 	defer reverter.Fail()
 
@@ -2244,7 +2569,11 @@ func (d *qemu) deviceAttachPath(deviceName string, configCopy map[string]string,
 		pciDevID++
 	}
 
-	pciDeviceName := fmt.Sprintf("%s%d", busDevicePortPrefix, pciDevID)
+	pciDeviceName, err := d.hotplugPCIPort(monitor, fmt.Sprintf("%s%d", busDevicePortPrefix, pciDevID))
+	if err != nil {
+		return err
+	}
+
 	d.logger.Debug("Using PCI bus device to hotplug virtiofs into", logger.Ctx{"device": deviceName, "port": pciDeviceName})
 
 	qemuDev := map[string]string{
@@ -2256,6 +2585,10 @@ func (d *qemu) deviceAttachPath(deviceName string, configCopy map[string]string,
 		"id":      deviceID,
 	}
 
+	if virtiofsDAXSize != "" {
+		qemuDev["cache-size"] = virtiofsDAXSize
+	}
+
 	err = monitor.AddDevice(qemuDev)
 	if err != nil {
 		return fmt.Errorf("Failed to add the virtiofs device: %w", err)
@@ -2265,6 +2598,34 @@ func (d *qemu) deviceAttachPath(deviceName string, configCopy map[string]string,
 	return nil
 }
 
+// hotplugPCIPort returns the PCIe port to hotplug a device into.
+// The preferred port is used if it exists and is empty, otherwise the first empty port is used.
+func (d *qemu) hotplugPCIPort(monitor *qmp.Monitor, preferredPort string) (string, error) {
+	pciDevs, err := monitor.QueryPCI()
+	if err != nil {
+		return "", err
+	}
+
+	freePorts := []string{}
+	for _, pciDev := range pciDevs {
+		if !strings.HasPrefix(pciDev.DevID, busDevicePortPrefix) || len(pciDev.Bridge.Devices) > 0 {
+			continue
+		}
+
+		if pciDev.DevID == preferredPort {
+			return preferredPort, nil
+		}
+
+		freePorts = append(freePorts, pciDev.DevID)
+	}
+
+	if len(freePorts) == 0 {
+		return "", fmt.Errorf("No free PCIe port available to hotplug the device into")
+	}
+
+	return freePorts[0], nil
+}
+
 func (d *qemu) deviceAttachBlockDevice(deviceName string, configCopy map[string]string, mount deviceConfig.MountEntryItem) error {
 	// Check if the agent is running.
 	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
@@ -2459,6 +2820,14 @@ func (d *qemu) deviceStop(dev device.Device, instanceRunning bool, _ string) err
 			}
 		}
 
+		// Detach serial port from running instance.
+		if configCopy["type"] == "serial" {
+			err = d.deviceDetachSerial(dev.Name())
+			if err != nil {
+				return err
+			}
+		}
+
 		// Detach disk from running instance.
 		if configCopy["type"] == "disk" {
 			if configCopy["path"] != "" {
@@ -2527,6 +2896,15 @@ func (d *qemu) deviceDetachNIC(deviceName string) error {
 		return err
 	}
 
+	// vDPA backed NICs use a netdev named after the vDPA device.
+	vDPADevName := d.localConfig[fmt.Sprintf("volatile.%s.last_state.vdpa.name", deviceName)]
+	if vDPADevName != "" {
+		err = monitor.RemoveNIC(fmt.Sprintf("vhost-%s", vDPADevName))
+		if err != nil {
+			return err
+		}
+	}
+
 	_, qemuBus, err := d.qemuArchConfig(d.architecture)
 	if err != nil {
 		return err
@@ -3119,8 +3497,22 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 	}
 
 	// Allow disabling the UEFI firmware.
+	var confidentialFwPath string
 	if shared.ValueInSlice("-bios", rawOptions) || shared.ValueInSlice("-kernel", rawOptions) {
 		d.logger.Warn("Starting VM without default firmware (-bios or -kernel in raw.qemu)")
+	} else if d.confidentialFirmwares() != nil {
+		// SEV-SNP and TDX guests can't use pflash, so the firmware gets loaded as a single image instead.
+		firmwares := d.confidentialFirmwares()
+		for _, firmware := range firmwares {
+			confidentialFwPath = d.fwPath(firmware)
+			if confidentialFwPath != "" {
+				break
+			}
+		}
+
+		if confidentialFwPath == "" {
+			return "", nil, fmt.Errorf("Unable to locate matching firmware: %+v", firmwares)
+		}
 	} else if d.architectureSupportsUEFI(d.architecture) {
 		// Open the UEFI NVRAM file and pass it via file descriptor to QEMU.
 		// This is so the QEMU process can still read/write the file after it has dropped its user privs.
@@ -3294,11 +3686,21 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 		devBus, devAddr, multi = bus.allocate(busFunctionGroupNone)
 	}
 
-	scsiOpts := qemuDevOpts{
-		busName:       bus.name,
-		devBus:        devBus,
-		devAddr:       devAddr,
-		multifunction: multi,
+	// Add the I/O threads used by the SCSI controller and the virtio-blk disks.
+	iothreads, _ := strconv.Atoi(d.expandedConfig["limits.cpu.iothreads"])
+	cfg = append(cfg, qemuIOThreads(&qemuIOThreadsOpts{count: iothreads})...)
+
+	scsiOpts := qemuSCSIOpts{
+		dev: qemuDevOpts{
+			busName:       bus.name,
+			devBus:        devBus,
+			devAddr:       devAddr,
+			multifunction: multi,
+		},
+	}
+
+	if iothreads > 0 {
+		scsiOpts.iothread = fmt.Sprintf("%s0", qemuIOThreadPrefix)
 	}
 
 	cfg = append(cfg, qemuSCSI(&scsiOpts)...)
@@ -3327,17 +3729,31 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 		}
 
 		if sevOpts != nil {
-			for i := range cfg {
-				if cfg[i].name == "machine" {
-					cfg[i].entries = append(cfg[i].entries, cfgEntry{"memory-encryption", "sev0"})
-					break
-				}
-			}
+			cfg = append(cfg, qemuConfidentialMachine(&qemuConfidentialMachineOpts{
+				objectID:     "sev0",
+				firmwarePath: confidentialFwPath,
+			})...)
 
 			cfg = append(cfg, qemuSEV(sevOpts)...)
 		}
 	}
 
+	// If user has requested Intel TDX, check if supported and add to QEMU config.
+	if shared.IsTrue(d.expandedConfig["security.tdx"]) {
+		err = d.setupTDX()
+		if err != nil {
+			return "", nil, err
+		}
+
+		cfg = append(cfg, qemuConfidentialMachine(&qemuConfidentialMachineOpts{
+			objectID:     "tdx0",
+			firmwarePath: confidentialFwPath,
+			splitIRQChip: true,
+		})...)
+
+		cfg = append(cfg, qemuTDX()...)
+	}
+
 	// If virtiofsd is running for the config directory then export the config drive via virtio-fs.
 	// This is used by the lxd-agent in preference to 9p (due to its improved performance) and in scenarios
 	// where 9p isn't available in the VM guest OS.
@@ -3416,9 +3832,9 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 				}
 
 				qemuDev := make(map[string]string)
-				if busName == "nvme" {
+				if busName == "nvme" || busName == "virtio-blk" {
 					// Allocate a PCI(e) port and write it to the config file so QMP can "hotplug" the
-					// NVME drive into it later.
+					// NVME or virtio-blk drive into it later.
 					devBus, devAddr, multi := bus.allocate(busFunctionGroupNone)
 
 					// Populate the qemu device with port info.
@@ -3506,6 +3922,34 @@ func (d *qemu) generateQemuConfigFile(cpuInfo *cpuTopology, mountInfo *storagePo
 				return "", nil, err
 			}
 		}
+
+		// Add watchdog device.
+		if len(runConf.WatchdogDevice) > 0 {
+			err = d.addWatchdogDeviceConfig(&cfg, bus, runConf.WatchdogDevice)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+
+		// Add shared memory device.
+		if len(runConf.SHMDevice) > 0 {
+			err = d.addSHMDeviceConfig(&cfg, bus, runConf.SHMDevice)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+
+		// Add serial device.
+		if len(runConf.SerialDevice) > 0 {
+			monHook, err := d.addSerialDeviceConfig(&cfg, runConf.SerialDevice)
+			if err != nil {
+				return "", nil, err
+			}
+
+			if monHook != nil {
+				monHooks = append(monHooks, monHook)
+			}
+		}
 	}
 
 	// VM generation ID is only available on x86.
@@ -3561,7 +4005,7 @@ func (d *qemu) addCPUMemoryConfig(cfg *[]cfgSection, cpuInfo *cpuTopology) error
 	cpuPinning := false
 
 	hostNodes := []uint64{}
-	if cpuInfo.vcpus == nil {
+	if cpuInfo.vcpus == nil && cpuInfo.numa == nil {
 		// If not pinning, default to exposing cores.
 		// Only one CPU will be added here, as the others will be hotplugged during start.
 		if d.architectureSupportsCPUHotplug() {
@@ -3597,24 +4041,40 @@ func (d *qemu) addCPUMemoryConfig(cfg *[]cfgSection, cpuInfo *cpuTopology) error
 			}
 		}
 
-		// Prepare the NUMA map.
+		// Prepare the NUMA map, either from the explicit guest NUMA layout or replicating the host one.
 		numa := []qemuNumaEntry{}
 		numaIDs := []uint64{}
-		numaNode := uint64(0)
-		for hostNode, entry := range cpuInfo.nodes {
-			hostNodes = append(hostNodes, hostNode)
-
-			numaIDs = append(numaIDs, numaNode)
-			for _, vcpu := range entry {
-				numa = append(numa, qemuNumaEntry{
-					node:   numaNode,
-					socket: vcpuSocket[vcpu],
-					core:   vcpuCore[vcpu],
-					thread: vcpuThread[vcpu],
-				})
+		if cpuInfo.numa != nil {
+			for _, node := range cpuInfo.numa {
+				hostNodes = append(hostNodes, node.hostNode)
+
+				numaIDs = append(numaIDs, node.id)
+				for _, vcpu := range node.vcpus {
+					numa = append(numa, qemuNumaEntry{
+						node:   node.id,
+						socket: vcpuSocket[vcpu],
+						core:   vcpuCore[vcpu],
+						thread: vcpuThread[vcpu],
+					})
+				}
 			}
+		} else {
+			numaNode := uint64(0)
+			for hostNode, entry := range cpuInfo.nodes {
+				hostNodes = append(hostNodes, hostNode)
+
+				numaIDs = append(numaIDs, numaNode)
+				for _, vcpu := range entry {
+					numa = append(numa, qemuNumaEntry{
+						node:   numaNode,
+						socket: vcpuSocket[vcpu],
+						core:   vcpuCore[vcpu],
+						thread: vcpuThread[vcpu],
+					})
+				}
 
-			numaNode++
+				numaNode++
+			}
 		}
 
 		// Prepare context.
@@ -3648,11 +4108,32 @@ func (d *qemu) addCPUMemoryConfig(cfg *[]cfgSection, cpuInfo *cpuTopology) error
 		cpuOpts.hugepages = hugetlb
 	}
 
+	// Back the memory with a file when starting from a fork base or when the instance can be forked.
+	forkBaseID := d.localConfig["volatile.fork.restore"]
+	if forkBaseID != "" {
+		cpuOpts.memoryFile = filepath.Join(forkBasePath(forkBaseID), "memory")
+		cpuOpts.memoryFilePrivate = true
+	} else if shared.IsTrue(d.expandedConfig["migration.fork"]) {
+		cpuOpts.memoryFile = d.forkMemoryPath()
+	}
+
+	if cpuOpts.memoryFile != "" && len(hostNodes) > 1 {
+		return fmt.Errorf("Forking isn't supported with multiple NUMA nodes")
+	}
+
 	// Determine per-node memory limit.
 	memSizeMB := memSizeBytes / 1024 / 1024
 	nodeMemory := int64(memSizeMB / int64(len(hostNodes)))
 	cpuOpts.memory = nodeMemory
 
+	// Apply the per-node memory size and huge pages of the explicit guest NUMA layout.
+	if cpuInfo.numa != nil {
+		err = d.addGuestNumaMemoryConfig(&cpuOpts, cpuInfo.numa, memSizeBytes)
+		if err != nil {
+			return err
+		}
+	}
+
 	if cfg != nil {
 		*cfg = append(*cfg, qemuMemory(&qemuMemoryOpts{memSizeMB})...)
 		*cfg = append(*cfg, qemuCPU(&cpuOpts, cpuPinning)...)
@@ -3710,6 +4191,17 @@ func (d *qemu) addRootDriveConfig(qemuDev map[string]string, mountInfo *storageP
 	return d.addDriveConfig(qemuDev, bootIndexes, driveConf)
 }
 
+// checkVirtiofsDAX returns an error if QEMU doesn't support virtio-fs DAX windows.
+func (d *qemu) checkVirtiofsDAX() error {
+	info := DriverStatuses()[instancetype.VM].Info
+	_, found := info.Features["virtiofs_dax"]
+	if !found {
+		return fmt.Errorf("QEMU doesn't support virtio-fs DAX windows")
+	}
+
+	return nil
+}
+
 // addDriveDirConfig adds the qemu config required for adding a supplementary drive directory share.
 func (d *qemu) addDriveDirConfig(cfg *[]cfgSection, bus *qemuBus, fdFiles *[]*os.File, agentMounts *[]instancetype.VMAgentMount, driveConf deviceConfig.MountEntryItem) error {
 	mountTag := fmt.Sprintf("lxd_%s", driveConf.DevName)
@@ -3720,6 +4212,11 @@ func (d *qemu) addDriveDirConfig(cfg *[]cfgSection, bus *qemuBus, fdFiles *[]*os
 		FSType: driveConf.FSType,
 	}
 
+	// The disk device provides an empty dev path when the directory is only shared using virtio-fs.
+	if driveConf.DevPath == "" {
+		agentMount.FSType = "virtiofs"
+	}
+
 	// If mount type is 9p, we need to specify to use the virtio transport to support more VM guest OSes.
 	// Also set the msize to 32MB to allow for reasonably fast 9p access.
 	if agentMount.FSType == "9p" {
@@ -3732,20 +4229,36 @@ func (d *qemu) addDriveDirConfig(cfg *[]cfgSection, bus *qemuBus, fdFiles *[]*os
 	// readonly, it should *not* be used as a security measure, as the VM guest could remount it R/W.
 	if readonly {
 		agentMount.Options = append(agentMount.Options, "ro")
-	}
-
-	// Record the 9p mount for the agent.
-	*agentMounts = append(*agentMounts, agentMount)
+	}
 
-	// Check if the disk device has provided a virtiofsd socket path.
+	// Check if the disk device has provided a virtiofsd socket path and DAX window size.
 	var virtiofsdSockPath string
+	var virtiofsDAXSize string
 	for _, opt := range driveConf.Opts {
 		if strings.HasPrefix(opt, fmt.Sprintf("%s=", device.DiskVirtiofsdSockMountOpt)) {
 			parts := strings.SplitN(opt, "=", 2)
 			virtiofsdSockPath = parts[1]
 		}
+
+		if strings.HasPrefix(opt, fmt.Sprintf("%s=", device.DiskVirtiofsDAXMountOpt)) {
+			parts := strings.SplitN(opt, "=", 2)
+			virtiofsDAXSize = parts[1]
+		}
+	}
+
+	// Indicate to agent to map the files through the DAX window. It is ignored if falling back to 9p.
+	if virtiofsdSockPath != "" && virtiofsDAXSize != "" {
+		err := d.checkVirtiofsDAX()
+		if err != nil {
+			return err
+		}
+
+		agentMount.Options = append(agentMount.Options, "dax")
 	}
 
+	// Record the 9p mount for the agent.
+	*agentMounts = append(*agentMounts, agentMount)
+
 	// If there is a virtiofsd socket path setup the virtio-fs share.
 	if virtiofsdSockPath != "" {
 		if !shared.PathExists(virtiofsdSockPath) {
@@ -3762,14 +4275,24 @@ func (d *qemu) addDriveDirConfig(cfg *[]cfgSection, bus *qemuBus, fdFiles *[]*os
 				devAddr:       devAddr,
 				multifunction: multi,
 			},
-			devName:  driveConf.DevName,
-			mountTag: mountTag,
-			path:     virtiofsdSockPath,
-			protocol: "virtio-fs",
+			devName:   driveConf.DevName,
+			mountTag:  mountTag,
+			path:      virtiofsdSockPath,
+			protocol:  "virtio-fs",
+			cacheSize: virtiofsDAXSize,
 		}
 		*cfg = append(*cfg, qemuDriveDir(&driveDirVirtioOpts)...)
 	}
 
+	// Skip the 9p share if the directory is only shared using virtio-fs.
+	if driveConf.DevPath == "" {
+		if virtiofsdSockPath == "" {
+			return fmt.Errorf("No share available for drive %q", driveConf.DevName)
+		}
+
+		return nil
+	}
+
 	// Add 9p share config.
 	devBus, devAddr, multi := bus.allocate(busFunctionGroup9p)
 
@@ -4067,7 +4590,7 @@ func (d *qemu) addDriveConfig(qemuDev map[string]string, bootIndexes map[string]
 		} else if media == "cdrom" {
 			qemuDev["driver"] = "scsi-cd"
 		}
-	} else if bus == "nvme" {
+	} else if bus == "nvme" || bus == "virtio-blk" {
 		if qemuDev["bus"] == "" {
 			// Figure out a hotplug slot.
 			pciDevID := qemuPCIDeviceIDStart
@@ -4084,12 +4607,21 @@ func (d *qemu) addDriveConfig(qemuDev map[string]string, bootIndexes map[string]
 			}
 
 			pciDeviceName := fmt.Sprintf("%s%d", busDevicePortPrefix, pciDevID)
-			d.logger.Debug("Using PCI bus device to hotplug NVME into", logger.Ctx{"device": driveConf.DevName, "port": pciDeviceName})
+			d.logger.Debug("Using PCI bus device to hotplug drive into", logger.Ctx{"device": driveConf.DevName, "bus": bus, "port": pciDeviceName})
 			qemuDev["bus"] = pciDeviceName
 			qemuDev["addr"] = "00.0"
 		}
 
-		qemuDev["driver"] = "nvme"
+		if bus == "nvme" {
+			qemuDev["driver"] = "nvme"
+		} else {
+			qemuDev["driver"] = "virtio-blk-pci"
+
+			iothread := d.diskIOThread(driveConf.DevName)
+			if iothread != "" {
+				qemuDev["iothread"] = iothread
+			}
+		}
 	}
 
 	if bootIndexes != nil {
@@ -4143,7 +4675,7 @@ func (d *qemu) addDriveConfig(qemuDev map[string]string, bootIndexes map[string]
 		}
 
 		if driveConf.Limits != nil {
-			err = m.SetBlockThrottle(qemuDev["id"], int(driveConf.Limits.ReadBytes), int(driveConf.Limits.WriteBytes), int(driveConf.Limits.ReadIOps), int(driveConf.Limits.WriteIOps))
+			err = m.SetBlockThrottle(qemuDev["id"], qemuBlockThrottle(driveConf.Limits))
 			if err != nil {
 				return fmt.Errorf("Failed applying limits for disk device %q: %w", driveConf.DevName, err)
 			}
@@ -4715,6 +5247,224 @@ func (d *qemu) addTPMDeviceConfig(cfg *[]cfgSection, tpmConfig []deviceConfig.Ru
 	return nil
 }
 
+func (d *qemu) addWatchdogDeviceConfig(cfg *[]cfgSection, bus *qemuBus, watchdogConfig []deviceConfig.RunConfigItem) error {
+	var devName, model string
+
+	for _, watchdogItem := range watchdogConfig {
+		if watchdogItem.Key == "devName" {
+			devName = watchdogItem.Value
+		} else if watchdogItem.Key == "model" {
+			model = watchdogItem.Value
+		}
+	}
+
+	watchdogOpts := qemuWatchdogOpts{
+		devName: filesystem.PathNameEncode(devName),
+		model:   model,
+	}
+
+	if model == "i6300esb" {
+		devBus, devAddr, multi := bus.allocate(busFunctionGroupNone)
+		watchdogOpts.dev = qemuDevOpts{
+			busName:       bus.name,
+			devBus:        devBus,
+			devAddr:       devAddr,
+			multifunction: multi,
+		}
+	}
+
+	*cfg = append(*cfg, qemuWatchdog(&watchdogOpts)...)
+
+	return nil
+}
+
+func (d *qemu) addSHMDeviceConfig(cfg *[]cfgSection, bus *qemuBus, shmConfig []deviceConfig.RunConfigItem) error {
+	var devName, path, size string
+
+	for _, shmItem := range shmConfig {
+		switch shmItem.Key {
+		case "devName":
+			devName = shmItem.Value
+		case "path":
+			path = shmItem.Value
+		case "size":
+			size = shmItem.Value
+		}
+	}
+
+	sizeBytes, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid shared memory size %q: %w", size, err)
+	}
+
+	devBus, devAddr, multi := bus.allocate(busFunctionGroupNone)
+
+	shmOpts := qemuSHMOpts{
+		dev: qemuDevOpts{
+			busName:       bus.name,
+			devBus:        devBus,
+			devAddr:       devAddr,
+			multifunction: multi,
+		},
+		devName: filesystem.PathNameEncode(devName),
+		path:    path,
+		size:    sizeBytes,
+	}
+
+	*cfg = append(*cfg, qemuSHM(&shmOpts)...)
+
+	return nil
+}
+
+// watchdogAction returns the action QEMU takes when the watchdog of the instance fires.
+// Returns an empty string if the instance doesn't have a watchdog.
+func (d *qemu) watchdogAction() string {
+	for _, dev := range d.expandedDevices {
+		if dev["type"] != "watchdog" {
+			continue
+		}
+
+		action := dev["action"]
+		if action == "" {
+			action = "reset"
+		}
+
+		// A guest reset is already handled as a reboot, otherwise keep the guest paused until LXD restarts it.
+		if action != "reset" && d.expandedConfig["boot.recovery"] == "restart" {
+			action = "pause"
+		}
+
+		return action
+	}
+
+	return ""
+}
+
+// updateWatchdogAction applies the watchdog action to the running instance.
+func (d *qemu) updateWatchdogAction() error {
+	action := d.watchdogAction()
+	if action == "" {
+		return nil
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err // The VM isn't running as no monitor socket available.
+	}
+
+	return monitor.SetAction(map[string]string{"watchdog": action})
+}
+
+func (d *qemu) addSerialDeviceConfig(cfg *[]cfgSection, serialConfig []deviceConfig.RunConfigItem) (monitorHook, error) {
+	var devName, bus, portName, network, address, logPath string
+
+	for _, serialItem := range serialConfig {
+		switch serialItem.Key {
+		case "devName":
+			devName = serialItem.Value
+		case "bus":
+			bus = serialItem.Value
+		case "name":
+			portName = serialItem.Value
+		case "network":
+			network = serialItem.Value
+		case "address":
+			address = serialItem.Value
+		case "logPath":
+			logPath = serialItem.Value
+		}
+	}
+
+	escapedDeviceName := filesystem.PathNameEncode(devName)
+	chardevID := fmt.Sprintf("qemu_serial-chardev_%s", escapedDeviceName)
+
+	// ISA serial ports can't be hotplugged so they must be part of the config file.
+	if bus == "isa" {
+		serialOpts := qemuSerialPortOpts{
+			devName: escapedDeviceName,
+			logPath: logPath,
+		}
+
+		if network == "tcp" {
+			host, port, err := net.SplitHostPort(address)
+			if err != nil {
+				return nil, err
+			}
+
+			serialOpts.host = host
+			serialOpts.port = port
+		} else {
+			serialOpts.path = address
+		}
+
+		*cfg = append(*cfg, qemuSerialPort(&serialOpts)...)
+
+		return nil, nil
+	}
+
+	addr := map[string]any{
+		"type": "unix",
+		"data": map[string]any{
+			"path": address,
+		},
+	}
+
+	if network == "tcp" {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+
+		addr = map[string]any{
+			"type": "inet",
+			"data": map[string]any{
+				"host": host,
+				"port": port,
+			},
+		}
+	}
+
+	monHook := func(m *qmp.Monitor) error {
+		revert := revert.New()
+		defer revert.Fail()
+
+		err := m.AddCharDevice(map[string]any{
+			"id": chardevID,
+			"backend": map[string]any{
+				"type": "socket",
+				"data": map[string]any{
+					"addr":      addr,
+					"server":    true,
+					"wait":      false,
+					"logfile":   logPath,
+					"logappend": true,
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to add the character device: %w", err)
+		}
+
+		revert.Add(func() { _ = m.RemoveCharDevice(chardevID) })
+
+		err = m.AddDevice(map[string]string{
+			"id":      fmt.Sprintf("%s%s", qemuDeviceIDPrefix, escapedDeviceName),
+			"driver":  "virtserialport",
+			"bus":     "dev-qemu_serial.0",
+			"name":    portName,
+			"chardev": chardevID,
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to add the serial port: %w", err)
+		}
+
+		revert.Success()
+		return nil
+	}
+
+	return monHook, nil
+}
+
 func (d *qemu) addVmgenDeviceConfig(cfg *[]cfgSection, guid string) error {
 	vmgenIDOpts := qemuVmgenIDOpts{
 		guid: guid,
@@ -4827,7 +5577,7 @@ func (d *qemu) Stop(stateful bool) error {
 		}
 
 		// Wait for QEMU process to exit and perform device cleanup.
-		err = d.onStop("stop")
+		err = d.onStop("stop", false)
 		if err != nil {
 			op.Done(err)
 			return err
@@ -4938,6 +5688,9 @@ func (d *qemu) Unfreeze() error {
 		return err
 	}
 
+	// The fork base can't be reused once the instance runs again.
+	d.forkBaseRelease()
+
 	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceResumed.Event(d, nil))
 	return nil
 }
@@ -5639,6 +6392,10 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 			value := d.expandedConfig[key]
 
 			if key == "limits.cpu" {
+				if hasGuestNumaLayout(oldExpandedConfig) || hasGuestNumaLayout(d.expandedConfig) {
+					return fmt.Errorf("Cannot update key %q when using a guest NUMA layout and the VM is running", key)
+				}
+
 				oldValue := oldExpandedConfig["limits.cpu"]
 
 				if oldValue != "" {
@@ -5682,6 +6439,11 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 				if err != nil {
 					return err
 				}
+			} else if key == "boot.recovery" {
+				err = d.updateWatchdogAction()
+				if err != nil {
+					return fmt.Errorf("Failed updating watchdog action: %w", err)
+				}
 			}
 		}
 	}
@@ -7176,6 +7938,12 @@ func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
 		useStateConn = true
 	}
 
+	// Only receive the changed blocks of the root disk if requested by the source when refreshing a volume
+	// that is transferred using the generic block migration type.
+	if args.Refresh && offerHeader.GetBlockDiff() && respTypes[0].FSType == migration.MigrationFSType_BLOCK_AND_RSYNC {
+		respHeader.BlockDiff = proto.Bool(true)
+	}
+
 	// Send response to source.
 	d.logger.Debug("Sending migration response to source")
 	err = args.ControlSend(respHeader)
@@ -7290,6 +8058,7 @@ func (d *qemu) MigrateReceive(args instance.MigrateReceiveArgs) error {
 			Refresh:               args.Refresh,                // Indicate to receiver volume should exist.
 			TrackProgress:         true,                        // Use a progress tracker on receiver to get in-cluster progress information.
 			Live:                  false,                       // Indicates we won't get a final rootfs sync.
+			BlockDiff:             respHeader.GetBlockDiff(),   // Indicates we will only get the changed blocks.
 			VolumeSize:            offerHeader.GetVolumeSize(), // Block size setting override.
 			VolumeOnly:            !args.Snapshots,
 			ClusterMoveSourceName: args.ClusterMoveSourceName,
@@ -7496,7 +8265,7 @@ func (d *qemu) SetAffinity(set []string) error {
 		}
 	}
 
-	return nil
+	return d.pinEmulatorThreads(pids, set)
 }
 
 // FileSFTPConn returns a connection to the agent SFTP endpoint.
@@ -7861,6 +8630,11 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 			}
 		}
 
+		status.Confidential, err = d.confidentialState()
+		if err != nil {
+			d.logger.Warn("Failed getting confidential computing state", logger.Ctx{"err": err})
+		}
+
 		// Populate host_name for network devices.
 		for k, m := range d.ExpandedDevices() {
 			// We only care about nics.
@@ -7895,6 +8669,17 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 		d.logger.Warn("Error getting disk usage", logger.Ctx{"err": err})
 	}
 
+	if d.isRunningStatusCode(statusCode) {
+		if status.Disk == nil {
+			status.Disk = map[string]api.InstanceStateDisk{}
+		}
+
+		err = d.diskCountersState(status.Disk)
+		if err != nil {
+			d.logger.Warn("Error getting disk I/O counters", logger.Ctx{"err": err})
+		}
+	}
+
 	return status, nil
 }
 
@@ -7927,9 +8712,72 @@ func (d *qemu) diskState() (map[string]api.InstanceStateDisk, error) {
 		Total: usage.Total,
 	}
 
+	// Report the protocol used by the directory shares.
+	if d.IsRunning() {
+		for devName, dev := range d.expandedDevices {
+			if dev["type"] != "disk" || dev["path"] == "" || devName == rootDiskName {
+				continue
+			}
+
+			protocol := device.DiskVMShareProtocol(d.DevicesPath(), devName)
+			if protocol == "" {
+				continue
+			}
+
+			disk[devName] = api.InstanceStateDisk{Protocol: protocol}
+		}
+	}
+
 	return disk, nil
 }
 
+// diskCountersState adds the I/O counters of the disks attached to the VM as block devices to the disk state.
+func (d *qemu) diskCountersState(disk map[string]api.InstanceStateDisk) error {
+	// Connect to the monitor.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	stats, err := monitor.GetBlockStats()
+	if err != nil {
+		return err
+	}
+
+	// Index the stats by block node name, as used when adding the disks.
+	nodeStats := make(map[string]qmp.BlockStats, len(stats))
+	for _, stat := range stats {
+		if stat.NodeName != "" {
+			nodeStats[stat.NodeName] = stat
+		}
+	}
+
+	for devName, dev := range d.expandedDevices {
+		if dev["type"] != "disk" {
+			continue
+		}
+
+		stat, ok := nodeStats[d.blockNodeName(filesystem.PathNameEncode(devName))]
+		if !ok {
+			continue
+		}
+
+		state := disk[devName]
+		state.Counters = &api.InstanceStateDiskCounters{
+			BytesRead:       int64(stat.BytesRead),
+			BytesWritten:    int64(stat.BytesWritten),
+			ReadsCompleted:  int64(stat.ReadsCompleted),
+			WritesCompleted: int64(stat.WritesCompleted),
+			ReadTime:        int64(stat.ReadTotalTime) / int64(time.Millisecond),
+			WriteTime:       int64(stat.WriteTotalTime) / int64(time.Millisecond),
+		}
+
+		disk[devName] = state
+	}
+
+	return nil
+}
+
 // agentGetState connects to the agent inside of the VM and does
 // an API call to get the current state.
 func (d *qemu) agentGetState() (*api.InstanceState, error) {
@@ -8043,7 +8891,7 @@ func (d *qemu) DeviceEventHandler(runConf *deviceConfig.RunConfig) error {
 		devID := fmt.Sprintf("%s%s", qemuDeviceIDPrefix, filesystem.PathNameEncode(mount.DevName))
 
 		// Apply the limits.
-		err = m.SetBlockThrottle(devID, int(mount.Limits.ReadBytes), int(mount.Limits.WriteBytes), int(mount.Limits.ReadIOps), int(mount.Limits.WriteIOps))
+		err = m.SetBlockThrottle(devID, qemuBlockThrottle(mount.Limits))
 		if err != nil {
 			return fmt.Errorf("Failed applying limits for disk device %q: %w", mount.DevName, err)
 		}
@@ -8052,6 +8900,20 @@ func (d *qemu) DeviceEventHandler(runConf *deviceConfig.RunConfig) error {
 	return nil
 }
 
+// qemuBlockThrottle converts the disk limits into QEMU I/O throttling limits.
+func qemuBlockThrottle(limits *deviceConfig.DiskLimits) qmp.BlockThrottle {
+	return qmp.BlockThrottle{
+		BytesRead:     int(limits.ReadBytes),
+		BytesWrite:    int(limits.WriteBytes),
+		IOPsRead:      int(limits.ReadIOps),
+		IOPsWrite:     int(limits.WriteIOps),
+		BytesReadMax:  int(limits.ReadBytesBurst),
+		BytesWriteMax: int(limits.WriteBytesBurst),
+		IOPsReadMax:   int(limits.ReadIOpsBurst),
+		IOPsWriteMax:  int(limits.WriteIOpsBurst),
+	}
+}
+
 // reservedVsockID returns true if the given vsockID equals 0, 1 or 2.
 // Those are reserved and we cannot use them.
 func (d *qemu) reservedVsockID(vsockID uint32) bool {
@@ -8320,6 +9182,7 @@ type cpuTopology struct {
 	threads int
 	vcpus   map[uint64]uint64
 	nodes   map[uint64][]uint64
+	numa    []guestNumaNode
 }
 
 // cpuTopology takes the CPU limit and computes the QEMU CPU topology.
@@ -8339,6 +9202,11 @@ func (d *qemu) cpuTopology(limit string) (*cpuTopology, error) {
 		topology.cores = nrLimit
 		topology.threads = 1
 
+		topology.numa, err = d.guestNumaNodes(topology)
+		if err != nil {
+			return nil, err
+		}
+
 		return topology, nil
 	}
 
@@ -8464,6 +9332,11 @@ func (d *qemu) cpuTopology(limit string) (*cpuTopology, error) {
 	topology.vcpus = vcpus
 	topology.nodes = numaNodes
 
+	topology.numa, err = d.guestNumaNodes(topology)
+	if err != nil {
+		return nil, err
+	}
+
 	return topology, nil
 }
 
@@ -8696,8 +9569,14 @@ func (d *qemu) checkFeatures(hostArch int, qemuPath string) (map[string]any, err
 		features["cpu_hotplug"] = struct{}{}
 	}
 
-	// Check AMD SEV features (only for x86 architecture)
+	// Check AMD SEV and Intel TDX features (only for x86 architecture)
 	if hostArch == osarch.ARCH_64BIT_INTEL_X86 {
+		// Get the confidential guest types supported by QEMU.
+		confidentialGuestTypes, err := monitor.GetObjectTypes("confidential-guest-support")
+		if err != nil {
+			logger.Debug("Failed querying confidential guest types during VM feature check", logger.Ctx{"err": err})
+		}
+
 		cmdline, err := os.ReadFile("/proc/cmdline")
 		if err != nil {
 			return nil, err
@@ -8730,8 +9609,32 @@ func (d *qemu) checkFeatures(hostArch int, qemuPath string) (map[string]any, err
 				} else if strings.TrimSpace(string(sevES)) == "Y" {
 					features["sev-es"] = struct{}{}
 				}
+
+				// Check if the SEV-SNP extension is enabled and QEMU can create SEV-SNP guests.
+				sevSNP, err := os.ReadFile("/sys/module/kvm_amd/parameters/sev_snp")
+				if err != nil {
+					logger.Debug("Failed querying SEV-SNP capability during VM feature check", logger.Ctx{"err": err})
+				} else if strings.TrimSpace(string(sevSNP)) == "Y" && shared.ValueInSlice("sev-snp-guest", confidentialGuestTypes) {
+					features["sev-snp"] = struct{}{}
+				}
 			}
 		}
+
+		// Check if TDX is enabled on the host and QEMU can create TDX guests.
+		tdx, err := os.ReadFile("/sys/module/kvm_intel/parameters/tdx")
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		} else if strings.TrimSpace(string(tdx)) == "Y" && shared.ValueInSlice("tdx-guest", confidentialGuestTypes) {
+			features["tdx"] = struct{}{}
+		}
+	}
+
+	// Check virtio-fs DAX window support.
+	properties, err := monitor.GetDeviceProperties("vhost-user-fs-pci")
+	if err != nil {
+		logger.Debug("Failed querying virtio-fs device properties during VM feature check", logger.Ctx{"err": err})
+	} else if shared.ValueInSlice("cache-size", properties) {
+		features["virtiofs_dax"] = struct{}{}
 	}
 
 	// Check if vhost-net accelerator (for NIC CPU offloading) is available.
@@ -8896,6 +9799,56 @@ func (d *qemu) deviceDetachUSB(usbDev deviceConfig.USBDeviceItem) error {
 	return nil
 }
 
+func (d *qemu) deviceAttachSerial(serialConfig []deviceConfig.RunConfigItem) error {
+	// Check if the agent is running.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	monHook, err := d.addSerialDeviceConfig(nil, serialConfig)
+	if err != nil {
+		return err
+	}
+
+	return monHook(monitor)
+}
+
+func (d *qemu) deviceDetachSerial(deviceName string) error {
+	// Check if the agent is running.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	escapedDeviceName := filesystem.PathNameEncode(deviceName)
+	deviceID := fmt.Sprintf("%s%s", qemuDeviceIDPrefix, escapedDeviceName)
+	chardevID := fmt.Sprintf("qemu_serial-chardev_%s", escapedDeviceName)
+
+	err = monitor.RemoveDevice(deviceID)
+	if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+		return fmt.Errorf("Failed removing device: %w", err)
+	}
+
+	// The character device can only be removed once the guest released the serial port.
+	waitDuration := time.Duration(time.Second * time.Duration(10))
+	waitUntil := time.Now().Add(waitDuration)
+	for {
+		err = monitor.RemoveCharDevice(chardevID)
+		if err == nil {
+			break
+		}
+
+		if time.Now().After(waitUntil) {
+			return fmt.Errorf("Failed to detach serial device after %v: %w", waitDuration, err)
+		}
+
+		time.Sleep(time.Second)
+	}
+
+	return nil
+}
+
 // Block node names may only be up to 31 characters long, so use a hash if longer.
 func (d *qemu) blockNodeName(name string) string {
 	if len(name) > 27 {
@@ -198,10 +198,10 @@ func TestQemuConfigTemplates(t *testing.T) {
 
 	t.Run("qemu_scsi", func(t *testing.T) {
 		testCases := []struct {
-			opts     qemuDevOpts
+			opts     qemuSCSIOpts
 			expected string
 		}{{
-			qemuDevOpts{"pci", "qemu_pcie1", "00.0", false},
+			qemuSCSIOpts{dev: qemuDevOpts{"pci", "qemu_pcie1", "00.0", false}},
 			`# SCSI controller
 			[device "qemu_scsi"]
 			driver = "virtio-scsi-pci"
@@ -209,18 +209,49 @@ func TestQemuConfigTemplates(t *testing.T) {
 			addr = "00.0"
 			`,
 		}, {
-			qemuDevOpts{"ccw", "devBus", "busAddr", true},
+			qemuSCSIOpts{dev: qemuDevOpts{"ccw", "devBus", "busAddr", true}},
 			`# SCSI controller
 			[device "qemu_scsi"]
 			driver = "virtio-scsi-ccw"
 			multifunction = "on"
 			`,
+		}, {
+			qemuSCSIOpts{dev: qemuDevOpts{"pci", "qemu_pcie1", "00.0", false}, iothread: "iothread0"},
+			`# SCSI controller
+			[device "qemu_scsi"]
+			driver = "virtio-scsi-pci"
+			bus = "qemu_pcie1"
+			addr = "00.0"
+			iothread = "iothread0"
+			`,
 		}}
 		for _, tc := range testCases {
 			runTest(tc.expected, qemuSCSI(&tc.opts))
 		}
 	})
 
+	t.Run("qemu_iothreads", func(t *testing.T) {
+		testCases := []struct {
+			opts     qemuIOThreadsOpts
+			expected string
+		}{{
+			qemuIOThreadsOpts{count: 0},
+			``,
+		}, {
+			qemuIOThreadsOpts{count: 2},
+			`# I/O threads
+			[object "iothread0"]
+			qom-type = "iothread"
+
+			[object "iothread1"]
+			qom-type = "iothread"
+			`,
+		}}
+		for _, tc := range testCases {
+			runTest(tc.expected, qemuIOThreads(&tc.opts))
+		}
+	})
+
 	t.Run("qemu_balloon", func(t *testing.T) {
 		testCases := []struct {
 			opts     qemuDevOpts
@@ -438,6 +469,69 @@ func TestQemuConfigTemplates(t *testing.T) {
 			size = "7629M"
 			share = "on"
 
+			[numa]
+			type = "node"
+			nodeid = "0"
+			memdev = "mem0"`,
+		}, {
+			qemuCPUOpts{
+				architecture:        "x86_64",
+				cpuCount:            2,
+				cpuSockets:          1,
+				cpuCores:            2,
+				cpuThreads:          1,
+				cpuNumaNodes:        []uint64{},
+				cpuNumaMapping:      []qemuNumaEntry{},
+				cpuNumaHostNodes:    []uint64{},
+				memoryFile:          "/forks/instance.memory",
+				memory:              1024,
+				qemuMemObjectFormat: "indexed",
+			},
+			`# CPU
+			[smp-opts]
+			cpus = "2"
+			sockets = "1"
+			cores = "2"
+			threads = "1"
+
+			[object "mem0"]
+			qom-type = "memory-backend-file"
+			mem-path = "/forks/instance.memory"
+			size = "1024M"
+			share = "on"
+
+			[numa]
+			type = "node"
+			nodeid = "0"
+			memdev = "mem0"`,
+		}, {
+			qemuCPUOpts{
+				architecture:        "x86_64",
+				cpuCount:            2,
+				cpuSockets:          1,
+				cpuCores:            2,
+				cpuThreads:          1,
+				cpuNumaNodes:        []uint64{},
+				cpuNumaMapping:      []qemuNumaEntry{},
+				cpuNumaHostNodes:    []uint64{},
+				memoryFile:          "/forks/base/memory",
+				memoryFilePrivate:   true,
+				memory:              1024,
+				qemuMemObjectFormat: "indexed",
+			},
+			`# CPU
+			[smp-opts]
+			cpus = "2"
+			sockets = "1"
+			cores = "2"
+			threads = "1"
+
+			[object "mem0"]
+			qom-type = "memory-backend-file"
+			mem-path = "/forks/base/memory"
+			size = "1024M"
+			share = "off"
+
 			[numa]
 			type = "node"
 			nodeid = "0"
@@ -648,6 +742,70 @@ func TestQemuConfigTemplates(t *testing.T) {
 			socket-id = "21"
 			core-id = "22"
 			thread-id = "23"`,
+		}, {
+			qemuCPUOpts{
+				architecture: "x86_64",
+				cpuCount:     4,
+				cpuSockets:   1,
+				cpuCores:     4,
+				cpuThreads:   1,
+				cpuNumaNodes: []uint64{0, 1},
+				cpuNumaMapping: []qemuNumaEntry{
+					{node: 0, socket: 0, core: 0, thread: 0},
+					{node: 1, socket: 0, core: 1, thread: 0},
+				},
+				cpuNumaHostNodes:    []uint64{1, 0},
+				cpuNumaMemory:       []int64{1024, 3072},
+				cpuNumaHugepages:    []string{"/hugepages1G", ""},
+				memory:              2048,
+				qemuMemObjectFormat: "indexed",
+			},
+			`# CPU
+			[smp-opts]
+			cpus = "4"
+			sockets = "1"
+			cores = "4"
+			threads = "1"
+
+			[object "mem0"]
+			qom-type = "memory-backend-file"
+			mem-path = "/hugepages1G"
+			prealloc = "on"
+			discard-data = "on"
+			size = "1024M"
+			policy = "bind"
+			share = "on"
+			host-nodes.0 = "1"
+
+			[numa]
+			type = "node"
+			nodeid = "0"
+			memdev = "mem0"
+
+			[object "mem1"]
+			qom-type = "memory-backend-memfd"
+			size = "3072M"
+			policy = "bind"
+			host-nodes.0 = "0"
+
+			[numa]
+			type = "node"
+			nodeid = "1"
+			memdev = "mem1"
+
+			[numa]
+			type = "cpu"
+			node-id = "0"
+			socket-id = "0"
+			core-id = "0"
+			thread-id = "0"
+
+			[numa]
+			type = "cpu"
+			node-id = "1"
+			socket-id = "0"
+			core-id = "1"
+			thread-id = "0"`,
 		}, {
 			qemuCPUOpts{
 				architecture: "arm64",
@@ -875,6 +1033,27 @@ func TestQemuConfigTemplates(t *testing.T) {
 			addr = "10.2"
 			tag = "vtag"
 			chardev = "lxd_vfs"`,
+		}, {
+			qemuDriveDirOpts{
+				dev:       qemuDevOpts{"pcie", "qemu_pcie2", "00.0", false},
+				path:      "/dev/dax",
+				devName:   "dax",
+				mountTag:  "dtag",
+				protocol:  "virtio-fs",
+				cacheSize: "1073741824",
+			},
+			`# dax drive (virtio-fs)
+			[chardev "lxd_dax"]
+			backend = "socket"
+			path = "/dev/dax"
+
+			[device "dev-lxd_dax-virtio-fs"]
+			driver = "vhost-user-fs-pci"
+			bus = "qemu_pcie2"
+			addr = "00.0"
+			tag = "dtag"
+			chardev = "lxd_dax"
+			cache-size = "1073741824"`,
 		}, {
 			qemuDriveDirOpts{
 				dev:      qemuDevOpts{"ccw", "devBus", "busAddr", true},
@@ -1081,6 +1260,176 @@ func TestQemuConfigTemplates(t *testing.T) {
 		}
 	})
 
+	t.Run("qemu_serial_port", func(t *testing.T) {
+		testCases := []struct {
+			opts     qemuSerialPortOpts
+			expected string
+		}{{
+			qemuSerialPortOpts{
+				devName: "mySerial",
+				path:    "/var/log/lxd/vm/serial.mySerial.sock",
+				logPath: "/var/log/lxd/vm/serial.mySerial.log",
+			},
+			`[chardev "qemu_serial-chardev_mySerial"]
+			backend = "socket"
+			path = "/var/log/lxd/vm/serial.mySerial.sock"
+			server = "on"
+			wait = "off"
+			logfile = "/var/log/lxd/vm/serial.mySerial.log"
+			logappend = "on"
+
+			[device "dev-lxd_mySerial"]
+			driver = "isa-serial"
+			chardev = "qemu_serial-chardev_mySerial"`,
+		}, {
+			qemuSerialPortOpts{
+				devName: "mySerial",
+				host:    "127.0.0.1",
+				port:    "4000",
+				logPath: "/var/log/lxd/vm/serial.mySerial.log",
+			},
+			`[chardev "qemu_serial-chardev_mySerial"]
+			backend = "socket"
+			host = "127.0.0.1"
+			port = "4000"
+			server = "on"
+			wait = "off"
+			logfile = "/var/log/lxd/vm/serial.mySerial.log"
+			logappend = "on"
+
+			[device "dev-lxd_mySerial"]
+			driver = "isa-serial"
+			chardev = "qemu_serial-chardev_mySerial"`,
+		}}
+		for _, tc := range testCases {
+			runTest(tc.expected, qemuSerialPort(&tc.opts))
+		}
+	})
+
+	t.Run("qemu_sev", func(t *testing.T) {
+		testCases := []struct {
+			opts     qemuSevOpts
+			expected string
+		}{{
+			qemuSevOpts{cbitpos: 51, reducedPhysBits: 1, policy: "0x5"},
+			`# Secure Encrypted Virtualization
+			[object "sev0"]
+			qom-type = "sev-guest"
+			cbitpos = "51"
+			reduced-phys-bits = "1"
+			policy = "0x5"
+			`,
+		}, {
+			qemuSevOpts{cbitpos: 51, reducedPhysBits: 1, policy: "0x30000", dhCertFD: "/dev/fdset/1", sessionDataFD: "/dev/fdset/2", snp: true},
+			`# Secure Encrypted Virtualization
+			[object "sev0"]
+			qom-type = "sev-snp-guest"
+			cbitpos = "51"
+			reduced-phys-bits = "1"
+			policy = "0x30000"
+			`,
+		}}
+		for _, tc := range testCases {
+			runTest(tc.expected, qemuSEV(&tc.opts))
+		}
+	})
+
+	t.Run("qemu_tdx", func(t *testing.T) {
+		runTest(`# Trust Domain Extensions
+		[object "tdx0"]
+		qom-type = "tdx-guest"
+		`, qemuTDX())
+	})
+
+	t.Run("qemu_confidential_machine", func(t *testing.T) {
+		testCases := []struct {
+			opts     qemuConfidentialMachineOpts
+			expected string
+		}{{
+			qemuConfidentialMachineOpts{objectID: "sev0"},
+			`# Confidential computing
+			[machine]
+			confidential-guest-support = "sev0"
+			`,
+		}, {
+			qemuConfidentialMachineOpts{objectID: "sev0", firmwarePath: "/usr/share/OVMF/OVMF.amdsev.fd"},
+			`# Confidential computing
+			[machine]
+			confidential-guest-support = "sev0"
+			firmware = "/usr/share/OVMF/OVMF.amdsev.fd"
+			`,
+		}, {
+			qemuConfidentialMachineOpts{objectID: "tdx0", firmwarePath: "/usr/share/OVMF/OVMF.inteltdx.fd", splitIRQChip: true},
+			`# Confidential computing
+			[machine]
+			confidential-guest-support = "tdx0"
+			kernel-irqchip = "split"
+			firmware = "/usr/share/OVMF/OVMF.inteltdx.fd"
+			`,
+		}}
+		for _, tc := range testCases {
+			runTest(tc.expected, qemuConfidentialMachine(&tc.opts))
+		}
+	})
+
+	t.Run("qemu_watchdog", func(t *testing.T) {
+		testCases := []struct {
+			opts     qemuWatchdogOpts
+			expected string
+		}{{
+			qemuWatchdogOpts{
+				dev:     qemuDevOpts{"pcie", "qemu_pcie1", "00.0", false},
+				devName: "myWatchdog",
+				model:   "i6300esb",
+			},
+			`# Watchdog
+			[device "dev-lxd_myWatchdog"]
+			driver = "i6300esb"
+			bus = "qemu_pcie1"
+			addr = "00.0"`,
+		}, {
+			qemuWatchdogOpts{
+				devName: "myWatchdog",
+				model:   "ib700",
+			},
+			`# Watchdog
+			[device "dev-lxd_myWatchdog"]
+			driver = "ib700"`,
+		}}
+		for _, tc := range testCases {
+			runTest(tc.expected, qemuWatchdog(&tc.opts))
+		}
+	})
+
+	t.Run("qemu_shm", func(t *testing.T) {
+		testCases := []struct {
+			opts     qemuSHMOpts
+			expected string
+		}{{
+			qemuSHMOpts{
+				dev:     qemuDevOpts{"pcie", "qemu_pcie2", "00.0", false},
+				devName: "myShm",
+				path:    "/dev/shm/lxd-shm/ring/memory",
+				size:    16777216,
+			},
+			`# Shared memory
+			[object "qemu_shm-mem_myShm"]
+			qom-type = "memory-backend-file"
+			mem-path = "/dev/shm/lxd-shm/ring/memory"
+			size = "16777216"
+			share = "on"
+
+			[device "dev-lxd_myShm"]
+			driver = "ivshmem-plain"
+			bus = "qemu_pcie2"
+			addr = "00.0"
+			memdev = "qemu_shm-mem_myShm"`,
+		}}
+		for _, tc := range testCases {
+			runTest(tc.expected, qemuSHM(&tc.opts))
+		}
+	})
+
 	t.Run("qemu_raw_cfg_override", func(t *testing.T) {
 		cfg := []cfgSection{{
 			name: "global",
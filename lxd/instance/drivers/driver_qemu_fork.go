@@ -0,0 +1,327 @@
+package drivers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/uuid"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/instance/drivers/qmp"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+)
+
+// forkMu serializes the creation of fork bases.
+var forkMu sync.Mutex
+
+// forksPath returns the tmpfs directory holding the memory of forkable VMs and their fork bases.
+func forksPath() string {
+	return shared.VarPath("virtual-machines-forks")
+}
+
+// forkBasePath returns the directory of the fork base with the given ID.
+// A fork base holds the device state (state) and a copy of the guest memory (memory) of the forked VM.
+func forkBasePath(baseID string) string {
+	return filepath.Join(forksPath(), baseID)
+}
+
+// forkMemoryPath returns the path of the file backing the guest memory when migration.fork is enabled.
+func (d *qemu) forkMemoryPath() string {
+	return filepath.Join(forksPath(), project.Instance(d.project.Name, d.name)+".memory")
+}
+
+// ForkBase pauses the VM and captures its state into a fork base that clones can be started from.
+// The fork base is reused for as long as the VM remains paused. Returns the fork base ID.
+func (d *qemu) ForkBase() (string, error) {
+	forkMu.Lock()
+	defer forkMu.Unlock()
+
+	if shared.IsFalseOrEmpty(d.expandedConfig["migration.fork"]) {
+		return "", fmt.Errorf("Forking requires migration.fork to be set to true")
+	}
+
+	if !d.IsRunning() {
+		return "", fmt.Errorf("Forking requires the instance to be running")
+	}
+
+	if !shared.PathExists(d.forkMemoryPath()) {
+		return "", fmt.Errorf("The instance must be restarted with migration.fork enabled before it can be forked")
+	}
+
+	// Reuse the existing fork base if the instance hasn't been resumed since it was created.
+	oldBaseID := d.localConfig["volatile.fork.base"]
+	wasFrozen := d.IsFrozen()
+	if oldBaseID != "" && wasFrozen && shared.PathExists(forkBasePath(oldBaseID)) {
+		return oldBaseID, nil
+	}
+
+	d.logger.Debug("Fork base creation started")
+	defer d.logger.Debug("Fork base creation finished")
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return "", err
+	}
+
+	err = monitor.Pause()
+	if err != nil {
+		return "", fmt.Errorf("Failed pausing instance: %w", err)
+	}
+
+	if !wasFrozen {
+		revert.Add(func() { _ = monitor.Start() })
+	}
+
+	baseID := uuid.New().String()
+	basePath := forkBasePath(baseID)
+
+	err = os.Mkdir(basePath, 0700)
+	if err != nil {
+		return "", err
+	}
+
+	revert.Add(func() { _ = os.RemoveAll(basePath) })
+
+	// The guest memory isn't part of the migration stream, it's copied from its backing file instead.
+	err = monitor.MigrateSetCapabilities(map[string]bool{"x-ignore-shared": true})
+	if err != nil {
+		return "", fmt.Errorf("Failed setting migration capabilities: %w", err)
+	}
+
+	// Make sure a later stateful stop or snapshot includes the guest memory again.
+	defer func() { _ = monitor.MigrateSetCapabilities(map[string]bool{"x-ignore-shared": false}) }()
+
+	err = d.forkSaveState(monitor, filepath.Join(basePath, "state"))
+	if err != nil {
+		return "", err
+	}
+
+	// The copy keeps the fork base intact when the instance is later resumed.
+	err = forkCopyMemory(d.forkMemoryPath(), filepath.Join(basePath, "memory"))
+	if err != nil {
+		return "", fmt.Errorf("Failed copying instance memory: %w", err)
+	}
+
+	err = d.VolatileSet(map[string]string{"volatile.fork.base": baseID})
+	if err != nil {
+		return "", err
+	}
+
+	// Clones already started from a previous fork base keep their own mapping of its memory.
+	if oldBaseID != "" {
+		_ = os.RemoveAll(forkBasePath(oldBaseID))
+	}
+
+	revert.Success()
+	return baseID, nil
+}
+
+// forkSaveState writes the device state of the VM to the fork base state file.
+func (d *qemu) forkSaveState(monitor *qmp.Monitor, statePath string) error {
+	stateFile, err := os.OpenFile(statePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stateFile.Close() }()
+
+	err = d.saveStateHandle(monitor, stateFile)
+	if err != nil {
+		return fmt.Errorf("Failed initializing state save to %q: %w", statePath, err)
+	}
+
+	err = monitor.MigrateWait("completed")
+	if err != nil {
+		return fmt.Errorf("Failed saving state to %q: %w", statePath, err)
+	}
+
+	return stateFile.Close()
+}
+
+// forkRestoreState loads the device state of a fork base into the VM.
+// The guest memory is already mapped copy-on-write from the fork base memory file.
+func (d *qemu) forkRestoreState(monitor *qmp.Monitor, baseID string) error {
+	statePath := filepath.Join(forkBasePath(baseID), "state")
+	d.logger.Debug("Fork restore starting", logger.Ctx{"source": statePath})
+	defer d.logger.Debug("Fork restore finished", logger.Ctx{"source": statePath})
+
+	stateFile, err := os.Open(statePath)
+	if err != nil {
+		return fmt.Errorf("Failed opening fork state file %q: %w", statePath, err)
+	}
+
+	defer func() { _ = stateFile.Close() }()
+
+	err = monitor.MigrateSetCapabilities(map[string]bool{"x-ignore-shared": true})
+	if err != nil {
+		return fmt.Errorf("Failed setting migration capabilities: %w", err)
+	}
+
+	err = d.restoreStateHandle(context.Background(), monitor, stateFile)
+	if err != nil {
+		return fmt.Errorf("Failed restoring fork state from %q: %w", statePath, err)
+	}
+
+	return monitor.MigrateSetCapabilities(map[string]bool{"x-ignore-shared": false})
+}
+
+// forkRestoreFinish completes the start of a VM from a fork base once the guest is running.
+func (d *qemu) forkRestoreFinish() error {
+	err := d.VolatileSet(map[string]string{"volatile.fork.restore": ""})
+	if err != nil {
+		return err
+	}
+
+	// The restored NICs still carry the MAC addresses of the forked instance, so replug them to expose the
+	// MAC addresses of this instance to the guest.
+	for _, entry := range d.expandedDevices.Sorted() {
+		if entry.Config["type"] != "nic" {
+			continue
+		}
+
+		dev, err := d.deviceLoad(d, entry.Name, entry.Config)
+		if err != nil || !dev.CanHotPlug() {
+			d.logger.Warn("Skipping NIC replug after fork", logger.Ctx{"device": entry.Name, "err": err})
+			continue
+		}
+
+		err = d.deviceStop(dev, true, "")
+		if err != nil {
+			d.logger.Warn("Failed unplugging NIC after fork", logger.Ctx{"device": entry.Name, "err": err})
+			continue
+		}
+
+		_, err = d.deviceStart(dev, true)
+		if err != nil {
+			d.logger.Warn("Failed plugging NIC after fork", logger.Ctx{"device": entry.Name, "err": err})
+		}
+	}
+
+	return nil
+}
+
+// forkBaseRelease removes the fork base of the VM once it's resumed or stopped.
+// Running clones keep their mapping of the fork base memory until they stop.
+func (d *qemu) forkBaseRelease() {
+	baseID := d.localConfig["volatile.fork.base"]
+	if baseID == "" {
+		return
+	}
+
+	_ = os.RemoveAll(forkBasePath(baseID))
+
+	err := d.VolatileSet(map[string]string{"volatile.fork.base": ""})
+	if err != nil {
+		d.logger.Warn("Failed clearing fork base", logger.Ctx{"err": err})
+	}
+}
+
+// forkPrepareFiles sets the permissions of the files backing the guest memory before QEMU is started.
+// The memory file of a forkable VM is only accessible by root. The fork base a clone is started from is made
+// readable, but not writable, by the unprivileged QEMU group so that clones don't have to run as root.
+func (d *qemu) forkPrepareFiles(forkBaseID string) error {
+	if forkBaseID == "" {
+		if shared.IsFalseOrEmpty(d.expandedConfig["migration.fork"]) {
+			return nil
+		}
+
+		// QEMU would otherwise create the file world-readable.
+		f, err := os.OpenFile(d.forkMemoryPath(), os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+
+		_ = f.Close()
+
+		return os.Chmod(d.forkMemoryPath(), 0600)
+	}
+
+	if d.state.OS.UnprivUser == "" {
+		return nil
+	}
+
+	basePath := forkBasePath(forkBaseID)
+	modes := map[string]os.FileMode{
+		basePath:                          0750,
+		filepath.Join(basePath, "state"):  0640,
+		filepath.Join(basePath, "memory"): 0640,
+	}
+
+	for path, mode := range modes {
+		err := os.Chown(path, 0, int(d.state.OS.UnprivGID))
+		if err != nil {
+			return err
+		}
+
+		err = os.Chmod(path, mode)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// forkCopyMemory copies a guest memory file, skipping the holes left by memory the guest never used.
+func forkCopyMemory(srcPath string, dstPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = src.Close() }()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = dst.Close() }()
+
+	err = dst.Truncate(info.Size())
+	if err != nil {
+		return err
+	}
+
+	offset := int64(0)
+	for offset < info.Size() {
+		dataStart, err := unix.Seek(int(src.Fd()), offset, unix.SEEK_DATA)
+		if err != nil {
+			if errors.Is(err, unix.ENXIO) {
+				break // No more data until the end of the file.
+			}
+
+			return err
+		}
+
+		dataEnd, err := unix.Seek(int(src.Fd()), dataStart, unix.SEEK_HOLE)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(io.NewOffsetWriter(dst, dataStart), io.NewSectionReader(src, dataStart, dataEnd-dataStart))
+		if err != nil {
+			return err
+		}
+
+		offset = dataEnd
+	}
+
+	return dst.Close()
+}
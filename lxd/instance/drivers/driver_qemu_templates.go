@@ -262,17 +262,49 @@ func qemuPCIe(opts *qemuPCIeOpts) []cfgSection {
 	}}
 }
 
-func qemuSCSI(opts *qemuDevOpts) []cfgSection {
+type qemuIOThreadsOpts struct {
+	count int
+}
+
+func qemuIOThreads(opts *qemuIOThreadsOpts) []cfgSection {
+	sections := []cfgSection{}
+	for i := 0; i < opts.count; i++ {
+		section := cfgSection{
+			name:    fmt.Sprintf(`object "%s%d"`, qemuIOThreadPrefix, i),
+			entries: []cfgEntry{{key: "qom-type", value: "iothread"}},
+		}
+
+		if i == 0 {
+			section.comment = "I/O threads"
+		}
+
+		sections = append(sections, section)
+	}
+
+	return sections
+}
+
+type qemuSCSIOpts struct {
+	dev      qemuDevOpts
+	iothread string
+}
+
+func qemuSCSI(opts *qemuSCSIOpts) []cfgSection {
 	entriesOpts := qemuDevEntriesOpts{
-		dev:     *opts,
+		dev:     opts.dev,
 		pciName: "virtio-scsi-pci",
 		ccwName: "virtio-scsi-ccw",
 	}
 
+	entries := qemuDeviceEntries(&entriesOpts)
+	if opts.iothread != "" {
+		entries = append(entries, cfgEntry{key: "iothread", value: opts.iothread})
+	}
+
 	return []cfgSection{{
 		name:    `device "qemu_scsi"`,
 		comment: "SCSI controller",
-		entries: qemuDeviceEntries(&entriesOpts),
+		entries: entries,
 	}}
 }
 
@@ -317,17 +349,23 @@ type qemuSevOpts struct {
 	policy          string
 	dhCertFD        string
 	sessionDataFD   string
+	snp             bool
 }
 
 func qemuSEV(opts *qemuSevOpts) []cfgSection {
+	qomType := "sev-guest"
+	if opts.snp {
+		qomType = "sev-snp-guest"
+	}
+
 	entries := []cfgEntry{
-		{key: "qom-type", value: "sev-guest"},
+		{key: "qom-type", value: qomType},
 		{key: "cbitpos", value: fmt.Sprintf("%d", opts.cbitpos)},
 		{key: "reduced-phys-bits", value: fmt.Sprintf("%d", opts.reducedPhysBits)},
 		{key: "policy", value: opts.policy},
 	}
 
-	if opts.dhCertFD != "" && opts.sessionDataFD != "" {
+	if !opts.snp && opts.dhCertFD != "" && opts.sessionDataFD != "" {
 		entries = append(entries, cfgEntry{key: "dh-cert-file", value: opts.dhCertFD}, cfgEntry{key: "session-file", value: opts.sessionDataFD})
 	}
 
@@ -338,6 +376,44 @@ func qemuSEV(opts *qemuSevOpts) []cfgSection {
 	}}
 }
 
+type qemuConfidentialMachineOpts struct {
+	objectID     string
+	firmwarePath string
+	splitIRQChip bool
+}
+
+func qemuConfidentialMachine(opts *qemuConfidentialMachineOpts) []cfgSection {
+	// QEMU merges this section into the main machine section.
+	entries := []cfgEntry{
+		{key: "confidential-guest-support", value: opts.objectID},
+	}
+
+	if opts.splitIRQChip {
+		entries = append(entries, cfgEntry{key: "kernel-irqchip", value: "split"})
+	}
+
+	// Equivalent to -bios, used for firmwares which can't be loaded through pflash.
+	if opts.firmwarePath != "" {
+		entries = append(entries, cfgEntry{key: "firmware", value: opts.firmwarePath})
+	}
+
+	return []cfgSection{{
+		name:    "machine",
+		comment: "Confidential computing",
+		entries: entries,
+	}}
+}
+
+func qemuTDX() []cfgSection {
+	return []cfgSection{{
+		name:    `object "tdx0"`,
+		comment: "Trust Domain Extensions",
+		entries: []cfgEntry{
+			{key: "qom-type", value: "tdx-guest"},
+		},
+	}}
+}
+
 type qemuVsockOpts struct {
 	dev     qemuDevOpts
 	vsockFD int
@@ -432,7 +508,11 @@ type qemuCPUOpts struct {
 	cpuNumaNodes        []uint64
 	cpuNumaMapping      []qemuNumaEntry
 	cpuNumaHostNodes    []uint64
+	cpuNumaMemory       []int64  // Per node memory in MB, overrides memory when set.
+	cpuNumaHugepages    []string // Per node hugetlbfs path, overrides hugepages when set.
 	hugepages           string
+	memoryFile          string
+	memoryFilePrivate   bool
 	memory              int64
 	qemuMemObjectFormat string
 }
@@ -440,18 +520,33 @@ type qemuCPUOpts struct {
 func qemuCPUNumaHostNode(opts *qemuCPUOpts, index int) []cfgSection {
 	entries := []cfgEntry{}
 
-	if opts.hugepages != "" {
+	hugepages := opts.hugepages
+	if index < len(opts.cpuNumaHugepages) && opts.cpuNumaHugepages[index] != "" {
+		hugepages = opts.cpuNumaHugepages[index]
+	}
+
+	memory := opts.memory
+	if index < len(opts.cpuNumaMemory) {
+		memory = opts.cpuNumaMemory[index]
+	}
+
+	if hugepages != "" {
 		entries = append(entries, []cfgEntry{
 			{key: "qom-type", value: "memory-backend-file"},
-			{key: "mem-path", value: opts.hugepages},
+			{key: "mem-path", value: hugepages},
 			{key: "prealloc", value: "on"},
 			{key: "discard-data", value: "on"},
 		}...)
+	} else if opts.memoryFile != "" {
+		entries = append(entries, []cfgEntry{
+			{key: "qom-type", value: "memory-backend-file"},
+			{key: "mem-path", value: opts.memoryFile},
+		}...)
 	} else {
 		entries = append(entries, cfgEntry{key: "qom-type", value: "memory-backend-memfd"})
 	}
 
-	entries = append(entries, cfgEntry{key: "size", value: fmt.Sprintf("%dM", opts.memory)})
+	entries = append(entries, cfgEntry{key: "size", value: fmt.Sprintf("%dM", memory)})
 
 	return []cfgSection{{
 		name:    fmt.Sprintf("object \"mem%d\"", index),
@@ -515,8 +610,12 @@ func qemuCPU(opts *qemuCPUOpts, pinning bool) []cfgSection {
 	if len(opts.cpuNumaHostNodes) == 0 {
 		// add one mem and one numa sections with index 0
 		numaHostNode := qemuCPUNumaHostNode(opts, 0)
-		// unconditionally append "share = "on" to the [object "mem0"] section
-		numaHostNode[0].entries = append(numaHostNode[0].entries, share)
+		// append "share = "on" to the [object "mem0"] section unless mapping a fork base privately
+		if opts.memoryFilePrivate {
+			numaHostNode[0].entries = append(numaHostNode[0].entries, cfgEntry{key: "share", value: "off"})
+		} else {
+			numaHostNode[0].entries = append(numaHostNode[0].entries, share)
+		}
 		return append(sections, numaHostNode...)
 	}
 
@@ -525,7 +624,7 @@ func qemuCPU(opts *qemuCPUOpts, pinning bool) []cfgSection {
 
 		extraMemEntries := []cfgEntry{{key: "policy", value: "bind"}}
 
-		if opts.hugepages != "" {
+		if opts.hugepages != "" || (index < len(opts.cpuNumaHugepages) && opts.cpuNumaHugepages[index] != "") {
 			// append share = "on" only if hugepages is set
 			extraMemEntries = append(extraMemEntries, share)
 		}
@@ -640,6 +739,7 @@ type qemuHostDriveOpts struct {
 	sockFd        string
 	readonly      bool
 	protocol      string
+	cacheSize     string
 }
 
 func qemuHostDrive(opts *qemuHostDriveOpts) []cfgSection {
@@ -690,6 +790,7 @@ func qemuHostDrive(opts *qemuHostDriveOpts) []cfgSection {
 		extraDeviceEntries = []cfgEntry{
 			{key: "tag", value: opts.mountTag},
 			{key: "chardev", value: opts.name},
+			{key: "cache-size", value: opts.cacheSize},
 		}
 	} else {
 		return []cfgSection{}
@@ -727,27 +828,29 @@ func qemuDriveConfig(opts *qemuDriveConfigOpts) []cfgSection {
 }
 
 type qemuDriveDirOpts struct {
-	dev      qemuDevOpts
-	devName  string
-	mountTag string
-	path     string
-	protocol string
-	proxyFD  int
-	readonly bool
+	dev       qemuDevOpts
+	devName   string
+	mountTag  string
+	path      string
+	protocol  string
+	proxyFD   int
+	readonly  bool
+	cacheSize string
 }
 
 func qemuDriveDir(opts *qemuDriveDirOpts) []cfgSection {
 	return qemuHostDrive(&qemuHostDriveOpts{
 		dev: opts.dev,
 		// Devices use "lxd_" prefix indicating that this is a user named device.
-		name:     fmt.Sprintf("lxd_%s", opts.devName),
-		comment:  fmt.Sprintf("%s drive (%s)", opts.devName, opts.protocol),
-		mountTag: opts.mountTag,
-		protocol: opts.protocol,
-		fsdriver: "proxy",
-		readonly: opts.readonly,
-		path:     opts.path,
-		sockFd:   fmt.Sprintf("%d", opts.proxyFD),
+		name:      fmt.Sprintf("lxd_%s", opts.devName),
+		comment:   fmt.Sprintf("%s drive (%s)", opts.devName, opts.protocol),
+		mountTag:  opts.mountTag,
+		protocol:  opts.protocol,
+		fsdriver:  "proxy",
+		readonly:  opts.readonly,
+		path:      opts.path,
+		sockFd:    fmt.Sprintf("%d", opts.proxyFD),
+		cacheSize: opts.cacheSize,
 	})
 }
 
@@ -889,6 +992,101 @@ func qemuTPM(opts *qemuTPMOpts) []cfgSection {
 	}}
 }
 
+type qemuSerialPortOpts struct {
+	devName string
+	path    string
+	host    string
+	port    string
+	logPath string
+}
+
+// qemuSerialPort returns the config of an ISA serial port backed by a listening socket.
+// Virtio serial ports are added through QMP instead so they can be hotplugged.
+func qemuSerialPort(opts *qemuSerialPortOpts) []cfgSection {
+	chardev := fmt.Sprintf("qemu_serial-chardev_%s", opts.devName)
+
+	entries := []cfgEntry{{key: "backend", value: "socket"}}
+	if opts.path != "" {
+		entries = append(entries, cfgEntry{key: "path", value: opts.path})
+	} else {
+		entries = append(entries, cfgEntry{key: "host", value: opts.host}, cfgEntry{key: "port", value: opts.port})
+	}
+
+	entries = append(entries,
+		cfgEntry{key: "server", value: "on"},
+		cfgEntry{key: "wait", value: "off"},
+		cfgEntry{key: "logfile", value: opts.logPath},
+		cfgEntry{key: "logappend", value: "on"},
+	)
+
+	return []cfgSection{{
+		name:    fmt.Sprintf(`chardev "%s"`, chardev),
+		entries: entries,
+	}, {
+		name: fmt.Sprintf(`device "dev-lxd_%s"`, opts.devName),
+		entries: []cfgEntry{
+			{key: "driver", value: "isa-serial"},
+			{key: "chardev", value: chardev},
+		},
+	}}
+}
+
+type qemuWatchdogOpts struct {
+	dev     qemuDevOpts
+	devName string
+	model   string
+}
+
+func qemuWatchdog(opts *qemuWatchdogOpts) []cfgSection {
+	entries := []cfgEntry{{key: "driver", value: opts.model}}
+
+	// The i6300esb watchdog is a PCI device whereas ib700 is an ISA device.
+	if opts.model == "i6300esb" {
+		entries = qemuDeviceEntries(&qemuDevEntriesOpts{
+			dev:     opts.dev,
+			pciName: "i6300esb",
+		})
+	}
+
+	return []cfgSection{{
+		name:    fmt.Sprintf(`device "dev-lxd_%s"`, opts.devName),
+		comment: "Watchdog",
+		entries: entries,
+	}}
+}
+
+type qemuSHMOpts struct {
+	dev     qemuDevOpts
+	devName string
+	path    string
+	size    int64
+}
+
+func qemuSHM(opts *qemuSHMOpts) []cfgSection {
+	memDev := fmt.Sprintf("qemu_shm-mem_%s", opts.devName)
+
+	entries := qemuDeviceEntries(&qemuDevEntriesOpts{
+		dev:     opts.dev,
+		pciName: "ivshmem-plain",
+	})
+
+	entries = append(entries, cfgEntry{key: "memdev", value: memDev})
+
+	return []cfgSection{{
+		name:    fmt.Sprintf(`object "%s"`, memDev),
+		comment: "Shared memory",
+		entries: []cfgEntry{
+			{key: "qom-type", value: "memory-backend-file"},
+			{key: "mem-path", value: opts.path},
+			{key: "size", value: fmt.Sprintf("%d", opts.size)},
+			{key: "share", value: "on"},
+		},
+	}, {
+		name:    fmt.Sprintf(`device "dev-lxd_%s"`, opts.devName),
+		entries: entries,
+	}}
+}
+
 type qemuVmgenIDOpts struct {
 	guid string
 }
@@ -0,0 +1,436 @@
+package drivers
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/instance/drivers/qmp"
+	"github.com/canonical/lxd/lxd/resources"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/osarch"
+	"github.com/canonical/lxd/shared/units"
+)
+
+// guestNumaNode is a guest NUMA node defined through the limits.numa.<node>.* keys.
+type guestNumaNode struct {
+	id        uint64
+	vcpus     []uint64
+	hostNode  uint64
+	memory    int64  // Memory of the node in bytes, 0 to split the remaining memory evenly.
+	hugepages uint64 // Huge page size in bytes, 0 to use the instance-wide memory backing.
+}
+
+// guestNumaNodes parses the explicit guest NUMA layout and validates it against the vCPU topology and the host
+// NUMA nodes. Returns nil if the instance doesn't define an explicit layout.
+func (d *qemu) guestNumaNodes(topology *cpuTopology) ([]guestNumaNode, error) {
+	nodes := map[uint64]*guestNumaNode{}
+	hasCPUs := map[uint64]bool{}
+	hasHostNode := map[uint64]bool{}
+
+	for key, value := range d.expandedConfig {
+		if !strings.HasPrefix(key, "limits.numa.") {
+			continue
+		}
+
+		fields := strings.Split(key, ".")
+		if len(fields) != 4 {
+			continue
+		}
+
+		id, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid guest NUMA node ID in %q: %w", key, err)
+		}
+
+		node, ok := nodes[id]
+		if !ok {
+			node = &guestNumaNode{id: id}
+			nodes[id] = node
+		}
+
+		switch fields[3] {
+		case "cpus":
+			vcpus, err := resources.ParseCpuset(value)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid %q: %w", key, err)
+			}
+
+			for _, vcpu := range vcpus {
+				node.vcpus = append(node.vcpus, uint64(vcpu))
+			}
+
+			hasCPUs[id] = true
+		case "host_node":
+			node.hostNode, err = strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid %q: %w", key, err)
+			}
+
+			hasHostNode[id] = true
+		case "memory":
+			node.memory, err = units.ParseByteSizeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid %q: %w", key, err)
+			}
+		case "hugepages":
+			size, err := units.ParseByteSizeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid %q: %w", key, err)
+			}
+
+			node.hugepages = uint64(size)
+		}
+	}
+
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	if d.architecture != osarch.ARCH_64BIT_INTEL_X86 {
+		return nil, fmt.Errorf("Guest NUMA layout is only supported on x86_64")
+	}
+
+	// Get the host NUMA nodes.
+	memory, err := resources.GetMemory()
+	if err != nil {
+		return nil, err
+	}
+
+	hostNodes := map[uint64]bool{}
+	for _, hostNode := range memory.Nodes {
+		hostNodes[hostNode.NUMANode] = true
+	}
+
+	nrVCPUs := uint64(topology.sockets * topology.cores * topology.threads)
+	seenVCPUs := map[uint64]uint64{}
+
+	result := make([]guestNumaNode, 0, len(nodes))
+	for id := uint64(0); id < uint64(len(nodes)); id++ {
+		node, ok := nodes[id]
+		if !ok {
+			return nil, fmt.Errorf("Guest NUMA node IDs must be contiguous and start at 0, node %d is missing", id)
+		}
+
+		if !hasCPUs[id] {
+			return nil, fmt.Errorf("Guest NUMA node %d requires limits.numa.%d.cpus", id, id)
+		}
+
+		if !hasHostNode[id] {
+			return nil, fmt.Errorf("Guest NUMA node %d requires limits.numa.%d.host_node", id, id)
+		}
+
+		if len(hostNodes) > 0 && !hostNodes[node.hostNode] {
+			return nil, fmt.Errorf("Guest NUMA node %d uses unavailable host NUMA node %d", id, node.hostNode)
+		}
+
+		for _, vcpu := range node.vcpus {
+			if vcpu >= nrVCPUs {
+				return nil, fmt.Errorf("Guest NUMA node %d uses vCPU %d but the instance only has %d vCPUs", id, vcpu, nrVCPUs)
+			}
+
+			otherID, found := seenVCPUs[vcpu]
+			if found {
+				return nil, fmt.Errorf("vCPU %d is used by both guest NUMA nodes %d and %d", vcpu, otherID, id)
+			}
+
+			seenVCPUs[vcpu] = id
+
+			// Warn if the pinned host CPU lives on another host NUMA node than the node memory.
+			if topology.vcpus != nil && !shared.ValueInSlice(vcpu, topology.nodes[node.hostNode]) {
+				d.logger.Warn("vCPU of guest NUMA node is pinned to a CPU outside of its host NUMA node", logger.Ctx{"node": id, "vcpu": vcpu, "cpu": topology.vcpus[vcpu], "hostNode": node.hostNode})
+			}
+		}
+
+		if node.hugepages > 0 {
+			sizes, err := resources.GetNUMANodeHugepageSizes(node.hostNode)
+			if err != nil {
+				return nil, err
+			}
+
+			if !shared.ValueInSlice(node.hugepages, sizes) {
+				return nil, fmt.Errorf("Host NUMA node %d doesn't support huge pages of %s", node.hostNode, units.GetByteSizeStringIEC(int64(node.hugepages), 0))
+			}
+		}
+
+		sort.Slice(node.vcpus, func(i, j int) bool { return node.vcpus[i] < node.vcpus[j] })
+		result = append(result, *node)
+	}
+
+	if uint64(len(seenVCPUs)) != nrVCPUs {
+		return nil, fmt.Errorf("All %d vCPUs must be assigned to a guest NUMA node", nrVCPUs)
+	}
+
+	return result, nil
+}
+
+// hasGuestNumaLayout returns true if the config defines an explicit guest NUMA layout.
+func hasGuestNumaLayout(config map[string]string) bool {
+	for key := range config {
+		if strings.HasPrefix(key, "limits.numa.") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addGuestNumaMemoryConfig sets the per-node memory size and huge pages backing of the guest NUMA nodes.
+// The memory left over by the nodes with an explicit size is split evenly across the other nodes.
+func (d *qemu) addGuestNumaMemoryConfig(cpuOpts *qemuCPUOpts, nodes []guestNumaNode, memSizeBytes int64) error {
+	var defaultHugepageSize uint64
+
+	// Work in MiB as the per-node sizes must add up to the memory size passed to QEMU.
+	memSizeMB := memSizeBytes / 1024 / 1024
+	sizedMemory := int64(0)
+	sizedMemoryMB := int64(0)
+	unsized := int64(0)
+	for _, node := range nodes {
+		if node.memory > 0 {
+			sizedMemory += node.memory
+			sizedMemoryMB += node.memory / 1024 / 1024
+		} else {
+			unsized++
+		}
+	}
+
+	if unsized == 0 && sizedMemory != memSizeBytes {
+		return fmt.Errorf("The memory of the guest NUMA nodes (%s) doesn't add up to limits.memory (%s)", units.GetByteSizeStringIEC(sizedMemory, 2), units.GetByteSizeStringIEC(memSizeBytes, 2))
+	}
+
+	remainingMB := memSizeMB - sizedMemoryMB
+	if unsized > 0 && remainingMB < unsized {
+		return fmt.Errorf("The memory of the guest NUMA nodes (%s) leaves no memory out of limits.memory (%s) for the guest NUMA nodes without limits.numa.<node>.memory", units.GetByteSizeStringIEC(sizedMemory, 2), units.GetByteSizeStringIEC(memSizeBytes, 2))
+	}
+
+	cpuOpts.cpuNumaMemory = make([]int64, 0, len(nodes))
+	cpuOpts.cpuNumaHugepages = make([]string, 0, len(nodes))
+
+	for _, node := range nodes {
+		if node.memory > 0 {
+			cpuOpts.cpuNumaMemory = append(cpuOpts.cpuNumaMemory, node.memory/1024/1024)
+		} else {
+			// The last node without an explicit size also gets the rounding remainder.
+			nodeMemoryMB := remainingMB / unsized
+			unsized--
+			if unsized == 0 {
+				nodeMemoryMB = remainingMB
+			}
+
+			remainingMB -= nodeMemoryMB
+			cpuOpts.cpuNumaMemory = append(cpuOpts.cpuNumaMemory, nodeMemoryMB)
+		}
+
+		if node.hugepages == 0 {
+			cpuOpts.cpuNumaHugepages = append(cpuOpts.cpuNumaHugepages, "")
+			continue
+		}
+
+		if defaultHugepageSize == 0 {
+			memory, err := resources.GetMemory()
+			if err != nil {
+				return err
+			}
+
+			defaultHugepageSize = memory.HugepagesSize
+		}
+
+		hugetlb, err := util.HugepagesPathForSize(node.hugepages, defaultHugepageSize)
+		if err != nil {
+			return err
+		}
+
+		cpuOpts.cpuNumaHugepages = append(cpuOpts.cpuNumaHugepages, hugetlb)
+	}
+
+	return nil
+}
+
+// validateCPUPinStrategy checks that the emulator thread pinning configuration is consistent.
+func (d *qemu) validateCPUPinStrategy() error {
+	emulatorLimit := d.expandedConfig["limits.cpu.emulator"]
+
+	if d.expandedConfig["limits.cpu.pin_strategy"] != "isolated" {
+		if emulatorLimit != "" {
+			return fmt.Errorf("limits.cpu.emulator requires limits.cpu.pin_strategy to be set to isolated")
+		}
+
+		return nil
+	}
+
+	if emulatorLimit == "" {
+		return fmt.Errorf("limits.cpu.pin_strategy isolated requires limits.cpu.emulator to be set")
+	}
+
+	// Isolation is only guaranteed when the vCPUs are pinned to a fixed set of CPUs.
+	cpuLimit := d.expandedConfig["limits.cpu"]
+	_, err := strconv.Atoi(cpuLimit)
+	if cpuLimit == "" || err == nil {
+		return fmt.Errorf("limits.cpu.pin_strategy isolated requires limits.cpu to be set to a set of CPUs")
+	}
+
+	vcpuPins, err := resources.ParseCpuset(cpuLimit)
+	if err != nil {
+		return err
+	}
+
+	emulatorPins, err := resources.ParseCpuset(emulatorLimit)
+	if err != nil {
+		return err
+	}
+
+	cpus, err := resources.GetCPU()
+	if err != nil {
+		return err
+	}
+
+	hostCPUs := []int64{}
+	for _, socket := range cpus.Sockets {
+		for _, core := range socket.Cores {
+			for _, thread := range core.Threads {
+				hostCPUs = append(hostCPUs, thread.ID)
+			}
+		}
+	}
+
+	for _, pin := range emulatorPins {
+		if !shared.ValueInSlice(pin, hostCPUs) {
+			return fmt.Errorf("Unavailable CPU %d requested in limits.cpu.emulator", pin)
+		}
+
+		if shared.ValueInSlice(pin, vcpuPins) {
+			return fmt.Errorf("CPU %d is used by both limits.cpu and limits.cpu.emulator", pin)
+		}
+	}
+
+	return nil
+}
+
+// pinEmulatorThreads pins the QEMU threads that aren't vCPU threads (emulator and I/O threads) according to
+// limits.cpu.pin_strategy. The vcpuSet argument is the list of host CPUs the vCPU threads are pinned to.
+func (d *qemu) pinEmulatorThreads(vcpuPIDs []int, vcpuSet []string) error {
+	affinitySet := unix.CPUSet{}
+
+	switch d.expandedConfig["limits.cpu.pin_strategy"] {
+	case "shared":
+		for _, cpu := range vcpuSet {
+			cpuCoreIndex, _ := strconv.Atoi(cpu)
+			affinitySet.Set(cpuCoreIndex)
+		}
+
+	case "isolated":
+		pins, err := resources.ParseCpuset(d.expandedConfig["limits.cpu.emulator"])
+		if err != nil {
+			return err
+		}
+
+		for _, pin := range pins {
+			affinitySet.Set(int(pin))
+		}
+
+	default:
+		return nil
+	}
+
+	pid, err := d.pid()
+	if err != nil {
+		return err
+	}
+
+	if pid <= 0 {
+		return nil
+	}
+
+	// Any thread of the QEMU process which isn't a vCPU thread is either an emulator or an I/O thread.
+	tasksPath := fmt.Sprintf("/proc/%d/task", pid)
+	tasks, err := os.ReadDir(tasksPath)
+	if err != nil {
+		return fmt.Errorf("Failed listing %q: %w", tasksPath, err)
+	}
+
+	for _, task := range tasks {
+		tid, err := strconv.Atoi(task.Name())
+		if err != nil || shared.ValueInSlice(tid, vcpuPIDs) {
+			continue
+		}
+
+		err = unix.SchedSetaffinity(tid, &affinitySet)
+		if err != nil {
+			return fmt.Errorf("Failed to set QEMU emulator thread affinity: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// repinEmulatorThreads pins the QEMU threads created since the vCPUs were pinned, for example by device hotplug.
+// The host CPUs of the vCPU threads are taken from their current affinity.
+func (d *qemu) repinEmulatorThreads() error {
+	if !shared.ValueInSlice(d.expandedConfig["limits.cpu.pin_strategy"], []string{"shared", "isolated"}) {
+		return nil
+	}
+
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	pids, err := monitor.GetCPUs()
+	if err != nil {
+		return fmt.Errorf("Failed to get VM instance's QEMU process list: %w", err)
+	}
+
+	cpus, err := resources.GetCPU()
+	if err != nil {
+		return err
+	}
+
+	vcpuSet := []string{}
+	for _, pid := range pids {
+		affinitySet := unix.CPUSet{}
+		err := unix.SchedGetaffinity(pid, &affinitySet)
+		if err != nil {
+			return fmt.Errorf("Failed to get QEMU vCPU thread affinity: %w", err)
+		}
+
+		for _, socket := range cpus.Sockets {
+			for _, core := range socket.Cores {
+				for _, thread := range core.Threads {
+					cpu := strconv.FormatInt(thread.ID, 10)
+					if affinitySet.IsSet(int(thread.ID)) && !shared.ValueInSlice(cpu, vcpuSet) {
+						vcpuSet = append(vcpuSet, cpu)
+					}
+				}
+			}
+		}
+	}
+
+	return d.pinEmulatorThreads(pids, vcpuSet)
+}
+
+// diskIOThread returns the QEMU I/O thread that the given virtio-blk disk should use.
+// Disks are spread across the I/O threads in the order they are allocated at boot time.
+// Returns an empty string if no I/O threads are configured.
+func (d *qemu) diskIOThread(devName string) string {
+	iothreads, _ := strconv.Atoi(d.expandedConfig["limits.cpu.iothreads"])
+	if iothreads <= 0 {
+		return ""
+	}
+
+	index := 0
+	for _, dev := range d.expandedDevices.Sorted() {
+		if dev.Name == devName {
+			break
+		}
+
+		index++
+	}
+
+	return fmt.Sprintf("%s%d", qemuIOThreadPrefix, index%iothreads)
+}
@@ -0,0 +1,86 @@
+package drivers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQemuGuestNumaMemoryConfig(t *testing.T) {
+	const GiB = 1024 * 1024 * 1024
+
+	testCases := []struct {
+		name         string
+		nodes        []guestNumaNode
+		memSizeBytes int64
+		expected     []int64
+		expectErr    bool
+	}{{
+		name:         "no node sized",
+		nodes:        []guestNumaNode{{id: 0}, {id: 1}},
+		memSizeBytes: 4 * GiB,
+		expected:     []int64{2048, 2048},
+	}, {
+		name:         "no node sized with remainder",
+		nodes:        []guestNumaNode{{id: 0}, {id: 1}, {id: 2}},
+		memSizeBytes: 4 * GiB,
+		expected:     []int64{1365, 1365, 1366},
+	}, {
+		name:         "all nodes sized",
+		nodes:        []guestNumaNode{{id: 0, memory: 1 * GiB}, {id: 1, memory: 3 * GiB}},
+		memSizeBytes: 4 * GiB,
+		expected:     []int64{1024, 3072},
+	}, {
+		name:         "all nodes sized not adding up",
+		nodes:        []guestNumaNode{{id: 0, memory: 1 * GiB}, {id: 1, memory: 1 * GiB}},
+		memSizeBytes: 4 * GiB,
+		expectErr:    true,
+	}, {
+		name:         "mixed sized and unsized nodes",
+		nodes:        []guestNumaNode{{id: 0, memory: 1 * GiB}, {id: 1}},
+		memSizeBytes: 4 * GiB,
+		expected:     []int64{1024, 3072},
+	}, {
+		name:         "mixed with several unsized nodes",
+		nodes:        []guestNumaNode{{id: 0}, {id: 1, memory: 2 * GiB}, {id: 2}},
+		memSizeBytes: 6 * GiB,
+		expected:     []int64{2048, 2048, 2048},
+	}, {
+		name:         "mixed without memory left",
+		nodes:        []guestNumaNode{{id: 0, memory: 4 * GiB}, {id: 1}},
+		memSizeBytes: 4 * GiB,
+		expectErr:    true,
+	}, {
+		name:         "mixed exceeding the memory",
+		nodes:        []guestNumaNode{{id: 0, memory: 5 * GiB}, {id: 1}},
+		memSizeBytes: 4 * GiB,
+		expectErr:    true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &qemu{}
+			cpuOpts := &qemuCPUOpts{}
+
+			err := d.addGuestNumaMemoryConfig(cpuOpts, tc.nodes, tc.memSizeBytes)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error. Got: %v", cpuOpts.cpuNumaMemory)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(tc.expected, cpuOpts.cpuNumaMemory) {
+				t.Errorf("Expected: %v. Got: %v", tc.expected, cpuOpts.cpuNumaMemory)
+			}
+
+			if !reflect.DeepEqual(make([]string, len(tc.nodes)), cpuOpts.cpuNumaHugepages) {
+				t.Errorf("Expected no huge pages. Got: %v", cpuOpts.cpuNumaHugepages)
+			}
+		})
+	}
+}
@@ -91,6 +91,29 @@ func (m *Monitor) QueryHotpluggableCPUs() ([]HotpluggableCPU, error) {
 	return resp.Return, nil
 }
 
+// GetDeviceProperties returns the names of the properties of the device type.
+func (m *Monitor) GetDeviceProperties(typeName string) ([]string, error) {
+	// Prepare the response.
+	var resp struct {
+		Return []struct {
+			Name string `json:"name"`
+		} `json:"return"`
+	}
+
+	args := map[string]string{"typename": typeName}
+	err := m.run("device-list-properties", args, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query device properties: %w", err)
+	}
+
+	properties := make([]string, 0, len(resp.Return))
+	for _, property := range resp.Return {
+		properties = append(properties, property.Name)
+	}
+
+	return properties, nil
+}
+
 // Status returns the current VM status.
 func (m *Monitor) Status() (string, error) {
 	// Prepare the response.
@@ -700,8 +723,13 @@ func (m *Monitor) QueryPCI() ([]PCIDevice, error) {
 type BlockStats struct {
 	BytesWritten    int `json:"wr_bytes"`
 	WritesCompleted int `json:"wr_operations"`
+	WriteTotalTime  int `json:"wr_total_time_ns"`
 	BytesRead       int `json:"rd_bytes"`
 	ReadsCompleted  int `json:"rd_operations"`
+	ReadTotalTime   int `json:"rd_total_time_ns"`
+
+	// NodeName is the name of the root block node of the device.
+	NodeName string `json:"-"`
 }
 
 // GetBlockStats return block device stats.
@@ -709,8 +737,9 @@ func (m *Monitor) GetBlockStats() (map[string]BlockStats, error) {
 	// Prepare the response
 	var resp struct {
 		Return []struct {
-			Stats BlockStats `json:"stats"`
-			QDev  string     `json:"qdev"`
+			Stats    BlockStats `json:"stats"`
+			QDev     string     `json:"qdev"`
+			NodeName string     `json:"node-name"`
 		} `json:"return"`
 	}
 
@@ -722,6 +751,7 @@ func (m *Monitor) GetBlockStats() (map[string]BlockStats, error) {
 	out := make(map[string]BlockStats)
 
 	for _, res := range resp.Return {
+		res.Stats.NodeName = res.NodeName
 		out[res.QDev] = res.Stats
 	}
 
@@ -770,6 +800,47 @@ func (m *Monitor) SEVCapabilities() (AMDSEVCapabilities, error) {
 	return resp.Return, nil
 }
 
+// SEVLaunchMeasurement returns the base64-encoded launch measurement of a SEV or SEV-ES guest.
+// The measurement is only available while the guest is paused before its first start.
+func (m *Monitor) SEVLaunchMeasurement() (string, error) {
+	// Prepare the response.
+	var resp struct {
+		Return struct {
+			Data string `json:"data"`
+		} `json:"return"`
+	}
+
+	err := m.run("query-sev-launch-measure", nil, &resp)
+	if err != nil {
+		return "", fmt.Errorf("Failed querying SEV launch measurement: %w", err)
+	}
+
+	return resp.Return.Data, nil
+}
+
+// GetObjectTypes returns the names of the non-abstract object types implementing the given type.
+func (m *Monitor) GetObjectTypes(implements string) ([]string, error) {
+	// Prepare the response.
+	var resp struct {
+		Return []struct {
+			Name string `json:"name"`
+		} `json:"return"`
+	}
+
+	args := map[string]any{"implements": implements, "abstract": false}
+	err := m.run("qom-list-types", args, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query object types: %w", err)
+	}
+
+	types := make([]string, 0, len(resp.Return))
+	for _, objectType := range resp.Return {
+		types = append(types, objectType.Name)
+	}
+
+	return types, nil
+}
+
 // NBDServerStart starts internal NBD server and returns a connection to it.
 func (m *Monitor) NBDServerStart() (net.Conn, error) {
 	var args struct {
@@ -1015,24 +1086,33 @@ func (m *Monitor) Eject(id string) error {
 	return nil
 }
 
-// SetBlockThrottle applies an I/O limit on a disk.
-func (m *Monitor) SetBlockThrottle(id string, bytesRead int, bytesWrite int, iopsRead int, iopsWrite int) error {
+// BlockThrottle represents the I/O limits of a disk. Zero values mean unlimited.
+type BlockThrottle struct {
+	BytesRead  int `json:"bps_rd"`
+	BytesWrite int `json:"bps_wr"`
+	IOPsRead   int `json:"iops_rd"`
+	IOPsWrite  int `json:"iops_wr"`
+
+	// Burst limits allowed above the base limits for up to a second.
+	BytesReadMax  int `json:"bps_rd_max,omitempty"`
+	BytesWriteMax int `json:"bps_wr_max,omitempty"`
+	IOPsReadMax   int `json:"iops_rd_max,omitempty"`
+	IOPsWriteMax  int `json:"iops_wr_max,omitempty"`
+}
+
+// SetBlockThrottle applies the I/O limits on a disk, replacing any previous limits.
+func (m *Monitor) SetBlockThrottle(id string, limits BlockThrottle) error {
 	var args struct {
 		ID string `json:"id"`
 
-		Bytes      int `json:"bps"`
-		BytesRead  int `json:"bps_rd"`
-		BytesWrite int `json:"bps_wr"`
-		IOPs       int `json:"iops"`
-		IOPsRead   int `json:"iops_rd"`
-		IOPsWrite  int `json:"iops_wr"`
+		Bytes int `json:"bps"`
+		IOPs  int `json:"iops"`
+
+		BlockThrottle
 	}
 
 	args.ID = id
-	args.BytesRead = bytesRead
-	args.BytesWrite = bytesWrite
-	args.IOPsRead = iopsRead
-	args.IOPsWrite = iopsWrite
+	args.BlockThrottle = limits
 
 	err := m.run("block_set_io_throttle", args, nil)
 	if err != nil {
@@ -22,6 +22,9 @@ var RingbufSize = 16
 // EventAgentStarted is the event sent once the lxd-agent has started.
 var EventAgentStarted = "LXD-AGENT-STARTED"
 
+// EventWatchdog is the event sent when the watchdog of the VM fires.
+var EventWatchdog = "WATCHDOG"
+
 // EventVMShutdown is the event sent when VM guest shuts down.
 var EventVMShutdown = "SHUTDOWN"
 
@@ -181,6 +181,7 @@ type Container interface {
 	InsertSeccompUnixDevice(prefix string, m deviceConfig.Device, pid int) error
 	DevptsFd() (*os.File, error)
 	IdmappedStorage(path string, fstype string) idmap.IdmapStorageType
+	CheckpointState() error
 }
 
 // VM interface is for VM specific functions.
@@ -192,6 +193,9 @@ type VM interface {
 	// UEFI vars handling.
 	UEFIVars() (*api.InstanceUEFIVars, error)
 	UEFIVarsUpdate(newUEFIVarsSet api.InstanceUEFIVars) error
+
+	// Forking.
+	ForkBase() (string, error)
 }
 
 // CriuMigrationArgs arguments for CRIU migration.
@@ -26,6 +26,7 @@ import (
 	"github.com/canonical/lxd/lxd/idmap"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/instance/operationlock"
+	"github.com/canonical/lxd/lxd/inventory"
 	"github.com/canonical/lxd/lxd/migration"
 	"github.com/canonical/lxd/lxd/seccomp"
 	"github.com/canonical/lxd/lxd/state"
@@ -112,6 +113,10 @@ func ValidConfig(sysOS *sys.OS, config map[string]string, expanded bool, instanc
 		return fmt.Errorf("nvidia.runtime is incompatible with privileged containers")
 	}
 
+	if config["healthcheck.type"] == "tcp" && strings.Contains(config["healthcheck.target"], "/") {
+		return fmt.Errorf("TCP health checks only take a port as healthcheck.target")
+	}
+
 	return nil
 }
 
@@ -479,7 +484,7 @@ func BackupLoadByName(s *state.State, project, name string) (*backup.InstanceBac
 		return nil, err
 	}
 
-	return backup.NewInstanceBackup(s, instance, args.ID, name, args.CreationDate, args.ExpiryDate, args.InstanceOnly, args.OptimizedStorage), nil
+	return backup.NewInstanceBackup(s, instance, args.ID, name, args.CreationDate, args.ExpiryDate, args.InstanceOnly, args.OptimizedStorage, args.Stateful), nil
 }
 
 // ResolveImage takes an instance source and returns a hash suitable for instance creation or download.
@@ -942,6 +947,13 @@ func CreateInternal(s *state.State, args db.InstanceArgs, clearLogDir bool) (Ins
 			return err
 		}
 
+		// Reserve the local devices needed by the instance so that concurrent placements don't select them.
+		expandedDevices := instancetype.ExpandInstanceDevices(args.Devices, args.Profiles).CloneNative()
+		err = reserveNodeDevices(ctx, tx, instanceID, args.Type, expandedDevices)
+		if err != nil {
+			return err
+		}
+
 		// Read back the instance, to get ID and creation time.
 		dbRow, err := cluster.GetInstance(ctx, tx.Tx(), args.Project, args.Name)
 		if err != nil {
@@ -1002,6 +1014,48 @@ func CreateInternal(s *state.State, args db.InstanceArgs, clearLogDir bool) (Ins
 	return inst, op, cleanup, err
 }
 
+// reserveNodeDevices allocates the free devices of the local member in the cluster device inventory to the
+// passthrough devices of the instance. Devices that can't be allocated are left to be checked on start, as several
+// instances can be configured with the same device as long as they don't run at the same time.
+func reserveNodeDevices(ctx context.Context, tx *db.ClusterTx, instanceID int64, instType instancetype.Type, devices map[string]map[string]string) error {
+	reqs := inventory.Requirements(instType, devices)
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	nodeDevices, err := tx.GetNodeDevices(ctx)
+	if err != nil {
+		return err
+	}
+
+	memberDevices := []db.NodeDevice{}
+	clusterDevices := []api.ClusterDevice{}
+	available := []bool{}
+	for _, nodeDevice := range nodeDevices {
+		if nodeDevice.NodeID != tx.GetNodeID() {
+			continue
+		}
+
+		memberDevices = append(memberDevices, nodeDevice)
+		clusterDevices = append(clusterDevices, nodeDevice.ClusterDevice)
+		available = append(available, nodeDevice.InstanceID == 0)
+	}
+
+	allocations, err := inventory.Assign(reqs, clusterDevices, available)
+	if err != nil {
+		return nil
+	}
+
+	for deviceName, index := range allocations {
+		err = tx.AllocateNodeDevice(ctx, tx.GetNodeID(), memberDevices[index].Type, memberDevices[index].Address, instanceID, deviceName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // NextSnapshotName finds the next snapshot for an instance.
 func NextSnapshotName(s *state.State, inst Instance, defaultPattern string) (string, error) {
 	var err error
@@ -0,0 +1,161 @@
+package instancetemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// placeholderRegexp matches the parameter placeholders ({{ name }}) in the instance definition of a template.
+var placeholderRegexp = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// parameterNameRegexp matches the valid parameter names.
+var parameterNameRegexp = regexp.MustCompile(`^\w+$`)
+
+// ValidName checks the instance template name is valid.
+func ValidName(name string) error {
+	if name == "" {
+		return fmt.Errorf("Name is required")
+	}
+
+	return validate.IsHostname(name)
+}
+
+// Validate checks the parameters and instance definition of the instance template are valid.
+func Validate(put api.InstanceTemplatePut) error {
+	for name, param := range put.Parameters {
+		if !parameterNameRegexp.MatchString(name) {
+			return fmt.Errorf("Invalid parameter name %q, only letters, digits and underscores are allowed", name)
+		}
+
+		if param.Required && param.Default != "" {
+			return fmt.Errorf("Required parameter %q can't have a default value", name)
+		}
+	}
+
+	if put.Instance.Template != "" || len(put.Instance.TemplateParameters) > 0 {
+		return fmt.Errorf("The instance definition can't reference another instance template")
+	}
+
+	return nil
+}
+
+// Render returns the instance definition of the template with the placeholders of its parameters replaced by the
+// given values, or by the parameter defaults when no value is given. Placeholders not matching any parameter of the
+// template are left untouched.
+func Render(put api.InstanceTemplatePut, values map[string]string) (*api.InstancesPost, error) {
+	for name := range values {
+		_, ok := put.Parameters[name]
+		if !ok {
+			return nil, fmt.Errorf("Unknown parameter %q", name)
+		}
+	}
+
+	resolved := make(map[string]string, len(put.Parameters))
+	for name, param := range put.Parameters {
+		value, ok := values[name]
+		if !ok {
+			if param.Required {
+				return nil, fmt.Errorf("Missing value for required parameter %q", name)
+			}
+
+			value = param.Default
+		}
+
+		resolved[name] = value
+	}
+
+	// Placeholders can only appear within JSON strings, so substitute them with JSON escaped values.
+	definition, err := json.Marshal(put.Instance)
+	if err != nil {
+		return nil, err
+	}
+
+	definition = placeholderRegexp.ReplaceAllFunc(definition, func(match []byte) []byte {
+		value, ok := resolved[string(placeholderRegexp.FindSubmatch(match)[1])]
+		if !ok {
+			return match
+		}
+
+		escaped, err := json.Marshal(value)
+		if err != nil {
+			return match
+		}
+
+		return escaped[1 : len(escaped)-1]
+	})
+
+	inst := api.InstancesPost{}
+	err = json.Unmarshal(definition, &inst)
+	if err != nil {
+		return nil, fmt.Errorf("Failed rendering the instance definition: %w", err)
+	}
+
+	return &inst, nil
+}
+
+// Merge returns the instance definition rendered from a template with the fields set in the instance creation
+// request applied on top of it. Config keys and devices of the request are added to those of the definition,
+// replacing any with the same name.
+func Merge(definition api.InstancesPost, req api.InstancesPost) api.InstancesPost {
+	if req.Name != "" {
+		definition.Name = req.Name
+	}
+
+	if req.Description != "" {
+		definition.Description = req.Description
+	}
+
+	if req.Source.Type != "" {
+		definition.Source = req.Source
+	}
+
+	if req.Type != "" {
+		definition.Type = req.Type
+	}
+
+	if req.InstanceType != "" {
+		definition.InstanceType = req.InstanceType
+	}
+
+	if req.Architecture != "" {
+		definition.Architecture = req.Architecture
+	}
+
+	if req.Profiles != nil {
+		definition.Profiles = req.Profiles
+	}
+
+	definition.Ephemeral = definition.Ephemeral || req.Ephemeral
+	definition.Stateful = definition.Stateful || req.Stateful
+
+	config := make(map[string]string, len(definition.Config)+len(req.Config))
+	for key, value := range definition.Config {
+		config[key] = value
+	}
+
+	for key, value := range req.Config {
+		config[key] = value
+	}
+
+	definition.Config = config
+
+	devices := make(map[string]map[string]string, len(definition.Devices)+len(req.Devices))
+	for name, device := range definition.Devices {
+		devices[name] = device
+	}
+
+	for name, device := range req.Devices {
+		devices[name] = device
+	}
+
+	definition.Devices = devices
+
+	definition.Template = ""
+	definition.TemplateParameters = nil
+
+	return definition
+}
@@ -0,0 +1,112 @@
+package instancetemplate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestValidate(t *testing.T) {
+	put := api.InstanceTemplatePut{
+		Parameters: map[string]api.InstanceTemplateParameter{
+			"size": {Default: "small"},
+		},
+	}
+
+	assert.NoError(t, Validate(put))
+
+	put.Parameters["bad-name"] = api.InstanceTemplateParameter{}
+	assert.Error(t, Validate(put))
+
+	delete(put.Parameters, "bad-name")
+	put.Parameters["size"] = api.InstanceTemplateParameter{Required: true, Default: "small"}
+	assert.Error(t, Validate(put))
+
+	put.Parameters["size"] = api.InstanceTemplateParameter{Required: true}
+	put.Instance.Template = "other"
+	assert.Error(t, Validate(put))
+}
+
+func TestRender(t *testing.T) {
+	put := api.InstanceTemplatePut{
+		Parameters: map[string]api.InstanceTemplateParameter{
+			"size":   {Default: "small"},
+			"domain": {Required: true},
+		},
+		Instance: api.InstancesPost{
+			Name:         "web-{{ size }}",
+			InstanceType: "{{size}}",
+			InstancePut: api.InstancePut{
+				Config: map[string]string{
+					"user.domain":          "{{ domain }}",
+					"cloud-init.user-data": "#cloud-config\nfqdn: {{ domain }}\nhostname: {{ v1.local_hostname }} {{ other }}",
+				},
+			},
+		},
+	}
+
+	_, err := Render(put, nil)
+	assert.Error(t, err, "Missing required parameter")
+
+	_, err = Render(put, map[string]string{"domain": "example.com", "unknown": "foo"})
+	assert.Error(t, err, "Unknown parameter")
+
+	inst, err := Render(put, map[string]string{"domain": `"example.com"`})
+	require.NoError(t, err)
+	assert.Equal(t, "web-small", inst.Name)
+	assert.Equal(t, "small", inst.InstanceType)
+	assert.Equal(t, `"example.com"`, inst.Config["user.domain"])
+	assert.Equal(t, "#cloud-config\nfqdn: \"example.com\"\nhostname: {{ v1.local_hostname }} {{ other }}", inst.Config["cloud-init.user-data"])
+
+	inst, err = Render(put, map[string]string{"domain": "example.com", "size": "large"})
+	require.NoError(t, err)
+	assert.Equal(t, "web-large", inst.Name)
+
+	// The template itself isn't modified.
+	assert.Equal(t, "web-{{ size }}", put.Instance.Name)
+}
+
+func TestMerge(t *testing.T) {
+	definition := api.InstancesPost{
+		Name:   "web",
+		Source: api.InstanceSource{Type: "image", Alias: "ubuntu/24.04"},
+		Type:   api.InstanceTypeContainer,
+		InstancePut: api.InstancePut{
+			Profiles: []string{"default", "web"},
+			Config:   map[string]string{"limits.cpu": "2", "user.foo": "bar"},
+			Devices:  map[string]map[string]string{"eth0": {"type": "nic", "network": "lxdbr0"}},
+		},
+	}
+
+	merged := Merge(definition, api.InstancesPost{
+		Template: "web",
+		InstancePut: api.InstancePut{
+			Config: map[string]string{"limits.cpu": "4"},
+		},
+	})
+
+	assert.Equal(t, "web", merged.Name)
+	assert.Equal(t, definition.Source, merged.Source)
+	assert.Equal(t, []string{"default", "web"}, merged.Profiles)
+	assert.Equal(t, map[string]string{"limits.cpu": "4", "user.foo": "bar"}, merged.Config)
+	assert.Empty(t, merged.Template)
+
+	merged = Merge(definition, api.InstancesPost{
+		Name:   "web1",
+		Source: api.InstanceSource{Type: "image", Alias: "debian/12"},
+		Type:   api.InstanceTypeVM,
+		InstancePut: api.InstancePut{
+			Profiles: []string{},
+			Devices:  map[string]map[string]string{"eth0": {"type": "nic", "network": "ovn0"}},
+		},
+	})
+
+	assert.Equal(t, "web1", merged.Name)
+	assert.Equal(t, "debian/12", merged.Source.Alias)
+	assert.Equal(t, api.InstanceTypeVM, merged.Type)
+	assert.Equal(t, []string{}, merged.Profiles)
+	assert.Equal(t, map[string]map[string]string{"eth0": {"type": "nic", "network": "ovn0"}}, merged.Devices)
+}
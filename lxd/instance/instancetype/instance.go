@@ -3,6 +3,7 @@ package instancetype
 import (
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -111,6 +112,34 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What order to shut down the instances in
 	"boot.stop.priority": validate.Optional(validate.IsInt64),
 
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.depends_on)
+	// Comma-separated list of instances of the same project, each optionally followed by a condition:
+	// `<instance>[:<condition>]`.
+	// Possible conditions are `running` (the default), `ready` (the instance reported itself ready, for example
+	// through the `lxd-agent` of a virtual machine) and `healthy` (see {ref}`instance-options-healthcheck`).
+	//
+	// The dependencies are started first and the instance is only started once their conditions are met, when
+	// starting instances with the LXD daemon, starting all instances at once and restoring a cluster member.
+	// When stopping all instances at once or evacuating a cluster member, the instances depending on an
+	// instance are stopped first.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Instances to start before this instance
+	"boot.depends_on": validate.Optional(func(value string) error {
+		_, err := ParseInstanceDependencies(value)
+		return err
+	}),
+
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.depends_on.timeout)
+	// If the conditions aren't met by then, the instance isn't started.
+	// ---
+	//  type: integer
+	//  defaultdesc: `300`
+	//  liveupdate: yes
+	//  shortdesc: Number of seconds to wait for the dependencies of the instance
+	"boot.depends_on.timeout": validate.Optional(validate.IsUint32),
+
 	// lxdmeta:generate(entities=instance; group=boot; key=boot.host_shutdown_timeout)
 	// Number of seconds to wait for the instance to shut down before it is force-stopped.
 	// ---
@@ -120,6 +149,38 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: How long to wait for the instance to shut down
 	"boot.host_shutdown_timeout": validate.Optional(validate.IsInt64),
 
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.restart_policy)
+	// Possible values are `never`, `on-failure` and `always`.
+	// With `on-failure`, the instance is started again when it stops unexpectedly, for example after a guest crash.
+	// For containers, any stop that isn't requested through LXD is considered unexpected.
+	// With `always`, the instance is also started again after a clean shutdown from within the instance.
+	// Stopping the instance through LXD never triggers the restart policy.
+	// ---
+	//  type: string
+	//  defaultdesc: `never`
+	//  liveupdate: yes
+	//  shortdesc: Whether to restart the instance when it stops on its own
+	"boot.restart_policy": validate.Optional(validate.IsOneOf("never", "on-failure", "always")),
+
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.restart_policy.delay)
+	// The delay doubles with every consecutive restart attempt, up to five minutes.
+	// ---
+	//  type: integer
+	//  defaultdesc: `5`
+	//  liveupdate: yes
+	//  shortdesc: Number of seconds to wait before the first restart attempt
+	"boot.restart_policy.delay": validate.Optional(validate.IsUint32),
+
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.restart_policy.max_attempts)
+	// The attempts counter is reset once the instance has been running for ten minutes.
+	// Set to `0` to never give up restarting the instance.
+	// ---
+	//  type: integer
+	//  defaultdesc: `3`
+	//  liveupdate: yes
+	//  shortdesc: Maximum number of consecutive restart attempts
+	"boot.restart_policy.max_attempts": validate.Optional(validate.IsUint32),
+
 	// lxdmeta:generate(entities=instance; group=cloud-init; key=cloud-init.network-config)
 	// The content is used as seed value for `cloud-init`.
 	// ---
@@ -202,6 +263,77 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What to do when evacuating the instance
 	"cluster.evacuate": validate.Optional(validate.IsOneOf("auto", "migrate", "live-migrate", "stop")),
 
+	// lxdmeta:generate(entities=instance; group=healthcheck; key=healthcheck.type)
+	// Possible values are `exec`, `tcp` and `http`.
+	// When not set, the instance isn't checked.
+	// See {ref}`instance-options-healthcheck` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: How to check the health of the instance
+	"healthcheck.type": validate.Optional(validate.IsOneOf("exec", "tcp", "http")),
+
+	// lxdmeta:generate(entities=instance; group=healthcheck; key=healthcheck.command)
+	// The command is run through `sh -c` inside the instance and the check fails if it exits with a non-zero status.
+	// For virtual machines, this requires the `lxd-agent`.
+	// Setting the command requires the `can_exec` entitlement on the instance, or `can_operate_instances` on the project for new instances, profiles and instance templates.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  condition: `healthcheck.type` is `exec`
+	//  shortdesc: Command to run inside the instance
+	"healthcheck.command": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=healthcheck; key=healthcheck.target)
+	// For `tcp`, specify the port (for example, `5432`).
+	// For `http`, specify the port, optionally followed by the path of the request (for example, `8080/healthz`).
+	// The checks always connect to the first global address of the instance.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  condition: `healthcheck.type` is `tcp` or `http`
+	//  shortdesc: Port (and path) to probe
+	"healthcheck.target": validate.Optional(func(value string) error {
+		_, _, err := ParseHealthcheckTarget(value)
+		return err
+	}),
+
+	// lxdmeta:generate(entities=instance; group=healthcheck; key=healthcheck.interval)
+	//
+	// ---
+	//  type: integer
+	//  defaultdesc: `30`
+	//  liveupdate: yes
+	//  shortdesc: Number of seconds between two checks
+	"healthcheck.interval": validate.Optional(validate.IsUint32),
+
+	// lxdmeta:generate(entities=instance; group=healthcheck; key=healthcheck.timeout)
+	//
+	// ---
+	//  type: integer
+	//  defaultdesc: `5`
+	//  liveupdate: yes
+	//  shortdesc: Number of seconds after which a check fails
+	"healthcheck.timeout": validate.Optional(validate.IsInRange(1, math.MaxUint32)),
+
+	// lxdmeta:generate(entities=instance; group=healthcheck; key=healthcheck.retries)
+	//
+	// ---
+	//  type: integer
+	//  defaultdesc: `3`
+	//  liveupdate: yes
+	//  shortdesc: Number of consecutive failed checks before the instance is unhealthy
+	"healthcheck.retries": validate.Optional(validate.IsUint32),
+
+	// lxdmeta:generate(entities=instance; group=healthcheck; key=healthcheck.action)
+	// Possible values are `none` (only mark the instance as unhealthy) and `restart`.
+	// ---
+	//  type: string
+	//  defaultdesc: `none`
+	//  liveupdate: yes
+	//  shortdesc: What to do when the instance becomes unhealthy
+	"healthcheck.action": validate.Optional(validate.IsOneOf("none", "restart")),
+
 	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.cpu)
 	// A number or a specific range of CPUs to expose to the instance.
 	//
@@ -311,6 +443,17 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Prevents the instance from being deleted
 	"security.protection.delete": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=security; key=security.session_recording)
+	// When enabled, the output of interactive console and `exec` sessions is recorded in asciicast v2 format into the log directory of the instance.
+	// Changing this option requires the `can_edit` entitlement on the project.
+	// See {ref}`instance-options-security-session-recording` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  shortdesc: Whether to record interactive console and `exec` sessions
+	"security.session_recording": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=snapshots; key=snapshots.schedule)
 	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable automatic snapshots.
 	//
@@ -392,6 +535,22 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	"volatile.last_state.power": validate.IsAny,
 	"volatile.last_state.ready": validate.IsBool,
 	"volatile.apply_quota":      validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.last_state.health)
+	// Possible values are `healthy` and `unhealthy`.
+	// The status is cleared when the instance starts or stops.
+	// ---
+	//  type: string
+	//  shortdesc: Instance health as of the last health check
+	"volatile.last_state.health": validate.Optional(validate.IsOneOf("healthy", "unhealthy")),
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.restart_policy.attempts)
+	//
+	// ---
+	//  type: integer
+	//  shortdesc: Number of consecutive restarts done by the restart policy
+	"volatile.restart_policy.attempts": validate.Optional(validate.IsUint32),
+
 	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.uuid)
 	// The instance UUID is globally unique across all servers and projects.
 	// ---
@@ -915,6 +1074,38 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Whether to back the instance using huge pages
 	"limits.memory.hugepages": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.cpu.pin_strategy)
+	// Possible values are `none` (QEMU emulator and I/O threads aren't pinned), `shared` (QEMU emulator and I/O threads are pinned to the CPUs used by the vCPU threads) and `isolated` (QEMU emulator and I/O threads are pinned to the CPUs set in `limits.cpu.emulator`).
+	//
+	// See {ref}`instance-options-limits-cpu-vm` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `none`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: How to pin the QEMU emulator and I/O threads
+	"limits.cpu.pin_strategy": validate.Optional(validate.IsOneOf("none", "shared", "isolated")),
+
+	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.cpu.emulator)
+	// A comma-separated list of host CPU IDs or ranges to run the QEMU emulator and I/O threads on.
+	// The CPUs must not overlap with the pinned vCPUs in `limits.cpu`.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: virtual machine and `limits.cpu.pin_strategy` is `isolated`
+	//  shortdesc: Which CPUs to run the QEMU emulator and I/O threads on
+	"limits.cpu.emulator": validate.Optional(validate.IsValidCPUSet),
+
+	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.cpu.iothreads)
+	// The SCSI controller uses the first I/O thread and `virtio-blk` disks are spread across all of them.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Number of QEMU I/O threads for disk devices
+	"limits.cpu.iothreads": validate.Optional(validate.IsInRange(0, 64)),
+
 	// lxdmeta:generate(entities=instance; group=migration; key=migration.stateful)
 	// Enabling this option prevents the use of some features that are incompatible with it.
 	// ---
@@ -925,6 +1116,17 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Whether to allow for stateful stop/start and snapshots
 	"migration.stateful": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=migration; key=migration.fork)
+	// When enabled, the instance memory is backed by a file so that the running instance can be forked into clones that share its memory copy-on-write.
+	// This option requires `migration.stateful` and is only supported on `x86_64`. It can't be combined with `limits.memory.hugepages`, `security.sev`, `security.tdx` or with CPU pinning across multiple NUMA nodes.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Whether to allow forking the running instance
+	"migration.fork": validate.Optional(validate.IsBool),
+
 	// Caller is responsible for full validation of any raw.* value.
 
 	// lxdmeta:generate(entities=instance; group=raw; key=raw.qemu)
@@ -995,6 +1197,19 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Whether AMD SEV-ES (SEV Encrypted State) is enabled for this VM
 	"security.sev.policy.es": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=security; key=security.sev.snp)
+	// This option requires {config:option}`instance-security:security.sev` to be enabled, as well as a host kernel and firmware with SEV-SNP support.
+	// The guest firmware must also support SEV-SNP, and {config:option}`instance-security:security.secureboot` must be disabled.
+	//
+	// See {ref}`instance-options-security-confidential` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Whether AMD SEV-SNP (SEV Secure Nested Paging) is enabled for this VM
+	"security.sev.snp": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=security; key=security.sev.session.dh)
 	//
 	// ---
@@ -1015,6 +1230,19 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: The guest owner's `base64`-encoded session blob
 	"security.sev.session.data": validate.Optional(validate.IsAny),
 
+	// lxdmeta:generate(entities=instance; group=security; key=security.tdx)
+	// This option requires a host kernel with Intel TDX support and a guest firmware built for TDX.
+	// It can't be combined with {config:option}`instance-security:security.sev`, and {config:option}`instance-security:security.secureboot` must be disabled.
+	//
+	// See {ref}`instance-options-security-confidential` for more information.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Whether Intel TDX (Trust Domain Extensions) is enabled for this VM
+	"security.tdx": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=miscellaneous; key=user.*)
 	// User keys can be used in search.
 	// ---
@@ -1047,6 +1275,20 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  shortdesc: Instance `vsock ID` used as of last start
 	"volatile.vsock_id": validate.Optional(validate.IsInt64),
 
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.fork.base)
+	//
+	// ---
+	//  type: string
+	//  shortdesc: Fork base created from the paused instance
+	"volatile.fork.base": validate.Optional(validate.IsUUID),
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.fork.restore)
+	//
+	// ---
+	//  type: string
+	//  shortdesc: Fork base to restore the instance from on its next start
+	"volatile.fork.restore": validate.Optional(validate.IsUUID),
+
 	// lxdmeta:generate(entities=instance; group=boot; key=boot.debug_edk2)
 	// The instance should use a debug version of the `edk2`.
 	// A log file can be found in `$LXD_DIR/logs/<instance_name>/edk2.log`.
@@ -1054,6 +1296,17 @@ var InstanceConfigKeysVM = map[string]func(value string) error{
 	//  type: bool
 	//  shortdesc: Enable debug version of the `edk2`
 	"boot.debug_edk2": validate.Optional(validate.IsBool),
+
+	// lxdmeta:generate(entities=instance; group=boot; key=boot.recovery)
+	// Possible values are `none` and `restart`.
+	// If set to `restart`, LXD restarts the instance when its watchdog fires.
+	// See {ref}`devices-watchdog-recovery` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `none`
+	//  liveupdate: yes
+	//  shortdesc: What to do when the watchdog of the instance fires
+	"boot.recovery": validate.Optional(validate.IsOneOf("none", "restart")),
 }
 
 // ConfigKeyChecker returns a function that will check whether or not
@@ -1198,6 +1451,15 @@ func ConfigKeyChecker(key string, instanceType Type) (func(value string) error,
 			return validate.IsAny, nil
 		}
 
+		// lxdmeta:generate(entities=instance; group=volatile; key=volatile.<name>.last_state.pty)
+		// The major and minor numbers of the pty backing a container serial device.
+		// ---
+		//  type: string
+		//  shortdesc: Pty device number of a serial device
+		if strings.HasSuffix(key, ".last_state.pty") {
+			return validate.IsAny, nil
+		}
+
 		if strings.HasSuffix(key, ".driver") {
 			return validate.IsAny, nil
 		}
@@ -1211,6 +1473,122 @@ func ConfigKeyChecker(key string, instanceType Type) (func(value string) error,
 		}
 	}
 
+	if strings.HasPrefix(key, "schedule.") && strings.Count(key, ".") == 2 {
+		// lxdmeta:generate(entities=instance; group=schedule; key=schedule.<name>.action)
+		// Possible values are `start`, `stop`, `restart`, `snapshot` and `exec`.
+		// ---
+		//  type: string
+		//  liveupdate: yes
+		//  shortdesc: Action to run on the instance
+		if strings.HasSuffix(key, ".action") {
+			return validate.Optional(validate.IsOneOf("start", "stop", "restart", "snapshot", "exec")), nil
+		}
+
+		// lxdmeta:generate(entities=instance; group=schedule; key=schedule.<name>.cron)
+		// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`) or a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`).
+		// ---
+		//  type: string
+		//  liveupdate: yes
+		//  shortdesc: When to run the action
+		if strings.HasSuffix(key, ".cron") {
+			return validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly", "@never"})), nil
+		}
+
+		// lxdmeta:generate(entities=instance; group=schedule; key=schedule.<name>.command)
+		// The command is run through `sh -c` inside the instance, which must be running.
+		// Setting the command requires the `can_exec` entitlement on the instance, or `can_operate_instances` on the project for new instances, profiles and instance templates.
+		// ---
+		//  type: string
+		//  liveupdate: yes
+		//  condition: `schedule.<name>.action` is `exec`
+		//  shortdesc: Command to run inside the instance
+		if strings.HasSuffix(key, ".command") {
+			return validate.IsAny, nil
+		}
+
+		// lxdmeta:generate(entities=instance; group=schedule; key=schedule.<name>.timeout)
+		// If the command is still running after this number of seconds, it is killed and the run is recorded as failed.
+		// ---
+		//  type: integer
+		//  defaultdesc: `3600`
+		//  liveupdate: yes
+		//  condition: `schedule.<name>.action` is `exec`
+		//  shortdesc: Number of seconds after which the command is killed
+		if strings.HasSuffix(key, ".timeout") {
+			return validate.Optional(validate.IsInRange(1, math.MaxUint32)), nil
+		}
+	}
+
+	if (instanceType == Any || instanceType == VM) && strings.HasPrefix(key, "limits.numa.") && strings.Count(key, ".") == 3 {
+		fields := strings.Split(key, ".")
+		_, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid guest NUMA node ID in configuration key: %s", key)
+		}
+
+		// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.numa.<node>.cpus)
+		// A comma-separated list of vCPU indexes or ranges to place on the guest NUMA node.
+		// Every vCPU must belong to exactly one guest NUMA node.
+		//
+		// See {ref}`instance-options-limits-numa` for more information.
+		// ---
+		//  type: string
+		//  liveupdate: no
+		//  condition: virtual machine
+		//  shortdesc: Which vCPUs belong to the guest NUMA node
+		if strings.HasSuffix(key, ".cpus") {
+			return validate.Optional(validate.IsValidCPUSet), nil
+		}
+
+		// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.numa.<node>.host_node)
+		// The memory of the guest NUMA node is bound to this host NUMA node.
+		// This option is required for every guest NUMA node.
+		// ---
+		//  type: integer
+		//  liveupdate: no
+		//  condition: virtual machine
+		//  shortdesc: Host NUMA node backing the guest NUMA node
+		if strings.HasSuffix(key, ".host_node") {
+			return validate.Optional(validate.IsUint32), nil
+		}
+
+		// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.numa.<node>.memory)
+		// Fixed value in bytes, which must be a whole number of MiB. Various suffixes are supported (see {ref}`instances-limit-units`).
+		// The memory of the guest NUMA nodes must add up to `limits.memory` if set on all of them.
+		// ---
+		//  type: string
+		//  defaultdesc: Remaining `limits.memory` split evenly across the guest NUMA nodes without this option
+		//  liveupdate: no
+		//  condition: virtual machine
+		//  shortdesc: Amount of memory on the guest NUMA node
+		if strings.HasSuffix(key, ".memory") {
+			return validate.Optional(func(value string) error {
+				size, err := units.ParseByteSizeString(value)
+				if err != nil {
+					return err
+				}
+
+				if size <= 0 || size%(1024*1024) != 0 {
+					return fmt.Errorf("Memory of the guest NUMA node must be a positive whole number of MiB")
+				}
+
+				return nil
+			}), nil
+		}
+
+		// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.numa.<node>.hugepages)
+		// Size of the huge pages backing the memory of the guest NUMA node, for example `2MiB` or `1GiB`.
+		// A `hugetlbfs` mount with that page size must exist on the host.
+		// ---
+		//  type: string
+		//  liveupdate: no
+		//  condition: virtual machine
+		//  shortdesc: Huge page size for the guest NUMA node
+		if strings.HasSuffix(key, ".hugepages") {
+			return validate.Optional(validate.IsSize), nil
+		}
+	}
+
 	if strings.HasPrefix(key, "environment.") {
 		return validate.IsAny, nil
 	}
@@ -0,0 +1,37 @@
+package instancetype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigKeyCheckerSchedule(t *testing.T) {
+	checker, err := ConfigKeyChecker("schedule.nightly.action", Any)
+	require.NoError(t, err)
+	assert.NoError(t, checker("stop"))
+	assert.Error(t, checker("pause"))
+
+	checker, err = ConfigKeyChecker("schedule.nightly.cron", Any)
+	require.NoError(t, err)
+	assert.NoError(t, checker("0 20 * * 1-5"))
+	assert.NoError(t, checker("@daily"))
+	assert.Error(t, checker("@startup"))
+
+	_, err = ConfigKeyChecker("schedule.nightly.other", Any)
+	assert.Error(t, err)
+
+	_, err = ConfigKeyChecker("schedule.action", Any)
+	assert.Error(t, err)
+}
+
+func TestConfigKeyCheckerNumaMemory(t *testing.T) {
+	checker, err := ConfigKeyChecker("limits.numa.0.memory", VM)
+	require.NoError(t, err)
+	assert.NoError(t, checker("2GiB"))
+	assert.NoError(t, checker("1536MiB"))
+	assert.Error(t, checker("1000kB"))
+	assert.Error(t, checker("1048577"))
+	assert.Error(t, checker("0"))
+}
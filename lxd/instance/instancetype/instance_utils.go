@@ -1,7 +1,10 @@
 package instancetype
 
 import (
+	"fmt"
+	"net/url"
 	"strconv"
+	"strings"
 
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/shared/api"
@@ -65,3 +68,65 @@ func ExpandInstanceDevices(devices deviceConfig.Devices, profiles []api.Profile)
 
 	return expandedDevices
 }
+
+// Instance dependency conditions.
+const (
+	DependencyRunning = "running"
+	DependencyReady   = "ready"
+	DependencyHealthy = "healthy"
+)
+
+// InstanceDependency is an instance of the same project that must be running, and optionally ready or
+// healthy, before an instance is started.
+type InstanceDependency struct {
+	Name      string
+	Condition string
+}
+
+// ParseInstanceDependencies parses a comma separated list of "<instance>[:<condition>]" entries, as used by the
+// boot.depends_on config key. The condition defaults to "running".
+func ParseInstanceDependencies(value string) ([]InstanceDependency, error) {
+	deps := []InstanceDependency{}
+	if strings.TrimSpace(value) == "" {
+		return deps, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		name, condition, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		if name == "" {
+			return nil, fmt.Errorf("Missing instance name in dependency %q", entry)
+		}
+
+		if condition == "" {
+			condition = DependencyRunning
+		}
+
+		if condition != DependencyRunning && condition != DependencyReady && condition != DependencyHealthy {
+			return nil, fmt.Errorf("Invalid condition %q for dependency on %q", condition, name)
+		}
+
+		deps = append(deps, InstanceDependency{Name: name, Condition: condition})
+	}
+
+	return deps, nil
+}
+
+// ParseHealthcheckTarget parses a "<port>[/<path>]" health check target, as used by the healthcheck.target config
+// key. The path defaults to "/".
+func ParseHealthcheckTarget(value string) (port string, path string, err error) {
+	port, path, _ = strings.Cut(value, "/")
+
+	portNum, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || portNum == 0 {
+		return "", "", fmt.Errorf("Invalid port %q in health check target %q", port, value)
+	}
+
+	path = "/" + path
+
+	u, err := url.ParseRequestURI(path)
+	if err != nil || u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		return "", "", fmt.Errorf("Invalid path %q in health check target %q", path, value)
+	}
+
+	return port, path, nil
+}
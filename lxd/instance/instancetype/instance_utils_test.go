@@ -0,0 +1,45 @@
+package instancetype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInstanceDependencies(t *testing.T) {
+	deps, err := ParseInstanceDependencies("")
+	require.NoError(t, err)
+	assert.Empty(t, deps)
+
+	deps, err = ParseInstanceDependencies("db:ready, cache,web:healthy")
+	require.NoError(t, err)
+	assert.Equal(t, []InstanceDependency{
+		{Name: "db", Condition: DependencyReady},
+		{Name: "cache", Condition: DependencyRunning},
+		{Name: "web", Condition: DependencyHealthy},
+	}, deps)
+
+	_, err = ParseInstanceDependencies("db:up")
+	assert.Error(t, err)
+
+	_, err = ParseInstanceDependencies("db,,cache")
+	assert.Error(t, err)
+}
+
+func TestParseHealthcheckTarget(t *testing.T) {
+	port, path, err := ParseHealthcheckTarget("8080")
+	require.NoError(t, err)
+	assert.Equal(t, "8080", port)
+	assert.Equal(t, "/", path)
+
+	port, path, err = ParseHealthcheckTarget("80/healthz?full=1")
+	require.NoError(t, err)
+	assert.Equal(t, "80", port)
+	assert.Equal(t, "/healthz?full=1", path)
+
+	for _, value := range []string{"", "0", "65536", ":8080", "10.0.0.1:80", "http://10.0.0.1/", "/healthz", "80/%zz"} {
+		_, _, err = ParseHealthcheckTarget(value)
+		assert.Error(t, err, value)
+	}
+}
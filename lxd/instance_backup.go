@@ -314,6 +314,16 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Backup names may not contain slashes"))
 	}
 
+	if req.Stateful {
+		if inst.Type() != instancetype.Container {
+			return response.BadRequest(fmt.Errorf("Stateful backups are only supported for containers"))
+		}
+
+		if !inst.IsRunning() {
+			return response.BadRequest(fmt.Errorf("Unable to create a stateful backup. The instance isn't running"))
+		}
+	}
+
 	fullName := name + shared.SnapshotDelimiter + req.Name
 	instanceOnly := req.InstanceOnly || req.ContainerOnly
 
@@ -325,6 +335,7 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 			ExpiryDate:           req.ExpiresAt,
 			InstanceOnly:         instanceOnly,
 			OptimizedStorage:     req.OptimizedStorage,
+			Stateful:             req.Stateful,
 			CompressionAlgorithm: req.CompressionAlgorithm,
 		}
 
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -19,11 +20,14 @@ import (
 
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/device"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
@@ -61,6 +65,12 @@ type consoleWs struct {
 
 	// channel type (either console or vga)
 	protocol string
+
+	// serial device to attach to instead of the main console
+	port string
+
+	// state used to emit lifecycle events
+	state *state.State
 }
 
 // Metadata returns a map of metadata.
@@ -207,8 +217,18 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 	defer logger.Debug("Console websocket finished")
 	<-s.allConnected
 
-	// Get console from instance.
-	console, consoleDisconnectCh, err := s.instance.Console(s.protocol)
+	var console *os.File
+	var consoleDisconnectCh chan error
+	var err error
+
+	if s.port != "" {
+		// Get console from the serial device.
+		console, consoleDisconnectCh, err = s.serialConsole()
+	} else {
+		// Get console from instance.
+		console, consoleDisconnectCh, err = s.instance.Console(s.protocol)
+	}
+
 	if err != nil {
 		return err
 	}
@@ -220,6 +240,18 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 		_ = shared.SetSize(int(console.Fd()), s.width, s.height)
 	}
 
+	// Record the console session if enabled.
+	var consoleRWC io.ReadWriteCloser = console
+	recorder, err := newSessionRecorder(s.state, s.instance, op, instance.ConsoleTypeConsole, s.width, s.height, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	if recorder != nil {
+		defer func() { _ = recorder.Close() }()
+		consoleRWC = recorder.wrap(console)
+	}
+
 	consoleDoneCh := make(chan struct{})
 
 	// Wait for control socket to connect and then read messages from the remote side in a loop.
@@ -275,6 +307,10 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 					continue
 				}
 
+				if recorder != nil {
+					recorder.resize(winchWidth, winchHeight)
+				}
+
 				logger.Debugf("Set window size to: %dx%d", winchWidth, winchHeight)
 			}
 		}
@@ -291,7 +327,7 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 		defer l.Debug("Finished mirroring websocket to console")
 
 		l.Debug("Started mirroring websocket")
-		readDone, writeDone := ws.Mirror(conn, console)
+		readDone, writeDone := ws.Mirror(conn, consoleRWC)
 
 		<-readDone
 		l.Debug("Finished mirroring console to websocket")
@@ -322,15 +358,19 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 	// Write a reset escape sequence to the console to cancel any ongoing reads to the handle
 	// and then close it. This ordering is important, close the console before closing the
 	// websocket to ensure console doesn't get stuck reading.
-	_, err = console.Write([]byte("\x1bc"))
-	if err != nil {
-		_ = console.Close()
-		return err
-	}
+	// Serial device connections are closed on disconnection instead, as the reset sequence would be
+	// sent to the software using the serial port.
+	if s.port == "" {
+		_, err = console.Write([]byte("\x1bc"))
+		if err != nil {
+			_ = console.Close()
+			return err
+		}
 
-	err = console.Close()
-	if err != nil {
-		return err
+		err = console.Close()
+		if err != nil {
+			return err
+		}
 	}
 
 	// Indicate to the control socket go routine to end if not already.
@@ -338,6 +378,42 @@ func (s *consoleWs) doConsole(op *operations.Operation) error {
 	return nil
 }
 
+// serialConsole connects to the host socket the serial device of the instance is exposed on.
+func (s *consoleWs) serialConsole() (*os.File, chan error, error) {
+	network, address, err := device.SerialConsoleAddress(s.instance, s.port)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed connecting to serial device %q: %w", s.port, err)
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	fileConn, ok := conn.(interface{ File() (*os.File, error) })
+	if !ok {
+		return nil, nil, fmt.Errorf("Unsupported serial device connection type %T", conn)
+	}
+
+	file, err := fileConn.File()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed getting serial device socket file: %w", err)
+	}
+
+	// Close the connection to the serial device when the console disconnects.
+	chDisconnect := make(chan error, 1)
+	go func() {
+		<-chDisconnect
+		_ = file.Close()
+	}()
+
+	s.state.Events.SendLifecycle(s.instance.Project().Name, lifecycle.InstanceConsole.Event(s.instance, logger.Ctx{"type": s.protocol, "port": s.port}))
+
+	return file, chDisconnect, nil
+}
+
 func (s *consoleWs) doVGA(op *operations.Operation) error {
 	defer logger.Debug("VGA websocket finished")
 
@@ -485,6 +561,17 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("VGA console is only supported by virtual machines"))
 	}
 
+	if post.Port != "" {
+		if post.Type != instance.ConsoleTypeConsole {
+			return response.BadRequest(fmt.Errorf("Serial devices can only be attached to with the console type"))
+		}
+
+		_, _, err = device.SerialConsoleAddress(inst, post.Port)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	if !inst.IsRunning() {
 		return response.BadRequest(fmt.Errorf("Instance is not running"))
 	}
@@ -512,6 +599,8 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 	ws.width = post.Width
 	ws.height = post.Height
 	ws.protocol = post.Type
+	ws.port = post.Port
+	ws.state = s
 
 	resources := map[string][]api.URL{}
 	resources["instances"] = []api.URL{*api.NewURL().Path(version.APIVersion, "instances", ws.instance.Name())}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// instanceDependenciesDefaultTimeout is how long to wait for the dependencies of an instance when
+// boot.depends_on.timeout isn't set.
+const instanceDependenciesDefaultTimeout = 300 * time.Second
+
+// instanceDependencies returns the dependencies of the instance, ignoring invalid ones.
+func instanceDependencies(inst instance.Instance) []instancetype.InstanceDependency {
+	deps, _ := instancetype.ParseInstanceDependencies(inst.ExpandedConfig()["boot.depends_on"])
+	return deps
+}
+
+// instancesSortByDependencies returns the instances ordered so that each instance comes after the instances of
+// the same project it depends on, or before them if dependentsFirst is true. The given order is kept otherwise,
+// and for the instances part of a dependency cycle.
+func instancesSortByDependencies(instances []instance.Instance, dependentsFirst bool) []instance.Instance {
+	index := make(map[string]int, len(instances))
+	for i, inst := range instances {
+		index[project.Instance(inst.Project().Name, inst.Name())] = i
+	}
+
+	// Record the edges to follow, from each instance to those that must come before it.
+	before := make([][]int, len(instances))
+	for i, inst := range instances {
+		for _, dep := range instanceDependencies(inst) {
+			j, ok := index[project.Instance(inst.Project().Name, dep.Name)]
+			if !ok || i == j {
+				continue
+			}
+
+			if dependentsFirst {
+				before[j] = append(before[j], i)
+			} else {
+				before[i] = append(before[i], j)
+			}
+		}
+	}
+
+	sorted := make([]instance.Instance, 0, len(instances))
+	visited := make([]bool, len(instances))
+
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+
+		visited[i] = true
+		for _, j := range before[i] {
+			visit(j)
+		}
+
+		sorted = append(sorted, instances[i])
+	}
+
+	for i := range instances {
+		visit(i)
+	}
+
+	return sorted
+}
+
+// instanceDependenciesCheck returns an error describing the first dependency of the instance whose condition
+// isn't met. The dependencies can be located on any cluster member. The live state of local dependencies is used,
+// while the state of remote ones is read from their volatile keys.
+func instanceDependenciesCheck(ctx context.Context, s *state.State, inst instance.Instance) error {
+	for _, dep := range instanceDependencies(inst) {
+		var location string
+		var config map[string]string
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			dbInst, err := dbCluster.GetInstance(ctx, tx.Tx(), inst.Project().Name, dep.Name)
+			if err != nil {
+				return err
+			}
+
+			location = dbInst.Node
+			config, err = dbCluster.GetInstanceConfig(ctx, tx.Tx(), dbInst.ID)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed loading dependency %q: %w", dep.Name, err)
+		}
+
+		running := config["volatile.last_state.power"] == instance.PowerStateRunning
+		health := config["volatile.last_state.health"]
+
+		if !s.ServerClustered || location == s.ServerName {
+			depInst, err := instance.LoadByProjectAndName(s, inst.Project().Name, dep.Name)
+			if err != nil {
+				return fmt.Errorf("Failed loading dependency %q: %w", dep.Name, err)
+			}
+
+			running = depInst.IsRunning()
+			health = instanceHealthcheckStatus(depInst)
+		}
+
+		if !running {
+			return fmt.Errorf("Dependency %q isn't running", dep.Name)
+		}
+
+		if dep.Condition == instancetype.DependencyReady && config["volatile.last_state.ready"] != "true" {
+			return fmt.Errorf("Dependency %q isn't ready", dep.Name)
+		}
+
+		if dep.Condition == instancetype.DependencyHealthy && health != "healthy" {
+			return fmt.Errorf("Dependency %q isn't healthy", dep.Name)
+		}
+	}
+
+	return nil
+}
+
+// instanceDependenciesTimeout returns how long to wait for the dependencies of the instance.
+func instanceDependenciesTimeout(inst instance.Instance) time.Duration {
+	timeout, err := strconv.ParseUint(inst.ExpandedConfig()["boot.depends_on.timeout"], 10, 32)
+	if err != nil {
+		return instanceDependenciesDefaultTimeout
+	}
+
+	return time.Duration(timeout) * time.Second
+}
+
+// instanceDependenciesWait waits for the conditions of the dependencies of the instance to be met.
+func instanceDependenciesWait(ctx context.Context, s *state.State, inst instance.Instance) error {
+	if len(instanceDependencies(inst)) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, instanceDependenciesTimeout(inst))
+	defer cancel()
+
+	for {
+		err := instanceDependenciesCheck(ctx, s, inst)
+		if err == nil {
+			return nil
+		}
+
+		// Don't wait for instances that don't exist.
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return fmt.Errorf("Dependencies of instance %q not met: %w", inst.Name(), err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Dependencies of instance %q not met: %w", inst.Name(), err)
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// instanceDependentsWaitStopped waits for the instances among the given ones that depend on the instance to stop.
+func instanceDependentsWaitStopped(ctx context.Context, inst instance.Instance, instances []instance.Instance) error {
+	dependents := []instance.Instance{}
+	for _, other := range instances {
+		if other.Project().Name != inst.Project().Name || other.Name() == inst.Name() {
+			continue
+		}
+
+		for _, dep := range instanceDependencies(other) {
+			if dep.Name == inst.Name() {
+				dependents = append(dependents, other)
+				break
+			}
+		}
+	}
+
+	if len(dependents) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, instanceDependenciesTimeout(inst))
+	defer cancel()
+
+	for {
+		running := ""
+		for _, dependent := range dependents {
+			if dependent.IsRunning() {
+				running = dependent.Name()
+				break
+			}
+		}
+
+		if running == "" {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Instance %q depending on %q is still running", running, inst.Name())
+		case <-time.After(time.Second):
+		}
+	}
+}
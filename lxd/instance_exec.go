@@ -260,6 +260,7 @@ func (s *execWs) Do(op *operations.Operation) error {
 
 	waitAttachedChildIsDead, markAttachedChildIsDead := context.WithCancel(context.Background())
 	var wgEOF sync.WaitGroup
+	var recorder *sessionRecorder
 
 	// Define a function to clean up TTYs and sockets when done.
 	finisher := func(cmdResult int, cmdErr error) error {
@@ -289,6 +290,10 @@ func (s *execWs) Do(op *operations.Operation) error {
 			_ = pty.Close()
 		}
 
+		if recorder != nil {
+			_ = recorder.Close()
+		}
+
 		metadata := shared.Jmap{"return": cmdResult}
 		err = op.ExtendMetadata(metadata)
 		if err != nil {
@@ -298,6 +303,14 @@ func (s *execWs) Do(op *operations.Operation) error {
 		return cmdErr
 	}
 
+	// Record interactive sessions if enabled.
+	if s.req.Interactive {
+		recorder, err = newSessionRecorder(s.s, s.instance, op, "exec", s.req.Width, s.req.Height, s.req.Command, s.req.Environment)
+		if err != nil {
+			return finisher(-1, err)
+		}
+	}
+
 	cmd, err := s.instance.Exec(s.req, stdin, stdout, stderr)
 	if err != nil {
 		return finisher(-1, err)
@@ -394,6 +407,10 @@ func (s *execWs) Do(op *operations.Operation) error {
 					l.Debug("Failed to set window size", logger.Ctx{"err": err, "width": winchWidth, "height": winchHeight})
 					continue
 				}
+
+				if recorder != nil {
+					recorder.resize(winchWidth, winchHeight)
+				}
 			} else if command.Command == "signal" {
 				err := cmd.Signal(unix.Signal(command.Signal))
 				if err != nil {
@@ -424,9 +441,19 @@ func (s *execWs) Do(op *operations.Operation) error {
 			if s.instance.Type() == instancetype.Container {
 				// For containers, we are running the command via the local LXD managed PTY and so
 				// need to use the same PTY handle for both read and write.
-				readDone, writeDone = ws.Mirror(conn, shared.NewExecWrapper(waitAttachedChildIsDead, ptys[0]))
+				var pty io.ReadWriteCloser = shared.NewExecWrapper(waitAttachedChildIsDead, ptys[0])
+				if recorder != nil {
+					pty = recorder.wrap(pty)
+				}
+
+				readDone, writeDone = ws.Mirror(conn, pty)
 			} else {
-				readDone = ws.MirrorRead(conn, ptys[execWSStdout])
+				var stdout io.Reader = ptys[execWSStdout]
+				if recorder != nil {
+					stdout = io.TeeReader(stdout, recorder)
+				}
+
+				readDone = ws.MirrorRead(conn, stdout)
 				writeDone = ws.MirrorWrite(conn, ttys[execWSStdin])
 			}
 
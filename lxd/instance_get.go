@@ -152,5 +152,7 @@ func instanceGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	instanceRedact(state)
+
 	return response.SyncResponseETag(true, state, etag)
 }
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// instanceHealthcheck tracks the health checks of a running instance.
+type instanceHealthcheck struct {
+	started   time.Time // Start time of the instance run the checks apply to.
+	lastCheck time.Time
+	failures  uint64
+	running   bool
+	status    string
+}
+
+var instanceHealthchecks = map[int]*instanceHealthcheck{}
+var instanceHealthchecksMu sync.Mutex
+
+// instanceHealthchecksTask returns a task that checks the health of the local instances configured for it.
+func instanceHealthchecksTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		instances, err := instance.LoadNodeAll(s, instancetype.Any)
+		if err != nil {
+			logger.Warn("Failed loading instances for health checks", logger.Ctx{"err": err})
+			return
+		}
+
+		instanceHealthchecksMu.Lock()
+		defer instanceHealthchecksMu.Unlock()
+
+		checked := map[int]bool{}
+		for _, inst := range instances {
+			if inst.ExpandedConfig()["healthcheck.type"] == "" || !inst.IsRunning() {
+				continue
+			}
+
+			checked[inst.ID()] = true
+
+			// Give the instance one interval to come up before checking it for the first time.
+			// The checks start afresh when the instance was restarted since the last check.
+			check, ok := instanceHealthchecks[inst.ID()]
+			if !ok || !check.started.Equal(inst.LastUsedDate()) {
+				instanceHealthchecks[inst.ID()] = &instanceHealthcheck{started: inst.LastUsedDate(), lastCheck: time.Now()}
+				continue
+			}
+
+			interval := instanceHealthcheckConfig(inst, "healthcheck.interval", 30)
+			if check.running || time.Since(check.lastCheck) < time.Duration(interval)*time.Second {
+				continue
+			}
+
+			check.lastCheck = time.Now()
+			check.running = true
+
+			go instanceHealthcheckRun(ctx, s, inst, check)
+		}
+
+		// Forget about the instances that stopped or aren't checked anymore so they start afresh.
+		for id, check := range instanceHealthchecks {
+			if !checked[id] && !check.running {
+				delete(instanceHealthchecks, id)
+			}
+		}
+	}
+
+	return f, task.Every(10 * time.Second)
+}
+
+// instanceHealthcheckStatus returns the health status of the current run of a local instance, based on the results
+// of its health checks. Returns an empty string if the instance wasn't checked since it started.
+func instanceHealthcheckStatus(inst instance.Instance) string {
+	instanceHealthchecksMu.Lock()
+	defer instanceHealthchecksMu.Unlock()
+
+	check, ok := instanceHealthchecks[inst.ID()]
+	if !ok || !check.started.Equal(inst.LastUsedDate()) {
+		return ""
+	}
+
+	return check.status
+}
+
+// instanceHealthcheckConfig returns the value of an unsigned integer health check setting or its default.
+func instanceHealthcheckConfig(inst instance.Instance, key string, defaultValue uint64) uint64 {
+	value, err := strconv.ParseUint(inst.ExpandedConfig()[key], 10, 32)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+// instanceHealthcheckRun checks the health of the instance, records its health status and applies the
+// configured action when the instance becomes unhealthy.
+func instanceHealthcheckRun(ctx context.Context, s *state.State, inst instance.Instance, check *instanceHealthcheck) {
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	timeout := time.Duration(instanceHealthcheckConfig(inst, "healthcheck.timeout", 5)) * time.Second
+	checkErr := instanceHealthcheckProbe(ctx, inst, timeout)
+
+	instanceHealthchecksMu.Lock()
+	check.running = false
+	if checkErr == nil {
+		check.failures = 0
+	} else {
+		check.failures++
+	}
+
+	failures := check.failures
+	retries := instanceHealthcheckConfig(inst, "healthcheck.retries", 3)
+	restart := checkErr != nil && failures >= retries && inst.ExpandedConfig()["healthcheck.action"] == "restart"
+	if restart {
+		// Give the instance a fresh set of retries once restarted.
+		check.failures = 0
+	}
+
+	previousStatus := check.status
+	if checkErr == nil {
+		check.status = "healthy"
+	} else if failures >= retries {
+		check.status = "unhealthy"
+	}
+
+	status := check.status
+
+	// Don't record the status of a previous run of the instance.
+	current := instanceHealthchecks[inst.ID()] == check
+	instanceHealthchecksMu.Unlock()
+
+	if checkErr != nil {
+		l.Debug("Instance health check failed", logger.Ctx{"failures": failures, "err": checkErr})
+	}
+
+	if !current {
+		return
+	}
+
+	if status != "" && status != previousStatus {
+		err := inst.VolatileSet(map[string]string{"volatile.last_state.health": status})
+		if err != nil {
+			l.Warn("Failed recording instance health", logger.Ctx{"err": err})
+		}
+
+		ctxMap := logger.Ctx{"status": status}
+		if checkErr != nil {
+			ctxMap["reason"] = checkErr.Error()
+			l.Warn("Instance is unhealthy", logger.Ctx{"err": checkErr})
+		} else {
+			l.Info("Instance is healthy")
+		}
+
+		s.Events.SendLifecycle(inst.Project().Name, lifecycle.InstanceHealthChanged.Event(inst, ctxMap))
+	}
+
+	if !restart {
+		return
+	}
+
+	l.Info("Restarting unhealthy instance")
+
+	shutdownTimeout := instanceHealthcheckConfig(inst, "boot.host_shutdown_timeout", 30)
+	err := inst.Restart(time.Duration(shutdownTimeout) * time.Second)
+	if err != nil {
+		l.Error("Failed restarting unhealthy instance", logger.Ctx{"err": err})
+	}
+}
+
+// instanceHealthcheckClient is the HTTP client of the health checks.
+// It doesn't use a proxy or follow redirects so that the requests only ever reach the instance.
+var instanceHealthcheckClient = &http.Client{
+	Transport: &http.Transport{},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// instanceHealthcheckProbe runs the configured health check against the instance.
+func instanceHealthcheckProbe(ctx context.Context, inst instance.Instance, timeout time.Duration) error {
+	config := inst.ExpandedConfig()
+
+	switch config["healthcheck.type"] {
+	case "exec":
+		return instanceHealthcheckExec(inst, config["healthcheck.command"], timeout)
+
+	case "tcp", "http":
+		port, path, err := instancetype.ParseHealthcheckTarget(config["healthcheck.target"])
+		if err != nil {
+			return err
+		}
+
+		address, err := instanceHealthcheckAddress(inst)
+		if err != nil {
+			return err
+		}
+
+		host := net.JoinHostPort(address, port)
+
+		if config["healthcheck.type"] == "tcp" {
+			conn, err := net.DialTimeout("tcp", host, timeout)
+			if err != nil {
+				return err
+			}
+
+			return conn.Close()
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+host+path, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := instanceHealthcheckClient.Do(req)
+		if err != nil {
+			return err
+		}
+
+		_ = resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+			return fmt.Errorf("Unexpected HTTP status %q", resp.Status)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("Unknown health check type %q", config["healthcheck.type"])
+}
+
+// instanceHealthcheckExec runs the health check command inside the instance and checks its exit status.
+func instanceHealthcheckExec(inst instance.Instance, command string, timeout time.Duration) error {
+	if command == "" {
+		return fmt.Errorf("No health check command configured")
+	}
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = devNull.Close() }()
+
+	req := api.InstanceExecPost{
+		Command:     []string{"sh", "-c", command},
+		Environment: instanceCommandEnvironment(),
+	}
+
+	cmd, err := inst.Exec(req, devNull, devNull, devNull)
+	if err != nil {
+		return err
+	}
+
+	type result struct {
+		exitStatus int
+		err        error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		exitStatus, err := cmd.Wait()
+		done <- result{exitStatus: exitStatus, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return res.err
+		}
+
+		if res.exitStatus != 0 {
+			return fmt.Errorf("Health check command exited with status %d", res.exitStatus)
+		}
+
+		return nil
+	case <-time.After(timeout):
+		_ = cmd.Signal(unix.SIGKILL)
+		return fmt.Errorf("Health check command timed out after %s", timeout)
+	}
+}
+
+// instanceHealthcheckAddress returns the first global address of the instance.
+func instanceHealthcheckAddress(inst instance.Instance) (string, error) {
+	hostInterfaces, _ := net.Interfaces()
+	instState, err := inst.RenderState(hostInterfaces)
+	if err != nil {
+		return "", err
+	}
+
+	names := make([]string, 0, len(instState.Network))
+	for name := range instState.Network {
+		if name != "lo" {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, family := range []string{"inet", "inet6"} {
+		for _, name := range names {
+			for _, address := range instState.Network[name].Addresses {
+				if address.Family == family && address.Scope == "global" {
+					return address.Address, nil
+				}
+			}
+		}
+	}
+
+	return "", fmt.Errorf("Instance has no global address")
+}
@@ -16,8 +16,10 @@ import (
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/version"
@@ -158,6 +160,12 @@ func instanceLogsGet(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	// Session recordings contain the commands and output of the recorded sessions.
+	canViewRecordings, err := instanceSessionRecordingCheckAccess(d.State(), r, projectName, name)
+	if err != nil && !api.StatusErrorCheck(err, http.StatusForbidden) {
+		return response.SmartError(err)
+	}
+
 	result := []string{}
 
 	fullName := project.Instance(projectName, name)
@@ -171,6 +179,10 @@ func instanceLogsGet(d *Daemon, r *http.Request) response.Response {
 			continue
 		}
 
+		if !canViewRecordings && validSessionRecordingFileName(f.Name()) {
+			continue
+		}
+
 		result = append(result, fmt.Sprintf("/%s/instances/%s/logs/%s", version.APIVersion, name, f.Name()))
 	}
 
@@ -257,6 +269,13 @@ func instanceLogGet(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Log file name %q not valid", file))
 	}
 
+	if validSessionRecordingFileName(file) {
+		_, err = instanceSessionRecordingCheckAccess(s, r, projectName, name)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	ent := response.FileResponseEntry{
 		Path:     shared.LogPath(project.Instance(projectName, name), file),
 		Filename: file,
@@ -341,8 +360,13 @@ func instanceLogDelete(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Log file name %q not valid", file))
 	}
 
-	if !strings.HasSuffix(file, ".log") || file == "lxc.log" || file == "qemu.log" {
-		return response.BadRequest(fmt.Errorf("Only log files excluding qemu.log and lxc.log may be deleted"))
+	if validSessionRecordingFileName(file) {
+		_, err = instanceSessionRecordingCheckAccess(s, r, projectName, name)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	} else if !strings.HasSuffix(file, ".log") || file == "lxc.log" || file == "qemu.log" {
+		return response.BadRequest(fmt.Errorf("Only log files excluding qemu.log and lxc.log and session recordings may be deleted"))
 	}
 
 	err = os.Remove(shared.LogPath(project.Instance(projectName, name), file))
@@ -689,8 +713,28 @@ func validLogFileName(fname string) bool {
 		fname == "lxc.conf" ||
 		fname == "qemu.log" ||
 		fname == "qemu.conf" ||
+		fname == instanceScheduleLogFile ||
+		fname == instanceScheduleLogFile+".1" ||
 		strings.HasPrefix(fname, "migration_") ||
-		strings.HasPrefix(fname, "snapshot_")
+		strings.HasPrefix(fname, "snapshot_") ||
+		validSessionRecordingFileName(fname)
+}
+
+// instanceSessionRecordingCheckAccess checks that the requestor can run commands in the instance, which is
+// required to read and delete its session recordings. Returns true if access is granted.
+func instanceSessionRecordingCheckAccess(s *state.State, r *http.Request, projectName string, name string) (bool, error) {
+	err := s.Authorizer.CheckPermission(r.Context(), r, entity.InstanceURL(projectName, name), auth.EntitlementCanExec)
+	if err != nil && auth.IsDeniedError(err) {
+		return false, api.StatusErrorf(http.StatusForbidden, "Accessing session recordings requires permission to run commands in the instance")
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func validSessionRecordingFileName(fName string) bool {
+	return strings.HasSuffix(fName, ".cast") && strings.HasPrefix(fName, "session_")
 }
 
 func validExecOutputFileName(fName string) bool {
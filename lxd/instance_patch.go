@@ -11,16 +11,20 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/device"
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
 	projecthelpers "github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/osarch"
 )
 
@@ -175,6 +179,8 @@ func instancePatch(d *Daemon, r *http.Request) response.Response {
 	if req.Devices == nil {
 		req.Devices = c.LocalDevices().CloneNative()
 	} else {
+		device.ProxyRestoreRedactedKeys(c.LocalDevices().CloneNative(), req.Devices)
+
 		for k, v := range c.LocalDevices() {
 			_, ok := req.Devices[k]
 			if !ok {
@@ -183,6 +189,11 @@ func instancePatch(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	err = instanceCommandConfigCheckAccess(s, r, entity.InstanceURL(projectName, name), auth.EntitlementCanExec, c.LocalConfig(), req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Check project limits.
 	apiProfiles := make([]api.Profile, 0, len(req.Profiles))
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -206,6 +217,11 @@ func instancePatch(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	err = instanceSessionRecordingConfigCheckAccess(s, r, projectName, c.ExpandedConfig(), instancetype.ExpandInstanceConfig(nil, req.Config, apiProfiles))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Update container configuration
 	args := db.InstanceArgs{
 		Architecture: architecture,
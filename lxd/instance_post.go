@@ -223,6 +223,12 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 				if err != nil {
 					return err
 				}
+
+				// Only keep the members having free devices matching the passthrough devices of the instance.
+				candidateMembers, err = clusterDevicesFilterMembers(ctx, tx, candidateMembers, inst.Type(), inst.ExpandedDevices().CloneNative())
+				if err != nil {
+					return err
+				}
 			}
 
 			return nil
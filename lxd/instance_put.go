@@ -10,9 +10,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 
+	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/device"
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
@@ -24,6 +26,7 @@ import (
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/osarch"
 	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/version"
@@ -123,6 +126,8 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	device.ProxyRestoreRedactedKeys(inst.LocalDevices().CloneNative(), configRaw.Devices)
+
 	architecture, err := osarch.ArchitectureId(configRaw.Architecture)
 	if err != nil {
 		architecture = 0
@@ -131,6 +136,11 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 	var do func(*operations.Operation) error
 	var opType operationtype.Type
 	if configRaw.Restore == "" {
+		err = instanceCommandConfigCheckAccess(s, r, entity.InstanceURL(projectName, name), auth.EntitlementCanExec, inst.LocalConfig(), configRaw.Config)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
 		// Check project limits.
 		apiProfiles := make([]api.Profile, 0, len(configRaw.Profiles))
 		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -154,6 +164,11 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
+		err = instanceSessionRecordingConfigCheckAccess(s, r, projectName, inst.ExpandedConfig(), instancetype.ExpandInstanceConfig(nil, configRaw.Config, apiProfiles))
+		if err != nil {
+			return response.SmartError(err)
+		}
+
 		// Update container configuration
 		do = func(op *operations.Operation) error {
 			defer unlock()
@@ -178,6 +193,21 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 
 		opType = operationtype.InstanceUpdate
 	} else {
+		snapName := configRaw.Restore
+		if !shared.IsSnapshot(snapName) {
+			snapName = name + shared.SnapshotDelimiter + snapName
+		}
+
+		snap, err := instance.LoadByProjectAndName(s, projectName, snapName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		err = instanceSessionRecordingConfigCheckAccess(s, r, projectName, inst.ExpandedConfig(), snap.ExpandedConfig())
+		if err != nil {
+			return response.SmartError(err)
+		}
+
 		// Snapshot Restore
 		do = func(op *operations.Operation) error {
 			defer unlock()
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// instanceScheduleLogFile is the instance log file recording the scheduled actions.
+const instanceScheduleLogFile = "schedule.log"
+
+// instanceScheduleLogMaxSize is the size above which the schedule log is rotated to instanceScheduleLogFile.1.
+const instanceScheduleLogMaxSize = 10 * 1024 * 1024
+
+// instanceScheduleOutputMaxSize is the maximum size of the output of a scheduled command recorded in the log.
+const instanceScheduleOutputMaxSize = 1024 * 1024
+
+// instanceScheduledAction is an action scheduled on an instance through the schedule.<name>.* config keys.
+type instanceScheduledAction struct {
+	name    string
+	action  string
+	cron    string
+	command string
+	timeout time.Duration
+}
+
+var instanceScheduledActionsRunning = sync.Map{}
+
+// instanceScheduledActions returns the actions scheduled in the expanded instance config, sorted by name.
+func instanceScheduledActions(config map[string]string) []instanceScheduledAction {
+	actions := []instanceScheduledAction{}
+	for key, value := range config {
+		if !strings.HasPrefix(key, "schedule.") || !strings.HasSuffix(key, ".action") || value == "" {
+			continue
+		}
+
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "schedule."), ".action")
+		if name == "" || strings.Contains(name, ".") {
+			continue
+		}
+
+		prefix := "schedule." + name + "."
+
+		timeout, err := strconv.ParseUint(config[prefix+"timeout"], 10, 32)
+		if err != nil || timeout == 0 {
+			timeout = 3600
+		}
+
+		actions = append(actions, instanceScheduledAction{
+			name:    name,
+			action:  value,
+			cron:    config[prefix+"cron"],
+			command: config[prefix+"command"],
+			timeout: time.Duration(timeout) * time.Second,
+		})
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].name < actions[j].name
+	})
+
+	return actions
+}
+
+// instanceScheduledActionsTask returns a task that runs the actions scheduled on the local instances.
+func instanceScheduledActionsTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		// Get the instances on the local member having actions due now.
+		type instanceActions struct {
+			inst    instance.Instance
+			actions []instanceScheduledAction
+		}
+
+		due := []instanceActions{}
+		filter := dbCluster.InstanceFilter{Node: &s.ServerName}
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+				// Only load the instances having actions due now.
+				config := instancetype.ExpandInstanceConfig(nil, dbInst.Config, dbInst.Profiles)
+
+				actions := []instanceScheduledAction{}
+				for _, action := range instanceScheduledActions(config) {
+					if action.cron != "" && snapshotIsScheduledNow(action.cron, int64(dbInst.ID)) {
+						actions = append(actions, action)
+					}
+				}
+
+				if len(actions) == 0 {
+					return nil
+				}
+
+				inst, err := instance.Load(s, dbInst, p)
+				if err != nil {
+					return fmt.Errorf("Failed loading instance %q (project %q) for scheduled actions task: %w", dbInst.Name, dbInst.Project, err)
+				}
+
+				due = append(due, instanceActions{inst: inst, actions: actions})
+
+				return nil
+			}, filter)
+		})
+		if err != nil {
+			logger.Error("Failed getting instance scheduled actions", logger.Ctx{"err": err})
+			return
+		}
+
+		for _, entry := range due {
+			go instanceScheduledActionsRun(s, entry.inst, entry.actions)
+		}
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := time.Minute
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
+
+// instanceScheduledActionsRun runs the given scheduled actions of the instance in order, unless actions of the
+// instance are still running from a previous schedule.
+func instanceScheduledActionsRun(s *state.State, inst instance.Instance, actions []instanceScheduledAction) {
+	_, loaded := instanceScheduledActionsRunning.LoadOrStore(inst.ID(), struct{}{})
+	if loaded {
+		logger.Warn("Skipping scheduled actions, previous ones are still running", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+		return
+	}
+
+	defer instanceScheduledActionsRunning.Delete(inst.ID())
+
+	for _, action := range actions {
+		l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "schedule": action.name, "action": action.action})
+
+		logFile, err := instanceScheduleLogOpen(inst)
+		if err != nil {
+			l.Warn("Failed opening instance schedule log", logger.Ctx{"err": err})
+		}
+
+		record := func(format string, args ...any) {
+			if logFile == nil {
+				return
+			}
+
+			_, _ = fmt.Fprintf(logFile, "%s %s: %s\n", time.Now().UTC().Format(time.RFC3339), action.name, fmt.Sprintf(format, args...))
+		}
+
+		record("running %q", action.action)
+		l.Info("Running scheduled instance action")
+
+		err = instanceScheduledActionRun(s, inst, action, logFile)
+		if err != nil {
+			record("failed: %v", err)
+			l.Error("Failed running scheduled instance action", logger.Ctx{"err": err})
+		} else {
+			record("done")
+		}
+
+		if logFile != nil {
+			_ = logFile.Close()
+		}
+	}
+}
+
+// instanceScheduleLogOpen opens the schedule log of the instance for appending.
+// The log is first rotated to instanceScheduleLogFile.1 if it has grown too large.
+func instanceScheduleLogOpen(inst instance.Instance) (*os.File, error) {
+	path := filepath.Join(inst.LogPath(), instanceScheduleLogFile)
+
+	fi, err := os.Stat(path)
+	if err == nil && fi.Size() >= instanceScheduleLogMaxSize {
+		err = os.Rename(path, path+".1")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+}
+
+// instanceScheduledActionExec runs the command of a scheduled action inside the instance, killing it once the
+// action timeout is reached. Up to instanceScheduleOutputMaxSize bytes of its output are written to the given writer.
+func instanceScheduledActionExec(inst instance.Instance, action instanceScheduledAction, output io.Writer) error {
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = devNull.Close() }()
+
+	outputRead, outputWrite, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = outputRead.Close() }()
+
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+
+		n, _ := io.Copy(output, io.LimitReader(outputRead, instanceScheduleOutputMaxSize))
+		if n == instanceScheduleOutputMaxSize {
+			_, _ = fmt.Fprintf(output, "\n[output truncated after %d bytes]\n", n)
+		}
+
+		// Keep draining the output so that the command doesn't block.
+		_, _ = io.Copy(io.Discard, outputRead)
+	}()
+
+	req := api.InstanceExecPost{
+		Command:     []string{"sh", "-c", action.command},
+		Environment: instanceCommandEnvironment(),
+	}
+
+	cmd, err := inst.Exec(req, devNull, outputWrite, outputWrite)
+	_ = outputWrite.Close()
+	if err != nil {
+		return err
+	}
+
+	type result struct {
+		exitStatus int
+		err        error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		exitStatus, err := cmd.Wait()
+		done <- result{exitStatus: exitStatus, err: err}
+	}()
+
+	var res result
+	select {
+	case res = <-done:
+	case <-time.After(action.timeout):
+		_ = cmd.Signal(unix.SIGKILL)
+		res.err = fmt.Errorf("Command timed out after %s", action.timeout)
+	}
+
+	// Wait for the output to be recorded, unless processes left behind by the command still hold it open.
+	select {
+	case <-copied:
+	case <-time.After(5 * time.Second):
+	}
+
+	if res.err != nil {
+		return res.err
+	}
+
+	if res.exitStatus != 0 {
+		return fmt.Errorf("Command exited with status %d", res.exitStatus)
+	}
+
+	return nil
+}
+
+// instanceScheduledActionRun runs a scheduled action on the instance. The output of commands is written to the
+// given log file.
+func instanceScheduledActionRun(s *state.State, inst instance.Instance, action instanceScheduledAction, logFile *os.File) error {
+	switch action.action {
+	case "start":
+		if inst.IsRunning() {
+			return nil
+		}
+
+		if s.DB.Cluster.LocalNodeIsEvacuated() {
+			return fmt.Errorf("The cluster member is evacuated")
+		}
+
+		return inst.Start(false)
+
+	case "stop":
+		if !inst.IsRunning() {
+			return nil
+		}
+
+		timeout, err := strconv.Atoi(inst.ExpandedConfig()["boot.host_shutdown_timeout"])
+		if err != nil {
+			timeout = 30
+		}
+
+		err = inst.Shutdown(time.Duration(timeout) * time.Second)
+		if err != nil {
+			logger.Warn("Failed shutting down instance, forcing stop", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			return inst.Stop(false)
+		}
+
+		return nil
+
+	case "restart":
+		if !inst.IsRunning() {
+			return nil
+		}
+
+		timeout, err := strconv.Atoi(inst.ExpandedConfig()["boot.host_shutdown_timeout"])
+		if err != nil {
+			timeout = 30
+		}
+
+		return inst.Restart(time.Duration(timeout) * time.Second)
+
+	case "snapshot":
+		instProject := inst.Project()
+		err := project.AllowSnapshotCreation(&instProject)
+		if err != nil {
+			return err
+		}
+
+		snapshotName, err := instance.NextSnapshotName(s, inst, "snap%d")
+		if err != nil {
+			return err
+		}
+
+		expiry, err := shared.GetExpiry(time.Now(), inst.ExpandedConfig()["snapshots.expiry"])
+		if err != nil {
+			return err
+		}
+
+		return inst.Snapshot(snapshotName, expiry, false)
+
+	case "exec":
+		if action.command == "" {
+			return fmt.Errorf("No command configured")
+		}
+
+		if !inst.IsRunning() {
+			return fmt.Errorf("The instance isn't running")
+		}
+
+		var output io.Writer = io.Discard
+		if logFile != nil {
+			output = logFile
+		}
+
+		return instanceScheduledActionExec(inst, action, output)
+	}
+
+	return fmt.Errorf("Unknown action %q", action.action)
+}
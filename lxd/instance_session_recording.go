@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kballard/go-shellquote"
+
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// asciicastHeader is the header line of an asciicast v2 recording.
+type asciicastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Command   string            `json:"command,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// sessionRecorder records the output of an interactive session into the log directory of an instance in
+// asciicast v2 format.
+type sessionRecorder struct {
+	mu sync.Mutex
+
+	state    *state.State
+	instance instance.Instance
+	op       *operations.Operation
+	ctx      map[string]any
+
+	file    *os.File
+	start   time.Time
+	pending []byte
+	err     error
+}
+
+// newSessionRecorder starts recording an interactive session if enabled by security.session_recording.
+// Returns nil if the sessions of the instance aren't recorded.
+func newSessionRecorder(s *state.State, inst instance.Instance, op *operations.Operation, sessionType string, width int, height int, command []string, env map[string]string) (*sessionRecorder, error) {
+	if shared.IsFalseOrEmpty(inst.ExpandedConfig()["security.session_recording"]) {
+		return nil, nil
+	}
+
+	if width <= 0 || height <= 0 {
+		width = 80
+		height = 24
+	}
+
+	start := time.Now()
+	header := asciicastHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: start.Unix(),
+		Title:     fmt.Sprintf("%s %s", inst.Name(), sessionType),
+	}
+
+	ctx := map[string]any{"type": sessionType}
+
+	if len(command) > 0 {
+		header.Command = shellquote.Join(command...)
+		ctx["command"] = command
+	}
+
+	if env["TERM"] != "" {
+		header.Env = map[string]string{"TERM": env["TERM"]}
+	}
+
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := fmt.Sprintf("session_%s_%s.cast", sessionType, op.ID())
+	file, err := os.OpenFile(filepath.Join(inst.LogPath(), fileName), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating session recording: %w", err)
+	}
+
+	_, err = file.Write(append(data, '\n'))
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("Failed writing session recording: %w", err)
+	}
+
+	return &sessionRecorder{
+		state:    s,
+		instance: inst,
+		op:       op,
+		ctx:      ctx,
+		file:     file,
+		start:    start,
+	}, nil
+}
+
+// event appends an event to the recording. Errors are kept until the recording is closed so that they don't
+// interrupt the session.
+func (r *sessionRecorder) event(code string, data string) {
+	if r.err != nil {
+		return
+	}
+
+	line, err := json.Marshal([]any{time.Since(r.start).Seconds(), code, data})
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	_, err = r.file.Write(append(line, '\n'))
+	if err != nil {
+		r.err = err
+	}
+}
+
+// Write records the output of the session.
+func (r *sessionRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data := append(r.pending, p...)
+
+	// Keep an incomplete trailing UTF-8 sequence until the rest of it is written.
+	end := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				end = i
+			}
+
+			break
+		}
+	}
+
+	r.pending = append([]byte(nil), data[end:]...)
+
+	if end > 0 {
+		r.event("o", string(data[:end]))
+	}
+
+	return len(p), nil
+}
+
+// resize records a change of the terminal size of the session.
+func (r *sessionRecorder) resize(width int, height int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.event("r", fmt.Sprintf("%dx%d", width, height))
+}
+
+// wrap returns a ReadWriteCloser recording everything read from rwc, i.e. the output of the session.
+func (r *sessionRecorder) wrap(rwc io.ReadWriteCloser) io.ReadWriteCloser {
+	return &sessionRecorderReadWriteCloser{ReadWriteCloser: rwc, recorder: r}
+}
+
+// finish records the remaining output and closes the recording file.
+func (r *sessionRecorder) finish() error {
+	if len(r.pending) > 0 {
+		r.event("o", string(r.pending))
+		r.pending = nil
+	}
+
+	err := r.file.Close()
+	if r.err == nil {
+		r.err = err
+	}
+
+	return r.err
+}
+
+// Close finishes the recording and emits the lifecycle event with the identity that opened the session.
+func (r *sessionRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.finish() != nil {
+		logger.Warn("Failed recording session", logger.Ctx{"project": r.instance.Project().Name, "instance": r.instance.Name(), "recording": r.file.Name(), "err": r.err})
+	}
+
+	r.state.Events.SendLifecycle(r.instance.Project().Name, lifecycle.InstanceSessionRecorded.Event(filepath.Base(r.file.Name()), r.instance, r.op.Requestor(), r.ctx))
+
+	return r.err
+}
+
+// sessionRecorderReadWriteCloser records the data read from the wrapped ReadWriteCloser.
+type sessionRecorderReadWriteCloser struct {
+	io.ReadWriteCloser
+
+	recorder *sessionRecorder
+}
+
+// Read reads from the wrapped ReadWriteCloser and records the data.
+func (rwc *sessionRecorderReadWriteCloser) Read(p []byte) (int, error) {
+	n, err := rwc.ReadWriteCloser.Read(p)
+	if n > 0 {
+		_, _ = rwc.recorder.Write(p[:n])
+	}
+
+	return n, err
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRecorderOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		record   func(r *sessionRecorder)
+		expected [][2]string
+	}{
+		{
+			"Output",
+			func(r *sessionRecorder) {
+				_, _ = r.Write([]byte("hello\r\n"))
+				_, _ = r.Write([]byte("world"))
+			},
+			[][2]string{{"o", "hello\r\n"}, {"o", "world"}},
+		},
+		{
+			"UTF-8 sequence split across writes",
+			func(r *sessionRecorder) {
+				_, _ = r.Write([]byte("caf\xc3"))
+				_, _ = r.Write([]byte("\xa9!"))
+			},
+			[][2]string{{"o", "caf"}, {"o", "é!"}},
+		},
+		{
+			"Incomplete UTF-8 sequence only",
+			func(r *sessionRecorder) {
+				_, _ = r.Write([]byte("\xe2\x82"))
+				_, _ = r.Write([]byte("\xac"))
+			},
+			[][2]string{{"o", "€"}},
+		},
+		{
+			"Resize",
+			func(r *sessionRecorder) {
+				_, _ = r.Write([]byte("a"))
+				r.resize(120, 40)
+				_, _ = r.Write([]byte("b"))
+			},
+			[][2]string{{"o", "a"}, {"r", "120x40"}, {"o", "b"}},
+		},
+		{
+			"Incomplete UTF-8 sequence flushed on close",
+			func(r *sessionRecorder) {
+				_, _ = r.Write([]byte("a\xc3"))
+			},
+			[][2]string{{"o", "a"}, {"o", "�"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "session.cast")
+			file, err := os.Create(path)
+			require.NoError(t, err)
+
+			r := &sessionRecorder{file: file, start: time.Now()}
+			tt.record(r)
+			require.NoError(t, r.finish())
+
+			recording, err := os.Open(path)
+			require.NoError(t, err)
+			defer recording.Close()
+
+			events := [][2]string{}
+			lastTime := 0.0
+			scanner := bufio.NewScanner(recording)
+			for scanner.Scan() {
+				var event []any
+				require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+				require.Len(t, event, 3)
+
+				eventTime, ok := event[0].(float64)
+				require.True(t, ok)
+				require.GreaterOrEqual(t, eventTime, lastTime)
+				lastTime = eventTime
+
+				code, ok := event[1].(string)
+				require.True(t, ok)
+				data, ok := event[2].(string)
+				require.True(t, ok)
+
+				events = append(events, [2]string{code, data})
+			}
+
+			require.NoError(t, scanner.Err())
+			require.Equal(t, tt.expected, events)
+		})
+	}
+}
@@ -195,6 +195,8 @@ func instanceSnapshotsGet(d *Daemon, r *http.Request) response.Response {
 				continue
 			}
 
+			instanceRedact(render)
+
 			resultMap = append(resultMap, render.(*api.InstanceSnapshot))
 		}
 	}
@@ -597,6 +599,8 @@ func snapshotGet(s *state.State, snapInst instance.Instance) response.Response {
 		return response.SmartError(err)
 	}
 
+	instanceRedact(render)
+
 	etag := []any{snapInst.ExpiryDate()}
 	return response.SyncResponseETag(true, render.(*api.InstanceSnapshot), etag)
 }
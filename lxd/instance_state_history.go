@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/instance"
+	instanceDrivers "github.com/canonical/lxd/lxd/instance/drivers"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// The usage of the local instances is sampled every minute and kept at full resolution for an hour, then
+// downsampled to 15 minutes for the last 24 hours.
+const (
+	instanceStateHistoryInterval   = time.Minute
+	instanceStateHistoryFineSize   = 60
+	instanceStateHistoryCoarseSize = 96
+	instanceStateHistoryRatio      = 15
+)
+
+var instanceStateHistories = map[int]*metrics.History{}
+var instanceStateHistoriesMu sync.Mutex
+
+// instanceStateHistoryTask returns a task that records the resource usage history of the local instances.
+func instanceStateHistoryTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		instances, err := instance.LoadNodeAll(s, instancetype.Any)
+		if err != nil {
+			logger.Warn("Failed loading instances for usage history", logger.Ctx{"err": err})
+			return
+		}
+
+		hostInterfaces, _ := net.Interfaces()
+
+		local := map[int]bool{}
+		for _, inst := range instances {
+			// Keep the history of stopped instances until they're deleted or moved.
+			local[inst.ID()] = true
+
+			if !inst.IsRunning() {
+				continue
+			}
+
+			metricSet, err := inst.Metrics(hostInterfaces)
+			if err != nil {
+				if !errors.Is(err, instanceDrivers.ErrInstanceIsStopped) {
+					logger.Warn("Failed getting instance metrics for usage history", logger.Ctx{"instance": inst.Name(), "project": inst.Project().Name, "err": err})
+				}
+
+				continue
+			}
+
+			instanceStateHistoriesMu.Lock()
+			history, ok := instanceStateHistories[inst.ID()]
+			if !ok {
+				history = metrics.NewHistory(instanceStateHistoryInterval, instanceStateHistoryFineSize, instanceStateHistoryCoarseSize, instanceStateHistoryRatio)
+				instanceStateHistories[inst.ID()] = history
+			}
+
+			instanceStateHistoriesMu.Unlock()
+
+			history.Add(metricSet, time.Now())
+		}
+
+		// Forget about the instances that were deleted or moved to another member.
+		instanceStateHistoriesMu.Lock()
+		for id := range instanceStateHistories {
+			if !local[id] {
+				delete(instanceStateHistories, id)
+			}
+		}
+
+		instanceStateHistoriesMu.Unlock()
+	}
+
+	return f, task.Every(instanceStateHistoryInterval)
+}
+
+// swagger:operation GET /1.0/instances/{name}/state/history instances instance_state_history_get
+//
+//	Get the resource usage history
+//
+//	Gets the CPU, memory, disk and network usage history of the instance.
+//
+//	The history is kept in memory by the server running the instance. The last hour
+//	is sampled every minute and the 23 hours before it are downsampled to 15 minutes.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: since
+//	    description: Only return the samples ending after this time (RFC3339)
+//	    type: string
+//	    example: 2021-03-23T20:00:00-04:00
+//	responses:
+//	  "200":
+//	    description: State history
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceStateHistory"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceStateHistoryGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := request.ProjectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if shared.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	var since time.Time
+	sinceParam := request.QueryParam(r, "since")
+	if sinceParam != "" {
+		since, err = time.Parse(time.RFC3339, sinceParam)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid since timestamp %q: %w", sinceParam, err))
+		}
+	}
+
+	// Handle requests targeted to an instance on a different member.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	history := api.InstanceStateHistory{Samples: []api.InstanceStateHistorySample{}}
+
+	instanceStateHistoriesMu.Lock()
+	instHistory, ok := instanceStateHistories[inst.ID()]
+	instanceStateHistoriesMu.Unlock()
+
+	if ok {
+		history.Samples = instHistory.Samples(since)
+	}
+
+	return response.SyncResponse(true, history)
+}
@@ -0,0 +1,630 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance/instancetemplate"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var instanceTemplatesCmd = APIEndpoint{
+	Path: "instance-templates",
+
+	Get:  APIEndpointAction{Handler: instanceTemplatesGet, AccessHandler: allowAuthenticated},
+	Post: APIEndpointAction{Handler: instanceTemplatesPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanCreateInstanceTemplates)},
+}
+
+var instanceTemplateCmd = APIEndpoint{
+	Path: "instance-templates/{name}",
+
+	Delete: APIEndpointAction{Handler: instanceTemplateDelete, AccessHandler: allowPermission(entity.TypeInstanceTemplate, auth.EntitlementCanDelete, "name")},
+	Get:    APIEndpointAction{Handler: instanceTemplateGet, AccessHandler: allowPermission(entity.TypeInstanceTemplate, auth.EntitlementCanView, "name")},
+	Patch:  APIEndpointAction{Handler: instanceTemplatePut, AccessHandler: allowPermission(entity.TypeInstanceTemplate, auth.EntitlementCanEdit, "name")},
+	Post:   APIEndpointAction{Handler: instanceTemplatePost, AccessHandler: allowPermission(entity.TypeInstanceTemplate, auth.EntitlementCanEdit, "name")},
+	Put:    APIEndpointAction{Handler: instanceTemplatePut, AccessHandler: allowPermission(entity.TypeInstanceTemplate, auth.EntitlementCanEdit, "name")},
+}
+
+// instanceTemplateEtag returns the data used to compute the ETag of an instance template.
+func instanceTemplateEtag(template *api.InstanceTemplate) []any {
+	return []any{template.Description, template.Parameters, template.Instance}
+}
+
+// swagger:operation GET /1.0/instance-templates instance-templates instance_templates_get
+//
+//  Get the instance templates
+//
+//  Returns a list of instance templates (URLs).
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of endpoints
+//            items:
+//              type: string
+//            example: |-
+//              [
+//                "/1.0/instance-templates/web",
+//                "/1.0/instance-templates/db"
+//              ]
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/instance-templates?recursion=1 instance-templates instance_templates_get_recursion1
+//
+//	Get the instance templates
+//
+//	Returns a list of instance templates (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of instance templates
+//	          items:
+//	            $ref: "#/definitions/InstanceTemplate"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceTemplatesGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+	recursion := util.IsRecursionRequest(r)
+
+	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), r, auth.EntitlementCanView, entity.TypeInstanceTemplate)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []api.InstanceTemplate{}
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		templateNames, err := tx.GetInstanceTemplateNames(ctx, projectName)
+		if err != nil {
+			return err
+		}
+
+		for _, templateName := range templateNames {
+			if !userHasPermission(entity.InstanceTemplateURL(projectName, templateName)) {
+				continue
+			}
+
+			if !recursion {
+				resultString = append(resultString, api.NewURL().Path(version.APIVersion, "instance-templates", templateName).String())
+				continue
+			}
+
+			_, template, err := tx.GetInstanceTemplate(ctx, projectName, templateName)
+			if err != nil {
+				return err
+			}
+
+			resultMap = append(resultMap, *template)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/instance-templates instance-templates instance_templates_post
+//
+//	Add an instance template
+//
+//	Creates a new instance template.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: template
+//	    description: Instance template
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceTemplatesPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceTemplatesPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	req := api.InstanceTemplatesPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = instancetemplate.ValidName(req.Name)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid instance template name %q: %w", req.Name, err))
+	}
+
+	err = instancetemplate.Validate(req.InstanceTemplatePut)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = instanceCommandConfigCheckAccess(s, r, entity.ProjectURL(projectName), auth.EntitlementCanOperateInstances, nil, req.Instance.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = instanceSessionRecordingConfigCheckAccess(s, r, projectName, nil, req.Instance.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return fmt.Errorf("Failed loading project %q: %w", projectName, err)
+		}
+
+		_, _, err = tx.GetInstanceTemplate(ctx, projectName, req.Name)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "The instance template already exists")
+		}
+
+		_, err = tx.CreateInstanceTemplate(ctx, projectName, req)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	lc := lifecycle.InstanceTemplateCreated.Event(req.Name, projectName, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation GET /1.0/instance-templates/{name} instance-templates instance_template_get
+//
+//	Get the instance template
+//
+//	Gets a specific instance template.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Instance template
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceTemplate"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceTemplateGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var template *api.InstanceTemplate
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, template, err = tx.GetInstanceTemplate(ctx, projectName, name)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, template, instanceTemplateEtag(template))
+}
+
+// swagger:operation PATCH /1.0/instance-templates/{name} instance-templates instance_template_patch
+//
+//  Partially update the instance template
+//
+//  Updates a subset of the instance template. The parameters and instance definition are replaced when provided.
+//
+//  ---
+//  consumes:
+//    - application/json
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//    - in: body
+//      name: template
+//      description: Instance template
+//      required: true
+//      schema:
+//        $ref: "#/definitions/InstanceTemplatePut"
+//  responses:
+//    "200":
+//      $ref: "#/responses/EmptySyncResponse"
+//    "400":
+//      $ref: "#/responses/BadRequest"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "412":
+//      $ref: "#/responses/PreconditionFailed"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/instance-templates/{name} instance-templates instance_template_put
+//
+//	Update the instance template
+//
+//	Updates the entire instance template.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: template
+//	    description: Instance template
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceTemplatePut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceTemplatePut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var id int64
+	var template *api.InstanceTemplate
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, template, err = tx.GetInstanceTemplate(ctx, projectName, name)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, instanceTemplateEtag(template))
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	// For PATCH requests, start from the current template so that omitted fields are kept.
+	req := api.InstanceTemplatePut{}
+	if r.Method == http.MethodPatch {
+		req = template.Writable()
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = instancetemplate.Validate(req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = instanceCommandConfigCheckAccess(s, r, entity.ProjectURL(projectName), auth.EntitlementCanOperateInstances, template.Instance.Config, req.Instance.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = instanceSessionRecordingConfigCheckAccess(s, r, projectName, template.Instance.Config, req.Instance.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpdateInstanceTemplate(ctx, id, req)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstanceTemplateUpdated.Event(name, projectName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation POST /1.0/instance-templates/{name} instance-templates instance_template_post
+//
+//	Rename the instance template
+//
+//	Renames an existing instance template.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: template
+//	    description: Instance template rename request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceTemplatePost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceTemplatePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.InstanceTemplatePost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = instancetemplate.ValidName(req.Name)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid instance template name %q: %w", req.Name, err))
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, _, err := tx.GetInstanceTemplate(ctx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		_, _, err = tx.GetInstanceTemplate(ctx, projectName, req.Name)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "Name %q already in use", req.Name)
+		}
+
+		return tx.RenameInstanceTemplate(ctx, id, req.Name)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	lc := lifecycle.InstanceTemplateRenamed.Event(req.Name, projectName, request.CreateRequestor(r), logger.Ctx{"old_name": name})
+	s.Events.SendLifecycle(projectName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation DELETE /1.0/instance-templates/{name} instance-templates instance_template_delete
+//
+//	Delete the instance template
+//
+//	Removes the instance template. Instances created from it aren't affected.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceTemplateDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, _, err := tx.GetInstanceTemplate(ctx, projectName, name)
+		if err != nil {
+			return err
+		}
+
+		return tx.DeleteInstanceTemplate(ctx, id)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstanceTemplateDeleted.Event(name, projectName, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// instanceTemplateApply replaces the instance creation request referencing an instance template by the instance
+// definition of the template, rendered with the requested parameters and with the fields set in the request applied
+// on top. The requestor must be allowed to view the template.
+func instanceTemplateApply(s *state.State, r *http.Request, projectName string, req *api.InstancesPost) error {
+	err := s.Authorizer.CheckPermission(r.Context(), r, entity.InstanceTemplateURL(projectName, req.Template), auth.EntitlementCanView)
+	if err != nil {
+		return err
+	}
+
+	var template *api.InstanceTemplate
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, template, err = tx.GetInstanceTemplate(ctx, projectName, req.Template)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	definition, err := instancetemplate.Render(template.Writable(), req.TemplateParameters)
+	if err != nil {
+		return api.StatusErrorf(http.StatusBadRequest, "Failed applying instance template %q: %w", req.Template, err)
+	}
+
+	*req = instancetemplate.Merge(*definition, *req)
+
+	return nil
+}
@@ -88,6 +88,17 @@ var instanceStateCmd = APIEndpoint{
 	Put: APIEndpointAction{Handler: instanceStatePut, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanUpdateState, "name")},
 }
 
+var instanceStateHistoryCmd = APIEndpoint{
+	Name: "instanceStateHistory",
+	Path: "instances/{name}/state/history",
+	Aliases: []APIEndpointAlias{
+		{Name: "containerStateHistory", Path: "containers/{name}/state/history"},
+		{Name: "vmStateHistory", Path: "virtual-machines/{name}/state/history"},
+	},
+
+	Get: APIEndpointAction{Handler: instanceStateHistoryGet, AccessHandler: allowPermission(entity.TypeInstance, auth.EntitlementCanView, "name")},
+}
+
 var instanceSFTPCmd = APIEndpoint{
 	Name: "instanceFile",
 	Path: "instances/{name}/sftp",
@@ -271,8 +282,9 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 	instancesStartMu.Lock()
 	defer instancesStartMu.Unlock()
 
-	// Sort based on instance boot priority.
+	// Sort based on instance boot priority, then start the dependencies of the instances first.
 	sort.Sort(instanceAutostartList(instances))
+	instances = instancesSortByDependencies(instances, false)
 
 	// Let's make up to 3 attempts to start instances.
 	maxAttempts := 3
@@ -294,6 +306,20 @@ func instancesStart(s *state.State, instances []instance.Instance) {
 
 		instLogger := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
+		// Wait for the instances it depends on.
+		err := instanceDependenciesWait(context.TODO(), s, inst)
+		if err != nil {
+			warnErr := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				return tx.UpsertWarningLocalNode(ctx, inst.Project().Name, entity.TypeInstance, inst.ID(), warningtype.InstanceAutostartFailure, err.Error())
+			})
+			if warnErr != nil {
+				instLogger.Warn("Failed to create instance autostart failure warning", logger.Ctx{"err": warnErr})
+			}
+
+			instLogger.Error("Failed to auto start instance", logger.Ctx{"err": err})
+			continue
+		}
+
 		// Try to start the instance.
 		var attempt = 0
 		for {
@@ -466,6 +466,7 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 							if err != nil {
 								resultErrListAppend(dbInst, err)
 							} else {
+								instanceRedact(c)
 								resultFullListAppend(&api.InstanceFull{Instance: *c.(*api.Instance)})
 							}
 
@@ -476,6 +477,7 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 						if err != nil {
 							resultErrListAppend(dbInst, err)
 						} else {
+							instanceRedact(c)
 							resultFullListAppend(c)
 						}
 					}
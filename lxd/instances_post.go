@@ -15,6 +15,7 @@ import (
 	"github.com/gorilla/websocket"
 
 	"github.com/canonical/lxd/lxd/archive"
+	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/backup"
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db"
@@ -34,6 +35,7 @@ import (
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	apiScriptlet "github.com/canonical/lxd/shared/api/scriptlet"
+	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/osarch"
 	"github.com/canonical/lxd/shared/revert"
@@ -425,6 +427,33 @@ func createFromCopy(s *state.State, r *http.Request, projectName string, profile
 		return response.SmartError(err)
 	}
 
+	// Forked instances share the memory of the source, so it must be a VM running on this member.
+	if req.Source.Fork {
+		if req.Source.Refresh {
+			return response.BadRequest(fmt.Errorf("Forking can't be combined with refreshing an existing instance"))
+		}
+
+		if source.Type() != instancetype.VM || source.IsSnapshot() {
+			return response.BadRequest(fmt.Errorf("Only virtual machines can be forked"))
+		}
+
+		if req.Stateful {
+			return response.BadRequest(fmt.Errorf("Instances with saved state can't be forked"))
+		}
+
+		if s.ServerClustered && s.ServerName != source.Location() {
+			return response.BadRequest(fmt.Errorf("Forking requires the source instance to be on the same cluster member"))
+		}
+
+		// Forking pauses the source instance.
+		err = s.Authorizer.CheckPermission(r.Context(), r, entity.InstanceURL(sourceProject, source.Name()), auth.EntitlementCanUpdateState)
+		if err != nil && auth.IsDeniedError(err) {
+			return response.Forbidden(fmt.Errorf("Forking requires permission to update the state of the source instance"))
+		} else if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	// When clustered, use the node name, otherwise use the hostname.
 	if s.ServerClustered {
 		serverName := s.ServerName
@@ -535,7 +564,22 @@ func createFromCopy(s *state.State, r *http.Request, projectName string, profile
 	}
 
 	run := func(op *operations.Operation) error {
-		_, err := instanceCreateAsCopy(s, instanceCreateAsCopyOpts{
+		// Capture the fork base before copying so the root disk matches the paused memory state.
+		var err error
+		var forkBaseID string
+		if req.Source.Fork {
+			vm, ok := source.(instance.VM)
+			if !ok {
+				return fmt.Errorf("Only virtual machines can be forked")
+			}
+
+			forkBaseID, err = vm.ForkBase()
+			if err != nil {
+				return fmt.Errorf("Failed creating fork base: %w", err)
+			}
+		}
+
+		inst, err := instanceCreateAsCopy(s, instanceCreateAsCopyOpts{
 			sourceInstance:       source,
 			targetInstance:       args,
 			instanceOnly:         req.Source.InstanceOnly || req.Source.ContainerOnly,
@@ -547,6 +591,19 @@ func createFromCopy(s *state.State, r *http.Request, projectName string, profile
 			return err
 		}
 
+		// Start the clone from the fork base.
+		if forkBaseID != "" {
+			err = inst.VolatileSet(map[string]string{"volatile.fork.restore": forkBaseID})
+			if err != nil {
+				return err
+			}
+
+			err = inst.Start(false)
+			if err != nil {
+				return fmt.Errorf("Failed starting forked instance: %w", err)
+			}
+		}
+
 		return nil
 	}
 
@@ -872,6 +929,22 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	// Expand the instance template if one is referenced.
+	if req.Template != "" {
+		err = instanceTemplateApply(s, r, targetProjectName, &req)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	} else if len(req.TemplateParameters) > 0 {
+		return response.BadRequest(fmt.Errorf("Template parameters require an instance template"))
+	}
+
+	// Check the config once expanded, as instance templates can set commands too.
+	err = instanceCommandConfigCheckAccess(s, r, entity.ProjectURL(targetProjectName), auth.EntitlementCanOperateInstances, nil, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Set type from URL if missing
 	urlType, err := urlInstanceTypeDetect(r)
 	if err != nil {
@@ -1096,6 +1169,18 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 				return err
 			}
 
+			// Only keep the members having free devices matching the passthrough devices of the instance.
+			instanceType, err := instancetype.New(string(req.Type))
+			if err != nil {
+				return err
+			}
+
+			devices := instancetype.ExpandInstanceDevices(deviceConfig.NewDevices(req.Devices), profiles).CloneNative()
+			candidateMembers, err = clusterDevicesFilterMembers(ctx, tx, candidateMembers, instanceType, devices)
+			if err != nil {
+				return err
+			}
+
 			return nil
 		}
 
@@ -1114,6 +1199,12 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Only allow overriding the session recording setting of the profiles with permission to edit the project.
+	err = instanceSessionRecordingConfigCheckAccess(s, r, targetProjectName, instancetype.ExpandInstanceConfig(nil, nil, profiles), instancetype.ExpandInstanceConfig(nil, req.Config, profiles))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	err = instance.ValidName(req.Name, false)
 	if err != nil {
 		return response.BadRequest(err)
@@ -169,8 +169,20 @@ func instancesPut(d *Daemon, r *http.Request) response.Response {
 				go func(inst instance.Instance) {
 					defer wgAction.Done()
 
-					inst.SetOperation(op)
-					err := doInstanceStatePut(inst, *req.State)
+					// Start the instances after their dependencies and stop them before their dependents.
+					var err error
+					switch action {
+					case instancetype.Start:
+						err = instanceDependenciesWait(context.TODO(), s, inst)
+					case instancetype.Stop:
+						err = instanceDependentsWaitStopped(context.TODO(), inst, instances)
+					}
+
+					if err == nil {
+						inst.SetOperation(op)
+						err = doInstanceStatePut(inst, *req.State)
+					}
+
 					if err != nil {
 						failuresLock.Lock()
 						failures[inst.Name()] = err
@@ -0,0 +1,166 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/canonical/lxd/lxd/resources"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Types of devices in the inventory.
+const (
+	TypeUSB     = "usb"
+	TypePCI     = "pci"
+	TypeGPU     = "gpu"
+	TypeSRIOVVF = "sriov-vf"
+)
+
+// Collect returns the devices of the local server that can be passed through to instances.
+func Collect() ([]api.ClusterDevice, error) {
+	usb, err := resources.GetUSB()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting USB devices: %w", err)
+	}
+
+	pci, err := resources.GetPCI()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting PCI devices: %w", err)
+	}
+
+	gpu, err := resources.GetGPU()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting GPUs: %w", err)
+	}
+
+	network, err := resources.GetNetwork()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting network cards: %w", err)
+	}
+
+	return Devices(usb, pci, gpu, network), nil
+}
+
+// Devices returns the devices that can be passed through to instances from the server resources.
+// PCI devices are only listed once, GPUs and SR-IOV virtual functions being listed with their specific type.
+func Devices(usb *api.ResourcesUSB, pci *api.ResourcesPCI, gpu *api.ResourcesGPU, network *api.ResourcesNetwork) []api.ClusterDevice {
+	devices := []api.ClusterDevice{}
+	pciKnown := map[string]bool{}
+
+	addGPU := func(card api.ResourcesGPUCard) {
+		if card.PCIAddress == "" || pciKnown[card.PCIAddress] {
+			return
+		}
+
+		device := api.ClusterDevice{
+			Type:      TypeGPU,
+			Address:   card.PCIAddress,
+			Vendor:    card.Vendor,
+			VendorID:  card.VendorID,
+			Product:   card.Product,
+			ProductID: card.ProductID,
+		}
+
+		if card.DRM != nil {
+			device.Name = card.DRM.CardName
+		}
+
+		pciKnown[card.PCIAddress] = true
+		devices = append(devices, device)
+	}
+
+	if gpu != nil {
+		for _, card := range gpu.Cards {
+			addGPU(card)
+
+			if card.SRIOV != nil {
+				for _, vf := range card.SRIOV.VFs {
+					addGPU(vf)
+				}
+			}
+		}
+	}
+
+	if network != nil {
+		for _, card := range network.Cards {
+			if card.SRIOV == nil {
+				continue
+			}
+
+			parent := ""
+			if len(card.Ports) > 0 {
+				parent = card.Ports[0].ID
+			}
+
+			for _, vf := range card.SRIOV.VFs {
+				if vf.PCIAddress == "" || pciKnown[vf.PCIAddress] {
+					continue
+				}
+
+				device := api.ClusterDevice{
+					Type:      TypeSRIOVVF,
+					Address:   vf.PCIAddress,
+					Vendor:    vf.Vendor,
+					VendorID:  vf.VendorID,
+					Product:   vf.Product,
+					ProductID: vf.ProductID,
+					Parent:    parent,
+				}
+
+				if len(vf.Ports) > 0 {
+					device.Name = vf.Ports[0].ID
+				}
+
+				pciKnown[vf.PCIAddress] = true
+				devices = append(devices, device)
+			}
+		}
+	}
+
+	if pci != nil {
+		for _, dev := range pci.Devices {
+			if pciKnown[dev.PCIAddress] {
+				continue
+			}
+
+			pciKnown[dev.PCIAddress] = true
+			devices = append(devices, api.ClusterDevice{
+				Type:      TypePCI,
+				Address:   dev.PCIAddress,
+				Vendor:    dev.Vendor,
+				VendorID:  dev.VendorID,
+				Product:   dev.Product,
+				ProductID: dev.ProductID,
+			})
+		}
+	}
+
+	if usb != nil {
+		for _, dev := range usb.Devices {
+			devices = append(devices, api.ClusterDevice{
+				Type:      TypeUSB,
+				Address:   usbAddress(dev.BusAddress, dev.DeviceAddress),
+				Vendor:    dev.Vendor,
+				VendorID:  dev.VendorID,
+				Product:   dev.Product,
+				ProductID: dev.ProductID,
+				Serial:    dev.Serial,
+			})
+		}
+	}
+
+	sort.SliceStable(devices, func(i, j int) bool {
+		if devices[i].Type != devices[j].Type {
+			return devices[i].Type < devices[j].Type
+		}
+
+		return devices[i].Address < devices[j].Address
+	})
+
+	return devices
+}
+
+// usbAddress returns the address of a USB device from its bus and device numbers.
+func usbAddress(busNum uint64, devNum uint64) string {
+	return fmt.Sprintf("%03d:%03d", busNum, devNum)
+}
@@ -0,0 +1,149 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestDevices(t *testing.T) {
+	gpu := &api.ResourcesGPU{
+		Cards: []api.ResourcesGPUCard{
+			{PCIAddress: "0000:03:00.0", VendorID: "10de", ProductID: "2235", DRM: &api.ResourcesGPUCardDRM{CardName: "card0"}},
+		},
+	}
+
+	network := &api.ResourcesNetwork{
+		Cards: []api.ResourcesNetworkCard{
+			{
+				PCIAddress: "0000:05:00.0",
+				Ports:      []api.ResourcesNetworkCardPort{{ID: "enp5s0f0"}},
+				SRIOV: &api.ResourcesNetworkCardSRIOV{
+					VFs: []api.ResourcesNetworkCard{
+						{PCIAddress: "0000:05:00.2", Ports: []api.ResourcesNetworkCardPort{{ID: "enp5s0f0v0"}}},
+					},
+				},
+			},
+		},
+	}
+
+	pci := &api.ResourcesPCI{
+		Devices: []api.ResourcesPCIDevice{
+			{PCIAddress: "0000:03:00.0"},
+			{PCIAddress: "0000:05:00.0"},
+			{PCIAddress: "0000:05:00.2"},
+		},
+	}
+
+	usb := &api.ResourcesUSB{
+		Devices: []api.ResourcesUSBDevice{
+			{BusAddress: 1, DeviceAddress: 4, VendorID: "0403", ProductID: "6001", Serial: "A1"},
+		},
+	}
+
+	devices := Devices(usb, pci, gpu, network)
+	require.Len(t, devices, 4)
+
+	assert.Equal(t, api.ClusterDevice{Type: TypeGPU, Address: "0000:03:00.0", Name: "card0", VendorID: "10de", ProductID: "2235"}, devices[0])
+	assert.Equal(t, api.ClusterDevice{Type: TypePCI, Address: "0000:05:00.0"}, devices[1])
+	assert.Equal(t, api.ClusterDevice{Type: TypeSRIOVVF, Address: "0000:05:00.2", Name: "enp5s0f0v0", Parent: "enp5s0f0"}, devices[2])
+	assert.Equal(t, api.ClusterDevice{Type: TypeUSB, Address: "001:004", VendorID: "0403", ProductID: "6001", Serial: "A1"}, devices[3])
+}
+
+func TestRequirements(t *testing.T) {
+	devices := map[string]map[string]string{
+		"eth0":  {"type": "nic", "nictype": "sriov", "parent": "enp5s0f0"},
+		"eth1":  {"type": "nic", "nictype": "bridged", "parent": "br0"},
+		"gpu0":  {"type": "gpu", "vendorid": "10de"},
+		"gpu1":  {"type": "gpu", "gputype": "mig"},
+		"pci0":  {"type": "pci", "address": "03:00.0"},
+		"root":  {"type": "disk", "path": "/", "pool": "default"},
+		"usb0":  {"type": "usb", "vendorid": "0403"},
+		"usb1":  {"type": "usb", "vendorid": "0403", "required": "true"},
+		"other": {"type": "unix-char", "path": "/dev/null"},
+	}
+
+	reqs := Requirements(instancetype.VM, devices)
+	names := []string{}
+	for _, req := range reqs {
+		names = append(names, req.DeviceName)
+		assert.True(t, req.Exclusive, req.DeviceName)
+	}
+
+	assert.Equal(t, []string{"eth0", "gpu0", "pci0", "usb1"}, names)
+
+	reqs = Requirements(instancetype.Container, devices)
+	exclusive := map[string]bool{}
+	for _, req := range reqs {
+		exclusive[req.DeviceName] = req.Exclusive
+	}
+
+	assert.Equal(t, map[string]bool{"eth0": true, "gpu0": false, "pci0": true, "usb1": false}, exclusive)
+}
+
+func TestRequirementMatches(t *testing.T) {
+	gpu := api.ClusterDevice{Type: TypeGPU, Address: "0000:03:00.0", Name: "card1", VendorID: "10de", ProductID: "2235"}
+	usb := api.ClusterDevice{Type: TypeUSB, Address: "001:004", VendorID: "0403", ProductID: "6001", Serial: "A1"}
+	vf := api.ClusterDevice{Type: TypeSRIOVVF, Address: "0000:05:00.2", Parent: "enp5s0f0"}
+
+	tests := []struct {
+		name   string
+		config map[string]string
+		device api.ClusterDevice
+		match  bool
+	}{
+		{name: "gpu vendor", config: map[string]string{"type": "gpu", "vendorid": "10de"}, device: gpu, match: true},
+		{name: "gpu other vendor", config: map[string]string{"type": "gpu", "vendorid": "1002"}, device: gpu, match: false},
+		{name: "gpu id", config: map[string]string{"type": "gpu", "id": "1"}, device: gpu, match: true},
+		{name: "gpu short pci", config: map[string]string{"type": "gpu", "pci": "03:00.0"}, device: gpu, match: true},
+		{name: "pci on gpu", config: map[string]string{"type": "pci", "address": "03:00.0"}, device: gpu, match: true},
+		{name: "pci on usb", config: map[string]string{"type": "pci", "address": "03:00.0"}, device: usb, match: false},
+		{name: "usb product", config: map[string]string{"type": "usb", "vendorid": "0403", "productid": "6001"}, device: usb, match: true},
+		{name: "usb serial", config: map[string]string{"type": "usb", "serial": "B2"}, device: usb, match: false},
+		{name: "usb bus", config: map[string]string{"type": "usb", "busnum": "1", "devnum": "4"}, device: usb, match: true},
+		{name: "usb other bus", config: map[string]string{"type": "usb", "busnum": "2"}, device: usb, match: false},
+		{name: "nic parent", config: map[string]string{"type": "nic", "nictype": "sriov", "parent": "enp5s0f0"}, device: vf, match: true},
+		{name: "nic other parent", config: map[string]string{"type": "nic", "nictype": "sriov", "parent": "enp5s0f1"}, device: vf, match: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Requirement{DeviceName: "dev", Config: tt.config}
+			assert.Equal(t, tt.match, req.Matches(tt.device))
+		})
+	}
+}
+
+func TestAssign(t *testing.T) {
+	devices := []api.ClusterDevice{
+		{Type: TypeGPU, Address: "0000:03:00.0", VendorID: "10de"},
+		{Type: TypeGPU, Address: "0000:04:00.0", VendorID: "10de"},
+	}
+
+	// The first requirement could take either GPU but must leave the second one the only GPU it matches.
+	reqs := []Requirement{
+		{DeviceName: "gpu0", Config: map[string]string{"type": "gpu", "vendorid": "10de"}, Exclusive: true},
+		{DeviceName: "gpu1", Config: map[string]string{"type": "gpu", "pci": "0000:03:00.0"}, Exclusive: true},
+	}
+
+	allocations, err := Assign(reqs, devices, []bool{true, true})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"gpu0": 1, "gpu1": 0}, allocations)
+
+	// No free device left for the second requirement.
+	_, err = Assign(reqs, devices, []bool{true, false})
+	assert.Error(t, err)
+
+	// Shared requirements can use devices allocated to other instances.
+	shared := []Requirement{{DeviceName: "gpu0", Config: map[string]string{"type": "gpu"}}}
+	allocations, err = Assign(shared, devices, []bool{false, false})
+	require.NoError(t, err)
+	assert.Empty(t, allocations)
+
+	_, err = Assign(shared, nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,186 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/canonical/lxd/lxd/device/pci"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Requirement represents an instance device that needs a matching device on the cluster member hosting the instance.
+type Requirement struct {
+	DeviceName string
+	Config     map[string]string
+
+	// Exclusive is true when the matching device is passed through and can't be used by other instances.
+	Exclusive bool
+}
+
+// Requirements returns the requirements of the instance devices, sorted by device name.
+// Only required USB, PCI, physical GPU and SR-IOV NIC devices specifying their parent interface are taken into account.
+func Requirements(instType instancetype.Type, devices map[string]map[string]string) []Requirement {
+	reqs := []Requirement{}
+
+	for name, config := range devices {
+		req := Requirement{DeviceName: name, Config: config}
+
+		switch config["type"] {
+		case "usb":
+			// USB devices are only needed when required to start the instance.
+			// They're passed through to VMs but only shared with containers.
+			if !shared.IsTrue(config["required"]) {
+				continue
+			}
+
+			req.Exclusive = instType == instancetype.VM
+
+		case "pci":
+			req.Exclusive = true
+
+		case "gpu":
+			if !shared.ValueInSlice(config["gputype"], []string{"", "physical"}) {
+				continue
+			}
+
+			req.Exclusive = instType == instancetype.VM
+
+		case "nic":
+			if config["nictype"] != "sriov" || config["parent"] == "" {
+				continue
+			}
+
+			req.Exclusive = true
+
+		default:
+			continue
+		}
+
+		reqs = append(reqs, req)
+	}
+
+	sort.Slice(reqs, func(i, j int) bool {
+		return reqs[i].DeviceName < reqs[j].DeviceName
+	})
+
+	return reqs
+}
+
+// Matches returns whether the device satisfies the requirement.
+func (r Requirement) Matches(device api.ClusterDevice) bool {
+	config := r.Config
+
+	switch config["type"] {
+	case "usb":
+		if device.Type != TypeUSB {
+			return false
+		}
+
+		if config["busnum"] != "" || config["devnum"] != "" {
+			var busNum, devNum uint64
+			_, err := fmt.Sscanf(device.Address, "%d:%d", &busNum, &devNum)
+			if err != nil {
+				return false
+			}
+
+			if config["busnum"] != "" && config["busnum"] != strconv.FormatUint(busNum, 10) {
+				return false
+			}
+
+			if config["devnum"] != "" && config["devnum"] != strconv.FormatUint(devNum, 10) {
+				return false
+			}
+		}
+
+		return (config["vendorid"] == "" || config["vendorid"] == device.VendorID) &&
+			(config["productid"] == "" || config["productid"] == device.ProductID) &&
+			(config["serial"] == "" || config["serial"] == device.Serial)
+
+	case "pci":
+		// Any PCI device can be passed through, whatever its more specific type.
+		return device.Type != TypeUSB && device.Address == pci.NormaliseAddress(config["address"])
+
+	case "gpu":
+		if device.Type != TypeGPU {
+			return false
+		}
+
+		return (config["vendorid"] == "" || config["vendorid"] == device.VendorID) &&
+			(config["productid"] == "" || config["productid"] == device.ProductID) &&
+			(config["pci"] == "" || pci.NormaliseAddress(config["pci"]) == device.Address) &&
+			(config["id"] == "" || "card"+config["id"] == device.Name)
+
+	case "nic":
+		return device.Type == TypeSRIOVVF && device.Parent == config["parent"]
+	}
+
+	return false
+}
+
+// Assign allocates a distinct device to each exclusive requirement and checks that a matching device exists for
+// each other requirement. Only the devices marked as available can be allocated, but shared requirements can be
+// satisfied by any device. Returns the index of the device allocated to each exclusive requirement, keyed on the
+// instance device name.
+func Assign(reqs []Requirement, devices []api.ClusterDevice, available []bool) (map[string]int, error) {
+	exclusive := []Requirement{}
+	for _, req := range reqs {
+		if req.Exclusive {
+			exclusive = append(exclusive, req)
+			continue
+		}
+
+		found := false
+		for _, device := range devices {
+			if req.Matches(device) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("No device matching %q", req.DeviceName)
+		}
+	}
+
+	// Allocate the devices using augmenting paths so that a requirement matching several devices doesn't take
+	// the only device matching another requirement.
+	allocatedTo := make([]int, len(devices))
+	for i := range allocatedTo {
+		allocatedTo[i] = -1
+	}
+
+	var allocate func(reqIndex int, visited []bool) bool
+	allocate = func(reqIndex int, visited []bool) bool {
+		for i, device := range devices {
+			if visited[i] || !available[i] || !exclusive[reqIndex].Matches(device) {
+				continue
+			}
+
+			visited[i] = true
+			if allocatedTo[i] < 0 || allocate(allocatedTo[i], visited) {
+				allocatedTo[i] = reqIndex
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for reqIndex, req := range exclusive {
+		if !allocate(reqIndex, make([]bool, len(devices))) {
+			return nil, fmt.Errorf("No free device matching %q", req.DeviceName)
+		}
+	}
+
+	allocations := make(map[string]int, len(exclusive))
+	for i, reqIndex := range allocatedTo {
+		if reqIndex >= 0 {
+			allocations[exclusive[reqIndex].DeviceName] = i
+		}
+	}
+
+	return allocations, nil
+}
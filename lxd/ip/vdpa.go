@@ -2,7 +2,10 @@ package ip
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
@@ -63,7 +66,9 @@ const (
 // vDPA device classes.
 const (
 	vdpaBusDevDir   = "/sys/bus/vdpa/devices"
+	vdpaBusDrvDir   = "/sys/bus/vdpa/drivers"
 	vdpaVhostDevDir = "/dev"
+	vdpaVhostDriver = "vhost_vdpa"
 )
 
 // VhostVdpa is the vhost-vdpa device information.
@@ -315,31 +320,40 @@ func ListVDPADevices() ([]*VDPADev, error) {
 	return devices, nil
 }
 
-// AddVDPADevice adds a new vDPA device.
+// AddVDPADevice adds a new vDPA device on the PCI management device with the given slot name.
 func AddVDPADevice(pciDevSlotName string, volatile map[string]string) (*VDPADev, error) {
-	// List existing vDPA devices
-	vdpaDevs, err := ListVDPADevices()
-	if err != nil {
-		return nil, err
+	return CreateVDPADevice(&MgmtVDPADev{BusName: "pci", DevName: pciDevSlotName}, nil, 0, volatile)
+}
+
+// CreateVDPADevice creates a new vDPA device on the management device and binds it to the vhost-vdpa driver.
+// The MAC address and MTU of the device are only set when provided.
+func CreateVDPADevice(mgmtDev *MgmtVDPADev, hwaddr net.HardwareAddr, mtu uint16, volatile map[string]string) (*VDPADev, error) {
+	// List existing vDPA devices from sysfs, as devices that aren't bound to vhost-vdpa can't be listed
+	// using ListVDPADevices.
+	entries, err := os.ReadDir(vdpaBusDevDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("Failed listing vDPA devices: %w", err)
 	}
 
 	existingVDPADevNames := make(map[string]struct{})
-	for _, vdpaDev := range vdpaDevs {
-		existingVDPADevNames[vdpaDev.Name] = struct{}{}
+	for _, entry := range entries {
+		existingVDPADevNames[entry.Name()] = struct{}{}
 	}
 
 	// Create the netlink attributes
 	header := []*nl.RtAttr{}
-	busName, err := newNetlinkAttribute(vDPAAttrMgmtDevBusName, "pci")
-	if err != nil {
-		return nil, fmt.Errorf("Failed creating vDPA `busName` netlink attr : %v", err)
-	}
+	if mgmtDev.BusName != "" {
+		busName, err := newNetlinkAttribute(vDPAAttrMgmtDevBusName, mgmtDev.BusName)
+		if err != nil {
+			return nil, fmt.Errorf("Failed creating vDPA `busName` netlink attr : %v", err)
+		}
 
-	header = append(header, busName)
+		header = append(header, busName)
+	}
 
-	mgmtDevDevName, err := newNetlinkAttribute(vDPAAttrMgmtDevDevName, pciDevSlotName)
+	mgmtDevDevName, err := newNetlinkAttribute(vDPAAttrMgmtDevDevName, mgmtDev.DevName)
 	if err != nil {
-		return nil, fmt.Errorf("Failed creating vDPA `pciDevSlotName` netlink attr : %v", err)
+		return nil, fmt.Errorf("Failed creating vDPA `mgmtDevDevName` netlink attr : %v", err)
 	}
 
 	header = append(header, mgmtDevDevName)
@@ -370,28 +384,79 @@ func AddVDPADevice(pciDevSlotName string, volatile map[string]string) (*VDPADev,
 
 	header = append(header, maxVQP)
 
+	if hwaddr != nil {
+		macAddr, err := newNetlinkAttribute(vDPAAttrDevNetCfgMacAddr, []byte(hwaddr))
+		if err != nil {
+			return nil, fmt.Errorf("Failed creating vDPA `hwaddr` netlink attr : %v", err)
+		}
+
+		header = append(header, macAddr)
+	}
+
+	if mtu > 0 {
+		mtuAttr, err := newNetlinkAttribute(vDPAAttrGetNetCfgMTU, mtu)
+		if err != nil {
+			return nil, fmt.Errorf("Failed creating vDPA `mtu` netlink attr : %v", err)
+		}
+
+		header = append(header, mtuAttr)
+	}
+
 	_, err = runVDPANetlinkCmd(vDPACmdDevNew, 0, header)
 	if err != nil {
 		return nil, fmt.Errorf("Failed creating vDPA device : %v", err)
 	}
 
+	// Update the volatile map
+	volatile["last_state.vdpa.name"] = generatedVDPADevName
+
+	// The device may have been claimed by another vDPA bus driver (such as virtio_vdpa), move it to vhost_vdpa.
+	err = bindVDPADeviceToVhost(generatedVDPADevName)
+	if err != nil {
+		_ = DeleteVDPADevice(generatedVDPADevName)
+		return nil, err
+	}
+
 	// Now that the vDPA device has been created in the kernel, return the VDPADev struct
 	msgs, err := runVDPANetlinkCmd(vDPACmdDevGet, 0, []*nl.RtAttr{devName})
 	if err != nil {
 		return nil, fmt.Errorf("Failed getting vDPA device : %v", err)
 	}
 
-	vdpaDevs, err = parseVDPADevList(msgs)
+	vdpaDevs, err := parseVDPADevList(msgs)
 	if err != nil {
 		return nil, fmt.Errorf("Failed parsing vDPA device : %v", err)
 	}
 
-	// Update the volatile map
-	volatile["last_state.vdpa.name"] = generatedVDPADevName
-
 	return vdpaDevs[0], nil
 }
 
+// bindVDPADeviceToVhost binds the vDPA device to the vhost_vdpa driver if it's bound to another driver.
+func bindVDPADeviceToVhost(vDPADevName string) error {
+	driverPath, err := filepath.EvalSymlinks(filepath.Join(vdpaBusDevDir, vDPADevName, "driver"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("Failed getting driver of vDPA device %q: %w", vDPADevName, err)
+	}
+
+	if driverPath != "" {
+		if filepath.Base(driverPath) == vdpaVhostDriver {
+			return nil
+		}
+
+		err = os.WriteFile(filepath.Join(driverPath, "unbind"), []byte(vDPADevName), 0200)
+		if err != nil {
+			return fmt.Errorf("Failed unbinding vDPA device %q from driver %q: %w", vDPADevName, filepath.Base(driverPath), err)
+		}
+	}
+
+	err = os.WriteFile(filepath.Join(vdpaBusDrvDir, vdpaVhostDriver, "bind"), []byte(vDPADevName), 0200)
+	if err != nil {
+		return fmt.Errorf("Failed binding vDPA device %q to driver %q: %w", vDPADevName, vdpaVhostDriver, err)
+	}
+
+	return nil
+}
+
 // DeleteVDPADevice deletes a vDPA management device.
 func DeleteVDPADevice(vDPADevName string) error {
 	header := []*nl.RtAttr{}
@@ -10,13 +10,14 @@ type InstanceLogAction string
 
 // All supported lifecycle events for instance logs.
 const (
-	InstanceLogRetrieved = InstanceLogAction(api.EventLifecycleInstanceLogRetrieved)
-	InstanceLogDeleted   = InstanceLogAction(api.EventLifecycleInstanceLogDeleted)
+	InstanceLogRetrieved    = InstanceLogAction(api.EventLifecycleInstanceLogRetrieved)
+	InstanceLogDeleted      = InstanceLogAction(api.EventLifecycleInstanceLogDeleted)
+	InstanceSessionRecorded = InstanceLogAction(api.EventLifecycleInstanceSessionRecorded)
 )
 
 // Event creates the lifecycle event for an action on an instance log.
 func (a InstanceLogAction) Event(file string, inst instance, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
-	u := api.NewURL().Path(version.APIVersion, "instances", inst.Name(), "backups", file).Project(inst.Project().Name)
+	u := api.NewURL().Path(version.APIVersion, "instances", inst.Name(), "logs", file).Project(inst.Project().Name)
 
 	return api.EventLifecycle{
 		Action:    string(a),
@@ -0,0 +1,28 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// NetworkIngressAction represents a lifecycle event action for network ingresses.
+type NetworkIngressAction string
+
+// All supported lifecycle events for network ingresses.
+const (
+	NetworkIngressCreated = NetworkIngressAction(api.EventLifecycleNetworkIngressCreated)
+	NetworkIngressDeleted = NetworkIngressAction(api.EventLifecycleNetworkIngressDeleted)
+	NetworkIngressUpdated = NetworkIngressAction(api.EventLifecycleNetworkIngressUpdated)
+)
+
+// Event creates the lifecycle event for an action on a network ingress.
+func (a NetworkIngressAction) Event(n network, hostname string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "networks", n.Name(), "ingresses", hostname).Project(n.Project())
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
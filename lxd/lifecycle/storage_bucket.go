@@ -16,14 +16,21 @@ type StorageBucketAction string
 // StorageBucketKeyAction represents a lifecycle event action for storage bucket keys.
 type StorageBucketKeyAction string
 
-// All supported lifecycle events for storage buckets and keys.
+// StorageBucketBackupAction represents a lifecycle event action for storage bucket backups.
+type StorageBucketBackupAction string
+
+// All supported lifecycle events for storage buckets, keys and backups.
 const (
-	StorageBucketCreated    = StorageBucketAction(api.EventLifecycleStorageBucketCreated)
-	StorageBucketDeleted    = StorageBucketAction(api.EventLifecycleStorageBucketDeleted)
-	StorageBucketUpdated    = StorageBucketAction(api.EventLifecycleStorageBucketUpdated)
-	StorageBucketKeyCreated = StorageBucketKeyAction(api.EventLifecycleStorageBucketKeyCreated)
-	StorageBucketKeyDeleted = StorageBucketKeyAction(api.EventLifecycleStorageBucketKeyDeleted)
-	StorageBucketKeyUpdated = StorageBucketKeyAction(api.EventLifecycleStorageBucketKeyUpdated)
+	StorageBucketCreated         = StorageBucketAction(api.EventLifecycleStorageBucketCreated)
+	StorageBucketDeleted         = StorageBucketAction(api.EventLifecycleStorageBucketDeleted)
+	StorageBucketUpdated         = StorageBucketAction(api.EventLifecycleStorageBucketUpdated)
+	StorageBucketKeyCreated      = StorageBucketKeyAction(api.EventLifecycleStorageBucketKeyCreated)
+	StorageBucketKeyDeleted      = StorageBucketKeyAction(api.EventLifecycleStorageBucketKeyDeleted)
+	StorageBucketKeyUpdated      = StorageBucketKeyAction(api.EventLifecycleStorageBucketKeyUpdated)
+	StorageBucketBackupCreated   = StorageBucketBackupAction(api.EventLifecycleStorageBucketBackupCreated)
+	StorageBucketBackupDeleted   = StorageBucketBackupAction(api.EventLifecycleStorageBucketBackupDeleted)
+	StorageBucketBackupRenamed   = StorageBucketBackupAction(api.EventLifecycleStorageBucketBackupRenamed)
+	StorageBucketBackupRetrieved = StorageBucketBackupAction(api.EventLifecycleStorageBucketBackupRetrieved)
 )
 
 // Event creates the lifecycle event for an action on a storage bucket.
@@ -49,3 +56,17 @@ func (a StorageBucketKeyAction) Event(pool pool, projectName string, bucketName
 		Requestor: requestor,
 	}
 }
+
+// Event creates the lifecycle event for an action on a storage bucket backup.
+func (a StorageBucketBackupAction) Event(poolName string, projectName string, fullBackupName string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	bucketName, backupName, _ := api.GetParentAndSnapshotName(fullBackupName)
+
+	u := api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "buckets", bucketName, "backups", backupName).Project(projectName)
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
@@ -20,6 +20,7 @@ package main
 #include <linux/seccomp.h>
 #include <linux/filter.h>
 #include <linux/audit.h>
+#include <sys/prctl.h>
 #include <sys/ptrace.h>
 #include <sys/wait.h>
 
@@ -34,6 +35,7 @@ package main
 
 #include "../shared/netutils/netns_getifaddrs.c"
 
+__ro_after_init bool checkpoint_restore_aware = false;
 __ro_after_init bool core_scheduling_aware = false;
 __ro_after_init bool close_range_aware = false;
 __ro_after_init bool tiocgptpeer_aware = false;
@@ -524,6 +526,26 @@ static void is_core_scheduling_aware(void)
 	core_scheduling_aware = true;
 }
 
+#ifndef PR_SET_MM
+#define PR_SET_MM 35
+#endif
+
+#ifndef PR_SET_MM_MAP_SIZE
+#define PR_SET_MM_MAP_SIZE 15
+#endif
+
+// The kernel only handles PR_SET_MM_MAP_SIZE when built with CONFIG_CHECKPOINT_RESTORE
+// which CRIU relies on to checkpoint and restore processes.
+static void is_checkpoint_restore_aware(void)
+{
+	unsigned int size = 0;
+
+	if (prctl(PR_SET_MM, PR_SET_MM_MAP_SIZE, (unsigned long)&size, 0, 0))
+		return;
+
+	checkpoint_restore_aware = true;
+}
+
 void checkfeature(void)
 {
 	__do_close int hostnetns_fd = -EBADF, newnetns_fd = -EBADF, pidfd = -EBADF;
@@ -535,6 +557,7 @@ void checkfeature(void)
 	is_tiocgptpeer_aware();
 	is_close_range_aware();
 	is_core_scheduling_aware();
+	is_checkpoint_restore_aware();
 
 	if (pidfd >= 0)
 		pidfd_setns_aware = !setns(pidfd, CLONE_NEWNET);
@@ -634,3 +657,7 @@ func canUsePidFdSetns() bool {
 func canUseCoreScheduling() bool {
 	return bool(C.core_scheduling_aware)
 }
+
+func canUseCheckpointRestore() bool {
+	return bool(C.checkpoint_restore_aware)
+}
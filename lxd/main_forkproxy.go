@@ -241,9 +241,13 @@ void forkproxy(void)
 import "C"
 
 import (
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
 	"os"
 	"os/signal"
 	"strconv"
@@ -283,7 +287,7 @@ type udpSession struct {
 func (c *cmdForkproxy) Command() *cobra.Command {
 	// Main subcommand
 	cmd := &cobra.Command{}
-	cmd.Use = "forkproxy <listen PID> <listen PidFd> <listen address> <connect PID> <connect PidFd> <connect address> <log path> <pid path> <listen gid> <listen uid> <listen mode> <security gid> <security uid>"
+	cmd.Use = "forkproxy <listen PID> <listen PidFd> <listen address> <connect PID> <connect PidFd> <connect address> <log path> <pid path> <listen gid> <listen uid> <listen mode> <security gid> <security uid> <proxy protocol> <TLS certificate fd> <TLS key fd> <proxy mode> <HTTP routes>"
 	cmd.Short = "Setup network connection proxying"
 	cmd.Long = `Description:
   Setup network connection proxying
@@ -291,8 +295,12 @@ func (c *cmdForkproxy) Command() *cobra.Command {
   This internal command will spawn a new proxy process for a particular
   container, connecting one side to the host and the other to the
   container.
+
+  If file descriptors for a TLS certificate and key are passed, TLS is
+  terminated on the listener. In "http" proxy mode, the requests are
+  routed to the connect address or to one of the HTTP routes.
 `
-	cmd.Args = cobra.ExactArgs(12)
+	cmd.Args = cobra.ExactArgs(16)
 	cmd.RunE = c.Run
 	cmd.Hidden = true
 
@@ -309,7 +317,7 @@ func rearmUDPFd(epFd C.int, connFd C.int) {
 	}
 }
 
-func listenerInstance(epFd C.int, lAddr *deviceConfig.ProxyAddress, cAddr *deviceConfig.ProxyAddress, connFd C.int, lStruct *lStruct, proxy bool) error {
+func listenerInstance(epFd C.int, lAddr *deviceConfig.ProxyAddress, cAddr *deviceConfig.ProxyAddress, connFd C.int, lStruct *lStruct, proxy bool, tlsConfig *tls.Config, httpListener *proxyHTTPListener) error {
 	// Single or multiple port -> single port
 	connectAddr := cAddr.Address
 	if cAddr.ConnType != "unix" {
@@ -354,6 +362,16 @@ func listenerInstance(epFd C.int, lAddr *deviceConfig.ProxyAddress, cAddr *devic
 		return err
 	}
 
+	if tlsConfig != nil {
+		srcConn = tls.Server(srcConn, tlsConfig)
+	}
+
+	// In HTTP mode, the HTTP server connects to the target of each request.
+	if httpListener != nil {
+		httpListener.conns <- srcConn
+		return nil
+	}
+
 	dstConn, err := net.Dial(cAddr.ConnType, connectAddr)
 	if err != nil {
 		_ = srcConn.Close()
@@ -410,7 +428,7 @@ func (c *cmdForkproxy) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Quick checks.
-	if len(args) != 12 {
+	if len(args) != 16 {
 		_ = cmd.Help()
 
 		if len(args) == 0 {
@@ -615,6 +633,39 @@ func (c *cmdForkproxy) Run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Setup TLS termination if requested.
+	var tlsConfig *tls.Config
+	if args[12] != "" {
+		cert, err := proxyLoadCertificate(args[12], args[13])
+		if err != nil {
+			fmt.Printf("Error: Failed to load TLS certificate: %v\n", err)
+			return err
+		}
+
+		tlsConfig = &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{cert},
+		}
+
+		// The HTTP server only speaks HTTP/1.1.
+		if args[14] == "http" {
+			tlsConfig.NextProtos = []string{"http/1.1"}
+		}
+	}
+
+	// Setup the HTTP server routing the requests in HTTP mode.
+	var httpListener *proxyHTTPListener
+	if args[14] == "http" {
+		routes, err := network.ProxyParseHTTPRoutes(args[15])
+		if err != nil {
+			fmt.Printf("Error: Failed to parse HTTP routes: %v\n", err)
+			return err
+		}
+
+		httpListener = &proxyHTTPListener{conns: make(chan net.Conn, 16)}
+		go func() { _ = proxyHTTPServer(cAddr, routes).Serve(httpListener) }()
+	}
+
 	// Handle SIGTERM which is sent when the proxy is to be removed
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, unix.SIGTERM)
@@ -683,7 +734,7 @@ func (c *cmdForkproxy) Run(cmd *cobra.Command, args []string) error {
 				continue
 			}
 
-			err := listenerInstance(epFd, lAddr, cAddr, curFd, srcConn, args[11] == "true")
+			err := listenerInstance(epFd, lAddr, cAddr, curFd, srcConn, args[11] == "true", tlsConfig, httpListener)
 			if err != nil {
 				fmt.Printf("Warning: Failed to prepare new listener instance: %v\n", err)
 			}
@@ -694,6 +745,133 @@ func (c *cmdForkproxy) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// proxyLoadCertificate loads the TLS certificate and key passed as file descriptors.
+func proxyLoadCertificate(certFd string, keyFd string) (tls.Certificate, error) {
+	readFd := func(value string) ([]byte, error) {
+		fd, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+
+		f := os.NewFile(uintptr(fd), value)
+		if f == nil {
+			return nil, fmt.Errorf("Invalid file descriptor %d", fd)
+		}
+
+		defer func() { _ = f.Close() }()
+
+		return io.ReadAll(f)
+	}
+
+	cert, err := readFd(certFd)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	key, err := readFd(keyFd)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.X509KeyPair(cert, key)
+}
+
+// proxyHTTPListener hands the connections accepted by forkproxy over to the HTTP server.
+type proxyHTTPListener struct {
+	conns chan net.Conn
+}
+
+// Accept waits for and returns the next connection.
+func (l *proxyHTTPListener) Accept() (net.Conn, error) {
+	conn, ok := <-l.conns
+	if !ok {
+		return nil, net.ErrClosed
+	}
+
+	return conn, nil
+}
+
+// Close is a no-op as the listeners are owned by forkproxy.
+func (l *proxyHTTPListener) Close() error {
+	return nil
+}
+
+// Addr returns a placeholder address as the connections come from several listeners.
+func (l *proxyHTTPListener) Addr() net.Addr {
+	return &net.TCPAddr{}
+}
+
+// proxyHTTPMatchRoute returns the route matching the host and path of a request, or nil if none matches.
+// Routes for the host of the request take precedence over routes for any host, then the longest path wins.
+func proxyHTTPMatchRoute(routes []deviceConfig.ProxyHTTPRoute, host string, path string) *deviceConfig.ProxyHTTPRoute {
+	hostName, _, err := net.SplitHostPort(host)
+	if err == nil {
+		host = hostName
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	var match *deviceConfig.ProxyHTTPRoute
+	for i := range routes {
+		route := &routes[i]
+
+		if route.Host != "" && route.Host != host {
+			continue
+		}
+
+		if route.Path != "/" && path != route.Path && !strings.HasPrefix(path, route.Path+"/") {
+			continue
+		}
+
+		if match != nil {
+			if match.Host != "" && route.Host == "" {
+				continue
+			}
+
+			if (match.Host == "") == (route.Host == "") && len(route.Path) <= len(match.Path) {
+				continue
+			}
+		}
+
+		match = route
+	}
+
+	return match
+}
+
+// proxyHTTPServer returns an HTTP server sending each request to the target of its route,
+// or to the connect address if no route matches.
+func proxyHTTPServer(cAddr *deviceConfig.ProxyAddress, routes []deviceConfig.ProxyHTTPRoute) *http.Server {
+	reverseProxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			target := cAddr
+			route := proxyHTTPMatchRoute(routes, r.In.Host, r.In.URL.Path)
+			if route != nil {
+				target = &route.Connect
+			}
+
+			r.SetURL(&url.URL{
+				Scheme: "http",
+				Host:   net.JoinHostPort(target.Address, fmt.Sprintf("%d", target.Ports[0])),
+			})
+
+			// Keep the original host so the target can tell the applications apart.
+			r.Out.Host = r.In.Host
+			r.SetXForwarded()
+		},
+		// Always connect directly, ignoring any proxy set in the environment.
+		Transport: &http.Transport{
+			DialContext:         (&net.Dialer{}).DialContext,
+			MaxIdleConnsPerHost: 16,
+		},
+	}
+
+	return &http.Server{
+		Handler:           reverseProxy,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+}
+
 func proxyCopy(dst net.Conn, src net.Conn) error {
 	var err error
 
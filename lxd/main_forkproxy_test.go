@@ -190,3 +190,108 @@ func TestParseAddr(t *testing.T) {
 		require.Equal(t, tt.expected, addr)
 	}
 }
+
+func TestParseHTTPRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		routes     string
+		expected   []deviceConfig.ProxyHTTPRoute
+		shouldFail bool
+	}{
+		{
+			"Host and path routes",
+			"App1.example.com=tcp:127.0.0.1:8001, example.com/api/=tcp:[::1]:8002,/static=tcp:127.0.0.1:8003",
+			[]deviceConfig.ProxyHTTPRoute{
+				{
+					Host:    "app1.example.com",
+					Path:    "/",
+					Connect: deviceConfig.ProxyAddress{ConnType: "tcp", Address: "127.0.0.1", Ports: []uint64{8001}},
+				},
+				{
+					Host:    "example.com",
+					Path:    "/api",
+					Connect: deviceConfig.ProxyAddress{ConnType: "tcp", Address: "::1", Ports: []uint64{8002}},
+				},
+				{
+					Host:    "",
+					Path:    "/static",
+					Connect: deviceConfig.ProxyAddress{ConnType: "tcp", Address: "127.0.0.1", Ports: []uint64{8003}},
+				},
+			},
+			false,
+		},
+		{
+			"Missing connect address",
+			"example.com",
+			nil,
+			true,
+		},
+		{
+			"Invalid host",
+			"exa_mple.com=tcp:127.0.0.1:8001",
+			nil,
+			true,
+		},
+		{
+			"Non TCP connect address",
+			"example.com=unix:/run/app.sock",
+			nil,
+			true,
+		},
+		{
+			"Port range",
+			"example.com=tcp:127.0.0.1:8001-8002",
+			nil,
+			true,
+		},
+		{
+			"Duplicate route",
+			"example.com/api=tcp:127.0.0.1:8001,example.com/api/=tcp:127.0.0.1:8002",
+			nil,
+			true,
+		},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		routes, err := network.ProxyParseHTTPRoutes(tt.routes)
+		if tt.shouldFail {
+			require.Error(t, err)
+			require.Nil(t, routes)
+			continue
+		}
+
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, routes)
+	}
+}
+
+func TestHTTPMatchRoute(t *testing.T) {
+	routes, err := network.ProxyParseHTTPRoutes("app1.example.com=tcp:127.0.0.1:8001,app1.example.com/api=tcp:127.0.0.1:8002,/api=tcp:127.0.0.1:8003,/api/v2=tcp:127.0.0.1:8004")
+	require.NoError(t, err)
+
+	tests := []struct {
+		host     string
+		path     string
+		expected uint64
+	}{
+		{"app1.example.com", "/", 8001},
+		{"APP1.example.com:8443", "/index.html", 8001},
+		{"app1.example.com", "/api/v2/users", 8002},
+		{"app1.example.com", "/apis", 8001},
+		{"app2.example.com", "/api", 8003},
+		{"app2.example.com", "/api/v2/users", 8004},
+		{"app2.example.com", "/", 0},
+	}
+
+	for _, tt := range tests {
+		route := proxyHTTPMatchRoute(routes, tt.host, tt.path)
+		if tt.expected == 0 {
+			require.Nil(t, route, "%s%s", tt.host, tt.path)
+			continue
+		}
+
+		require.NotNil(t, route, "%s%s", tt.host, tt.path)
+		require.Equal(t, tt.expected, route.Connect.Ports[0], "%s%s", tt.host, tt.path)
+	}
+}
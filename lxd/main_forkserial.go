@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+type cmdForkserial struct {
+	global *cmdGlobal
+}
+
+func (c *cmdForkserial) Command() *cobra.Command {
+	// Main subcommand
+	cmd := &cobra.Command{}
+	cmd.Use = "forkserial <listen address> <log path>"
+	cmd.Short = "Expose a serial port of a container"
+	cmd.Long = `Description:
+  Expose a serial port of a container
+
+  This internal command is used to bridge the pty of a serial device to a
+  Unix socket or a TCP listener (unix:<path> or tcp:<address>:<port>) and to
+  log everything the container writes to the serial port. The log is rotated
+  once it reaches 10MiB, keeping a single previous log.
+
+  The pty multiplexer and the pty must be passed as file descriptors 3 and 4.
+  Only one client can be connected at a time, a new client replaces the
+  previous one.
+`
+	cmd.RunE = c.Run
+	cmd.Hidden = true
+
+	return cmd
+}
+
+func (c *cmdForkserial) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	if len(args) != 2 {
+		_ = cmd.Help()
+
+		if len(args) == 0 {
+			return nil
+		}
+
+		return fmt.Errorf("Missing required arguments")
+	}
+
+	network, address, found := strings.Cut(args[0], ":")
+	if !found || !(network == "unix" || network == "tcp") {
+		return fmt.Errorf("Invalid listen address %q", args[0])
+	}
+
+	ptx := os.NewFile(3, "ptx")
+	if ptx == nil {
+		return fmt.Errorf("Missing pty multiplexer file descriptor")
+	}
+
+	// Keep the pty open so reading from the multiplexer doesn't fail while nothing uses the serial port.
+	pty := os.NewFile(4, "pty")
+	if pty == nil {
+		return fmt.Errorf("Missing pty file descriptor")
+	}
+
+	defer func() { _ = pty.Close() }()
+
+	logFile := &forkserialLog{path: args[1]}
+	err := logFile.open()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = logFile.Close() }()
+
+	// Any stale socket is removed by LXD before starting, an existing file is never replaced.
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return fmt.Errorf("Failed listening on %q: %w", args[0], err)
+	}
+
+	defer func() { _ = listener.Close() }()
+
+	if network == "unix" {
+		err = os.Chmod(address, 0600)
+		if err != nil {
+			return err
+		}
+	}
+
+	var connLock sync.Mutex
+	var client net.Conn
+
+	// Log the output of the serial port and forward it to the connected client.
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := ptx.Read(buf)
+			if n > 0 {
+				_, _ = logFile.Write(buf[:n])
+
+				connLock.Lock()
+				if client != nil {
+					_, _ = client.Write(buf[:n])
+				}
+
+				connLock.Unlock()
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		connLock.Lock()
+		if client != nil {
+			_ = client.Close()
+		}
+
+		client = conn
+		connLock.Unlock()
+
+		// Forward the input of the client to the serial port.
+		go func() {
+			_, _ = io.Copy(ptx, conn)
+
+			connLock.Lock()
+			if client == conn {
+				client = nil
+			}
+
+			connLock.Unlock()
+
+			_ = conn.Close()
+		}()
+	}
+}
+
+// forkserialLogMaxSize is the size at which the serial port log is rotated.
+const forkserialLogMaxSize = 10 * 1024 * 1024
+
+// forkserialLog is the log of a serial port, rotated once it reaches forkserialLogMaxSize.
+type forkserialLog struct {
+	path string
+	file *os.File
+	size int64
+}
+
+// open opens the log file for appending.
+func (l *forkserialLog) open() error {
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed opening log file %q: %w", l.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("Failed getting size of log file %q: %w", l.path, err)
+	}
+
+	l.file = file
+	l.size = info.Size()
+
+	return nil
+}
+
+// Write appends to the log, moving the current log to "<path>.1" first if it would exceed the maximum size.
+func (l *forkserialLog) Write(p []byte) (int, error) {
+	if l.size > 0 && l.size+int64(len(p)) > forkserialLogMaxSize {
+		_ = l.file.Close()
+
+		err := os.Rename(l.path, l.path+".1")
+		if err != nil {
+			return 0, err
+		}
+
+		err = l.open()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := l.file.Write(p)
+	l.size += int64(n)
+
+	return n, err
+}
+
+// Close closes the log file.
+func (l *forkserialLog) Close() error {
+	if l.file == nil {
+		return nil
+	}
+
+	return l.file.Close()
+}
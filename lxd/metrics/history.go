@@ -0,0 +1,263 @@
+package metrics
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// historyCounters holds the usage counters extracted from the metrics of an instance.
+type historyCounters struct {
+	timestamp            time.Time
+	cpuSeconds           float64
+	memoryBytes          float64
+	diskReadBytes        float64
+	diskWrittenBytes     float64
+	networkReceiveBytes  float64
+	networkTransmitBytes float64
+}
+
+// historyEntry is a sample of the history along with the sequence numbers of the full resolution samples it
+// covers.
+type historyEntry struct {
+	sample api.InstanceStateHistorySample
+	first  uint64
+	last   uint64
+}
+
+// historyRing is a fixed size ring buffer of history entries.
+type historyRing struct {
+	entries []historyEntry
+	start   int
+}
+
+// push adds an entry to the ring, replacing the oldest one if the ring is full.
+func (r *historyRing) push(entry historyEntry) {
+	if len(r.entries) < cap(r.entries) {
+		r.entries = append(r.entries, entry)
+		return
+	}
+
+	r.entries[r.start] = entry
+	r.start = (r.start + 1) % len(r.entries)
+}
+
+// list returns the entries of the ring, oldest first.
+func (r *historyRing) list() []historyEntry {
+	entries := make([]historyEntry, 0, len(r.entries))
+	entries = append(entries, r.entries[r.start:]...)
+	entries = append(entries, r.entries[:r.start]...)
+
+	return entries
+}
+
+// History is a bounded history of the resource usage of an instance.
+// The most recent samples are kept at full resolution, older ones are downsampled by averaging groups of
+// consecutive samples into a single coarse sample.
+type History struct {
+	mu sync.Mutex
+
+	interval time.Duration
+	ratio    int
+	fine     historyRing
+	coarse   historyRing
+	pending  []historyEntry
+	seq      uint64
+	last     *historyCounters
+}
+
+// NewHistory returns a new History for metrics collected every interval. It keeps fineSize samples at full
+// resolution and coarseSize samples each averaging ratio full resolution samples.
+func NewHistory(interval time.Duration, fineSize int, coarseSize int, ratio int) *History {
+	// Samples must be downsampled before they're dropped from the full resolution ring.
+	if ratio > fineSize {
+		ratio = fineSize
+	}
+
+	return &History{
+		interval: interval,
+		ratio:    ratio,
+		fine:     historyRing{entries: make([]historyEntry, 0, fineSize)},
+		coarse:   historyRing{entries: make([]historyEntry, 0, coarseSize)},
+		pending:  make([]historyEntry, 0, ratio),
+	}
+}
+
+// Add records the usage between the previously added metric set and this one.
+// No sample is recorded for the first metric set, after a counter reset (e.g. the instance restarted) or when
+// the previous metric set is more than two intervals old.
+func (h *History) Add(metricSet *MetricSet, timestamp time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	current := historyCountersFromMetricSet(metricSet, timestamp)
+	last := h.last
+	h.last = &current
+
+	if last == nil {
+		return
+	}
+
+	elapsed := current.timestamp.Sub(last.timestamp)
+	if elapsed < time.Second || elapsed > 2*h.interval {
+		return
+	}
+
+	deltas := []float64{
+		current.cpuSeconds - last.cpuSeconds,
+		current.diskReadBytes - last.diskReadBytes,
+		current.diskWrittenBytes - last.diskWrittenBytes,
+		current.networkReceiveBytes - last.networkReceiveBytes,
+		current.networkTransmitBytes - last.networkTransmitBytes,
+	}
+
+	for _, delta := range deltas {
+		if delta < 0 {
+			return
+		}
+	}
+
+	seconds := elapsed.Seconds()
+	sample := api.InstanceStateHistorySample{
+		Timestamp:           current.timestamp,
+		Interval:            int64(math.Round(seconds)),
+		CPUUsage:            deltas[0] / seconds,
+		MemoryUsage:         int64(current.memoryBytes),
+		DiskReadRate:        int64(deltas[1] / seconds),
+		DiskWriteRate:       int64(deltas[2] / seconds),
+		NetworkReceiveRate:  int64(deltas[3] / seconds),
+		NetworkTransmitRate: int64(deltas[4] / seconds),
+	}
+
+	h.seq++
+	entry := historyEntry{sample: sample, first: h.seq, last: h.seq}
+	h.fine.push(entry)
+
+	h.pending = append(h.pending, entry)
+	if len(h.pending) >= h.ratio {
+		h.coarse.push(historyDownsample(h.pending))
+		h.pending = h.pending[:0]
+	}
+}
+
+// Samples returns the recorded samples ending after since, oldest first.
+// Downsampled samples are only returned for the period not fully covered by full resolution samples.
+func (h *History) Samples(since time.Time) []api.InstanceStateHistorySample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fine := h.fine.list()
+
+	oldest := h.seq + 1
+	if len(fine) > 0 {
+		oldest = fine[0].first
+	}
+
+	var covered uint64
+	samples := []api.InstanceStateHistorySample{}
+
+	for _, entry := range h.coarse.list() {
+		if entry.first >= oldest {
+			break
+		}
+
+		covered = entry.last
+		if entry.sample.Timestamp.After(since) {
+			samples = append(samples, entry.sample)
+		}
+	}
+
+	for _, entry := range fine {
+		if entry.last <= covered {
+			continue
+		}
+
+		if entry.sample.Timestamp.After(since) {
+			samples = append(samples, entry.sample)
+		}
+	}
+
+	return samples
+}
+
+// historyDownsample averages consecutive samples into a single sample covering all of them.
+func historyDownsample(entries []historyEntry) historyEntry {
+	var cpuUsage, memoryUsage, diskReadRate, diskWriteRate, networkReceiveRate, networkTransmitRate float64
+
+	last := entries[len(entries)-1]
+	out := api.InstanceStateHistorySample{Timestamp: last.sample.Timestamp}
+	for _, entry := range entries {
+		sample := entry.sample
+		weight := float64(sample.Interval)
+		out.Interval += sample.Interval
+
+		cpuUsage += sample.CPUUsage * weight
+		memoryUsage += float64(sample.MemoryUsage) * weight
+		diskReadRate += float64(sample.DiskReadRate) * weight
+		diskWriteRate += float64(sample.DiskWriteRate) * weight
+		networkReceiveRate += float64(sample.NetworkReceiveRate) * weight
+		networkTransmitRate += float64(sample.NetworkTransmitRate) * weight
+	}
+
+	entry := historyEntry{sample: out, first: entries[0].first, last: last.last}
+	if out.Interval == 0 {
+		return entry
+	}
+
+	total := float64(out.Interval)
+	out.CPUUsage = cpuUsage / total
+	out.MemoryUsage = int64(memoryUsage / total)
+	out.DiskReadRate = int64(diskReadRate / total)
+	out.DiskWriteRate = int64(diskWriteRate / total)
+	out.NetworkReceiveRate = int64(networkReceiveRate / total)
+	out.NetworkTransmitRate = int64(networkTransmitRate / total)
+	entry.sample = out
+
+	return entry
+}
+
+// historyCountersFromMetricSet extracts the usage counters from the metrics of an instance.
+func historyCountersFromMetricSet(m *MetricSet, timestamp time.Time) historyCounters {
+	sum := func(metricType MetricType, skip func(labels map[string]string) bool) float64 {
+		var total float64
+		for _, sample := range m.set[metricType] {
+			if skip != nil && skip(sample.Labels) {
+				continue
+			}
+
+			total += sample.Value
+		}
+
+		return total
+	}
+
+	// Idle time is only reported by virtual machines and isn't usage.
+	cpuSkip := func(labels map[string]string) bool {
+		return labels["mode"] == "idle" || labels["mode"] == "iowait"
+	}
+
+	// The loopback traffic of virtual machines never leaves the guest.
+	networkSkip := func(labels map[string]string) bool {
+		return labels["device"] == "lo"
+	}
+
+	counters := historyCounters{
+		timestamp:            timestamp,
+		cpuSeconds:           sum(CPUSecondsTotal, cpuSkip),
+		diskReadBytes:        sum(DiskReadBytesTotal, nil),
+		diskWrittenBytes:     sum(DiskWrittenBytesTotal, nil),
+		networkReceiveBytes:  sum(NetworkReceiveBytesTotal, networkSkip),
+		networkTransmitBytes: sum(NetworkTransmitBytesTotal, networkSkip),
+	}
+
+	// Containers without a memory limit don't report their total and available memory.
+	if len(m.set[MemoryMemTotalBytes]) > 0 && len(m.set[MemoryMemAvailableBytes]) > 0 {
+		counters.memoryBytes = sum(MemoryMemTotalBytes, nil) - sum(MemoryMemAvailableBytes, nil)
+	} else {
+		counters.memoryBytes = sum(MemoryRSSBytes, nil)
+	}
+
+	return counters
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newMetricSet := func(cpuSeconds float64, rxBytes float64, memoryBytes float64) *MetricSet {
+		m := NewMetricSet(map[string]string{"project": "default", "name": "c1"})
+		m.AddSamples(CPUSecondsTotal,
+			Sample{Value: cpuSeconds, Labels: map[string]string{"mode": "user", "cpu": "0"}},
+			Sample{Value: 1000, Labels: map[string]string{"mode": "idle", "cpu": "0"}},
+		)
+		m.AddSamples(NetworkReceiveBytesTotal,
+			Sample{Value: rxBytes, Labels: map[string]string{"device": "eth0"}},
+			Sample{Value: 5000, Labels: map[string]string{"device": "lo"}},
+		)
+		m.AddSamples(MemoryRSSBytes, Sample{Value: memoryBytes})
+
+		return m
+	}
+
+	h := NewHistory(time.Minute, 3, 3, 2)
+
+	// The first metric set only initialises the counters.
+	h.Add(newMetricSet(0, 0, 100), start)
+	require.Empty(t, h.Samples(time.Time{}))
+
+	for i := 1; i <= 6; i++ {
+		h.Add(newMetricSet(float64(i*30), float64(i*600), float64(i*100)), start.Add(time.Duration(i)*time.Minute))
+	}
+
+	// The downsampled samples are returned for the period not covered by the full resolution samples.
+	samples := h.Samples(time.Time{})
+	require.Len(t, samples, 4)
+
+	for i, sample := range samples[:2] {
+		require.Equal(t, start.Add(time.Duration(2*i+2)*time.Minute), sample.Timestamp)
+		require.Equal(t, int64(120), sample.Interval)
+		require.Equal(t, 0.5, sample.CPUUsage)
+		require.Equal(t, int64(10), sample.NetworkReceiveRate)
+		require.Equal(t, int64(i*200+150), sample.MemoryUsage)
+	}
+
+	for i, sample := range samples[2:] {
+		require.Equal(t, start.Add(time.Duration(i+5)*time.Minute), sample.Timestamp)
+		require.Equal(t, int64(60), sample.Interval)
+		require.Equal(t, 0.5, sample.CPUUsage)
+		require.Equal(t, int64(10), sample.NetworkReceiveRate)
+		require.Equal(t, int64((i+5)*100), sample.MemoryUsage)
+	}
+
+	// Filter on the end of the sampling interval.
+	require.Len(t, h.Samples(start.Add(5*time.Minute)), 1)
+
+	// A counter reset doesn't record a sample.
+	h.Add(newMetricSet(0, 0, 100), start.Add(7*time.Minute))
+	require.Len(t, h.Samples(start.Add(5*time.Minute)), 1)
+
+	// Neither does a gap in the collection.
+	h.Add(newMetricSet(30, 600, 100), start.Add(10*time.Minute))
+	require.Len(t, h.Samples(start.Add(5*time.Minute)), 1)
+
+	h.Add(newMetricSet(60, 1200, 100), start.Add(11*time.Minute))
+	require.Len(t, h.Samples(start.Add(5*time.Minute)), 2)
+}
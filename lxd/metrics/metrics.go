@@ -95,6 +95,7 @@ func (m *MetricSet) String() string {
 		GoGoroutines,
 		GoHeapObjects,
 		Instances,
+		NetworkIngressTargetUp,
 	}
 
 	for _, metricType := range metricTypes {
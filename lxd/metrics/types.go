@@ -146,142 +146,170 @@ const (
 	GoNextGCBytes
 	// Instances represents the instance count.
 	Instances
+	// StoragePoolSpaceTotalBytes represents the total space of a storage pool.
+	StoragePoolSpaceTotalBytes
+	// StoragePoolSpaceUsedBytes represents the used space of a storage pool.
+	StoragePoolSpaceUsedBytes
+	// StoragePoolSpaceProvisionedBytes represents the sum of the volume sizes in a storage pool.
+	StoragePoolSpaceProvisionedBytes
+	// NetworkIngressRequestsTotal represents the number of requests received by a network ingress.
+	NetworkIngressRequestsTotal
+	// NetworkIngressErrorsTotal represents the number of requests of a network ingress which failed with a server error.
+	NetworkIngressErrorsTotal
+	// NetworkIngressTargetUp represents whether a target of a network ingress passes its health checks.
+	NetworkIngressTargetUp
+	// NetworkIngressTargetRequestsTotal represents the number of requests sent to a target of a network ingress.
+	NetworkIngressTargetRequestsTotal
 )
 
 // MetricNames associates a metric type to its name.
 var MetricNames = map[MetricType]string{
-	CPUSecondsTotal:             "lxd_cpu_seconds_total",
-	CPUs:                        "lxd_cpu_effective_total",
-	DiskReadBytesTotal:          "lxd_disk_read_bytes_total",
-	DiskReadsCompletedTotal:     "lxd_disk_reads_completed_total",
-	DiskWrittenBytesTotal:       "lxd_disk_written_bytes_total",
-	DiskWritesCompletedTotal:    "lxd_disk_writes_completed_total",
-	FilesystemAvailBytes:        "lxd_filesystem_avail_bytes",
-	FilesystemFreeBytes:         "lxd_filesystem_free_bytes",
-	FilesystemSizeBytes:         "lxd_filesystem_size_bytes",
-	GoAllocBytes:                "lxd_go_alloc_bytes",
-	GoAllocBytesTotal:           "lxd_go_alloc_bytes_total",
-	GoBuckHashSysBytes:          "lxd_go_buck_hash_sys_bytes",
-	GoFreesTotal:                "lxd_go_frees_total",
-	GoGCSysBytes:                "lxd_go_gc_sys_bytes",
-	GoGoroutines:                "lxd_go_goroutines",
-	GoHeapAllocBytes:            "lxd_go_heap_alloc_bytes",
-	GoHeapIdleBytes:             "lxd_go_heap_idle_bytes",
-	GoHeapInuseBytes:            "lxd_go_heap_inuse_bytes",
-	GoHeapObjects:               "lxd_go_heap_objects",
-	GoHeapReleasedBytes:         "lxd_go_heap_released_bytes",
-	GoHeapSysBytes:              "lxd_go_heap_sys_bytes",
-	GoLookupsTotal:              "lxd_go_lookups_total",
-	GoMallocsTotal:              "lxd_go_mallocs_total",
-	GoMCacheInuseBytes:          "lxd_go_mcache_inuse_bytes",
-	GoMCacheSysBytes:            "lxd_go_mcache_sys_bytes",
-	GoMSpanInuseBytes:           "lxd_go_mspan_inuse_bytes",
-	GoMSpanSysBytes:             "lxd_go_mspan_sys_bytes",
-	GoNextGCBytes:               "lxd_go_next_gc_bytes",
-	GoOtherSysBytes:             "lxd_go_other_sys_bytes",
-	GoStackInuseBytes:           "lxd_go_stack_inuse_bytes",
-	GoStackSysBytes:             "lxd_go_stack_sys_bytes",
-	GoSysBytes:                  "lxd_go_sys_bytes",
-	MemoryActiveAnonBytes:       "lxd_memory_Active_anon_bytes",
-	MemoryActiveFileBytes:       "lxd_memory_Active_file_bytes",
-	MemoryActiveBytes:           "lxd_memory_Active_bytes",
-	MemoryCachedBytes:           "lxd_memory_Cached_bytes",
-	MemoryDirtyBytes:            "lxd_memory_Dirty_bytes",
-	MemoryHugePagesFreeBytes:    "lxd_memory_HugepagesFree_bytes",
-	MemoryHugePagesTotalBytes:   "lxd_memory_HugepagesTotal_bytes",
-	MemoryInactiveAnonBytes:     "lxd_memory_Inactive_anon_bytes",
-	MemoryInactiveFileBytes:     "lxd_memory_Inactive_file_bytes",
-	MemoryInactiveBytes:         "lxd_memory_Inactive_bytes",
-	MemoryMappedBytes:           "lxd_memory_Mapped_bytes",
-	MemoryMemAvailableBytes:     "lxd_memory_MemAvailable_bytes",
-	MemoryMemFreeBytes:          "lxd_memory_MemFree_bytes",
-	MemoryMemTotalBytes:         "lxd_memory_MemTotal_bytes",
-	MemoryRSSBytes:              "lxd_memory_RSS_bytes",
-	MemoryShmemBytes:            "lxd_memory_Shmem_bytes",
-	MemorySwapBytes:             "lxd_memory_Swap_bytes",
-	MemoryUnevictableBytes:      "lxd_memory_Unevictable_bytes",
-	MemoryWritebackBytes:        "lxd_memory_Writeback_bytes",
-	MemoryOOMKillsTotal:         "lxd_memory_OOM_kills_total",
-	NetworkReceiveBytesTotal:    "lxd_network_receive_bytes_total",
-	NetworkReceiveDropTotal:     "lxd_network_receive_drop_total",
-	NetworkReceiveErrsTotal:     "lxd_network_receive_errs_total",
-	NetworkReceivePacketsTotal:  "lxd_network_receive_packets_total",
-	NetworkTransmitBytesTotal:   "lxd_network_transmit_bytes_total",
-	NetworkTransmitDropTotal:    "lxd_network_transmit_drop_total",
-	NetworkTransmitErrsTotal:    "lxd_network_transmit_errs_total",
-	NetworkTransmitPacketsTotal: "lxd_network_transmit_packets_total",
-	OperationsTotal:             "lxd_operations_total",
-	ProcsTotal:                  "lxd_procs_total",
-	UptimeSeconds:               "lxd_uptime_seconds",
-	WarningsTotal:               "lxd_warnings_total",
-	Instances:                   "lxd_instances",
+	CPUSecondsTotal:                   "lxd_cpu_seconds_total",
+	CPUs:                              "lxd_cpu_effective_total",
+	DiskReadBytesTotal:                "lxd_disk_read_bytes_total",
+	DiskReadsCompletedTotal:           "lxd_disk_reads_completed_total",
+	DiskWrittenBytesTotal:             "lxd_disk_written_bytes_total",
+	DiskWritesCompletedTotal:          "lxd_disk_writes_completed_total",
+	FilesystemAvailBytes:              "lxd_filesystem_avail_bytes",
+	FilesystemFreeBytes:               "lxd_filesystem_free_bytes",
+	FilesystemSizeBytes:               "lxd_filesystem_size_bytes",
+	GoAllocBytes:                      "lxd_go_alloc_bytes",
+	GoAllocBytesTotal:                 "lxd_go_alloc_bytes_total",
+	GoBuckHashSysBytes:                "lxd_go_buck_hash_sys_bytes",
+	GoFreesTotal:                      "lxd_go_frees_total",
+	GoGCSysBytes:                      "lxd_go_gc_sys_bytes",
+	GoGoroutines:                      "lxd_go_goroutines",
+	GoHeapAllocBytes:                  "lxd_go_heap_alloc_bytes",
+	GoHeapIdleBytes:                   "lxd_go_heap_idle_bytes",
+	GoHeapInuseBytes:                  "lxd_go_heap_inuse_bytes",
+	GoHeapObjects:                     "lxd_go_heap_objects",
+	GoHeapReleasedBytes:               "lxd_go_heap_released_bytes",
+	GoHeapSysBytes:                    "lxd_go_heap_sys_bytes",
+	GoLookupsTotal:                    "lxd_go_lookups_total",
+	GoMallocsTotal:                    "lxd_go_mallocs_total",
+	GoMCacheInuseBytes:                "lxd_go_mcache_inuse_bytes",
+	GoMCacheSysBytes:                  "lxd_go_mcache_sys_bytes",
+	GoMSpanInuseBytes:                 "lxd_go_mspan_inuse_bytes",
+	GoMSpanSysBytes:                   "lxd_go_mspan_sys_bytes",
+	GoNextGCBytes:                     "lxd_go_next_gc_bytes",
+	GoOtherSysBytes:                   "lxd_go_other_sys_bytes",
+	GoStackInuseBytes:                 "lxd_go_stack_inuse_bytes",
+	GoStackSysBytes:                   "lxd_go_stack_sys_bytes",
+	GoSysBytes:                        "lxd_go_sys_bytes",
+	MemoryActiveAnonBytes:             "lxd_memory_Active_anon_bytes",
+	MemoryActiveFileBytes:             "lxd_memory_Active_file_bytes",
+	MemoryActiveBytes:                 "lxd_memory_Active_bytes",
+	MemoryCachedBytes:                 "lxd_memory_Cached_bytes",
+	MemoryDirtyBytes:                  "lxd_memory_Dirty_bytes",
+	MemoryHugePagesFreeBytes:          "lxd_memory_HugepagesFree_bytes",
+	MemoryHugePagesTotalBytes:         "lxd_memory_HugepagesTotal_bytes",
+	MemoryInactiveAnonBytes:           "lxd_memory_Inactive_anon_bytes",
+	MemoryInactiveFileBytes:           "lxd_memory_Inactive_file_bytes",
+	MemoryInactiveBytes:               "lxd_memory_Inactive_bytes",
+	MemoryMappedBytes:                 "lxd_memory_Mapped_bytes",
+	MemoryMemAvailableBytes:           "lxd_memory_MemAvailable_bytes",
+	MemoryMemFreeBytes:                "lxd_memory_MemFree_bytes",
+	MemoryMemTotalBytes:               "lxd_memory_MemTotal_bytes",
+	MemoryRSSBytes:                    "lxd_memory_RSS_bytes",
+	MemoryShmemBytes:                  "lxd_memory_Shmem_bytes",
+	MemorySwapBytes:                   "lxd_memory_Swap_bytes",
+	MemoryUnevictableBytes:            "lxd_memory_Unevictable_bytes",
+	MemoryWritebackBytes:              "lxd_memory_Writeback_bytes",
+	MemoryOOMKillsTotal:               "lxd_memory_OOM_kills_total",
+	NetworkReceiveBytesTotal:          "lxd_network_receive_bytes_total",
+	NetworkReceiveDropTotal:           "lxd_network_receive_drop_total",
+	NetworkReceiveErrsTotal:           "lxd_network_receive_errs_total",
+	NetworkReceivePacketsTotal:        "lxd_network_receive_packets_total",
+	NetworkTransmitBytesTotal:         "lxd_network_transmit_bytes_total",
+	NetworkTransmitDropTotal:          "lxd_network_transmit_drop_total",
+	NetworkTransmitErrsTotal:          "lxd_network_transmit_errs_total",
+	NetworkTransmitPacketsTotal:       "lxd_network_transmit_packets_total",
+	OperationsTotal:                   "lxd_operations_total",
+	ProcsTotal:                        "lxd_procs_total",
+	UptimeSeconds:                     "lxd_uptime_seconds",
+	WarningsTotal:                     "lxd_warnings_total",
+	Instances:                         "lxd_instances",
+	StoragePoolSpaceTotalBytes:        "lxd_storage_pool_space_total_bytes",
+	StoragePoolSpaceUsedBytes:         "lxd_storage_pool_space_used_bytes",
+	StoragePoolSpaceProvisionedBytes:  "lxd_storage_pool_space_provisioned_bytes",
+	NetworkIngressRequestsTotal:       "lxd_network_ingress_requests_total",
+	NetworkIngressErrorsTotal:         "lxd_network_ingress_errors_total",
+	NetworkIngressTargetUp:            "lxd_network_ingress_target_up",
+	NetworkIngressTargetRequestsTotal: "lxd_network_ingress_target_requests_total",
 }
 
 // MetricHeaders represents the metric headers which contain help messages as specified by OpenMetrics.
 var MetricHeaders = map[MetricType]string{
-	CPUSecondsTotal:             "# HELP lxd_cpu_seconds_total The total number of CPU time used in seconds.",
-	CPUs:                        "# HELP lxd_cpu_effective_total The total number of effective CPUs.",
-	DiskReadBytesTotal:          "# HELP lxd_disk_read_bytes_total The total number of bytes read.",
-	DiskReadsCompletedTotal:     "# HELP lxd_disk_reads_completed_total The total number of completed reads.",
-	DiskWrittenBytesTotal:       "# HELP lxd_disk_written_bytes_total The total number of bytes written.",
-	DiskWritesCompletedTotal:    "# HELP lxd_disk_writes_completed_total The total number of completed writes.",
-	FilesystemAvailBytes:        "# HELP lxd_filesystem_avail_bytes The number of available space in bytes.",
-	FilesystemFreeBytes:         "# HELP lxd_filesystem_free_bytes The number of free space in bytes.",
-	FilesystemSizeBytes:         "# HELP lxd_filesystem_size_bytes The size of the filesystem in bytes.",
-	GoAllocBytes:                "# HELP lxd_go_alloc_bytes Number of bytes allocated and still in use.",
-	GoAllocBytesTotal:           "# HELP lxd_go_alloc_bytes_total Total number of bytes allocated, even if freed.",
-	GoBuckHashSysBytes:          "# HELP lxd_go_buck_hash_sys_bytes Number of bytes used by the profiling bucket hash table.",
-	GoFreesTotal:                "# HELP lxd_go_frees_total Total number of frees.",
-	GoGCSysBytes:                "# HELP lxd_go_gc_sys_bytes Number of bytes used for garbage collection system metadata.",
-	GoGoroutines:                "# HELP lxd_go_goroutines Number of goroutines that currently exist.",
-	GoHeapAllocBytes:            "# HELP lxd_go_heap_alloc_bytes Number of heap bytes allocated and still in use.",
-	GoHeapIdleBytes:             "# HELP lxd_go_heap_idle_bytes Number of heap bytes waiting to be used.",
-	GoHeapInuseBytes:            "# HELP lxd_go_heap_inuse_bytes Number of heap bytes that are in use.",
-	GoHeapObjects:               "# HELP lxd_go_heap_objects Number of allocated objects.",
-	GoHeapReleasedBytes:         "# HELP lxd_go_heap_released_bytes Number of heap bytes released to OS.",
-	GoHeapSysBytes:              "# HELP lxd_go_heap_sys_bytes Number of heap bytes obtained from system.",
-	GoLookupsTotal:              "# HELP lxd_go_lookups_total Total number of pointer lookups.",
-	GoMallocsTotal:              "# HELP lxd_go_mallocs_total Total number of mallocs.",
-	GoMCacheInuseBytes:          "# HELP lxd_go_mcache_inuse_bytes Number of bytes in use by mcache structures.",
-	GoMCacheSysBytes:            "# HELP lxd_go_mcache_sys_bytes Number of bytes used for mcache structures obtained from system.",
-	GoMSpanInuseBytes:           "# HELP lxd_go_mspan_inuse_bytes Number of bytes in use by mspan structures.",
-	GoMSpanSysBytes:             "# HELP lxd_go_mspan_sys_bytes Number of bytes used for mspan structures obtained from system.",
-	GoNextGCBytes:               "# HELP lxd_go_next_gc_bytes Number of heap bytes when next garbage collection will take place.",
-	GoOtherSysBytes:             "# HELP lxd_go_other_sys_bytes Number of bytes used for other system allocations.",
-	GoStackInuseBytes:           "# HELP lxd_go_stack_inuse_bytes Number of bytes in use by the stack allocator.",
-	GoStackSysBytes:             "# HELP lxd_go_stack_sys_bytes Number of bytes obtained from system for stack allocator.",
-	GoSysBytes:                  "# HELP lxd_go_sys_bytes Number of bytes obtained from system.",
-	MemoryActiveAnonBytes:       "# HELP lxd_memory_Active_anon_bytes The amount of anonymous memory on active LRU list.",
-	MemoryActiveFileBytes:       "# HELP lxd_memory_Active_file_bytes The amount of file-backed memory on active LRU list.",
-	MemoryActiveBytes:           "# HELP lxd_memory_Active_bytes The amount of memory on active LRU list.",
-	MemoryCachedBytes:           "# HELP lxd_memory_Cached_bytes The amount of cached memory.",
-	MemoryDirtyBytes:            "# HELP lxd_memory_Dirty_bytes The amount of memory waiting to get written back to the disk.",
-	MemoryHugePagesFreeBytes:    "# HELP lxd_memory_HugepagesFree_bytes The amount of free memory for hugetlb.",
-	MemoryHugePagesTotalBytes:   "# HELP lxd_memory_HugepagesTotal_bytes The amount of used memory for hugetlb.",
-	MemoryInactiveAnonBytes:     "# HELP lxd_memory_Inactive_anon_bytes The amount of anonymous memory on inactive LRU list.",
-	MemoryInactiveFileBytes:     "# HELP lxd_memory_Inactive_file_bytes The amount of file-backed memory on inactive LRU list.",
-	MemoryInactiveBytes:         "# HELP lxd_memory_Inactive_bytes The amount of memory on inactive LRU list.",
-	MemoryMappedBytes:           "# HELP lxd_memory_Mapped_bytes The amount of mapped memory.",
-	MemoryMemAvailableBytes:     "# HELP lxd_memory_MemAvailable_bytes The amount of available memory.",
-	MemoryMemFreeBytes:          "# HELP lxd_memory_MemFree_bytes The amount of free memory.",
-	MemoryMemTotalBytes:         "# HELP lxd_memory_MemTotal_bytes The amount of used memory.",
-	MemoryRSSBytes:              "# HELP lxd_memory_RSS_bytes The amount of anonymous and swap cache memory.",
-	MemoryShmemBytes:            "# HELP lxd_memory_Shmem_bytes The amount of cached filesystem data that is swap-backed.",
-	MemorySwapBytes:             "# HELP lxd_memory_Swap_bytes The amount of used swap memory.",
-	MemoryUnevictableBytes:      "# HELP lxd_memory_Unevictable_bytes The amount of unevictable memory.",
-	MemoryWritebackBytes:        "# HELP lxd_memory_Writeback_bytes The amount of memory queued for syncing to disk.",
-	MemoryOOMKillsTotal:         "# HELP lxd_memory_OOM_kills_total The number of out of memory kills.",
-	NetworkReceiveBytesTotal:    "# HELP lxd_network_receive_bytes_total The amount of received bytes on a given interface.",
-	NetworkReceiveDropTotal:     "# HELP lxd_network_receive_drop_total The amount of received dropped bytes on a given interface.",
-	NetworkReceiveErrsTotal:     "# HELP lxd_network_receive_errs_total The amount of received errors on a given interface.",
-	NetworkReceivePacketsTotal:  "# HELP lxd_network_receive_packets_total The amount of received packets on a given interface.",
-	NetworkTransmitBytesTotal:   "# HELP lxd_network_transmit_bytes_total The amount of transmitted bytes on a given interface.",
-	NetworkTransmitDropTotal:    "# HELP lxd_network_transmit_drop_total The amount of transmitted dropped bytes on a given interface.",
-	NetworkTransmitErrsTotal:    "# HELP lxd_network_transmit_errs_total The amount of transmitted errors on a given interface.",
-	NetworkTransmitPacketsTotal: "# HELP lxd_network_transmit_packets_total The amount of transmitted packets on a given interface.",
-	OperationsTotal:             "# HELP lxd_operations_total The number of running operations",
-	ProcsTotal:                  "# HELP lxd_procs_total The number of running processes.",
-	UptimeSeconds:               "# HELP lxd_uptime_seconds The daemon uptime in seconds.",
-	WarningsTotal:               "# HELP lxd_warnings_total The number of active warnings.",
-	Instances:                   "# HELP lxd_instances The number of instances.",
+	CPUSecondsTotal:                   "# HELP lxd_cpu_seconds_total The total number of CPU time used in seconds.",
+	CPUs:                              "# HELP lxd_cpu_effective_total The total number of effective CPUs.",
+	DiskReadBytesTotal:                "# HELP lxd_disk_read_bytes_total The total number of bytes read.",
+	DiskReadsCompletedTotal:           "# HELP lxd_disk_reads_completed_total The total number of completed reads.",
+	DiskWrittenBytesTotal:             "# HELP lxd_disk_written_bytes_total The total number of bytes written.",
+	DiskWritesCompletedTotal:          "# HELP lxd_disk_writes_completed_total The total number of completed writes.",
+	FilesystemAvailBytes:              "# HELP lxd_filesystem_avail_bytes The number of available space in bytes.",
+	FilesystemFreeBytes:               "# HELP lxd_filesystem_free_bytes The number of free space in bytes.",
+	FilesystemSizeBytes:               "# HELP lxd_filesystem_size_bytes The size of the filesystem in bytes.",
+	GoAllocBytes:                      "# HELP lxd_go_alloc_bytes Number of bytes allocated and still in use.",
+	GoAllocBytesTotal:                 "# HELP lxd_go_alloc_bytes_total Total number of bytes allocated, even if freed.",
+	GoBuckHashSysBytes:                "# HELP lxd_go_buck_hash_sys_bytes Number of bytes used by the profiling bucket hash table.",
+	GoFreesTotal:                      "# HELP lxd_go_frees_total Total number of frees.",
+	GoGCSysBytes:                      "# HELP lxd_go_gc_sys_bytes Number of bytes used for garbage collection system metadata.",
+	GoGoroutines:                      "# HELP lxd_go_goroutines Number of goroutines that currently exist.",
+	GoHeapAllocBytes:                  "# HELP lxd_go_heap_alloc_bytes Number of heap bytes allocated and still in use.",
+	GoHeapIdleBytes:                   "# HELP lxd_go_heap_idle_bytes Number of heap bytes waiting to be used.",
+	GoHeapInuseBytes:                  "# HELP lxd_go_heap_inuse_bytes Number of heap bytes that are in use.",
+	GoHeapObjects:                     "# HELP lxd_go_heap_objects Number of allocated objects.",
+	GoHeapReleasedBytes:               "# HELP lxd_go_heap_released_bytes Number of heap bytes released to OS.",
+	GoHeapSysBytes:                    "# HELP lxd_go_heap_sys_bytes Number of heap bytes obtained from system.",
+	GoLookupsTotal:                    "# HELP lxd_go_lookups_total Total number of pointer lookups.",
+	GoMallocsTotal:                    "# HELP lxd_go_mallocs_total Total number of mallocs.",
+	GoMCacheInuseBytes:                "# HELP lxd_go_mcache_inuse_bytes Number of bytes in use by mcache structures.",
+	GoMCacheSysBytes:                  "# HELP lxd_go_mcache_sys_bytes Number of bytes used for mcache structures obtained from system.",
+	GoMSpanInuseBytes:                 "# HELP lxd_go_mspan_inuse_bytes Number of bytes in use by mspan structures.",
+	GoMSpanSysBytes:                   "# HELP lxd_go_mspan_sys_bytes Number of bytes used for mspan structures obtained from system.",
+	GoNextGCBytes:                     "# HELP lxd_go_next_gc_bytes Number of heap bytes when next garbage collection will take place.",
+	GoOtherSysBytes:                   "# HELP lxd_go_other_sys_bytes Number of bytes used for other system allocations.",
+	GoStackInuseBytes:                 "# HELP lxd_go_stack_inuse_bytes Number of bytes in use by the stack allocator.",
+	GoStackSysBytes:                   "# HELP lxd_go_stack_sys_bytes Number of bytes obtained from system for stack allocator.",
+	GoSysBytes:                        "# HELP lxd_go_sys_bytes Number of bytes obtained from system.",
+	MemoryActiveAnonBytes:             "# HELP lxd_memory_Active_anon_bytes The amount of anonymous memory on active LRU list.",
+	MemoryActiveFileBytes:             "# HELP lxd_memory_Active_file_bytes The amount of file-backed memory on active LRU list.",
+	MemoryActiveBytes:                 "# HELP lxd_memory_Active_bytes The amount of memory on active LRU list.",
+	MemoryCachedBytes:                 "# HELP lxd_memory_Cached_bytes The amount of cached memory.",
+	MemoryDirtyBytes:                  "# HELP lxd_memory_Dirty_bytes The amount of memory waiting to get written back to the disk.",
+	MemoryHugePagesFreeBytes:          "# HELP lxd_memory_HugepagesFree_bytes The amount of free memory for hugetlb.",
+	MemoryHugePagesTotalBytes:         "# HELP lxd_memory_HugepagesTotal_bytes The amount of used memory for hugetlb.",
+	MemoryInactiveAnonBytes:           "# HELP lxd_memory_Inactive_anon_bytes The amount of anonymous memory on inactive LRU list.",
+	MemoryInactiveFileBytes:           "# HELP lxd_memory_Inactive_file_bytes The amount of file-backed memory on inactive LRU list.",
+	MemoryInactiveBytes:               "# HELP lxd_memory_Inactive_bytes The amount of memory on inactive LRU list.",
+	MemoryMappedBytes:                 "# HELP lxd_memory_Mapped_bytes The amount of mapped memory.",
+	MemoryMemAvailableBytes:           "# HELP lxd_memory_MemAvailable_bytes The amount of available memory.",
+	MemoryMemFreeBytes:                "# HELP lxd_memory_MemFree_bytes The amount of free memory.",
+	MemoryMemTotalBytes:               "# HELP lxd_memory_MemTotal_bytes The amount of used memory.",
+	MemoryRSSBytes:                    "# HELP lxd_memory_RSS_bytes The amount of anonymous and swap cache memory.",
+	MemoryShmemBytes:                  "# HELP lxd_memory_Shmem_bytes The amount of cached filesystem data that is swap-backed.",
+	MemorySwapBytes:                   "# HELP lxd_memory_Swap_bytes The amount of used swap memory.",
+	MemoryUnevictableBytes:            "# HELP lxd_memory_Unevictable_bytes The amount of unevictable memory.",
+	MemoryWritebackBytes:              "# HELP lxd_memory_Writeback_bytes The amount of memory queued for syncing to disk.",
+	MemoryOOMKillsTotal:               "# HELP lxd_memory_OOM_kills_total The number of out of memory kills.",
+	NetworkReceiveBytesTotal:          "# HELP lxd_network_receive_bytes_total The amount of received bytes on a given interface.",
+	NetworkReceiveDropTotal:           "# HELP lxd_network_receive_drop_total The amount of received dropped bytes on a given interface.",
+	NetworkReceiveErrsTotal:           "# HELP lxd_network_receive_errs_total The amount of received errors on a given interface.",
+	NetworkReceivePacketsTotal:        "# HELP lxd_network_receive_packets_total The amount of received packets on a given interface.",
+	NetworkTransmitBytesTotal:         "# HELP lxd_network_transmit_bytes_total The amount of transmitted bytes on a given interface.",
+	NetworkTransmitDropTotal:          "# HELP lxd_network_transmit_drop_total The amount of transmitted dropped bytes on a given interface.",
+	NetworkTransmitErrsTotal:          "# HELP lxd_network_transmit_errs_total The amount of transmitted errors on a given interface.",
+	NetworkTransmitPacketsTotal:       "# HELP lxd_network_transmit_packets_total The amount of transmitted packets on a given interface.",
+	OperationsTotal:                   "# HELP lxd_operations_total The number of running operations",
+	ProcsTotal:                        "# HELP lxd_procs_total The number of running processes.",
+	UptimeSeconds:                     "# HELP lxd_uptime_seconds The daemon uptime in seconds.",
+	WarningsTotal:                     "# HELP lxd_warnings_total The number of active warnings.",
+	Instances:                         "# HELP lxd_instances The number of instances.",
+	StoragePoolSpaceTotalBytes:        "# HELP lxd_storage_pool_space_total_bytes The total space of the storage pool in bytes.",
+	StoragePoolSpaceUsedBytes:         "# HELP lxd_storage_pool_space_used_bytes The used space of the storage pool in bytes.",
+	StoragePoolSpaceProvisionedBytes:  "# HELP lxd_storage_pool_space_provisioned_bytes The sum of the sizes of the volumes in the storage pool in bytes.",
+	NetworkIngressRequestsTotal:       "# HELP lxd_network_ingress_requests_total The number of requests received by the network ingress.",
+	NetworkIngressErrorsTotal:         "# HELP lxd_network_ingress_errors_total The number of requests of the network ingress which failed with a server error.",
+	NetworkIngressTargetUp:            "# HELP lxd_network_ingress_target_up Whether the network ingress target passes its health checks.",
+	NetworkIngressTargetRequestsTotal: "# HELP lxd_network_ingress_target_requests_total The number of requests sent to the network ingress target.",
 }
@@ -0,0 +1,58 @@
+package migration
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// blockDiffHeaderSize is the size of the header preceding each block of a block diff stream.
+// The header contains the offset and the length of the block as big endian 64bit integers.
+const blockDiffHeaderSize = 16
+
+// WriteBlockDiff writes the data that changed at the given offset of a block volume to a block diff stream.
+func WriteBlockDiff(w io.Writer, offset int64, data []byte) error {
+	header := make([]byte, blockDiffHeaderSize)
+	binary.BigEndian.PutUint64(header[0:8], uint64(offset))
+	binary.BigEndian.PutUint64(header[8:16], uint64(len(data)))
+
+	_, err := w.Write(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ApplyBlockDiff reads a block diff stream until EOF and writes each of its blocks to the target at their offset.
+// Returns the number of bytes written to the target.
+func ApplyBlockDiff(r io.Reader, target io.WriterAt) (int64, error) {
+	header := make([]byte, blockDiffHeaderSize)
+
+	var written int64
+	for {
+		_, err := io.ReadFull(r, header)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return written, nil
+			}
+
+			return written, fmt.Errorf("Failed reading block diff header: %w", err)
+		}
+
+		offset := int64(binary.BigEndian.Uint64(header[0:8]))
+		length := int64(binary.BigEndian.Uint64(header[8:16]))
+
+		n, err := io.CopyN(io.NewOffsetWriter(target, offset), r, length)
+		written += n
+		if err != nil {
+			return written, fmt.Errorf("Failed applying block diff at offset %d: %w", offset, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package migration
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryWriterAt is an in-memory io.WriterAt.
+type memoryWriterAt struct {
+	data []byte
+}
+
+func (m *memoryWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	end := int(off) + len(p)
+	if end > len(m.data) {
+		m.data = append(m.data, make([]byte, end-len(m.data))...)
+	}
+
+	copy(m.data[off:], p)
+
+	return len(p), nil
+}
+
+func TestBlockDiff(t *testing.T) {
+	type block struct {
+		offset int64
+		data   string
+	}
+
+	tests := []struct {
+		name    string
+		target  string
+		blocks  []block
+		result  string
+		written int64
+	}{
+		{
+			name:    "No blocks",
+			target:  "aaaaaaaa",
+			result:  "aaaaaaaa",
+			written: 0,
+		},
+		{
+			name:    "Single block",
+			target:  "aaaaaaaa",
+			blocks:  []block{{offset: 2, data: "bb"}},
+			result:  "aabbaaaa",
+			written: 2,
+		},
+		{
+			name:    "Several blocks",
+			target:  "aaaaaaaa",
+			blocks:  []block{{offset: 0, data: "b"}, {offset: 4, data: "cc"}, {offset: 7, data: "d"}},
+			result:  "baaaccad",
+			written: 4,
+		},
+		{
+			name:    "Block extending the target",
+			target:  "aaaa",
+			blocks:  []block{{offset: 2, data: "bbbb"}},
+			result:  "aabbbb",
+			written: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &bytes.Buffer{}
+			for _, b := range tt.blocks {
+				require.NoError(t, WriteBlockDiff(stream, b.offset, []byte(b.data)))
+			}
+
+			target := &memoryWriterAt{data: []byte(tt.target)}
+			written, err := ApplyBlockDiff(stream, target)
+			require.NoError(t, err)
+			assert.Equal(t, tt.written, written)
+			assert.Equal(t, tt.result, string(target.data))
+		})
+	}
+}
+
+func TestApplyBlockDiffTruncated(t *testing.T) {
+	stream := &bytes.Buffer{}
+	require.NoError(t, WriteBlockDiff(stream, 0, []byte("bbbb")))
+
+	// Truncated header.
+	target := &memoryWriterAt{data: []byte("aaaa")}
+	_, err := ApplyBlockDiff(bytes.NewReader(stream.Bytes()[:8]), target)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	// Truncated data, the received part is applied.
+	target = &memoryWriterAt{data: []byte("aaaa")}
+	written, err := ApplyBlockDiff(bytes.NewReader(stream.Bytes()[:stream.Len()-2]), target)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, int64(2), written)
+	assert.Equal(t, "bbaa", string(target.data))
+}
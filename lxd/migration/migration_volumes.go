@@ -73,6 +73,7 @@ type VolumeTargetArgs struct {
 	TrackProgress         bool
 	Refresh               bool
 	Live                  bool
+	BlockDiff             bool // Indicates the block volume is received as a diff against its current content.
 	VolumeSize            int64
 	ContentType           string
 	VolumeOnly            bool
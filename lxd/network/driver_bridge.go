@@ -70,6 +70,7 @@ func (n *bridge) DBType() db.NetworkType {
 func (n *bridge) Info() Info {
 	info := n.common.Info()
 	info.AddressForwards = true
+	info.Ingresses = true
 
 	return info
 }
@@ -32,6 +32,7 @@ type Info struct {
 	AddressForwards    bool // Indicates if driver supports address forwards.
 	LoadBalancers      bool // Indicates if driver supports load balancers.
 	Peering            bool // Indicates if the driver supports network peering.
+	Ingresses          bool // Indicates if the driver supports ingresses.
 }
 
 // forwardTarget represents a single port forward target.
@@ -281,6 +282,7 @@ func (n *common) Info() Info {
 		NodeSpecificConfig: true,
 		AddressForwards:    false,
 		LoadBalancers:      false,
+		Ingresses:          false,
 	}
 }
 
@@ -1444,3 +1444,54 @@ func ProxyParseAddr(data string) (*deviceConfig.ProxyAddress, error) {
 
 	return newProxyAddr, nil
 }
+
+// ProxyParseHTTPRoutes validates a comma separated list of HTTP routes in the form
+// <host>[/<path>]=<connect address> and parses it into its constituent parts.
+// The host can be omitted to match any host.
+func ProxyParseHTTPRoutes(data string) ([]deviceConfig.ProxyHTTPRoute, error) {
+	routes := []deviceConfig.ProxyHTTPRoute{}
+
+	for _, entry := range shared.SplitNTrimSpace(data, ",", -1, true) {
+		match, connect, found := strings.Cut(entry, "=")
+		if !found || match == "" {
+			return nil, fmt.Errorf("Invalid HTTP route %q, must be <host>[/<path>]=<connect address>", entry)
+		}
+
+		host, path, _ := strings.Cut(match, "/")
+		host = strings.ToLower(host)
+
+		if host != "" && net.ParseIP(host) == nil {
+			for _, label := range strings.Split(host, ".") {
+				err := validate.IsHostname(label)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid host %q in HTTP route %q: %w", host, entry, err)
+				}
+			}
+		}
+
+		connectAddr, err := ProxyParseAddr(connect)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid connect address in HTTP route %q: %w", entry, err)
+		}
+
+		if connectAddr.ConnType != "tcp" || len(connectAddr.Ports) != 1 {
+			return nil, fmt.Errorf("HTTP route %q must connect to a single TCP port", entry)
+		}
+
+		route := deviceConfig.ProxyHTTPRoute{
+			Host:    host,
+			Path:    "/" + strings.TrimSuffix(path, "/"),
+			Connect: *connectAddr,
+		}
+
+		for _, existing := range routes {
+			if existing.Host == route.Host && existing.Path == route.Path {
+				return nil, fmt.Errorf("Duplicate HTTP route for %q", match)
+			}
+		}
+
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
@@ -0,0 +1,1110 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/acme"
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/ingress"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var networkIngressesCmd = APIEndpoint{
+	Path: "networks/{networkName}/ingresses",
+
+	Get:  APIEndpointAction{Handler: networkIngressesGet, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanView, "networkName")},
+	Post: APIEndpointAction{Handler: networkIngressesPost, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanEdit, "networkName")},
+}
+
+var networkIngressCmd = APIEndpoint{
+	Path: "networks/{networkName}/ingresses/{hostname}",
+
+	Delete: APIEndpointAction{Handler: networkIngressDelete, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanDelete, "networkName")},
+	Get:    APIEndpointAction{Handler: networkIngressGet, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanView, "networkName")},
+	Put:    APIEndpointAction{Handler: networkIngressPut, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanEdit, "networkName")},
+	Patch:  APIEndpointAction{Handler: networkIngressPut, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanEdit, "networkName")},
+}
+
+var networkIngressStateCmd = APIEndpoint{
+	Path: "networks/{networkName}/ingresses/{hostname}/state",
+
+	Get: APIEndpointAction{Handler: networkIngressStateGet, AccessHandler: allowPermission(entity.TypeNetwork, auth.EntitlementCanView, "networkName")},
+}
+
+// networkIngressCertificatesLock serializes the requests for network ingress certificates.
+var networkIngressCertificatesLock sync.Mutex
+
+// networkIngressLoadNetwork loads the network of the request and checks it supports ingresses.
+func networkIngressLoadNetwork(d *Daemon, r *http.Request) (network.Network, error) {
+	s := d.State()
+
+	projectName, reqProject, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return nil, err
+	}
+
+	networkName, err := url.PathUnescape(mux.Vars(r)["networkName"])
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading network: %w", err)
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(reqProject.Config, networkName, n.IsManaged()) {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Network not found")
+	}
+
+	if !n.Info().Ingresses {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Network driver %q does not support ingresses", n.Type())
+	}
+
+	return n, nil
+}
+
+// networkIngressValidate validates the ingress served on the local member.
+func networkIngressValidate(s *state.State, n network.Network, ingressID int64, info *api.NetworkIngressesPost) error {
+	err := ingress.ValidateHostname(info.Hostname)
+	if err != nil {
+		return err
+	}
+
+	listenAddress := net.ParseIP(info.ListenAddress)
+	if listenAddress == nil {
+		return fmt.Errorf("Invalid listen address %q", info.ListenAddress)
+	}
+
+	if listenAddress.IsUnspecified() {
+		return fmt.Errorf("Cannot use unspecified address: %q", info.ListenAddress)
+	}
+
+	err = ingress.ValidateConfig(info.Config)
+	if err != nil {
+		return err
+	}
+
+	if shared.IsTrue(info.Config["tls.acme"]) {
+		_, email, _, agreeToS := s.GlobalConfig.ACME()
+		if email == "" || !agreeToS {
+			return fmt.Errorf("Obtaining certificates through ACME requires the %q and %q server options", "acme.email", "acme.agree_tos")
+		}
+	}
+
+	err = ingress.ValidateRoutes(info.Routes)
+	if err != nil {
+		return err
+	}
+
+	// Check the targets are within the network's subnets.
+	var subnets []*net.IPNet
+	for _, key := range []string{"ipv4.address", "ipv6.address"} {
+		_, subnet, err := net.ParseCIDR(n.Config()[key])
+		if err == nil {
+			subnets = append(subnets, subnet)
+		}
+	}
+
+	if len(subnets) == 0 && len(info.Routes) > 0 {
+		return fmt.Errorf("Network %q has no subnets to route to", n.Name())
+	}
+
+	for _, route := range info.Routes {
+		targetAddress := net.ParseIP(route.TargetAddress)
+
+		found := false
+		for _, subnet := range subnets {
+			if network.SubnetContainsIP(subnet, targetAddress) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("Target address %q is not within the network subnets", route.TargetAddress)
+		}
+	}
+
+	return s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Check the listen address isn't used by a network forward on this member.
+		forwards, err := tx.GetProjectNetworkForwardListenAddressesOnMember(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed loading network forwards: %w", err)
+		}
+
+		for _, networkForwards := range forwards {
+			for _, listenAddresses := range networkForwards {
+				for _, forwardAddress := range listenAddresses {
+					if listenAddress.Equal(net.ParseIP(forwardAddress)) {
+						return api.StatusErrorf(http.StatusConflict, "Listen address %q is used by a network forward", info.ListenAddress)
+					}
+				}
+			}
+		}
+
+		// Check the host name isn't already served by another ingress on this member.
+		networks, err := tx.GetNetworkIngressNetworksOnMember(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed loading network ingresses: %w", err)
+		}
+
+		for _, projectNetworks := range networks {
+			for networkID := range projectNetworks {
+				records, err := tx.GetNetworkIngresses(ctx, networkID, true, info.Hostname)
+				if err != nil {
+					return fmt.Errorf("Failed loading network ingresses: %w", err)
+				}
+
+				for recordID := range records {
+					if recordID != ingressID {
+						return api.StatusErrorf(http.StatusConflict, "Host name %q is already served by another network ingress", info.Hostname)
+					}
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// networkIngressServe starts serving the ingress on the local member or updates it.
+func networkIngressServe(d *Daemon, projectName string, networkName string, ingressID int64, record *api.NetworkIngress) error {
+	s := d.State()
+
+	info := ingress.Ingress{
+		Project:       projectName,
+		Network:       networkName,
+		Hostname:      record.Hostname,
+		ListenAddress: record.ListenAddress,
+		Config:        record.Config,
+		Routes:        record.Routes,
+	}
+
+	if record.Config["tls.certificate"] != "" {
+		cert, err := tls.X509KeyPair([]byte(record.Config["tls.certificate"]), []byte(record.Config["tls.key"]))
+		if err != nil {
+			return fmt.Errorf("Failed loading TLS certificate: %w", err)
+		}
+
+		info.Certificate = &cert
+	} else if shared.IsTrue(record.Config["tls.acme"]) {
+		var certificate string
+		var privateKey string
+
+		err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+
+			certificate, privateKey, err = tx.GetNetworkIngressCertificate(ctx, ingressID)
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		// Only serve a previously obtained certificate if it's still valid for the host name.
+		if certificate != "" {
+			cert, err := tls.X509KeyPair([]byte(certificate), []byte(privateKey))
+			if err != nil {
+				return fmt.Errorf("Failed loading TLS certificate: %w", err)
+			}
+
+			leaf, err := x509.ParseCertificate(cert.Certificate[0])
+			if err == nil && leaf.VerifyHostname(record.Hostname) == nil {
+				info.Certificate = &cert
+			}
+		}
+	}
+
+	return d.ingress.Set(info)
+}
+
+// networkIngressRedact returns a copy of the ingress without the private key of its certificate.
+func networkIngressRedact(record *api.NetworkIngress) *api.NetworkIngress {
+	_, ok := record.Config["tls.key"]
+	if !ok {
+		return record
+	}
+
+	redacted := *record
+	redacted.Config = make(map[string]string, len(record.Config))
+	for k, v := range record.Config {
+		if k != "tls.key" {
+			redacted.Config[k] = v
+		}
+	}
+
+	return &redacted
+}
+
+// networkIngressesStart starts serving the network ingresses of the local member.
+func networkIngressesStart(d *Daemon) error {
+	s := d.State()
+
+	type localIngress struct {
+		projectName string
+		networkName string
+		record      *api.NetworkIngress
+	}
+
+	ingresses := map[int64]localIngress{}
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		networks, err := tx.GetNetworkIngressNetworksOnMember(ctx)
+		if err != nil {
+			return err
+		}
+
+		for projectName, projectNetworks := range networks {
+			for networkID, networkName := range projectNetworks {
+				records, err := tx.GetNetworkIngresses(ctx, networkID, true)
+				if err != nil {
+					return err
+				}
+
+				for ingressID, record := range records {
+					ingresses[ingressID] = localIngress{projectName: projectName, networkName: networkName, record: record}
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading network ingresses: %w", err)
+	}
+
+	for ingressID, i := range ingresses {
+		err := networkIngressServe(d, i.projectName, i.networkName, ingressID, i.record)
+		if err != nil {
+			logger.Error("Failed starting network ingress", logger.Ctx{"project": i.projectName, "network": i.networkName, "hostname": i.record.Hostname, "err": err})
+		}
+	}
+
+	return nil
+}
+
+// networkIngressCertificatesUpdate obtains the missing or expiring ACME certificates of the network
+// ingresses of the local member.
+func networkIngressCertificatesUpdate(d *Daemon) error {
+	s := d.State()
+
+	networkIngressCertificatesLock.Lock()
+	defer networkIngressCertificatesLock.Unlock()
+
+	_, email, caURL, agreeToS := s.GlobalConfig.ACME()
+
+	type acmeIngress struct {
+		projectName string
+		networkName string
+		record      *api.NetworkIngress
+		certificate string
+		privateKey  string
+	}
+
+	ingresses := map[int64]*acmeIngress{}
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		networks, err := tx.GetNetworkIngressNetworksOnMember(ctx)
+		if err != nil {
+			return err
+		}
+
+		for projectName, projectNetworks := range networks {
+			for networkID, networkName := range projectNetworks {
+				records, err := tx.GetNetworkIngresses(ctx, networkID, true)
+				if err != nil {
+					return err
+				}
+
+				for ingressID, record := range records {
+					if !shared.IsTrue(record.Config["tls.acme"]) {
+						continue
+					}
+
+					i := &acmeIngress{projectName: projectName, networkName: networkName, record: record}
+
+					i.certificate, i.privateKey, err = tx.GetNetworkIngressCertificate(ctx, ingressID)
+					if err != nil {
+						return err
+					}
+
+					ingresses[ingressID] = i
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading network ingresses: %w", err)
+	}
+
+	var lastErr error
+
+	for ingressID, i := range ingresses {
+		l := logger.AddContext(logger.Ctx{"project": i.projectName, "network": i.networkName, "hostname": i.record.Hostname})
+
+		if i.certificate != "" {
+			cert, err := tls.X509KeyPair([]byte(i.certificate), []byte(i.privateKey))
+			if err == nil {
+				leaf, err := x509.ParseCertificate(cert.Certificate[0])
+				if err == nil && !acme.CertificateNeedsUpdate(i.record.Hostname, leaf) {
+					continue
+				}
+			}
+		}
+
+		if email == "" || !agreeToS {
+			err := fmt.Errorf("Obtaining certificates through ACME requires the %q and %q server options", "acme.email", "acme.agree_tos")
+			d.ingress.SetCertificateError(i.record.Hostname, err)
+			lastErr = err
+			continue
+		}
+
+		newCert, err := acme.IssueCertificate(d.ingress, i.record.Hostname, email, caURL)
+		if err != nil {
+			l.Warn("Failed obtaining network ingress certificate", logger.Ctx{"err": err})
+			d.ingress.SetCertificateError(i.record.Hostname, err)
+			lastErr = err
+			continue
+		}
+
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateNetworkIngressCertificate(ctx, ingressID, string(newCert.Certificate), string(newCert.PrivateKey))
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		d.ingress.SetCertificateError(i.record.Hostname, nil)
+
+		err = networkIngressServe(d, i.projectName, i.networkName, ingressID, i.record)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		l.Info("Updated network ingress certificate")
+	}
+
+	return lastErr
+}
+
+// networkIngressCertificatesRenew starts an operation obtaining the missing or expiring ACME certificates
+// of the network ingresses of the local member. If wait is true, it waits for the operation to complete.
+func networkIngressCertificatesRenew(ctx context.Context, d *Daemon, wait bool) error {
+	s := d.State()
+
+	opRun := func(op *operations.Operation) error {
+		return networkIngressCertificatesUpdate(d)
+	}
+
+	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.RenewNetworkIngressCertificates, nil, nil, opRun, nil, nil, nil)
+	if err != nil {
+		logger.Error("Failed creating renew network ingress certificates operation", logger.Ctx{"err": err})
+		return err
+	}
+
+	err = op.Start()
+	if err != nil {
+		logger.Error("Failed starting renew network ingress certificates operation", logger.Ctx{"err": err})
+		return err
+	}
+
+	if !wait {
+		return nil
+	}
+
+	err = op.Wait(ctx)
+	if err != nil {
+		logger.Error("Failed renewing network ingress certificates", logger.Ctx{"err": err})
+		return err
+	}
+
+	return nil
+}
+
+func networkIngressCertificatesTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		_ = networkIngressCertificatesRenew(ctx, d, true)
+	}
+
+	return f, task.Daily()
+}
+
+// API endpoints
+
+// swagger:operation GET /1.0/networks/{networkName}/ingresses network-ingresses network_ingresses_get
+//
+//  Get the network ingresses
+//
+//  Returns a list of network ingresses (URLs).
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of endpoints
+//            items:
+//              type: string
+//            example: |-
+//              [
+//                "/1.0/networks/lxdbr0/ingresses/www.example.net",
+//                "/1.0/networks/lxdbr0/ingresses/api.example.net"
+//              ]
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/networks/{networkName}/ingresses?recursion=1 network-ingresses network_ingresses_get_recursion1
+//
+//	Get the network ingresses
+//
+//	Returns a list of network ingresses (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of network ingresses
+//	          items:
+//	            $ref: "#/definitions/NetworkIngress"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIngressesGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	n, err := networkIngressLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	memberSpecific := false // Get ingresses for all cluster members.
+
+	var records map[int64]*api.NetworkIngress
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		records, err = tx.GetNetworkIngresses(ctx, n.ID(), memberSpecific)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network ingresses: %w", err))
+	}
+
+	if util.IsRecursionRequest(r) {
+		ingresses := make([]*api.NetworkIngress, 0, len(records))
+		for _, record := range records {
+			ingresses = append(ingresses, networkIngressRedact(record))
+		}
+
+		return response.SyncResponse(true, ingresses)
+	}
+
+	ingressURLs := make([]string, 0, len(records))
+	for _, record := range records {
+		ingressURLs = append(ingressURLs, fmt.Sprintf("/%s/networks/%s/ingresses/%s", version.APIVersion, url.PathEscape(n.Name()), url.PathEscape(record.Hostname)))
+	}
+
+	return response.SyncResponse(true, ingressURLs)
+}
+
+// swagger:operation POST /1.0/networks/{networkName}/ingresses network-ingresses network_ingresses_post
+//
+//	Add a network ingress
+//
+//	Creates a new network ingress on the cluster member handling the request.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	  - in: body
+//	    name: ingress
+//	    description: Ingress
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkIngressesPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIngressesPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	// Parse the request into a record.
+	req := api.NetworkIngressesPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	req.Normalise() // So we handle the request in normalised/canonical form.
+
+	n, err := networkIngressLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = networkIngressValidate(s, n, -1, &req)
+	if err != nil {
+		return response.SmartError(api.StatusErrorf(http.StatusBadRequest, "Failed validating ingress: %w", err))
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	var ingressID int64
+	var record *api.NetworkIngress
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		ingressID, err = tx.CreateNetworkIngress(ctx, n.ID(), &req)
+		if err != nil {
+			return err
+		}
+
+		_, record, err = tx.GetNetworkIngress(ctx, n.ID(), true, req.Hostname)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed creating ingress: %w", err))
+	}
+
+	reverter.Add(func() {
+		_ = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.DeleteNetworkIngress(ctx, n.ID(), ingressID)
+		})
+	})
+
+	err = networkIngressServe(d, n.Project(), n.Name(), ingressID, record)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed starting ingress: %w", err))
+	}
+
+	reverter.Success()
+
+	if shared.IsTrue(record.Config["tls.acme"]) {
+		_ = networkIngressCertificatesRenew(s.ShutdownCtx, d, false)
+	}
+
+	lc := lifecycle.NetworkIngressCreated.Event(n, req.Hostname, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(n.Project(), lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// networkIngressLoad loads the ingress of the request.
+// Returns a forwarded response if the ingress is located on another cluster member.
+func networkIngressLoad(d *Daemon, r *http.Request, n network.Network) (int64, *api.NetworkIngress, response.Response) {
+	s := d.State()
+
+	hostname, err := url.PathUnescape(mux.Vars(r)["hostname"])
+	if err != nil {
+		return -1, nil, response.SmartError(err)
+	}
+
+	targetMember := request.QueryParam(r, "target")
+	memberSpecific := targetMember != ""
+
+	var ingressID int64
+	var record *api.NetworkIngress
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		ingressID, record, err = tx.GetNetworkIngress(ctx, n.ID(), memberSpecific, hostname)
+
+		return err
+	})
+	if err != nil {
+		return -1, nil, response.SmartError(err)
+	}
+
+	// The ingress is served by a single member, send the request there.
+	if s.ServerClustered && record.Location != s.ServerName {
+		resp := forwardedResponseToNode(s, r, record.Location)
+		if resp != nil {
+			return -1, nil, resp
+		}
+	}
+
+	return ingressID, record, nil
+}
+
+// swagger:operation DELETE /1.0/networks/{networkName}/ingresses/{hostname} network-ingresses network_ingress_delete
+//
+//	Delete the network ingress
+//
+//	Removes the network ingress.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIngressDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	n, err := networkIngressLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	ingressID, record, resp := networkIngressLoad(d, r, n)
+	if resp != nil {
+		return resp
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteNetworkIngress(ctx, n.ID(), ingressID)
+	})
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed deleting ingress: %w", err))
+	}
+
+	d.ingress.Remove(record.Hostname)
+
+	s.Events.SendLifecycle(n.Project(), lifecycle.NetworkIngressDeleted.Event(n, record.Hostname, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/networks/{networkName}/ingresses/{hostname} network-ingresses network_ingress_get
+//
+//	Get the network ingress
+//
+//	Gets a specific network ingress.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Network ingress
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkIngress"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIngressGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	n, err := networkIngressLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	hostname, err := url.PathUnescape(mux.Vars(r)["hostname"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	targetMember := request.QueryParam(r, "target")
+	memberSpecific := targetMember != ""
+
+	var record *api.NetworkIngress
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, record, err = tx.GetNetworkIngress(ctx, n.ID(), memberSpecific, hostname)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	record = networkIngressRedact(record)
+
+	return response.SyncResponseETag(true, record, record.Etag())
+}
+
+// swagger:operation PATCH /1.0/networks/{networkName}/ingresses/{hostname} network-ingresses network_ingress_patch
+//
+//  Partially update the network ingress
+//
+//  Updates a subset of the network ingress configuration.
+//
+//  ---
+//  consumes:
+//    - application/json
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//    - in: body
+//      name: ingress
+//      description: Ingress configuration
+//      required: true
+//      schema:
+//        $ref: "#/definitions/NetworkIngressPut"
+//  responses:
+//    "200":
+//      $ref: "#/responses/EmptySyncResponse"
+//    "400":
+//      $ref: "#/responses/BadRequest"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "412":
+//      $ref: "#/responses/PreconditionFailed"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/networks/{networkName}/ingresses/{hostname} network-ingresses network_ingress_put
+//
+//	Update the network ingress
+//
+//	Updates the entire network ingress configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: ingress
+//	    description: Ingress configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkIngressPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIngressPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	n, err := networkIngressLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	ingressID, record, resp := networkIngressLoad(d, r, n)
+	if resp != nil {
+		return resp
+	}
+
+	// Validate the ETag against the ingress as returned to clients.
+	err = util.EtagCheck(r, networkIngressRedact(record).Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	// Decode the request.
+	req := api.NetworkIngressPut{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	if r.Method == http.MethodPatch {
+		// If config being updated via "patch" method, then merge all existing config with the keys that
+		// are present in the request config.
+		for k, v := range record.Config {
+			_, ok := req.Config[k]
+			if !ok {
+				req.Config[k] = v
+			}
+		}
+
+		// If ingress being updated via "patch" method and routes not specified, then merge existing routes
+		// into ingress.
+		if req.Routes == nil {
+			req.Routes = record.Routes
+		}
+	}
+
+	// The private key isn't returned to clients, keep the existing one if the certificate is unchanged.
+	_, ok := req.Config["tls.key"]
+	if !ok && req.Config["tls.certificate"] != "" && req.Config["tls.certificate"] == record.Config["tls.certificate"] {
+		req.Config["tls.key"] = record.Config["tls.key"]
+	}
+
+	req.Normalise() // So we handle the request in normalised/canonical form.
+
+	err = networkIngressValidate(s, n, ingressID, &api.NetworkIngressesPost{NetworkIngressPut: req, Hostname: record.Hostname, ListenAddress: record.ListenAddress})
+	if err != nil {
+		return response.SmartError(api.StatusErrorf(http.StatusBadRequest, "Failed validating ingress: %w", err))
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpdateNetworkIngress(ctx, n.ID(), ingressID, req)
+	})
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed updating ingress: %w", err))
+	}
+
+	reverter.Add(func() {
+		_ = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateNetworkIngress(ctx, n.ID(), ingressID, record.Writable())
+		})
+	})
+
+	newRecord := *record
+	newRecord.SetWritable(req)
+
+	err = networkIngressServe(d, n.Project(), n.Name(), ingressID, &newRecord)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed updating ingress: %w", err))
+	}
+
+	reverter.Success()
+
+	if shared.IsTrue(newRecord.Config["tls.acme"]) {
+		_ = networkIngressCertificatesRenew(s.ShutdownCtx, d, false)
+	}
+
+	s.Events.SendLifecycle(n.Project(), lifecycle.NetworkIngressUpdated.Event(n, record.Hostname, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/networks/{networkName}/ingresses/{hostname}/state network-ingresses network_ingress_state_get
+//
+//	Get the network ingress state
+//
+//	Gets the certificate, request and target health state of a specific network ingress.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Network ingress state
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkIngressState"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkIngressStateGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	n, err := networkIngressLoadNetwork(d, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	_, record, resp := networkIngressLoad(d, r, n)
+	if resp != nil {
+		return resp
+	}
+
+	state := d.ingress.State(record.Hostname)
+	if state == nil {
+		return response.InternalError(fmt.Errorf("Network ingress %q isn't running", record.Hostname))
+	}
+
+	return response.SyncResponse(true, state)
+}
@@ -995,6 +995,9 @@ func networkDelete(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	// Stop serving the network ingresses, their records are removed along with the network.
+	d.ingress.RemoveNetwork(n.Project(), n.Name())
+
 	// If this is a cluster notification, we're done, any database work will be done by the node that is
 	// originally serving the request.
 	if clusterNotification {
@@ -1153,6 +1156,9 @@ func networkPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// The network ingresses are served under the network name.
+	d.ingress.RenameNetwork(n.Project(), networkName, req.Name)
+
 	requestor := request.CreateRequestor(r)
 	lc := lifecycle.NetworkRenamed.Event(n, requestor, map[string]any{"old_name": networkName})
 	s.Events.SendLifecycle(projectName, lc)
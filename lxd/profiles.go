@@ -18,6 +18,7 @@ import (
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db"
 	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/device"
 	deviceConfig "github.com/canonical/lxd/lxd/device/config"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
@@ -187,6 +188,7 @@ func profilesGet(d *Daemon, r *http.Request) response.Response {
 					return err
 				}
 
+				device.ProxyRedactDevices(apiProfile.Devices)
 				apiProfiles = append(apiProfiles, apiProfile)
 			}
 		} else {
@@ -296,6 +298,16 @@ func profilesPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	err = instanceCommandConfigCheckAccess(s, r, entity.ProjectURL(p.Name), auth.EntitlementCanOperateInstances, nil, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = instanceSessionRecordingConfigCheckAccess(s, r, p.Name, nil, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// At this point we don't know the instance type, so just use instancetype.Any type for validation.
 	err = instance.ValidDevices(s, *p, instancetype.Any, deviceConfig.NewDevices(req.Devices), nil)
 	if err != nil {
@@ -428,7 +440,17 @@ func profileGet(d *Daemon, r *http.Request) response.Response {
 	resp.UsedBy = project.FilterUsedBy(s.Authorizer, r, resp.UsedBy)
 
 	etag := []any{resp.Config, resp.Description, resp.Devices}
-	return response.SyncResponseETag(true, resp, etag)
+
+	// The ETag covers the stored devices, so the secrets are only removed from the response.
+	redacted := *resp
+	redacted.Devices = make(map[string]map[string]string, len(resp.Devices))
+	for devName, dev := range resp.Devices {
+		redacted.Devices[devName] = dev
+	}
+
+	device.ProxyRedactDevices(redacted.Devices)
+
+	return response.SyncResponseETag(true, &redacted, etag)
 }
 
 // swagger:operation PUT /1.0/profiles/{name} profiles profile_put
@@ -526,6 +548,18 @@ func profilePut(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	device.ProxyRestoreRedactedKeys(profile.Devices, req.Devices)
+
+	err = instanceCommandConfigCheckAccess(s, r, entity.ProjectURL(p.Name), auth.EntitlementCanOperateInstances, profile.Config, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = instanceSessionRecordingConfigCheckAccess(s, r, p.Name, profile.Config, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	err = doProfileUpdate(s, *p, name, id, profile, req)
 
 	if err == nil && !isClusterNotification(r) {
@@ -667,6 +701,8 @@ func profilePatch(d *Daemon, r *http.Request) response.Response {
 	if req.Devices == nil {
 		req.Devices = profile.Devices
 	} else {
+		device.ProxyRestoreRedactedKeys(profile.Devices, req.Devices)
+
 		for k, v := range profile.Devices {
 			_, ok := req.Devices[k]
 			if !ok {
@@ -675,6 +711,16 @@ func profilePatch(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	err = instanceCommandConfigCheckAccess(s, r, entity.ProjectURL(p.Name), auth.EntitlementCanOperateInstances, profile.Config, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = instanceSessionRecordingConfigCheckAccess(s, r, p.Name, profile.Config, req.Config)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	requestor := request.CreateRequestor(r)
 	s.Events.SendLifecycle(p.Name, lifecycle.ProfileUpdated.Event(name, p.Name, requestor, nil))
 
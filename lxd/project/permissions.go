@@ -585,6 +585,29 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 				return nil
 			}
 
+		case "restricted.devices.shm":
+			devicesChecks["shm"] = func(device map[string]string) error {
+				if restrictionValue != "allow" {
+					return fmt.Errorf("Shared memory devices are forbidden")
+				}
+
+				return nil
+			}
+
+		case "restricted.devices.serial":
+			devicesChecks["serial"] = func(device map[string]string) error {
+				switch restrictionValue {
+				case "block":
+					return fmt.Errorf("Serial devices are forbidden")
+				case "unix":
+					if strings.HasPrefix(device["listen"], "tcp:") {
+						return fmt.Errorf("Serial devices listening on TCP are forbidden")
+					}
+				}
+
+				return nil
+			}
+
 		case "restricted.devices.proxy":
 			devicesChecks["proxy"] = func(device map[string]string) error {
 				if restrictionValue != "allow" {
@@ -824,6 +847,8 @@ var allRestrictions = map[string]string{
 	"restricted.devices.gpu":               "block",
 	"restricted.devices.usb":               "block",
 	"restricted.devices.pci":               "block",
+	"restricted.devices.shm":               "block",
+	"restricted.devices.serial":            "unix",
 	"restricted.devices.proxy":             "block",
 	"restricted.devices.nic":               "managed",
 	"restricted.devices.disk":              "managed",
@@ -871,6 +896,10 @@ func isContainerLowLevelOptionForbidden(key string) bool {
 
 // Return true if a low-level VM option is forbidden.
 func isVMLowLevelOptionForbidden(key string) bool {
+	if strings.HasPrefix(key, "limits.numa.") && strings.HasSuffix(key, ".hugepages") {
+		return true
+	}
+
 	return shared.ValueInSlice(key, []string{
 		"boot.host_shutdown_timeout",
 		"limits.memory.hugepages",
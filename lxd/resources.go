@@ -1,17 +1,24 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/gorilla/mux"
 
 	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/resources"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/version"
 )
 
 var api10ResourcesCmd = APIEndpoint{
@@ -81,9 +88,44 @@ func api10ResourcesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Report the instances the vDPA devices are allocated to.
+	if len(res.Network.VDPA) > 0 {
+		err = resourcesVDPAUsedBy(s, res.Network.VDPA)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	return response.SyncResponse(true, res)
 }
 
+// resourcesVDPAUsedBy fills in the instance each vDPA device is allocated to.
+func resourcesVDPAUsedBy(s *state.State, devices []api.ResourcesNetworkVDPA) error {
+	usedBy := map[string]string{}
+
+	filter := dbCluster.InstanceFilter{Node: &s.ServerName}
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+			for key, value := range dbInst.Config {
+				if strings.HasPrefix(key, "volatile.") && strings.HasSuffix(key, ".last_state.vdpa.name") {
+					usedBy[value] = api.NewURL().Path(version.APIVersion, "instances", dbInst.Name).Project(dbInst.Project).String()
+				}
+			}
+
+			return nil
+		}, filter)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	for i := range devices {
+		devices[i].UsedBy = usedBy[devices[i].Name]
+	}
+
+	return nil
+}
+
 // swagger:operation GET /1.0/storage-pools/{name}/resources storage storage_pool_resources
 //
 //	Get storage pool resources information
@@ -151,5 +193,10 @@ func storagePoolResourcesGet(d *Daemon, r *http.Request) response.Response {
 		return response.InternalError(err)
 	}
 
+	res.Space.Provisioned, err = pool.GetProvisionedSpace()
+	if err != nil {
+		return response.InternalError(err)
+	}
+
 	return response.SyncResponse(true, res)
 }
@@ -17,6 +17,34 @@ import (
 )
 
 var sysDevicesCPU = "/sys/devices/system/cpu"
+var sysModule = "/sys/module"
+
+// getCPUConfidentialComputing returns the confidential computing technologies enabled in the KVM modules.
+func getCPUConfidentialComputing() []string {
+	parameters := []struct {
+		name string
+		path string
+	}{
+		{name: "sev", path: "kvm_amd/parameters/sev"},
+		{name: "sev-es", path: "kvm_amd/parameters/sev_es"},
+		{name: "sev-snp", path: "kvm_amd/parameters/sev_snp"},
+		{name: "tdx", path: "kvm_intel/parameters/tdx"},
+	}
+
+	technologies := []string{}
+	for _, parameter := range parameters {
+		value, err := os.ReadFile(filepath.Join(sysModule, parameter.path))
+		if err != nil {
+			continue
+		}
+
+		if strings.TrimSpace(string(value)) == "Y" {
+			technologies = append(technologies, parameter.name)
+		}
+	}
+
+	return technologies
+}
 
 // GetCPUIsolated returns a slice of IDs corresponding to isolated threads.
 func GetCPUIsolated() []int64 {
@@ -495,5 +523,8 @@ func GetCPU() (*api.ResourcesCPU, error) {
 
 	cpu.Architecture = strings.TrimRight(string(uname.Machine[:]), "\x00")
 
+	// Get the confidential computing technologies
+	cpu.ConfidentialComputing = getCPUConfidentialComputing()
+
 	return &cpu, nil
 }
@@ -278,3 +278,32 @@ func GetMemory() (*api.ResourcesMemory, error) {
 
 	return &memory, nil
 }
+
+// GetNUMANodeHugepageSizes returns the huge page sizes (in bytes) supported by the given NUMA node.
+func GetNUMANodeHugepageSizes(node uint64) ([]uint64, error) {
+	hugepagesPath := filepath.Join(sysDevicesNode, fmt.Sprintf("node%d", node), "hugepages")
+	if !sysfsExists(hugepagesPath) {
+		return nil, fmt.Errorf("NUMA node %d doesn't exist or doesn't support huge pages", node)
+	}
+
+	entries, err := os.ReadDir(hugepagesPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list %q: %w", hugepagesPath, err)
+	}
+
+	sizes := []uint64{}
+	for _, entry := range entries {
+		// Entries are named after the page size, for example "hugepages-2048kB".
+		value := strings.TrimPrefix(entry.Name(), "hugepages-")
+		value = strings.Replace(value, "kB", "KiB", 1)
+
+		size, err := units.ParseByteSizeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse huge page size %q: %w", entry.Name(), err)
+		}
+
+		sizes = append(sizes, uint64(size))
+	}
+
+	return sizes, nil
+}
@@ -17,6 +17,7 @@ import (
 )
 
 var sysClassNet = "/sys/class/net"
+var sysBusVDPA = "/sys/bus/vdpa/devices"
 
 var netProtocols = map[uint64]string{
 	1:  "ethernet",
@@ -470,9 +471,77 @@ func GetNetwork() (*api.ResourcesNetwork, error) {
 		network.Total++
 	}
 
+	// Add vDPA devices
+	vdpaDevices, err := getVDPADevices()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vdpaDevices) > 0 {
+		network.VDPA = vdpaDevices
+	}
+
 	return &network, nil
 }
 
+// getVDPADevices returns the vDPA devices found on the vDPA bus.
+func getVDPADevices() ([]api.ResourcesNetworkVDPA, error) {
+	if !sysfsExists(sysBusVDPA) {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(sysBusVDPA)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list %q: %w", sysBusVDPA, err)
+	}
+
+	devices := make([]api.ResourcesNetworkVDPA, 0, len(entries))
+	for _, entry := range entries {
+		devicePath, err := filepath.EvalSymlinks(filepath.Join(sysBusVDPA, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to resolve %q: %w", filepath.Join(sysBusVDPA, entry.Name()), err)
+		}
+
+		device := api.ResourcesNetworkVDPA{Name: entry.Name()}
+
+		// Management device, prefixed with its bus name when it has one (e.g. "pci/0000:0d:00.2").
+		parentPath := filepath.Dir(devicePath)
+		device.ManagementDevice = filepath.Base(parentPath)
+		if sysfsExists(filepath.Join(parentPath, "subsystem")) {
+			subsystem, err := filepath.EvalSymlinks(filepath.Join(parentPath, "subsystem"))
+			if err != nil {
+				return nil, fmt.Errorf("Failed to resolve subsystem of %q: %w", parentPath, err)
+			}
+
+			device.ManagementDevice = filepath.Base(subsystem) + "/" + device.ManagementDevice
+		}
+
+		// Driver
+		if sysfsExists(filepath.Join(devicePath, "driver")) {
+			driver, err := filepath.EvalSymlinks(filepath.Join(devicePath, "driver"))
+			if err != nil {
+				return nil, fmt.Errorf("Failed to resolve driver of %q: %w", devicePath, err)
+			}
+
+			device.Driver = filepath.Base(driver)
+		}
+
+		// vhost-vdpa device
+		vhostMatches, err := filepath.Glob(filepath.Join(devicePath, "vhost-vdpa-*"))
+		if err != nil {
+			return nil, fmt.Errorf("Malformed vhost-vdpa device name search pattern: %w", err)
+		}
+
+		if len(vhostMatches) > 0 {
+			device.VhostDevice = filepath.Base(vhostMatches[0])
+		}
+
+		devices = append(devices, device)
+	}
+
+	return devices, nil
+}
+
 // GetNetworkState returns the OS configuration for the network interface.
 func GetNetworkState(name string) (*api.NetworkState, error) {
 	// Get some information
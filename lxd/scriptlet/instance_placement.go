@@ -175,6 +175,54 @@ func InstancePlacementRun(ctx context.Context, l logger.Logger, s *state.State,
 		return rv, nil
 	}
 
+	getClusterMemberDevicesFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var memberName string
+
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "member_name", &memberName)
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+		for i := range candidateMembers {
+			if candidateMembers[i].Name == memberName {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return starlark.String("Invalid member name"), nil
+		}
+
+		// Get the member devices and their allocations from the cluster-wide inventory.
+		devices := []api.ClusterDevice{}
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			nodeDevices, err := tx.GetNodeDevices(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, nodeDevice := range nodeDevices {
+				if nodeDevice.Location == memberName {
+					devices = append(devices, nodeDevice.ClusterDevice)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rv, err := StarlarkMarshal(devices)
+		if err != nil {
+			return nil, fmt.Errorf("Marshalling member devices for %q failed: %w", memberName, err)
+		}
+
+		return rv, nil
+	}
+
 	getInstanceResourcesFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		var err error
 		var res apiScriptlet.InstanceResources
@@ -306,6 +354,7 @@ func InstancePlacementRun(ctx context.Context, l logger.Logger, s *state.State,
 		"set_target":                   starlark.NewBuiltin("set_target", setTargetFunc),
 		"get_cluster_member_resources": starlark.NewBuiltin("get_cluster_member_resources", getClusterMemberResourcesFunc),
 		"get_cluster_member_state":     starlark.NewBuiltin("get_cluster_member_state", getClusterMemberStateFunc),
+		"get_cluster_member_devices":   starlark.NewBuiltin("get_cluster_member_devices", getClusterMemberDevicesFunc),
 		"get_instance_resources":       starlark.NewBuiltin("get_instance_resources", getInstanceResourcesFunc),
 	}
 
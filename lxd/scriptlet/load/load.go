@@ -22,6 +22,7 @@ func InstancePlacementCompile(src string) (*starlark.Program, error) {
 			"set_target",
 			"get_cluster_member_resources",
 			"get_cluster_member_state",
+			"get_cluster_member_devices",
 			"get_instance_resources",
 		})
 	}
@@ -3,23 +3,28 @@ package main
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/operationtype"
 	"github.com/canonical/lxd/lxd/db/warningtype"
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/lxd/task"
 	"github.com/canonical/lxd/lxd/warnings"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/units"
 	"github.com/canonical/lxd/shared/version"
 )
 
@@ -220,3 +225,169 @@ func storagePoolDriversCacheUpdate(s *state.State) {
 	storagePoolSupportedDriversCacheVal.Store(supportedDrivers)
 	storagePoolDriversCacheLock.Unlock()
 }
+
+// storagePoolsCheckSpaceTask returns a task that raises or resolves the space warnings of the storage pools
+// based on their space.warning_levels setting.
+func storagePoolsCheckSpaceTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		storagePoolsCheckSpace(ctx, d.State())
+	}
+
+	return f, task.Every(5 * time.Minute)
+}
+
+func storagePoolsCheckSpace(ctx context.Context, s *state.State) {
+	var poolNames []string
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		poolNames, err = tx.GetCreatedStoragePoolNames(ctx)
+
+		return err
+	})
+	if err != nil {
+		if !response.IsNotFoundError(err) {
+			logger.Error("Failed loading storage pools for space check", logger.Ctx{"err": err})
+		}
+
+		return
+	}
+
+	for _, poolName := range poolNames {
+		pool, err := storagePools.LoadByName(s, poolName)
+		if err != nil {
+			logger.Warn("Failed loading storage pool for space check", logger.Ctx{"pool": poolName, "err": err})
+			continue
+		}
+
+		reachedLevel, res, err := storagePoolSpaceWarningLevel(pool)
+		if err != nil {
+			logger.Debug("Failed checking storage pool space", logger.Ctx{"pool": poolName, "err": err})
+			continue
+		}
+
+		if reachedLevel <= 0 {
+			_ = warnings.ResolveWarningsByLocalNodeAndProjectAndTypeAndEntity(s.DB.Cluster, "", warningtype.StoragePoolSpaceLow, entity.TypeStoragePool, int(pool.ID()))
+			continue
+		}
+
+		msg := fmt.Sprintf("Used space reached %d%% (%s used of %s)", reachedLevel, units.GetByteSizeStringIEC(int64(res.Space.Used), 2), units.GetByteSizeStringIEC(int64(res.Space.Total), 2))
+		provisioned, err := pool.GetProvisionedSpace()
+		if err == nil && provisioned > 0 {
+			msg = fmt.Sprintf("%s, %s provisioned", msg, units.GetByteSizeStringIEC(int64(provisioned), 2))
+		}
+
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpsertWarningLocalNode(ctx, "", entity.TypeStoragePool, int(pool.ID()), warningtype.StoragePoolSpaceLow, msg)
+		})
+		if err != nil {
+			logger.Warn("Failed to create warning", logger.Ctx{"pool": poolName, "err": err})
+		}
+	}
+}
+
+// storagePoolsDeduplicateTask returns a task that deduplicates the storage pools whose dir.dedup.schedule
+// is due.
+func storagePoolsDeduplicateTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		var poolNames []string
+
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+
+			poolNames, err = tx.GetCreatedStoragePoolNames(ctx)
+
+			return err
+		})
+		if err != nil {
+			if !response.IsNotFoundError(err) {
+				logger.Error("Failed loading storage pools for deduplication", logger.Ctx{"err": err})
+			}
+
+			return
+		}
+
+		// Check the schedules first as deduplicating a pool can take longer than a minute.
+		var pools []storagePools.Pool
+		for _, poolName := range poolNames {
+			pool, err := storagePools.LoadByName(s, poolName)
+			if err != nil {
+				logger.Warn("Failed loading storage pool for deduplication", logger.Ctx{"pool": poolName, "err": err})
+				continue
+			}
+
+			schedule := pool.Driver().Config()["dir.dedup.schedule"]
+			if schedule == "" || !snapshotIsScheduledNow(schedule, pool.ID()) {
+				continue
+			}
+
+			pools = append(pools, pool)
+		}
+
+		for _, pool := range pools {
+			opRun := func(op *operations.Operation) error {
+				return pool.Deduplicate(op)
+			}
+
+			op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.StoragePoolDeduplicate, nil, nil, opRun, nil, nil, nil)
+			if err != nil {
+				logger.Error("Failed creating storage pool deduplication operation", logger.Ctx{"pool": pool.Name(), "err": err})
+				continue
+			}
+
+			logger.Info("Deduplicating storage pool", logger.Ctx{"pool": pool.Name()})
+			err = op.Start()
+			if err != nil {
+				logger.Error("Failed starting storage pool deduplication operation", logger.Ctx{"pool": pool.Name(), "err": err})
+				continue
+			}
+
+			err = op.Wait(ctx)
+			if err != nil {
+				logger.Error("Failed deduplicating storage pool", logger.Ctx{"pool": pool.Name(), "err": err})
+				continue
+			}
+
+			logger.Info("Done deduplicating storage pool", logger.Ctx{"pool": pool.Name()})
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+// storagePoolSpaceWarningLevel returns the highest of the pool's space.warning_levels that the used space of
+// the pool has reached (or 0 if none) along with the pool's resources.
+func storagePoolSpaceWarningLevel(pool storagePools.Pool) (int, *api.ResourcesStoragePool, error) {
+	levels := pool.ToAPI().Config["space.warning_levels"]
+	if levels == "" {
+		return 0, nil, nil
+	}
+
+	res, err := pool.GetResources()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if res.Space.Total == 0 {
+		return 0, res, nil
+	}
+
+	usedPercent := float64(res.Space.Used) * 100 / float64(res.Space.Total)
+
+	reachedLevel := 0
+	for _, level := range shared.SplitNTrimSpace(levels, ",", -1, true) {
+		levelPercent, err := strconv.Atoi(level)
+		if err != nil {
+			return 0, nil, fmt.Errorf("Invalid space warning level %q: %w", level, err)
+		}
+
+		if usedPercent >= float64(levelPercent) && levelPercent > reachedLevel {
+			reachedLevel = levelPercent
+		}
+	}
+
+	return reachedLevel, res, nil
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"archive/tar"
 	"archive/zip"
 	"context"
 	"encoding/json"
@@ -12,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -55,6 +57,20 @@ import (
 var unavailablePools = make(map[string]struct{})
 var unavailablePoolsMu = sync.Mutex{}
 
+// provisionedSpaceCacheEntry is the cached provisioned space of a pool.
+type provisionedSpaceCacheEntry struct {
+	provisioned uint64
+	expiry      time.Time
+}
+
+// provisionedSpaceCache caches the provisioned space of the pools by pool ID. Entries are invalidated when
+// volumes are created, resized or deleted on the local member and expire after provisionedSpaceCacheDuration
+// to catch any other change (such as root disk sizes changed through profiles).
+var provisionedSpaceCache = make(map[int64]provisionedSpaceCacheEntry)
+var provisionedSpaceCacheMu = sync.Mutex{}
+
+const provisionedSpaceCacheDuration = 5 * time.Minute
+
 // instanceDiskVolumeEffectiveFields fields from the instance disks that are applied to the volume's effective
 // config (but not stored in the disk's volume database record).
 var instanceDiskVolumeEffectiveFields = []string{
@@ -264,6 +280,176 @@ func (b *lxdBackend) GetResources() (*api.ResourcesStoragePool, error) {
 	return b.driver.GetResources()
 }
 
+// GetProvisionedSpace returns the sum of the sizes (in bytes) of the volumes in the pool that are located on the
+// local member. The value is cached as calculating it loads all the volumes and instances of the pool from the
+// database.
+func (b *lxdBackend) GetProvisionedSpace() (uint64, error) {
+	provisionedSpaceCacheMu.Lock()
+	entry, ok := provisionedSpaceCache[b.ID()]
+	provisionedSpaceCacheMu.Unlock()
+
+	if ok && time.Now().Before(entry.expiry) {
+		return entry.provisioned, nil
+	}
+
+	provisioned, err := b.provisionedSpace("", "", "")
+	if err != nil {
+		return 0, err
+	}
+
+	provisionedSpaceCacheMu.Lock()
+	provisionedSpaceCache[b.ID()] = provisionedSpaceCacheEntry{
+		provisioned: provisioned,
+		expiry:      time.Now().Add(provisionedSpaceCacheDuration),
+	}
+
+	provisionedSpaceCacheMu.Unlock()
+
+	return provisioned, nil
+}
+
+// invalidateProvisionedSpace removes the cached provisioned space of the pool after one of its volumes was
+// created, resized or deleted.
+func (b *lxdBackend) invalidateProvisionedSpace() {
+	provisionedSpaceCacheMu.Lock()
+	delete(provisionedSpaceCache, b.ID())
+	provisionedSpaceCacheMu.Unlock()
+}
+
+// provisionedSpace returns the sum of the sizes (in bytes) of the volumes in the pool that are located on the
+// local member. Volumes without a size limit, such as filesystem volumes on non block-backed drivers, and
+// snapshots aren't accounted for. If specified, the volume matching excludeProjectName, excludeVolType and
+// excludeVolName is skipped, this is used to calculate the effect of creating or resizing that volume.
+func (b *lxdBackend) provisionedSpace(excludeProjectName string, excludeVolType drivers.VolumeType, excludeVolName string) (uint64, error) {
+	var dbVols []*db.StorageVolume
+	rootDiskSizes := make(map[string]string)
+
+	err := b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		poolID := b.ID()
+		dbVols, err = tx.GetStorageVolumes(ctx, true, db.StorageVolumeFilter{PoolID: &poolID})
+		if err != nil {
+			return fmt.Errorf("Failed loading storage volumes: %w", err)
+		}
+
+		// Instance volume sizes are defined by the instance's root disk device rather than the volume config.
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+			devices := instancetype.ExpandInstanceDevices(inst.Devices, inst.Profiles)
+			_, rootDiskConf, err := instancetype.GetRootDiskDevice(devices.CloneNative())
+			if err != nil || rootDiskConf["pool"] != b.name {
+				return nil
+			}
+
+			rootDiskSizes[project.Instance(inst.Project, inst.Name)] = rootDiskConf["size"]
+
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var provisioned uint64
+	for _, dbVol := range dbVols {
+		if shared.IsSnapshot(dbVol.Name) {
+			continue
+		}
+
+		volDBType, err := VolumeTypeNameToDBType(dbVol.Type)
+		if err != nil {
+			return 0, err
+		}
+
+		volType, err := VolumeDBTypeToType(volDBType)
+		if err != nil {
+			return 0, err
+		}
+
+		if dbVol.Project == excludeProjectName && volType == excludeVolType && dbVol.Name == excludeVolName {
+			continue
+		}
+
+		volDBContentType, err := VolumeContentTypeNameToContentType(dbVol.ContentType)
+		if err != nil {
+			return 0, err
+		}
+
+		contentType, err := VolumeDBContentTypeToContentType(volDBContentType)
+		if err != nil {
+			return 0, err
+		}
+
+		vol := b.GetVolume(volType, contentType, dbVol.Name, dbVol.Config)
+		if volType == drivers.VolumeTypeContainer || volType == drivers.VolumeTypeVM {
+			rootDiskSize := rootDiskSizes[project.Instance(dbVol.Project, dbVol.Name)]
+			if rootDiskSize != "" {
+				vol.SetConfigSize(rootDiskSize)
+			}
+		}
+
+		size := vol.ConfigSize()
+		if size == "" {
+			continue
+		}
+
+		sizeBytes, err := units.ParseByteSizeString(size)
+		if err != nil {
+			return 0, fmt.Errorf("Failed parsing size of volume %q: %w", dbVol.Name, err)
+		}
+
+		if sizeBytes > 0 {
+			provisioned += uint64(sizeBytes)
+		}
+	}
+
+	return provisioned, nil
+}
+
+// checkOvercommit checks whether creating or resizing the specified volume to size would exceed the space
+// allowed by the pool's volume.overcommit_ratio setting. Does nothing if the setting isn't set or the driver
+// doesn't report the total space of the pool.
+func (b *lxdBackend) checkOvercommit(projectName string, volType drivers.VolumeType, volName string, size string) error {
+	if b.db.Config["volume.overcommit_ratio"] == "" || size == "" {
+		return nil
+	}
+
+	ratio, err := strconv.ParseFloat(b.db.Config["volume.overcommit_ratio"], 64)
+	if err != nil {
+		return fmt.Errorf("Invalid volume.overcommit_ratio: %w", err)
+	}
+
+	sizeBytes, err := units.ParseByteSizeString(size)
+	if err != nil {
+		return err
+	}
+
+	if sizeBytes <= 0 {
+		return nil
+	}
+
+	res, err := b.driver.GetResources()
+	if err != nil {
+		return fmt.Errorf("Failed getting storage pool resources: %w", err)
+	}
+
+	if res.Space.Total == 0 {
+		return nil
+	}
+
+	provisioned, err := b.provisionedSpace(projectName, volType, volName)
+	if err != nil {
+		return fmt.Errorf("Failed calculating provisioned space: %w", err)
+	}
+
+	limit := uint64(float64(res.Space.Total) * ratio)
+	if provisioned+uint64(sizeBytes) > limit {
+		return api.StatusErrorf(http.StatusInsufficientStorage, "Storage pool overcommit limit exceeded (provisioned %s of %s allowed by volume.overcommit_ratio %s)", units.GetByteSizeStringIEC(int64(provisioned+uint64(sizeBytes)), 2), units.GetByteSizeStringIEC(int64(limit), 2), b.db.Config["volume.overcommit_ratio"])
+	}
+
+	return nil
+}
+
 // IsUsed returns whether the storage pool is used by any volumes or profiles (excluding image volumes).
 func (b *lxdBackend) IsUsed() (bool, error) {
 	usedBy, err := UsedBy(context.TODO(), b.state, b, true, true, cluster.StoragePoolVolumeTypeNameImage)
@@ -500,6 +686,22 @@ func (b *lxdBackend) ApplyPatch(name string) error {
 	return nil
 }
 
+// Deduplicate shares the data of identical content across the volumes of the pool.
+func (b *lxdBackend) Deduplicate(op *operations.Operation) error {
+	l := b.logger.AddContext(nil)
+	l.Debug("Deduplicate started")
+	defer l.Debug("Deduplicate finished")
+
+	deduped, err := b.driver.Deduplicate(op)
+	if err != nil {
+		return err
+	}
+
+	l.Info("Deduplicated storage pool", logger.Ctx{"bytes": deduped})
+
+	return nil
+}
+
 // ensureInstanceSymlink creates a symlink in the instance directory to the instance's mount path
 // if doesn't exist already.
 func (b *lxdBackend) ensureInstanceSymlink(instanceType instancetype.Type, projectName string, instanceName string, mountPath string) error {
@@ -684,6 +886,11 @@ func (b *lxdBackend) CreateInstance(inst instance.Instance, op *operations.Opera
 		return err
 	}
 
+	err = b.checkOvercommit(inst.Project().Name, volType, inst.Name(), vol.ConfigSize())
+	if err != nil {
+		return err
+	}
+
 	err = b.driver.CreateVolume(vol, nil, op)
 	if err != nil {
 		return err
@@ -1139,6 +1346,11 @@ func (b *lxdBackend) CreateInstanceFromCopy(inst instance.Instance, src instance
 			return err
 		}
 
+		err = b.checkOvercommit(inst.Project().Name, volType, inst.Name(), vol.ConfigSize())
+		if err != nil {
+			return err
+		}
+
 		// Get the src volume name on storage.
 		srcVolStorageName := project.Instance(src.Project().Name, src.Name())
 		srcVol := b.GetVolume(volType, contentType, srcVolStorageName, srcConfig.Volume.Config)
@@ -1859,6 +2071,11 @@ func (b *lxdBackend) CreateInstanceFromImage(inst instance.Instance, fingerprint
 		return err
 	}
 
+	err = b.checkOvercommit(inst.Project().Name, volType, inst.Name(), vol.ConfigSize())
+	if err != nil {
+		return err
+	}
+
 	// Leave reverting on failure to caller, they are expected to call DeleteInstance().
 
 	// If the driver doesn't support optimized image volumes or the optimized image volume should not be used,
@@ -2530,6 +2747,8 @@ func (b *lxdBackend) UpdateInstance(inst instance.Instance, newDesc string, newC
 		if err != nil {
 			return err
 		}
+
+		b.invalidateProvisionedSpace()
 	}
 
 	b.state.Events.SendLifecycle(inst.Project().Name, lifecycle.StorageVolumeUpdated.Event(newVol, string(newVol.Type()), inst.Project().Name, op, nil))
@@ -2892,6 +3111,11 @@ func (b *lxdBackend) SetInstanceQuota(inst instance.Instance, size string, vmSta
 		return err
 	}
 
+	err = b.checkOvercommit(inst.Project().Name, volType, inst.Name(), size)
+	if err != nil {
+		return err
+	}
+
 	// Apply the main volume quota.
 	vol := b.GetVolume(volType, contentVolume, volStorageName, dbVol.Config)
 	err = b.driver.SetVolumeQuota(vol, size, false, op)
@@ -2899,6 +3123,8 @@ func (b *lxdBackend) SetInstanceQuota(inst instance.Instance, size string, vmSta
 		return err
 	}
 
+	b.invalidateProvisionedSpace()
+
 	// Apply the filesystem volume quota (only when main volume is block).
 	if vol.IsVMBlock() {
 		// Apply default VM config filesystem size if main volume size is specified and no custom
@@ -3996,6 +4222,12 @@ func (b *lxdBackend) CreateBucket(projectName string, bucket api.StorageBucketsP
 		}
 
 		revert.Add(func() { _ = s3Client.RemoveBucket(ctx, bucket.Name) })
+
+		// Apply versioning, lifecycle and policy settings.
+		err = s3.ApplyBucketConfig(ctx, s3Client, bucket.Name, bucket.Config, nil)
+		if err != nil {
+			return err
+		}
 	} else {
 		// Handle per-driver implementation for remote storage drivers.
 		err = b.driver.CreateBucket(bucketVol, op)
@@ -4092,6 +4324,27 @@ func (b *lxdBackend) UpdateBucket(projectName string, bucketName string, bucket
 			if err != nil {
 				return err
 			}
+
+			// Restart MinIO process to apply versioning, lifecycle and policy settings.
+			if s3.BucketConfigChanged(changedConfig) {
+				minioProc, err = b.ActivateBucket(projectName, bucketName, op)
+				if err != nil {
+					return err
+				}
+
+				s3Client, err := minioProc.S3Client()
+				if err != nil {
+					return err
+				}
+
+				ctx, ctxCancel := context.WithTimeout(context.TODO(), time.Duration(time.Second*30))
+				defer ctxCancel()
+
+				err = s3.ApplyBucketConfig(ctx, s3Client, bucketName, bucket.Config, changedConfig)
+				if err != nil {
+					return err
+				}
+			}
 		} else {
 			// Handle per-driver implementation for remote storage drivers.
 			err = b.driver.UpdateBucket(curBucketVol, changedConfig)
@@ -4170,6 +4423,15 @@ func (b *lxdBackend) DeleteBucket(projectName string, bucketName string, op *ope
 		}
 	}
 
+	// Remove backups directory for bucket.
+	backupsPath := backup.BucketBackupPath(projectName, b.name, bucket.Name)
+	if shared.PathExists(backupsPath) {
+		err := os.RemoveAll(backupsPath)
+		if err != nil {
+			return err
+		}
+	}
+
 	_ = BucketDBDelete(context.TODO(), b, bucket.ID)
 	if err != nil {
 		return err
@@ -4738,6 +5000,235 @@ func (b *lxdBackend) GetBucketURL(bucketName string) *url.URL {
 	return b.driver.GetBucketURL(bucketName)
 }
 
+// bucketS3Client returns an S3 client with full access to the bucket and the name of the bucket on the S3 endpoint.
+func (b *lxdBackend) bucketS3Client(projectName string, bucket *db.StorageBucket, op *operations.Operation) (*minio.Client, string, error) {
+	memberSpecific := !b.Driver().Info().Remote // Member specific if storage pool isn't remote.
+
+	if memberSpecific {
+		// Handle common MinIO implementation for local storage drivers.
+		minioProc, err := b.ActivateBucket(projectName, bucket.Name, op)
+		if err != nil {
+			return nil, "", err
+		}
+
+		s3Client, err := minioProc.S3Client()
+		if err != nil {
+			return nil, "", err
+		}
+
+		return s3Client, bucket.Name, nil
+	}
+
+	// Handle per-driver implementation for remote storage drivers.
+	bucketVolName := project.StorageVolume(projectName, bucket.Name)
+	bucketVol := b.GetVolume(drivers.VolumeTypeBucket, drivers.ContentTypeFS, bucketVolName, bucket.Config)
+
+	return b.driver.GetBucketS3Client(bucketVol)
+}
+
+// GenerateBucketBackupConfig returns the backup config entry for this bucket, including its keys.
+func (b *lxdBackend) GenerateBucketBackupConfig(projectName string, bucketName string, op *operations.Operation) (*backupConfig.Config, error) {
+	memberSpecific := !b.Driver().Info().Remote // Member specific if storage pool isn't remote.
+
+	var bucket *db.StorageBucket
+	var bucketKeys []*db.StorageBucketKey
+	err := b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		bucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, memberSpecific, bucketName)
+		if err != nil {
+			return err
+		}
+
+		bucketKeys, err = tx.GetStoragePoolBucketKeys(ctx, bucket.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	config := &backupConfig.Config{
+		Bucket:     &bucket.StorageBucket,
+		BucketKeys: make([]*api.StorageBucketKey, 0, len(bucketKeys)),
+	}
+
+	for _, key := range bucketKeys {
+		config.BucketKeys = append(config.BucketKeys, &key.StorageBucketKey)
+	}
+
+	return config, nil
+}
+
+// BackupBucket writes the objects of the bucket to the supplied tarball writer.
+// Only the current version of each object is included.
+func (b *lxdBackend) BackupBucket(projectName string, bucketName string, tarWriter *instancewriter.InstanceTarWriter, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": projectName, "bucketName": bucketName})
+	l.Debug("BackupBucket started")
+	defer l.Debug("BackupBucket finished")
+
+	err := b.isStatusReady()
+	if err != nil {
+		return err
+	}
+
+	if !b.Driver().Info().Buckets {
+		return fmt.Errorf("Storage pool does not support buckets")
+	}
+
+	memberSpecific := !b.Driver().Info().Remote // Member specific if storage pool isn't remote.
+
+	var bucket *db.StorageBucket
+	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		bucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, memberSpecific, bucketName)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s3Client, s3BucketName, err := b.bucketS3Client(projectName, bucket, op)
+	if err != nil {
+		return err
+	}
+
+	ctx, ctxCancel := context.WithCancel(context.TODO())
+	defer ctxCancel()
+
+	for objInfo := range s3Client.ListObjects(ctx, s3BucketName, minio.ListObjectsOptions{Recursive: true}) {
+		if objInfo.Err != nil {
+			return fmt.Errorf("Failed listing bucket objects: %w", objInfo.Err)
+		}
+
+		// Skip directory markers as they cannot be represented as files in the tarball.
+		if strings.HasSuffix(objInfo.Key, "/") {
+			continue
+		}
+
+		obj, err := s3Client.GetObject(ctx, s3BucketName, objInfo.Key, minio.GetObjectOptions{})
+		if err != nil {
+			return fmt.Errorf("Failed getting bucket object %q: %w", objInfo.Key, err)
+		}
+
+		fileInfo := instancewriter.FileInfo{
+			FileName:    "backup/bucket/" + objInfo.Key,
+			FileSize:    objInfo.Size,
+			FileMode:    0600,
+			FileModTime: objInfo.LastModified,
+		}
+
+		err = tarWriter.WriteFileFromReader(obj, &fileInfo)
+		_ = obj.Close()
+		if err != nil {
+			return fmt.Errorf("Failed writing bucket object %q to backup: %w", objInfo.Key, err)
+		}
+	}
+
+	return nil
+}
+
+// CreateBucketFromBackup creates a bucket and its keys from the backup config and uploads the objects found in
+// the backup tarball into it.
+func (b *lxdBackend) CreateBucketFromBackup(srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) error {
+	l := b.logger.AddContext(logger.Ctx{"project": srcBackup.Project, "bucketName": srcBackup.Name})
+	l.Debug("CreateBucketFromBackup started")
+	defer l.Debug("CreateBucketFromBackup finished")
+
+	if srcBackup.Config == nil || srcBackup.Config.Bucket == nil {
+		return fmt.Errorf("Valid bucket config not found in index")
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Copy bucket config from backup, excluding volatile keys as those are specific to the source bucket.
+	bucketConfig := make(map[string]string, len(srcBackup.Config.Bucket.Config))
+	for k, v := range srcBackup.Config.Bucket.Config {
+		if strings.HasPrefix(k, "volatile.") {
+			continue
+		}
+
+		bucketConfig[k] = v
+	}
+
+	bucket := api.StorageBucketsPost{
+		Name: srcBackup.Name,
+		StorageBucketPut: api.StorageBucketPut{
+			Description: srcBackup.Config.Bucket.Description,
+			Config:      bucketConfig,
+		},
+	}
+
+	err := b.CreateBucket(srcBackup.Project, bucket, op)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { _ = b.DeleteBucket(srcBackup.Project, bucket.Name, op) })
+
+	// Restore the bucket keys with their existing credentials.
+	for _, key := range srcBackup.Config.BucketKeys {
+		keyReq := api.StorageBucketKeysPost{
+			Name:                key.Name,
+			StorageBucketKeyPut: key.Writable(),
+		}
+
+		_, err = b.CreateBucketKey(srcBackup.Project, bucket.Name, keyReq, op)
+		if err != nil {
+			return fmt.Errorf("Failed restoring bucket key %q: %w", key.Name, err)
+		}
+	}
+
+	memberSpecific := !b.Driver().Info().Remote // Member specific if storage pool isn't remote.
+
+	var dbBucket *db.StorageBucket
+	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbBucket, err = tx.GetStoragePoolBucket(ctx, b.id, srcBackup.Project, memberSpecific, bucket.Name)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s3Client, s3BucketName, err := b.bucketS3Client(srcBackup.Project, dbBucket, op)
+	if err != nil {
+		return err
+	}
+
+	tr, cancelFunc, err := backup.TarReader(srcData, b.state.OS, shared.VarPath("backups"))
+	if err != nil {
+		return err
+	}
+
+	defer cancelFunc()
+
+	objectPrefix := "backup/bucket/"
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break // End of archive.
+		}
+
+		if err != nil {
+			return fmt.Errorf("Error reading backup file: %w", err)
+		}
+
+		if hdr.Typeflag != tar.TypeReg || !strings.HasPrefix(hdr.Name, objectPrefix) {
+			continue
+		}
+
+		objectKey := strings.TrimPrefix(hdr.Name, objectPrefix)
+
+		_, err = s3Client.PutObject(context.TODO(), s3BucketName, objectKey, tr, hdr.Size, minio.PutObjectOptions{})
+		if err != nil {
+			return fmt.Errorf("Failed restoring bucket object %q: %w", objectKey, err)
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
 // CreateCustomVolume creates an empty custom volume.
 func (b *lxdBackend) CreateCustomVolume(projectName string, volName string, desc string, config map[string]string, contentType drivers.ContentType, op *operations.Operation) error {
 	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volName": volName, "desc": desc, "config": config, "contentType": contentType})
@@ -4765,6 +5256,11 @@ func (b *lxdBackend) CreateCustomVolume(projectName string, volName string, desc
 		return fmt.Errorf("Storage pool does not support custom volume type")
 	}
 
+	err = b.checkOvercommit(projectName, vol.Type(), volName, vol.ConfigSize())
+	if err != nil {
+		return err
+	}
+
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -5570,6 +6066,14 @@ func (b *lxdBackend) UpdateCustomVolume(projectName string, volName string, newD
 			return fmt.Errorf(`Custom volume "volatile.uuid" property cannot be changed`)
 		}
 
+		// Check that growing the volume doesn't exceed the pool's overcommit limit.
+		if changedConfig["size"] != "" {
+			err = b.checkOvercommit(projectName, drivers.VolumeTypeCustom, volName, newVol.ConfigSize())
+			if err != nil {
+				return err
+			}
+		}
+
 		// Check for config changing that is not allowed when running instances are using it.
 		if changedConfig["security.shifted"] != "" {
 			err = VolumeUsedByInstanceDevices(b.state, b.name, projectName, &curVol.StorageVolume, true, func(dbInst db.InstanceArgs, project api.Project, usedByDevices []string) error {
@@ -5613,6 +6117,8 @@ func (b *lxdBackend) UpdateCustomVolume(projectName string, volName string, newD
 		if err != nil {
 			return err
 		}
+
+		b.invalidateProvisionedSpace()
 	}
 
 	b.state.Events.SendLifecycle(projectName, lifecycle.StorageVolumeUpdated.Event(newVol, string(newVol.Type()), projectName, op, nil))
@@ -76,6 +76,10 @@ func (b *mockBackend) GetResources() (*api.ResourcesStoragePool, error) {
 	return nil, nil
 }
 
+func (b *mockBackend) GetProvisionedSpace() (uint64, error) {
+	return 0, nil
+}
+
 func (b *mockBackend) IsUsed() (bool, error) {
 	return false, nil
 }
@@ -104,6 +108,10 @@ func (b *mockBackend) ApplyPatch(name string) error {
 	return nil
 }
 
+func (b *mockBackend) Deduplicate(op *operations.Operation) error {
+	return nil
+}
+
 func (b *mockBackend) GetVolume(volType drivers.VolumeType, contentType drivers.ContentType, volName string, volConfig map[string]string) drivers.Volume {
 	return drivers.Volume{}
 }
@@ -277,6 +285,18 @@ func (b *mockBackend) GetBucketURL(bucketName string) *url.URL {
 	return nil
 }
 
+func (b *mockBackend) GenerateBucketBackupConfig(projectName string, bucketName string, op *operations.Operation) (*backupConfig.Config, error) {
+	return nil, nil
+}
+
+func (b *mockBackend) BackupBucket(projectName string, bucketName string, tarWriter *instancewriter.InstanceTarWriter, op *operations.Operation) error {
+	return nil
+}
+
+func (b *mockBackend) CreateBucketFromBackup(srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) error {
+	return nil
+}
+
 func (b *mockBackend) CreateCustomVolume(projectName string, volName string, desc string, config map[string]string, contentType drivers.ContentType, op *operations.Operation) error {
 	return nil
 }
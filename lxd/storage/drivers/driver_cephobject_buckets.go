@@ -16,6 +16,7 @@ import (
 
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/storage/s3"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/revert"
@@ -144,10 +145,26 @@ func (d *cephobject) CreateBucket(bucket Volume, op *operations.Operation) error
 		}
 	}
 
+	// Apply versioning, lifecycle and policy settings.
+	err = d.setBucketConfig(ctx, bucket, bucket.config, nil)
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 	return nil
 }
 
+// setBucketConfig applies the versioning, lifecycle and policy settings to the bucket using the bucket user.
+func (d *cephobject) setBucketConfig(ctx context.Context, bucket Volume, config map[string]string, changedConfig map[string]string) error {
+	minioClient, storageBucketName, err := d.GetBucketS3Client(bucket)
+	if err != nil {
+		return err
+	}
+
+	return s3.ApplyBucketConfig(ctx, minioClient, storageBucketName, config, changedConfig)
+}
+
 // setBucketQuota sets the bucket quota.
 func (d *cephobject) setBucketQuota(bucket Volume, quotaSize string) error {
 	_, bucketName := project.StorageVolumeParts(bucket.name)
@@ -194,9 +211,47 @@ func (d *cephobject) UpdateBucket(bucket Volume, changedConfig map[string]string
 		}
 	}
 
+	if s3.BucketConfigChanged(changedConfig) {
+		// Merge the changed keys into the current config as the lifecycle rule is built from several keys.
+		newConfig := make(map[string]string, len(bucket.config))
+		for k, v := range bucket.config {
+			newConfig[k] = v
+		}
+
+		for k, v := range changedConfig {
+			newConfig[k] = v
+		}
+
+		ctx, ctxCancel := context.WithTimeout(context.TODO(), time.Duration(time.Second*30))
+		defer ctxCancel()
+
+		err := d.setBucketConfig(ctx, bucket, newConfig, changedConfig)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// GetBucketS3Client returns an S3 client using the bucket user's credentials and the name of the bucket in radosgw.
+func (d *cephobject) GetBucketS3Client(bucket Volume) (*minio.Client, string, error) {
+	_, bucketName := project.StorageVolumeParts(bucket.name)
+	storageBucketName := d.radosgwBucketName(bucketName)
+
+	bucketUser, _, err := d.radosgwadminGetUser(context.TODO(), storageBucketName)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed getting bucket user: %w", err)
+	}
+
+	minioClient, err := d.s3Client(*bucketUser)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return minioClient, storageBucketName, nil
+}
+
 // bucketKeyRadosgwAccessRole returns the radosgw access setting for the specified role name.
 func (d *cephobject) bucketKeyRadosgwAccessRole(roleName string) (string, error) {
 	switch roleName {
@@ -8,6 +8,8 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/minio/minio-go/v7"
+
 	"github.com/canonical/lxd/lxd/backup"
 	"github.com/canonical/lxd/lxd/instancewriter"
 	"github.com/canonical/lxd/lxd/migration"
@@ -114,6 +116,11 @@ func (d *common) fillVolumeConfig(vol *Volume, excludedKeys ...string) error {
 			continue
 		}
 
+		// The overcommit ratio is a pool setting and isn't inherited by volumes.
+		if volKey == "overcommit_ratio" {
+			continue
+		}
+
 		// If volume type is not custom or bucket, don't copy "size" property to volume config.
 		if (vol.volType != VolumeTypeCustom && vol.volType != VolumeTypeBucket) && volKey == "size" {
 			continue
@@ -261,6 +268,12 @@ func (d *common) ApplyPatch(name string) error {
 	return patch()
 }
 
+// Deduplicate shares the data of identical content across the volumes of the pool.
+// Returns the number of bytes that were deduplicated.
+func (d *common) Deduplicate(op *operations.Operation) (int64, error) {
+	return 0, ErrNotSupported
+}
+
 // moveGPTAltHeader moves the GPT alternative header to the end of the disk device supplied.
 // If the device supplied is not detected as not being a GPT disk then no action is taken and nil is returned.
 // If the required sgdisk command is not available a warning is logged, but no error is returned, as really it is
@@ -520,6 +533,11 @@ func (d *common) UpdateBucket(bucket Volume, changedConfig map[string]string) er
 	return ErrNotSupported
 }
 
+// GetBucketS3Client returns an S3 client with full access to the bucket and the name of the bucket on the S3 endpoint.
+func (d *common) GetBucketS3Client(bucket Volume) (*minio.Client, string, error) {
+	return nil, "", ErrNotSupported
+}
+
 // ValidateBucketKey validates the supplied bucket key config.
 func (d *common) ValidateBucketKey(keyName string, creds S3Credentials, roleName string) error {
 	if keyName == "" {
@@ -11,6 +11,7 @@ import (
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/validate"
 )
 
 type dir struct {
@@ -112,7 +113,19 @@ func (d *dir) Delete(op *operations.Operation) error {
 
 // Validate checks that all provide keys are supported and that no conflicting or missing configuration is present.
 func (d *dir) Validate(config map[string]string) error {
-	return d.validatePool(config, nil, nil)
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=storage-dir; group=pool-conf; key=dir.dedup.schedule)
+		// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable deduplication (the default).
+		//
+		// Deduplication shares the data of identical files across all volumes of the pool.
+		// It requires a filesystem that supports sharing extents, like XFS or Btrfs.
+		// ---
+		//  type: string
+		//  shortdesc: Schedule for offline deduplication of the pool
+		"dir.dedup.schedule": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"})),
+	}
+
+	return d.validatePool(config, rules, nil)
 }
 
 // Update applies any driver changes required from a configuration change.
@@ -161,3 +174,8 @@ func (d *dir) Unmount() (bool, error) {
 func (d *dir) GetResources() (*api.ResourcesStoragePool, error) {
 	return genericVFSGetResources(d)
 }
+
+// Deduplicate shares the data of identical files across the volumes of the pool.
+func (d *dir) Deduplicate(op *operations.Operation) (int64, error) {
+	return deduplicateFiles(d.Logger(), GetPoolMountPath(d.name))
+}
@@ -448,19 +448,24 @@ func (d *dir) CreateVolumeSnapshot(snapVol Volume, op *operations.Operation) err
 
 	if snapVol.contentType != ContentTypeBlock || snapVol.volType != VolumeTypeCustom {
 		var rsyncArgs []string
+		var reflinkExclude []string
 
 		if snapVol.IsVMBlock() {
 			rsyncArgs = append(rsyncArgs, "--exclude", genericVolumeDiskFile)
+			reflinkExclude = append(reflinkExclude, genericVolumeDiskFile)
 		}
 
-		bwlimit := d.config["rsync.bwlimit"]
 		srcPath := GetVolumeMountPath(d.name, snapVol.volType, parentName)
-		d.Logger().Debug("Copying fileystem volume", logger.Ctx{"sourcePath": srcPath, "targetPath": snapPath, "bwlimit": bwlimit, "rsyncArgs": rsyncArgs})
 
-		// Copy filesystem volume into snapshot directory.
-		_, err = rsync.LocalCopy(srcPath, snapPath, bwlimit, true, rsyncArgs...)
-		if err != nil {
-			return err
+		// Clone the files into the snapshot directory if the filesystem supports it, otherwise copy them.
+		if !tryReflinkCopy(d.Logger(), GetPoolMountPath(d.name), srcPath, snapPath, reflinkExclude...) {
+			bwlimit := d.config["rsync.bwlimit"]
+			d.Logger().Debug("Copying fileystem volume", logger.Ctx{"sourcePath": srcPath, "targetPath": snapPath, "bwlimit": bwlimit, "rsyncArgs": rsyncArgs})
+
+			_, err = rsync.LocalCopy(srcPath, snapPath, bwlimit, true, rsyncArgs...)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -476,16 +481,18 @@ func (d *dir) CreateVolumeSnapshot(snapVol Volume, op *operations.Operation) err
 			return err
 		}
 
-		d.Logger().Debug("Copying block volume", logger.Ctx{"srcDevPath": srcDevPath, "targetPath": targetDevPath})
-
 		err = ensureSparseFile(targetDevPath, 0)
 		if err != nil {
 			return err
 		}
 
-		err = copyDevice(srcDevPath, targetDevPath)
-		if err != nil {
-			return err
+		if !tryReflinkFile(d.Logger(), srcDevPath, targetDevPath) {
+			d.Logger().Debug("Copying block volume", logger.Ctx{"srcDevPath": srcDevPath, "targetPath": targetDevPath})
+
+			err = copyDevice(srcDevPath, targetDevPath)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -593,15 +600,19 @@ func (d *dir) RestoreVolume(vol Volume, snapVol Volume, op *operations.Operation
 	// Restore filesystem volume.
 	if vol.contentType != ContentTypeBlock || vol.volType != VolumeTypeCustom {
 		var rsyncArgs []string
+		var reflinkExclude []string
 
 		if vol.IsVMBlock() {
 			rsyncArgs = append(rsyncArgs, "--exclude", genericVolumeDiskFile)
+			reflinkExclude = append(reflinkExclude, genericVolumeDiskFile)
 		}
 
-		bwlimit := d.config["rsync.bwlimit"]
-		_, err := rsync.LocalCopy(srcPath, volPath, bwlimit, true, rsyncArgs...)
-		if err != nil {
-			return fmt.Errorf("Failed to rsync volume: %w", err)
+		if !tryReflinkCopy(d.Logger(), GetPoolMountPath(d.name), srcPath, volPath, reflinkExclude...) {
+			bwlimit := d.config["rsync.bwlimit"]
+			_, err := rsync.LocalCopy(srcPath, volPath, bwlimit, true, rsyncArgs...)
+			if err != nil {
+				return fmt.Errorf("Failed to rsync volume: %w", err)
+			}
 		}
 	}
 
@@ -617,16 +628,18 @@ func (d *dir) RestoreVolume(vol Volume, snapVol Volume, op *operations.Operation
 			return err
 		}
 
-		d.Logger().Debug("Restoring block volume", logger.Ctx{"srcDevPath": srcDevPath, "targetPath": targetDevPath})
-
 		err = ensureSparseFile(targetDevPath, 0)
 		if err != nil {
 			return err
 		}
 
-		err = copyDevice(srcDevPath, targetDevPath)
-		if err != nil {
-			return err
+		if !tryReflinkFile(d.Logger(), srcDevPath, targetDevPath) {
+			d.Logger().Debug("Restoring block volume", logger.Ctx{"srcDevPath": srcDevPath, "targetPath": targetDevPath})
+
+			err = copyDevice(srcDevPath, targetDevPath)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
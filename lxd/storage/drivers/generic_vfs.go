@@ -337,7 +337,13 @@ func genericVFSCreateVolumeFromMigration(d Driver, initVolume func(vol Volume) (
 			wrapper = migration.ProgressTracker(op, "block_progress", volName)
 		}
 
-		to, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+		// Keep the existing content when only the changed blocks are received.
+		flags := os.O_WRONLY | os.O_TRUNC
+		if volTargetArgs.BlockDiff {
+			flags = os.O_WRONLY
+		}
+
+		to, err := os.OpenFile(path, flags, 0)
 		if err != nil {
 			return fmt.Errorf("Error opening file for writing %q: %w", path, err)
 		}
@@ -356,7 +362,12 @@ func genericVFSCreateVolumeFromMigration(d Driver, initVolume func(vol Volume) (
 		d.Logger().Debug("Receiving block volume started", logger.Ctx{"volName": volName, "path": path})
 		defer d.Logger().Debug("Receiving block volume stopped", logger.Ctx{"volName": volName, "path": path})
 
-		_, err = io.Copy(to, fromPipe)
+		if volTargetArgs.BlockDiff {
+			_, err = migration.ApplyBlockDiff(fromPipe, to)
+		} else {
+			_, err = io.Copy(to, fromPipe)
+		}
+
 		if err != nil {
 			return fmt.Errorf("Error copying from migration connection to %q: %w", path, err)
 		}
@@ -986,9 +997,11 @@ func genericVFSCopyVolume(d Driver, initVolume func(vol Volume) (revert.Hook, er
 	bwlimit := d.Config()["rsync.bwlimit"]
 
 	var rsyncArgs []string
+	var reflinkExclude []string
 
 	if srcVol.IsVMBlock() {
 		rsyncArgs = append(rsyncArgs, "--exclude", genericVolumeDiskFile)
+		reflinkExclude = append(reflinkExclude, genericVolumeDiskFile)
 	}
 
 	revert := revert.New()
@@ -1006,6 +1019,11 @@ func genericVFSCopyVolume(d Driver, initVolume func(vol Volume) (revert.Hook, er
 
 	// Define function to send a filesystem volume.
 	sendFSVol := func(srcPath string, targetPath string) error {
+		// Clone the files rather than copying them if the filesystem supports it.
+		if tryReflinkCopy(d.Logger(), GetPoolMountPath(d.Name()), srcPath, targetPath, reflinkExclude...) {
+			return nil
+		}
+
 		d.Logger().Debug("Copying fileystem volume", logger.Ctx{"sourcePath": srcPath, "targetPath": targetPath, "bwlimit": bwlimit, "rsyncArgs": rsyncArgs})
 		_, err := rsync.LocalCopy(srcPath, targetPath, bwlimit, true, rsyncArgs...)
 
@@ -1029,6 +1047,11 @@ func genericVFSCopyVolume(d Driver, initVolume func(vol Volume) (revert.Hook, er
 			return err
 		}
 
+		// Clone the disk file rather than copying it if the filesystem supports it.
+		if tryReflinkFile(d.Logger(), srcDevPath, targetDevPath) {
+			return nil
+		}
+
 		d.Logger().Debug("Copying block volume", logger.Ctx{"srcDevPath": srcDevPath, "targetPath": targetDevPath})
 		err = copyDevice(srcDevPath, targetDevPath)
 		if err != nil {
@@ -4,6 +4,8 @@ import (
 	"io"
 	"net/url"
 
+	"github.com/minio/minio-go/v7"
+
 	"github.com/canonical/lxd/lxd/backup"
 	"github.com/canonical/lxd/lxd/instancewriter"
 	"github.com/canonical/lxd/lxd/migration"
@@ -49,6 +51,7 @@ type Driver interface {
 	Validate(config map[string]string) error
 	Update(changedConfig map[string]string) error
 	ApplyPatch(name string) error
+	Deduplicate(op *operations.Operation) (int64, error)
 
 	// Buckets.
 	ValidateBucket(bucket Volume) error
@@ -56,6 +59,7 @@ type Driver interface {
 	CreateBucket(bucket Volume, op *operations.Operation) error
 	DeleteBucket(bucket Volume, op *operations.Operation) error
 	UpdateBucket(bucket Volume, changedConfig map[string]string) error
+	GetBucketS3Client(bucket Volume) (*minio.Client, string, error)
 	ValidateBucketKey(keyName string, creds S3Credentials, roleName string) error
 	CreateBucketKey(bucket Volume, keyName string, creds S3Credentials, roleName string, op *operations.Operation) (*S3Credentials, error)
 	UpdateBucketKey(bucket Volume, keyName string, creds S3Credentials, roleName string, op *operations.Operation) (*S3Credentials, error)
@@ -0,0 +1,424 @@
+package drivers
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// dedupMinFileSize is the minimum size of the files considered for deduplication.
+const dedupMinFileSize = 64 * 1024
+
+// dedupChunkSize is the maximum length of a single deduplication request.
+// Some filesystems silently cap requests at 16MiB, so bigger files are deduplicated in chunks.
+const dedupChunkSize = 16 * 1024 * 1024
+
+// reflinkSupportedCache caches whether the filesystems support FICLONE, keyed by device ID.
+var reflinkSupportedCache = map[uint64]bool{}
+var reflinkSupportedCacheMu sync.Mutex
+
+// reflinkSupported checks whether files in srcPath can be cloned into targetPath.
+// This requires both paths to be on the same filesystem as poolPath and the filesystem to support FICLONE.
+// The filesystem is only probed once, using temporary files in poolPath.
+func reflinkSupported(poolPath string, srcPath string, targetPath string) bool {
+	var poolStat, srcStat, targetStat unix.Stat_t
+
+	err := unix.Stat(poolPath, &poolStat)
+	if err != nil {
+		return false
+	}
+
+	err = unix.Stat(srcPath, &srcStat)
+	if err != nil {
+		return false
+	}
+
+	err = unix.Stat(targetPath, &targetStat)
+	if err != nil {
+		return false
+	}
+
+	if srcStat.Dev != poolStat.Dev || targetStat.Dev != poolStat.Dev {
+		return false
+	}
+
+	reflinkSupportedCacheMu.Lock()
+	defer reflinkSupportedCacheMu.Unlock()
+
+	supported, ok := reflinkSupportedCache[poolStat.Dev]
+	if !ok {
+		supported = reflinkProbe(poolPath)
+		reflinkSupportedCache[poolStat.Dev] = supported
+	}
+
+	return supported
+}
+
+// reflinkProbe checks whether the filesystem of path supports FICLONE by cloning a temporary file in path.
+func reflinkProbe(path string) bool {
+	src, err := os.CreateTemp(path, ".lxd_reflink_")
+	if err != nil {
+		return false
+	}
+
+	defer func() {
+		_ = src.Close()
+		_ = os.Remove(src.Name())
+	}()
+
+	_, err = src.Write(make([]byte, 4096))
+	if err != nil {
+		return false
+	}
+
+	target, err := os.CreateTemp(path, ".lxd_reflink_")
+	if err != nil {
+		return false
+	}
+
+	defer func() {
+		_ = target.Close()
+		_ = os.Remove(target.Name())
+	}()
+
+	err = unix.IoctlFileClone(int(target.Fd()), int(src.Fd()))
+
+	return err == nil
+}
+
+// tryReflinkCopy clones the content of srcPath into targetPath if the filesystem of the pool at poolPath
+// supports reflinks. Any existing content of targetPath is replaced, except for the top-level entries listed in
+// exclude. Returns false if the content wasn't cloned and must be copied by other means.
+func tryReflinkCopy(l logger.Logger, poolPath string, srcPath string, targetPath string, exclude ...string) bool {
+	if !reflinkSupported(poolPath, srcPath, targetPath) {
+		return false
+	}
+
+	err := reflinkCopy(srcPath, targetPath, exclude...)
+	if err != nil {
+		l.Warn("Failed cloning volume content, falling back to a full copy", logger.Ctx{"sourcePath": srcPath, "targetPath": targetPath, "err": err})
+		return false
+	}
+
+	l.Debug("Cloned volume content", logger.Ctx{"sourcePath": srcPath, "targetPath": targetPath})
+
+	return true
+}
+
+// reflinkCopy replaces the content of targetPath with reflinked copies of the content of srcPath.
+// Top-level entries listed in exclude are neither removed from targetPath nor copied from srcPath.
+func reflinkCopy(srcPath string, targetPath string, exclude ...string) error {
+	targetEntries, err := os.ReadDir(targetPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range targetEntries {
+		if shared.ValueInSlice(entry.Name(), exclude) {
+			continue
+		}
+
+		err = os.RemoveAll(filepath.Join(targetPath, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	srcEntries, err := os.ReadDir(srcPath)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"-a", "--reflink=always", "-t", targetPath}
+	for _, entry := range srcEntries {
+		if shared.ValueInSlice(entry.Name(), exclude) {
+			continue
+		}
+
+		args = append(args, filepath.Join(srcPath, entry.Name()))
+	}
+
+	// Nothing to copy.
+	if len(args) == 4 {
+		return nil
+	}
+
+	_, err = shared.RunCommand("cp", args...)
+	if err != nil {
+		return err
+	}
+
+	// Match the permissions and ownership of the root directory too.
+	var stat unix.Stat_t
+	err = unix.Stat(srcPath, &stat)
+	if err != nil {
+		return err
+	}
+
+	err = os.Chown(targetPath, int(stat.Uid), int(stat.Gid))
+	if err != nil {
+		return err
+	}
+
+	return os.Chmod(targetPath, os.FileMode(stat.Mode&0o7777))
+}
+
+// tryReflinkFile clones the regular file at srcPath into the regular file at targetPath if the filesystem
+// supports reflinks. Returns false if the file wasn't cloned and must be copied by other means.
+func tryReflinkFile(l logger.Logger, srcPath string, targetPath string) bool {
+	err := reflinkFile(srcPath, targetPath)
+	if err != nil {
+		if !errors.Is(err, ErrNotSupported) {
+			l.Warn("Failed cloning volume file, falling back to a full copy", logger.Ctx{"sourcePath": srcPath, "targetPath": targetPath, "err": err})
+		}
+
+		return false
+	}
+
+	l.Debug("Cloned volume file", logger.Ctx{"sourcePath": srcPath, "targetPath": targetPath})
+
+	return true
+}
+
+// reflinkFile replaces the content of the regular file at targetPath with a clone of srcPath.
+// If the target file was bigger than the source, its size is preserved like when copying the data over it.
+// Returns ErrNotSupported if either path isn't a regular file or if the filesystem doesn't support reflinks.
+func reflinkFile(srcPath string, targetPath string) error {
+	srcInfo, err := os.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+
+	targetInfo, err := os.Stat(targetPath)
+	if err != nil {
+		return err
+	}
+
+	if !srcInfo.Mode().IsRegular() || !targetInfo.Mode().IsRegular() {
+		return ErrNotSupported
+	}
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = src.Close() }()
+
+	target, err := os.OpenFile(targetPath, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = target.Close() }()
+
+	err = unix.IoctlFileClone(int(target.Fd()), int(src.Fd()))
+	if err != nil {
+		if errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.EXDEV) || errors.Is(err, unix.EINVAL) {
+			return ErrNotSupported
+		}
+
+		return err
+	}
+
+	if targetInfo.Size() > srcInfo.Size() {
+		err = target.Truncate(targetInfo.Size())
+		if err != nil {
+			return err
+		}
+	}
+
+	return target.Close()
+}
+
+// dedupFile describes a file considered for deduplication.
+type dedupFile struct {
+	path string
+	size int64
+}
+
+// deduplicateFiles shares the extents of identical regular files found below path.
+// Files are grouped by size and hash, and the filesystem then verifies that the content is identical before
+// sharing the extents. Returns the number of bytes that were deduplicated.
+func deduplicateFiles(l logger.Logger, path string) (int64, error) {
+	type inode struct {
+		dev uint64
+		ino uint64
+	}
+
+	seen := map[inode]bool{}
+	bySize := map[int64][]dedupFile{}
+
+	err := filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Files may disappear while walking the pool.
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if info.Size() < dedupMinFileSize {
+			return nil
+		}
+
+		// Skip additional hardlinks to the same file.
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if ok {
+			key := inode{dev: uint64(stat.Dev), ino: stat.Ino}
+			if seen[key] {
+				return nil
+			}
+
+			seen[key] = true
+		}
+
+		bySize[info.Size()] = append(bySize[info.Size()], dedupFile{path: filePath, size: info.Size()})
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var deduped int64
+
+	for _, files := range bySize {
+		if len(files) < 2 {
+			continue
+		}
+
+		byHash := map[[sha256.Size]byte][]dedupFile{}
+		for _, file := range files {
+			hash, err := dedupFileHash(file.path)
+			if err != nil {
+				l.Debug("Skipping file for deduplication", logger.Ctx{"path": file.path, "err": err})
+				continue
+			}
+
+			byHash[hash] = append(byHash[hash], file)
+		}
+
+		for _, identical := range byHash {
+			if len(identical) < 2 {
+				continue
+			}
+
+			for _, file := range identical[1:] {
+				n, err := dedupFileRange(identical[0], file)
+				if err != nil {
+					if errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.EINVAL) {
+						return deduped, ErrNotSupported
+					}
+
+					l.Debug("Failed deduplicating file", logger.Ctx{"source": identical[0].path, "target": file.path, "err": err})
+					continue
+				}
+
+				deduped += n
+			}
+		}
+	}
+
+	return deduped, nil
+}
+
+// dedupFileHash returns the SHA256 hash of the file at path.
+func dedupFileHash(path string) ([sha256.Size]byte, error) {
+	var hash [sha256.Size]byte
+
+	f, err := os.Open(path)
+	if err != nil {
+		return hash, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return hash, err
+	}
+
+	copy(hash[:], h.Sum(nil))
+
+	return hash, nil
+}
+
+// dedupFileRange shares the extents of src with target, chunk by chunk.
+// The filesystem compares the content of each chunk and skips those that differ.
+// Returns the number of bytes that were deduplicated.
+func dedupFileRange(src dedupFile, target dedupFile) (int64, error) {
+	srcFile, err := os.Open(src.path)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = srcFile.Close() }()
+
+	targetFile, err := os.Open(target.path)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = targetFile.Close() }()
+
+	var deduped int64
+
+	for offset := int64(0); offset < src.size; offset += dedupChunkSize {
+		length := min(int64(dedupChunkSize), src.size-offset)
+
+		value := unix.FileDedupeRange{
+			Src_offset: uint64(offset),
+			Src_length: uint64(length),
+			Info: []unix.FileDedupeRangeInfo{{
+				Dest_fd:     int64(targetFile.Fd()),
+				Dest_offset: uint64(offset),
+			}},
+		}
+
+		err = unix.IoctlFileDedupeRange(int(srcFile.Fd()), &value)
+		if err != nil {
+			return deduped, err
+		}
+
+		status := value.Info[0].Status
+		if status < 0 {
+			return deduped, fmt.Errorf("Failed deduplicating range at offset %d: %w", offset, unix.Errno(-status))
+		}
+
+		// The file was modified since it was hashed.
+		if status == unix.FILE_DEDUPE_RANGE_DIFFERS {
+			break
+		}
+
+		deduped += int64(value.Info[0].Bytes_deduped)
+	}
+
+	return deduped, nil
+}
@@ -0,0 +1,171 @@
+package drivers
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// Test reflinkSupported.
+func TestReflinkSupported(t *testing.T) {
+	poolPath := t.TempDir()
+	srcPath := filepath.Join(poolPath, "src")
+	targetPath := filepath.Join(poolPath, "target")
+	require.NoError(t, os.Mkdir(srcPath, 0700))
+	require.NoError(t, os.Mkdir(targetPath, 0700))
+
+	supported := reflinkSupported(poolPath, srcPath, targetPath)
+
+	// The result is cached and consistent.
+	assert.Equal(t, supported, reflinkSupported(poolPath, srcPath, targetPath))
+
+	// The probe files are created in the pool directory and cleaned up.
+	for _, path := range []string{poolPath, srcPath, targetPath} {
+		entries, err := os.ReadDir(path)
+		require.NoError(t, err)
+
+		for _, entry := range entries {
+			assert.False(t, strings.HasPrefix(entry.Name(), ".lxd_reflink_"), "Probe file left in %q", path)
+		}
+	}
+
+	// Missing paths aren't supported.
+	assert.False(t, reflinkSupported(poolPath, filepath.Join(poolPath, "missing"), targetPath))
+
+	// Paths on another filesystem than the pool aren't supported.
+	poolStat, err := os.Stat(poolPath)
+	require.NoError(t, err)
+
+	shmStat, err := os.Stat("/dev/shm")
+	if err == nil && !os.SameFile(poolStat, shmStat) {
+		assert.False(t, reflinkSupported(poolPath, srcPath, "/dev/shm"))
+	}
+}
+
+// Test reflinkFile.
+func TestReflinkFile(t *testing.T) {
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "src")
+	targetPath := filepath.Join(dir, "target")
+
+	// Directories aren't supported.
+	err := reflinkFile(dir, dir)
+	assert.ErrorIs(t, err, ErrNotSupported)
+
+	content := bytes.Repeat([]byte("a"), 8192)
+	require.NoError(t, os.WriteFile(srcPath, content, 0600))
+	require.NoError(t, os.WriteFile(targetPath, bytes.Repeat([]byte("b"), 16384), 0600))
+
+	err = reflinkFile(srcPath, targetPath)
+	if err == ErrNotSupported {
+		t.Skip("Filesystem doesn't support reflinks")
+	}
+
+	require.NoError(t, err)
+
+	// The content is cloned and the bigger size of the target is preserved.
+	data, err := os.ReadFile(targetPath)
+	require.NoError(t, err)
+	assert.Len(t, data, 16384)
+	assert.Equal(t, content, data[:8192])
+}
+
+// Test reflinkCopy.
+func TestReflinkCopy(t *testing.T) {
+	poolPath := t.TempDir()
+	srcPath := filepath.Join(poolPath, "src")
+	targetPath := filepath.Join(poolPath, "target")
+	require.NoError(t, os.Mkdir(srcPath, 0711))
+	require.NoError(t, os.Mkdir(targetPath, 0700))
+
+	if !reflinkSupported(poolPath, srcPath, targetPath) {
+		assert.False(t, tryReflinkCopy(logger.Log, poolPath, srcPath, targetPath))
+		t.Skip("Filesystem doesn't support reflinks")
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(srcPath, "file"), []byte("src"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(srcPath, "excluded"), []byte("src"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(targetPath, "stale"), []byte("target"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(targetPath, "excluded"), []byte("target"), 0600))
+
+	require.NoError(t, reflinkCopy(srcPath, targetPath, "excluded"))
+
+	// The content is replaced except for the excluded entries.
+	data, err := os.ReadFile(filepath.Join(targetPath, "file"))
+	require.NoError(t, err)
+	assert.Equal(t, "src", string(data))
+
+	data, err = os.ReadFile(filepath.Join(targetPath, "excluded"))
+	require.NoError(t, err)
+	assert.Equal(t, "target", string(data))
+
+	assert.NoFileExists(t, filepath.Join(targetPath, "stale"))
+
+	// The permissions of the root directory match the source.
+	info, err := os.Stat(targetPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0711), info.Mode().Perm())
+}
+
+// Test dedupFileHash.
+func TestDedupFileHash(t *testing.T) {
+	dir := t.TempDir()
+
+	paths := []string{filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")}
+	require.NoError(t, os.WriteFile(paths[0], []byte("content"), 0600))
+	require.NoError(t, os.WriteFile(paths[1], []byte("content"), 0600))
+	require.NoError(t, os.WriteFile(paths[2], []byte("other"), 0600))
+
+	hashA, err := dedupFileHash(paths[0])
+	require.NoError(t, err)
+
+	hashB, err := dedupFileHash(paths[1])
+	require.NoError(t, err)
+
+	hashC, err := dedupFileHash(paths[2])
+	require.NoError(t, err)
+
+	assert.Equal(t, hashA, hashB)
+	assert.NotEqual(t, hashA, hashC)
+
+	_, err = dedupFileHash(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+// Test deduplicateFiles.
+func TestDeduplicateFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	// Small files, unique files and hardlinks aren't deduplicated.
+	small := bytes.Repeat([]byte("a"), dedupMinFileSize-1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "small1"), small, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "small2"), small, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unique1"), bytes.Repeat([]byte("b"), dedupMinFileSize), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unique2"), bytes.Repeat([]byte("c"), dedupMinFileSize), 0600))
+	require.NoError(t, os.Link(filepath.Join(dir, "unique1"), filepath.Join(dir, "hardlink")))
+
+	deduped, err := deduplicateFiles(logger.Log, dir)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), deduped)
+
+	// Identical files are deduplicated if the filesystem supports it.
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0700))
+	identical := bytes.Repeat([]byte("d"), 2*dedupMinFileSize)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "identical1"), identical, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "identical2"), identical, 0600))
+
+	deduped, err = deduplicateFiles(logger.Log, dir)
+	if err == ErrNotSupported {
+		t.Skip("Filesystem doesn't support deduplication")
+	}
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(identical)), deduped)
+}
@@ -50,6 +50,7 @@ type Pool interface {
 	ToAPI() api.StoragePool
 
 	GetResources() (*api.ResourcesStoragePool, error)
+	GetProvisionedSpace() (uint64, error)
 	IsUsed() (bool, error)
 	Delete(clientType request.ClientType, op *operations.Operation) error
 	Update(clientType request.ClientType, newDesc string, newConfig map[string]string, op *operations.Operation) error
@@ -59,6 +60,7 @@ type Pool interface {
 	Unmount() (bool, error)
 
 	ApplyPatch(name string) error
+	Deduplicate(op *operations.Operation) error
 
 	GetVolume(volumeType drivers.VolumeType, contentType drivers.ContentType, name string, config map[string]string) drivers.Volume
 
@@ -111,6 +113,9 @@ type Pool interface {
 	DeleteBucketKey(projectName string, bucketName string, keyName string, op *operations.Operation) error
 	ActivateBucket(projectName string, bucketName string, op *operations.Operation) (*miniod.Process, error)
 	GetBucketURL(bucketName string) *url.URL
+	GenerateBucketBackupConfig(projectName string, bucketName string, op *operations.Operation) (*backupConfig.Config, error)
+	BackupBucket(projectName string, bucketName string, tarWriter *instancewriter.InstanceTarWriter, op *operations.Operation) error
+	CreateBucketFromBackup(srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) error
 
 	// Custom volumes.
 	CreateCustomVolume(projectName string, volName string, desc string, config map[string]string, contentType drivers.ContentType, op *operations.Operation) error
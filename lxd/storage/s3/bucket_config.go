@@ -0,0 +1,156 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// lifecycleRuleID is the ID of the object expiry lifecycle rule managed by LXD.
+const lifecycleRuleID = "lxd-expiry"
+
+// bucketLifecycleKeys are the bucket config keys that make up the lifecycle rule.
+var bucketLifecycleKeys = []string{"lifecycle.expiry", "lifecycle.noncurrent_expiry", "lifecycle.prefix"}
+
+// bucketConfigKeys are the bucket config keys that are applied to the bucket through the S3 API.
+var bucketConfigKeys = append([]string{"versioning", "policy"}, bucketLifecycleKeys...)
+
+// BucketConfigChanged returns true if changedConfig contains any keys that need applying through the S3 API.
+func BucketConfigChanged(changedConfig map[string]string) bool {
+	for _, key := range bucketConfigKeys {
+		_, found := changedConfig[key]
+		if found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ApplyBucketConfig applies the versioning, lifecycle and policy settings from config to the bucket.
+// If changedConfig is nil then all settings are applied (as is the case for a new bucket), otherwise only the
+// settings affected by the keys in changedConfig are applied.
+func ApplyBucketConfig(ctx context.Context, client *minio.Client, bucketName string, config map[string]string, changedConfig map[string]string) error {
+	changed := func(keys ...string) bool {
+		if changedConfig == nil {
+			return true
+		}
+
+		for _, key := range keys {
+			_, found := changedConfig[key]
+			if found {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	// Versioning can only be suspended once enabled, so only do so for existing buckets.
+	if changed("versioning") {
+		if shared.IsTrue(config["versioning"]) {
+			err := client.EnableVersioning(ctx, bucketName)
+			if err != nil {
+				return fmt.Errorf("Failed enabling bucket versioning: %w", err)
+			}
+		} else if changedConfig != nil {
+			err := client.SuspendVersioning(ctx, bucketName)
+			if err != nil {
+				return fmt.Errorf("Failed suspending bucket versioning: %w", err)
+			}
+		}
+	}
+
+	if changed(bucketLifecycleKeys...) {
+		lifecycleConfig, err := bucketLifecycle(config)
+		if err != nil {
+			return err
+		}
+
+		// An empty lifecycle configuration removes any existing rules.
+		if !lifecycleConfig.Empty() || changedConfig != nil {
+			err = client.SetBucketLifecycle(ctx, bucketName, lifecycleConfig)
+			if err != nil {
+				return fmt.Errorf("Failed setting bucket lifecycle: %w", err)
+			}
+		}
+	}
+
+	// An empty policy removes any existing bucket policy.
+	if changed("policy") && (config["policy"] != "" || changedConfig != nil) {
+		err := client.SetBucketPolicy(ctx, bucketName, config["policy"])
+		if err != nil {
+			return fmt.Errorf("Failed setting bucket policy: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// bucketLifecycle returns the lifecycle configuration for the supplied bucket config.
+func bucketLifecycle(config map[string]string) (*lifecycle.Configuration, error) {
+	lifecycleConfig := lifecycle.NewConfiguration()
+
+	expiry, err := bucketLifecycleDays(config["lifecycle.expiry"])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid lifecycle.expiry: %w", err)
+	}
+
+	noncurrentExpiry, err := bucketLifecycleDays(config["lifecycle.noncurrent_expiry"])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid lifecycle.noncurrent_expiry: %w", err)
+	}
+
+	if expiry == 0 && noncurrentExpiry == 0 {
+		return lifecycleConfig, nil
+	}
+
+	rule := lifecycle.Rule{
+		ID:     lifecycleRuleID,
+		Status: "Enabled",
+		RuleFilter: lifecycle.Filter{
+			Prefix: config["lifecycle.prefix"],
+		},
+	}
+
+	if expiry > 0 {
+		rule.Expiration.Days = lifecycle.ExpirationDays(expiry)
+	}
+
+	if noncurrentExpiry > 0 {
+		rule.NoncurrentVersionExpiration.NoncurrentDays = lifecycle.ExpirationDays(noncurrentExpiry)
+	}
+
+	lifecycleConfig.Rules = append(lifecycleConfig.Rules, rule)
+
+	return lifecycleConfig, nil
+}
+
+// bucketLifecycleDays parses a lifecycle expiry value in days. An empty value returns 0.
+func bucketLifecycleDays(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil {
+		return -1, err
+	}
+
+	if days < 0 {
+		return -1, fmt.Errorf("Expiry must be a positive number of days")
+	}
+
+	return days, nil
+}
+
+// ValidateBucketLifecycleDays validates a lifecycle expiry value in days.
+func ValidateBucketLifecycleDays(value string) error {
+	_, err := bucketLifecycleDays(value)
+	return err
+}
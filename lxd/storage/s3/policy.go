@@ -17,9 +17,35 @@ type Policy struct {
 
 // PolicyStatement defines the S3 policy statement.
 type PolicyStatement struct {
-	Effect   string
-	Action   []string
-	Resource []string
+	Effect    string
+	Principal json.RawMessage `json:",omitempty"`
+	Action    PolicyList
+	Resource  PolicyList
+}
+
+// PolicyList is a list of policy values which may be given as either a single string or a list of strings.
+type PolicyList []string
+
+// UnmarshalJSON accepts both a single string and a list of strings.
+func (l *PolicyList) UnmarshalJSON(data []byte) error {
+	var value string
+
+	err := json.Unmarshal(data, &value)
+	if err == nil {
+		*l = PolicyList{value}
+		return nil
+	}
+
+	var values []string
+
+	err = json.Unmarshal(data, &values)
+	if err != nil {
+		return fmt.Errorf("Value must be a string or a list of strings")
+	}
+
+	*l = values
+
+	return nil
 }
 
 // BucketPolicy generates an S3 bucket policy for role.
@@ -139,3 +165,29 @@ func comparePolicy(policyA Policy, policyB Policy) bool {
 
 	return true
 }
+
+// ValidateBucketPolicy validates that value is an S3 bucket policy document.
+func ValidateBucketPolicy(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	var policy Policy
+
+	err := json.Unmarshal([]byte(value), &policy)
+	if err != nil {
+		return fmt.Errorf("Invalid policy JSON: %w", err)
+	}
+
+	if len(policy.Statement) == 0 {
+		return fmt.Errorf("Policy must contain at least one statement")
+	}
+
+	for i, statement := range policy.Statement {
+		if len(statement.Principal) == 0 || string(statement.Principal) == "null" {
+			return fmt.Errorf("Policy statement %d must specify a principal", i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package s3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBucketPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		valid  bool
+	}{
+		{
+			name:   "Empty policy",
+			policy: "",
+			valid:  true,
+		},
+		{
+			name:   "Action and resource lists",
+			policy: `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Principal": "*", "Action": ["s3:GetObject", "s3:GetObjectVersion"], "Resource": ["arn:aws:s3:::foo/*"]}]}`,
+			valid:  true,
+		},
+		{
+			name:   "Action and resource strings",
+			policy: `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Principal": {"AWS": ["*"]}, "Action": "s3:GetObject", "Resource": "arn:aws:s3:::foo/*"}]}`,
+			valid:  true,
+		},
+		{
+			name:   "Missing principal",
+			policy: `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::foo/*"}]}`,
+			valid:  false,
+		},
+		{
+			name:   "Invalid action",
+			policy: `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Principal": "*", "Action": 1, "Resource": "arn:aws:s3:::foo/*"}]}`,
+			valid:  false,
+		},
+		{
+			name:   "No statement",
+			policy: `{"Version": "2012-10-17", "Statement": []}`,
+			valid:  false,
+		},
+		{
+			name:   "Invalid JSON",
+			policy: "invalid",
+			valid:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBucketPolicy(tt.policy)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestBucketPolicyRole(t *testing.T) {
+	// The single string form matches the same role as the list form.
+	role, err := BucketPolicyRole("foo", []byte(`{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "arn:aws:s3:::foo/*"}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, roleAdmin, role)
+
+	role, err = BucketPolicyRole("foo", []byte(`{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": ["s3:*"], "Resource": ["arn:aws:s3:::foo/*"]}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, roleAdmin, role)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	"github.com/canonical/lxd/lxd/rsync"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/lxd/storage/s3"
 	"github.com/canonical/lxd/lxd/sys"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -294,6 +296,8 @@ func VolumeDBCreate(pool Pool, projectName string, volumeName string, volumeDesc
 		return fmt.Errorf("Error inserting volume %q for project %q in pool %q of type %q into database %q", volumeName, projectName, pool.Name(), volumeType, err)
 	}
 
+	p.invalidateProvisionedSpace()
+
 	return nil
 }
 
@@ -317,6 +321,8 @@ func VolumeDBDelete(pool Pool, projectName string, volumeName string, volumeType
 		return fmt.Errorf("Error deleting storage volume from database: %w", err)
 	}
 
+	p.invalidateProvisionedSpace()
+
 	return nil
 }
 
@@ -613,6 +619,33 @@ func validatePoolCommonRules() map[string]func(string) error {
 		//  defaultdesc: `true`
 		//  shortdesc: Whether to use compression while migrating storage pools
 		"rsync.compression": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=storage-btrfs,storage-ceph,storage-lvm,storage-zfs,storage-powerflex; group=pool-conf; key=volume.overcommit_ratio)
+		// The sum of the sizes of the volumes in the pool (the provisioned space) is not allowed to
+		// exceed the total space of the pool multiplied by this ratio.
+		// Creating or growing a volume beyond that limit fails.
+		// ---
+		//  type: string
+		//  defaultdesc: no limit
+		//  shortdesc: Maximum ratio of provisioned space to total space of the pool
+		"volume.overcommit_ratio": validate.Optional(func(value string) error {
+			ratio, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("Invalid ratio %q: %w", value, err)
+			}
+
+			if ratio <= 0 {
+				return fmt.Errorf("Ratio must be greater than 0")
+			}
+
+			return nil
+		}),
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=pool-conf; key=space.warning_levels)
+		// Specify a comma-separated list of percentages (for example, `80,90,95`).
+		// A warning is raised when the used space of the pool reaches any of those levels.
+		// ---
+		//  type: string
+		//  shortdesc: Fill levels of the pool at which warnings are raised
+		"space.warning_levels": validate.Optional(validate.IsListOf(validate.IsInRange(1, 100))),
 	}
 
 	// Add to pool config rules (prefixed with volume.*) which are common for pool and volume.
@@ -649,6 +682,48 @@ func validateVolumeCommonRules(vol drivers.Volume) map[string]func(string) error
 		rules["volatile.rootfs.size"] = validate.Optional(validate.IsInt64)
 	}
 
+	// Versioning, lifecycle and policy settings are only used for buckets.
+	if vol.Type() == drivers.VolumeTypeBucket {
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephobject,storage-lvm,storage-zfs; group=bucket-conf; key=versioning)
+		// Once enabled, versioning can be suspended by setting this option to `false`, but existing object
+		// versions are kept.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to keep multiple versions of objects in the bucket
+		rules["versioning"] = validate.Optional(validate.IsBool)
+
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephobject,storage-lvm,storage-zfs; group=bucket-conf; key=lifecycle.expiry)
+		// Objects are deleted the given number of days after they were created.
+		// On versioned buckets, the current version is replaced by a delete marker instead.
+		// ---
+		//  type: integer
+		//  shortdesc: Number of days after which objects expire
+		rules["lifecycle.expiry"] = s3.ValidateBucketLifecycleDays
+
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephobject,storage-lvm,storage-zfs; group=bucket-conf; key=lifecycle.noncurrent_expiry)
+		// Only relevant for buckets with `versioning` enabled.
+		// ---
+		//  type: integer
+		//  shortdesc: Number of days after which noncurrent object versions expire
+		rules["lifecycle.noncurrent_expiry"] = s3.ValidateBucketLifecycleDays
+
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephobject,storage-lvm,storage-zfs; group=bucket-conf; key=lifecycle.prefix)
+		// If set, the expiry rules only apply to objects with the given key prefix.
+		// ---
+		//  type: string
+		//  shortdesc: Object key prefix that the expiry rules apply to
+		rules["lifecycle.prefix"] = validate.IsAny
+
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephobject,storage-lvm,storage-zfs; group=bucket-conf; key=policy)
+		// Specify a JSON S3 bucket policy document to grant access beyond the bucket keys.
+		// The policy resources must refer to the bucket name as used in the bucket's S3 URL.
+		// ---
+		//  type: string
+		//  shortdesc: S3 bucket policy
+		rules["policy"] = s3.ValidateBucketPolicy
+	}
+
 	return rules
 }
 
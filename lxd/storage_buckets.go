@@ -4,22 +4,30 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"sort"
 
 	"github.com/gorilla/mux"
 
 	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/backup"
 	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/operationtype"
 	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/version"
 )
@@ -383,6 +391,11 @@ func storagePoolBucketsPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// If we're getting binary content, process separately.
+	if r.Header.Get("Content-Type") == "application/octet-stream" {
+		return createStoragePoolBucketFromBackup(s, r, request.ProjectParam(r), bucketProjectName, r.Body, poolName, r.Header.Get("X-LXD-name"))
+	}
+
 	// Parse the request into a record.
 	req := api.StorageBucketsPost{}
 	err = json.NewDecoder(r.Body).Decode(&req)
@@ -427,6 +440,93 @@ func storagePoolBucketsPost(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponseLocation(true, adminKey, u.String())
 }
 
+// createStoragePoolBucketFromBackup creates a storage bucket from the uploaded bucket backup tarball.
+func createStoragePoolBucketFromBackup(s *state.State, r *http.Request, requestProjectName string, projectName string, data io.Reader, poolName string, bucketName string) response.Response {
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Create temporary file to store uploaded backup data.
+	backupFile, err := os.CreateTemp(shared.VarPath("backups"), fmt.Sprintf("%s_", backup.WorkingDirPrefix))
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	defer func() { _ = os.Remove(backupFile.Name()) }()
+	revert.Add(func() { _ = backupFile.Close() })
+
+	// Stream uploaded backup data into temporary file.
+	_, err = io.Copy(backupFile, data)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	// Parse the backup information.
+	_, err = backupFile.Seek(0, io.SeekStart)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	logger.Debug("Reading backup file info")
+	bInfo, err := backup.GetInfo(backupFile, s.OS, backupFile.Name())
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if bInfo.Type != backup.TypeBucket {
+		return response.BadRequest(fmt.Errorf("Backup is not a storage bucket backup"))
+	}
+
+	bInfo.Project = projectName
+	bInfo.Pool = poolName
+
+	// Override bucket name.
+	if bucketName != "" {
+		bInfo.Name = bucketName
+	}
+
+	logger.Debug("Backup file info loaded", logger.Ctx{
+		"type":    bInfo.Type,
+		"name":    bInfo.Name,
+		"project": bInfo.Project,
+		"backend": bInfo.Backend,
+		"pool":    bInfo.Pool,
+	})
+
+	pool, err := storagePools.LoadByName(s, bInfo.Pool)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading storage pool: %w", err))
+	}
+
+	// Copy reverter so far so we can use it inside run after this function has finished.
+	runRevert := revert.Clone()
+
+	run := func(op *operations.Operation) error {
+		defer func() { _ = backupFile.Close() }()
+		defer runRevert.Fail()
+
+		err = pool.CreateBucketFromBackup(*bInfo, backupFile, nil)
+		if err != nil {
+			return fmt.Errorf("Create storage bucket from backup: %w", err)
+		}
+
+		s.Events.SendLifecycle(projectName, lifecycle.StorageBucketCreated.Event(pool, projectName, bInfo.Name, op.Requestor(), nil))
+
+		runRevert.Success()
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_buckets"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", bInfo.Pool, "buckets", bInfo.Name)}
+
+	op, err := operations.OperationCreate(s, requestProjectName, operations.OperationClassTask, operationtype.StorageBucketBackupRestore, resources, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	revert.Success()
+	return operations.OperationResponse(op)
+}
+
 // swagger:operation PATCH /1.0/storage-pools/{name}/buckets/{bucketName} storage storage_pool_bucket_patch
 //
 //  Partially update the storage bucket.
@@ -0,0 +1,755 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/backup"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var storagePoolBucketBackupsCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/buckets/{bucketName}/backups",
+
+	Get:  APIEndpointAction{Handler: storagePoolBucketBackupsGet, AccessHandler: allowPermission(entity.TypeStorageBucket, auth.EntitlementCanView, "poolName", "bucketName")},
+	Post: APIEndpointAction{Handler: storagePoolBucketBackupsPost, AccessHandler: allowPermission(entity.TypeStorageBucket, auth.EntitlementCanEdit, "poolName", "bucketName")},
+}
+
+var storagePoolBucketBackupCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/buckets/{bucketName}/backups/{backupName}",
+
+	Delete: APIEndpointAction{Handler: storagePoolBucketBackupDelete, AccessHandler: allowPermission(entity.TypeStorageBucket, auth.EntitlementCanEdit, "poolName", "bucketName")},
+	Get:    APIEndpointAction{Handler: storagePoolBucketBackupGet, AccessHandler: allowPermission(entity.TypeStorageBucket, auth.EntitlementCanView, "poolName", "bucketName")},
+	Post:   APIEndpointAction{Handler: storagePoolBucketBackupPost, AccessHandler: allowPermission(entity.TypeStorageBucket, auth.EntitlementCanEdit, "poolName", "bucketName")},
+}
+
+var storagePoolBucketBackupExportCmd = APIEndpoint{
+	Path: "storage-pools/{poolName}/buckets/{bucketName}/backups/{backupName}/export",
+
+	Get: APIEndpointAction{Handler: storagePoolBucketBackupExportGet, AccessHandler: allowPermission(entity.TypeStorageBucket, auth.EntitlementCanView, "poolName", "bucketName")},
+}
+
+// storagePoolBucketBackupRequest holds the common fields of a storage bucket backup request.
+type storagePoolBucketBackupRequest struct {
+	projectName string
+	pool        storagePools.Pool
+	bucket      *db.StorageBucket
+}
+
+// storagePoolBucketBackupRequestLoad loads the storage pool and bucket referenced by the request.
+func storagePoolBucketBackupRequestLoad(s *state.State, r *http.Request) (*storagePoolBucketBackupRequest, error) {
+	bucketProjectName, err := project.StorageBucketProject(r.Context(), s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return nil, err
+	}
+
+	poolName, err := url.PathUnescape(mux.Vars(r)["poolName"])
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName, err := url.PathUnescape(mux.Vars(r)["bucketName"])
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := storagePools.LoadByName(s, poolName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading storage pool: %w", err)
+	}
+
+	if !pool.Driver().Info().Buckets {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Storage pool does not support buckets")
+	}
+
+	memberSpecific := !pool.Driver().Info().Remote // Member specific if storage pool isn't remote.
+
+	var bucket *db.StorageBucket
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		bucket, err = tx.GetStoragePoolBucket(ctx, pool.ID(), bucketProjectName, memberSpecific, bucketName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &storagePoolBucketBackupRequest{
+		projectName: bucketProjectName,
+		pool:        pool,
+		bucket:      bucket,
+	}, nil
+}
+
+// storagePoolBucketBackupLoadByName loads the backup with the given full name of the storage bucket.
+func storagePoolBucketBackupLoadByName(s *state.State, req *storagePoolBucketBackupRequest, backupName string) (*backup.BucketBackup, error) {
+	var b db.StoragePoolBucketBackup
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		b, err = tx.GetStoragePoolBucketBackup(ctx, req.bucket.ID, backupName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return backup.NewBucketBackup(s, req.projectName, req.pool.Name(), req.bucket.Name, req.bucket.ID, b.ID, b.Name, b.CreationDate, b.ExpiryDate), nil
+}
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/buckets/{bucketName}/backups storage storage_pool_bucket_backups_get
+//
+//  Get the storage bucket backups
+//
+//  Returns a list of storage bucket backups (URLs).
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//    - in: query
+//      name: target
+//      description: Cluster member name
+//      type: string
+//      example: lxd01
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of endpoints
+//            items:
+//              type: string
+//            example: |-
+//              [
+//                "/1.0/storage-pools/default/buckets/foo/backups/backup0",
+//                "/1.0/storage-pools/default/buckets/foo/backups/backup1"
+//              ]
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/buckets/{bucketName}/backups?recursion=1 storage storage_pool_bucket_backups_get_recursion1
+//
+//	Get the storage bucket backups
+//
+//	Returns a list of storage bucket backups (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of storage bucket backups
+//	          items:
+//	            $ref: "#/definitions/StorageBucketBackup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolBucketBackupsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	req, err := storagePoolBucketBackupRequestLoad(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	recursion := util.IsRecursionRequest(r)
+
+	var bucketBackups []db.StoragePoolBucketBackup
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		bucketBackups, err = tx.GetStoragePoolBucketBackups(ctx, req.bucket.ID)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []*api.StorageBucketBackup{}
+
+	for _, b := range bucketBackups {
+		bucketBackup := backup.NewBucketBackup(s, req.projectName, req.pool.Name(), req.bucket.Name, req.bucket.ID, b.ID, b.Name, b.CreationDate, b.ExpiryDate)
+
+		if !recursion {
+			_, backupName, _ := api.GetParentAndSnapshotName(bucketBackup.Name())
+			url := api.NewURL().Path(version.APIVersion, "storage-pools", req.pool.Name(), "buckets", req.bucket.Name, "backups", backupName).String()
+			resultString = append(resultString, url)
+		} else {
+			resultMap = append(resultMap, bucketBackup.Render())
+		}
+	}
+
+	if !recursion {
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/storage-pools/{poolName}/buckets/{bucketName}/backups storage storage_pool_bucket_backups_post
+//
+//	Create a storage bucket backup
+//
+//	Creates a new storage bucket backup.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	  - in: body
+//	    name: bucket
+//	    description: Storage bucket backup
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/StorageBucketBackupsPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolBucketBackupsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	bucketReq, err := storagePoolBucketBackupRequestLoad(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := bucketReq.projectName
+	poolName := bucketReq.pool.Name()
+	bucketName := bucketReq.bucket.Name
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return project.AllowBackupCreation(tx, projectName)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	rj := shared.Jmap{}
+	err = json.NewDecoder(r.Body).Decode(&rj)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	expiry, _ := rj.GetString("expires_at")
+	if expiry == "" {
+		// Disable expiration by setting it to zero time.
+		rj["expires_at"] = time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	// Create body with correct expiry.
+	body, err := json.Marshal(rj)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	req := api.StorageBucketBackupsPost{}
+
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Name == "" {
+		var backups []db.StoragePoolBucketBackup
+
+		// come up with a name.
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			backups, err = tx.GetStoragePoolBucketBackups(ctx, bucketReq.bucket.ID)
+			return err
+		})
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		base := bucketName + shared.SnapshotDelimiter + "backup"
+		length := len(base)
+		max := 0
+
+		for _, backup := range backups {
+			// Ignore backups not containing base.
+			if !strings.HasPrefix(backup.Name, base) {
+				continue
+			}
+
+			substr := backup.Name[length:]
+			var num int
+			count, err := fmt.Sscanf(substr, "%d", &num)
+			if err != nil || count != 1 {
+				continue
+			}
+
+			if num >= max {
+				max = num + 1
+			}
+		}
+
+		req.Name = fmt.Sprintf("backup%d", max)
+	}
+
+	// Validate the name.
+	if strings.Contains(req.Name, "/") {
+		return response.BadRequest(fmt.Errorf("Backup names may not contain slashes"))
+	}
+
+	fullName := bucketName + shared.SnapshotDelimiter + req.Name
+
+	backup := func(op *operations.Operation) error {
+		args := db.StoragePoolBucketBackup{
+			Name:                 fullName,
+			BucketID:             bucketReq.bucket.ID,
+			CreationDate:         time.Now(),
+			ExpiryDate:           req.ExpiresAt,
+			CompressionAlgorithm: req.CompressionAlgorithm,
+		}
+
+		err := bucketBackupCreate(s, args, projectName, poolName, bucketName)
+		if err != nil {
+			return fmt.Errorf("Create bucket backup: %w", err)
+		}
+
+		s.Events.SendLifecycle(projectName, lifecycle.StorageBucketBackupCreated.Event(poolName, projectName, args.Name, op.Requestor(), nil))
+
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_buckets"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "buckets", bucketName)}
+	resources["backups"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "buckets", bucketName, "backups", req.Name)}
+
+	op, err := operations.OperationCreate(s, request.ProjectParam(r), operations.OperationClassTask, operationtype.StorageBucketBackupCreate, resources, nil, backup, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/buckets/{bucketName}/backups/{backupName} storage storage_pool_bucket_backup_get
+//
+//	Get the storage bucket backup
+//
+//	Gets a specific storage bucket backup.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	responses:
+//	  "200":
+//	    description: Storage bucket backup
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/StorageBucketBackup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolBucketBackupGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	req, err := storagePoolBucketBackupRequestLoad(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Get backup name.
+	backupName, err := url.PathUnescape(mux.Vars(r)["backupName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	fullName := req.bucket.Name + shared.SnapshotDelimiter + backupName
+
+	backup, err := storagePoolBucketBackupLoadByName(s, req, fullName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, backup.Render())
+}
+
+// swagger:operation POST /1.0/storage-pools/{poolName}/buckets/{bucketName}/backups/{backupName} storage storage_pool_bucket_backup_post
+//
+//	Rename a storage bucket backup
+//
+//	Renames a storage bucket backup.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	  - in: body
+//	    name: bucket rename
+//	    description: Storage bucket backup
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/StorageBucketBackupPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolBucketBackupPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	bucketReq, err := storagePoolBucketBackupRequestLoad(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Get backup name.
+	backupName, err := url.PathUnescape(mux.Vars(r)["backupName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.StorageBucketBackupPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Validate the name.
+	if req.Name == "" {
+		return response.BadRequest(fmt.Errorf("No backup name provided"))
+	}
+
+	if strings.Contains(req.Name, "/") {
+		return response.BadRequest(fmt.Errorf("Backup names may not contain slashes"))
+	}
+
+	projectName := bucketReq.projectName
+	poolName := bucketReq.pool.Name()
+	bucketName := bucketReq.bucket.Name
+	oldName := bucketName + shared.SnapshotDelimiter + backupName
+
+	backup, err := storagePoolBucketBackupLoadByName(s, bucketReq, oldName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	newName := bucketName + shared.SnapshotDelimiter + req.Name
+
+	rename := func(op *operations.Operation) error {
+		err := backup.Rename(newName)
+		if err != nil {
+			return err
+		}
+
+		s.Events.SendLifecycle(projectName, lifecycle.StorageBucketBackupRenamed.Event(poolName, projectName, newName, op.Requestor(), logger.Ctx{"old_name": oldName}))
+
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_buckets"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "buckets", bucketName)}
+	resources["backups"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "buckets", bucketName, "backups", backupName)}
+
+	op, err := operations.OperationCreate(s, request.ProjectParam(r), operations.OperationClassTask, operationtype.StorageBucketBackupRename, resources, nil, rename, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// swagger:operation DELETE /1.0/storage-pools/{poolName}/buckets/{bucketName}/backups/{backupName} storage storage_pool_bucket_backup_delete
+//
+//	Delete a storage bucket backup
+//
+//	Deletes a storage bucket backup.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolBucketBackupDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	bucketReq, err := storagePoolBucketBackupRequestLoad(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Get backup name.
+	backupName, err := url.PathUnescape(mux.Vars(r)["backupName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := bucketReq.projectName
+	poolName := bucketReq.pool.Name()
+	bucketName := bucketReq.bucket.Name
+	fullName := bucketName + shared.SnapshotDelimiter + backupName
+
+	backup, err := storagePoolBucketBackupLoadByName(s, bucketReq, fullName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	remove := func(op *operations.Operation) error {
+		err := backup.Delete()
+		if err != nil {
+			return err
+		}
+
+		s.Events.SendLifecycle(projectName, lifecycle.StorageBucketBackupDeleted.Event(poolName, projectName, fullName, op.Requestor(), nil))
+
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	resources["storage_buckets"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "buckets", bucketName)}
+	resources["backups"] = []api.URL{*api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "buckets", bucketName, "backups", backupName)}
+
+	op, err := operations.OperationCreate(s, request.ProjectParam(r), operations.OperationClassTask, operationtype.StorageBucketBackupRemove, resources, nil, remove, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// swagger:operation GET /1.0/storage-pools/{poolName}/buckets/{bucketName}/backups/{backupName}/export storage storage_pool_bucket_backup_export_get
+//
+//	Get the raw backup file
+//
+//	Download the raw backup file from the server.
+//
+//	---
+//	produces:
+//	  - application/octet-stream
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	responses:
+//	  "200":
+//	    description: Raw backup data
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func storagePoolBucketBackupExportGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	bucketReq, err := storagePoolBucketBackupRequestLoad(s, r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Get backup name.
+	backupName, err := url.PathUnescape(mux.Vars(r)["backupName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := bucketReq.projectName
+	poolName := bucketReq.pool.Name()
+	fullName := bucketReq.bucket.Name + shared.SnapshotDelimiter + backupName
+
+	// Ensure the backup exists.
+	_, err = storagePoolBucketBackupLoadByName(s, bucketReq, fullName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	ent := response.FileResponseEntry{
+		Path: backup.BucketBackupPath(projectName, poolName, fullName),
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.StorageBucketBackupRetrieved.Event(poolName, projectName, fullName, request.CreateRequestor(r), nil))
+
+	return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
+}
@@ -51,6 +51,8 @@ func (s *OS) initDirs() error {
 		{filepath.Join(s.VarDir, "shmounts"), 0711},
 		// snapshots is 0700 as liblxc does not need to access this.
 		{filepath.Join(s.VarDir, "snapshots"), 0700},
+		// virtual-machines-forks is 0711 so that unprivileged QEMU processes can reach their fork base.
+		{filepath.Join(s.VarDir, "virtual-machines-forks"), 0711},
 		{filepath.Join(s.VarDir, "virtual-machines-snapshots"), 0700},
 		{filepath.Join(s.VarDir, "storage-pools"), 0711},
 	}
@@ -74,6 +74,7 @@ type OS struct {
 	CGInfo cgroup.Info
 
 	// Kernel features
+	CheckpointRestore       bool
 	CloseRange              bool
 	CoreScheduling          bool
 	IdmappedMounts          bool
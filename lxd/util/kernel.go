@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/units"
 )
 
 // LoadModule loads the kernel module with the given name, by invoking
@@ -82,3 +83,45 @@ func HugepagesPath() (string, error) {
 
 	return matches[0], nil
 }
+
+// HugepagesPathForSize attempts to locate the mount point of the hugepages filesystem using the given page size.
+// Mounts without a pagesize option are assumed to use the default huge page size of the system.
+func HugepagesPathForSize(size uint64, defaultSize uint64) (string, error) {
+	file, err := os.Open("/proc/mounts")
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		cols := strings.Fields(line)
+		if len(cols) < 4 || cols[2] != "hugetlbfs" {
+			continue
+		}
+
+		pageSize := defaultSize
+		for _, opt := range strings.Split(cols[3], ",") {
+			value, found := strings.CutPrefix(opt, "pagesize=")
+			if !found {
+				continue
+			}
+
+			// The kernel reports the page size with a binary unit suffix, for example "2M".
+			bytes, err := units.ParseByteSizeString(value + "iB")
+			if err != nil {
+				return "", fmt.Errorf("Failed parsing hugetlbfs page size %q: %w", value, err)
+			}
+
+			pageSize = uint64(bytes)
+		}
+
+		if pageSize == size {
+			return cols[1], nil
+		}
+	}
+
+	return "", fmt.Errorf("No hugetlbfs mount found with a page size of %s", units.GetByteSizeStringIEC(int64(size), 0))
+}
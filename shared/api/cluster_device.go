@@ -0,0 +1,56 @@
+package api
+
+// ClusterDevice represents a device of a cluster member that can be passed through to an instance.
+//
+// swagger:model
+//
+// API extension: cluster_device_inventory.
+type ClusterDevice struct {
+	// Name of the cluster member the device is on
+	// Example: lxd01
+	Location string `json:"location" yaml:"location"`
+
+	// Type of device (usb, pci, gpu or sriov-vf)
+	// Example: gpu
+	Type string `json:"type" yaml:"type"`
+
+	// Address of the device (PCI address, or bus and device number for USB devices)
+	// Example: 0000:03:00.0
+	Address string `json:"address" yaml:"address"`
+
+	// Host name of the device (DRM card name for GPUs, interface name for SR-IOV virtual functions)
+	// Example: card0
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Name of the vendor
+	// Example: NVIDIA Corporation
+	Vendor string `json:"vendor,omitempty" yaml:"vendor,omitempty"`
+
+	// ID of the vendor
+	// Example: 10de
+	VendorID string `json:"vendor_id" yaml:"vendor_id"`
+
+	// Name of the product
+	// Example: GA102GL [A40]
+	Product string `json:"product,omitempty" yaml:"product,omitempty"`
+
+	// ID of the product
+	// Example: 2235
+	ProductID string `json:"product_id" yaml:"product_id"`
+
+	// Serial number (USB devices only)
+	// Example: 0123456789
+	Serial string `json:"serial,omitempty" yaml:"serial,omitempty"`
+
+	// Parent network interface (SR-IOV virtual functions only)
+	// Example: enp5s0f0
+	Parent string `json:"parent,omitempty" yaml:"parent,omitempty"`
+
+	// Instance the device is allocated to
+	// Example: /1.0/instances/vm1?project=default
+	UsedBy string `json:"used_by,omitempty" yaml:"used_by,omitempty"`
+
+	// Name of the instance device the device is allocated to
+	// Example: gpu0
+	UsedByDevice string `json:"used_by_device,omitempty" yaml:"used_by_device,omitempty"`
+}
@@ -41,6 +41,7 @@ const (
 	EventLifecycleInstanceFileDeleted               = "instance-file-deleted"
 	EventLifecycleInstanceFilePushed                = "instance-file-pushed"
 	EventLifecycleInstanceFileRetrieved             = "instance-file-retrieved"
+	EventLifecycleInstanceHealthChanged             = "instance-health-changed"
 	EventLifecycleInstanceLogDeleted                = "instance-log-deleted"
 	EventLifecycleInstanceLogRetrieved              = "instance-log-retrieved"
 	EventLifecycleInstanceMetadataRetrieved         = "instance-metadata-retrieved"
@@ -54,6 +55,7 @@ const (
 	EventLifecycleInstanceRestarted                 = "instance-restarted"
 	EventLifecycleInstanceRestored                  = "instance-restored"
 	EventLifecycleInstanceResumed                   = "instance-resumed"
+	EventLifecycleInstanceSessionRecorded           = "instance-session-recorded"
 	EventLifecycleInstanceShutdown                  = "instance-shutdown"
 	EventLifecycleInstanceSnapshotCreated           = "instance-snapshot-created"
 	EventLifecycleInstanceSnapshotDeleted           = "instance-snapshot-deleted"
@@ -61,7 +63,12 @@ const (
 	EventLifecycleInstanceSnapshotUpdated           = "instance-snapshot-updated"
 	EventLifecycleInstanceStarted                   = "instance-started"
 	EventLifecycleInstanceStopped                   = "instance-stopped"
+	EventLifecycleInstanceTemplateCreated           = "instance-template-created"
+	EventLifecycleInstanceTemplateDeleted           = "instance-template-deleted"
+	EventLifecycleInstanceTemplateRenamed           = "instance-template-renamed"
+	EventLifecycleInstanceTemplateUpdated           = "instance-template-updated"
 	EventLifecycleInstanceUpdated                   = "instance-updated"
+	EventLifecycleInstanceWatchdogTriggered         = "instance-watchdog-triggered"
 	EventLifecycleNetworkACLCreated                 = "network-acl-created"
 	EventLifecycleNetworkACLDeleted                 = "network-acl-deleted"
 	EventLifecycleNetworkACLRenamed                 = "network-acl-renamed"
@@ -71,6 +78,9 @@ const (
 	EventLifecycleNetworkForwardCreated             = "network-forward-created"
 	EventLifecycleNetworkForwardDeleted             = "network-forward-deleted"
 	EventLifecycleNetworkForwardUpdated             = "network-forward-updated"
+	EventLifecycleNetworkIngressCreated             = "network-ingress-created"
+	EventLifecycleNetworkIngressDeleted             = "network-ingress-deleted"
+	EventLifecycleNetworkIngressUpdated             = "network-ingress-updated"
 	EventLifecycleNetworkLoadBalancerCreated        = "network-load-balancer-created"
 	EventLifecycleNetworkLoadBalancerDeleted        = "network-load-balancer-deleted"
 	EventLifecycleNetworkLoadBalancerUpdated        = "network-load-balancer-updated"
@@ -103,6 +113,10 @@ const (
 	EventLifecycleStorageBucketKeyCreated           = "storage-bucket-key-created"
 	EventLifecycleStorageBucketKeyUpdated           = "storage-bucket-key-updated"
 	EventLifecycleStorageBucketKeyDeleted           = "storage-bucket-key-deleted"
+	EventLifecycleStorageBucketBackupCreated        = "storage-bucket-backup-created"
+	EventLifecycleStorageBucketBackupDeleted        = "storage-bucket-backup-deleted"
+	EventLifecycleStorageBucketBackupRenamed        = "storage-bucket-backup-renamed"
+	EventLifecycleStorageBucketBackupRetrieved      = "storage-bucket-backup-retrieved"
 	EventLifecycleStorageVolumeCreated              = "storage-volume-created"
 	EventLifecycleStorageVolumeBackupCreated        = "storage-volume-backup-created"
 	EventLifecycleStorageVolumeBackupDeleted        = "storage-volume-backup-deleted"
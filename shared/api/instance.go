@@ -49,6 +49,18 @@ type InstancesPost struct {
 	// Type (container or virtual-machine)
 	// Example: container
 	Type InstanceType `json:"type" yaml:"type"`
+
+	// Instance template to create the instance from
+	// Example: web
+	//
+	// API extension: instance_templates
+	Template string `json:"template,omitempty" yaml:"template,omitempty"`
+
+	// Values of the instance template parameters
+	// Example: {"size": "large"}
+	//
+	// API extension: instance_templates
+	TemplateParameters map[string]string `json:"template_parameters,omitempty" yaml:"template_parameters,omitempty"`
 }
 
 // InstancesPut represents the fields available for a mass update.
@@ -418,6 +430,12 @@ type InstanceSource struct {
 	//
 	// API extension: instance_allow_inconsistent_copy
 	AllowInconsistent bool `json:"allow_inconsistent" yaml:"allow_inconsistent"`
+
+	// Whether to fork a running virtual machine into a clone sharing its memory (for stateful copy)
+	// Example: false
+	//
+	// API extension: instance_fork
+	Fork bool `json:"fork,omitempty" yaml:"fork,omitempty"`
 }
 
 // InstanceUEFIVars represents the UEFI variables of a LXD virtual machine.
@@ -35,6 +35,11 @@ type InstanceBackupsPost struct {
 	//
 	// API extension: backup_compression_algorithm
 	CompressionAlgorithm string `json:"compression_algorithm" yaml:"compression_algorithm"`
+	// Whether to include the running state of the instance
+	// Example: true
+	//
+	// API extension: instance_stateful_backups
+	Stateful bool `json:"stateful" yaml:"stateful"`
 }
 
 // InstanceBackup represents a LXD instance backup.
@@ -66,6 +71,12 @@ type InstanceBackup struct {
 	// Whether to use a pool-optimized binary format (instead of plain tarball)
 	// Example: true
 	OptimizedStorage bool `json:"optimized_storage" yaml:"optimized_storage"`
+
+	// Whether the running state of the instance is included
+	// Example: true
+	//
+	// API extension: instance_stateful_backups
+	Stateful bool `json:"stateful" yaml:"stateful"`
 }
 
 // InstanceBackupPost represents the fields available for the renaming of a instance backup.
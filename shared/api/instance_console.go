@@ -27,4 +27,10 @@ type InstanceConsolePost struct {
 	//
 	// API extension: console_vga_type
 	Type string `json:"type" yaml:"type"`
+
+	// Name of the serial device to attach to instead of the main console (console type only)
+	// Example: serial0
+	//
+	// API extension: instance_serial_device
+	Port string `json:"port" yaml:"port"`
 }
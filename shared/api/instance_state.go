@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // InstanceStatePut represents the modifiable fields of a LXD instance's state.
 //
 // swagger:model
@@ -56,6 +60,30 @@ type InstanceState struct {
 
 	// CPU usage information
 	CPU InstanceStateCPU `json:"cpu" yaml:"cpu"`
+
+	// Confidential computing information (only set while a confidential virtual machine is running)
+	//
+	// API extension: instance_confidential_computing
+	Confidential *InstanceStateConfidential `json:"confidential,omitempty" yaml:"confidential,omitempty"`
+}
+
+// InstanceStateConfidential represents the confidential computing section of a LXD instance's state.
+//
+// swagger:model
+//
+// API extension: instance_confidential_computing.
+type InstanceStateConfidential struct {
+	// Confidential computing technology used by the instance (sev, sev-es, sev-snp or tdx)
+	// Example: sev-es
+	Type string `json:"type" yaml:"type"`
+
+	// Guest policy (AMD SEV only)
+	// Example: 0x5
+	Policy string `json:"policy,omitempty" yaml:"policy,omitempty"`
+
+	// Base64-encoded launch measurement reported by the AMD secure processor (SEV and SEV-ES only)
+	// Example: vfJoX4yj3W+0LPcH2dCWJGCOzgy4/3FKd0XZ+4FcrDVrrJENchoDZ8wj7BG1JXgO
+	LaunchMeasurement string `json:"launch_measurement,omitempty" yaml:"launch_measurement,omitempty"`
 }
 
 // InstanceStateDisk represents the disk information section of a LXD instance's state.
@@ -73,6 +101,51 @@ type InstanceStateDisk struct {
 	//
 	// API extension: instances_state_total
 	Total int64 `json:"total" yaml:"total"`
+
+	// Protocol used to share the directory with the virtual machine (virtiofs or 9p)
+	// Example: virtiofs
+	//
+	// API extension: disk_share_protocol
+	Protocol string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+
+	// I/O counters (only set while the instance is running)
+	//
+	// API extension: instance_state_disk_counters
+	Counters *InstanceStateDiskCounters `json:"counters,omitempty" yaml:"counters,omitempty"`
+}
+
+// InstanceStateDiskCounters represents I/O counters as part of the disk section of a LXD instance's state.
+//
+// For containers, the counters are those of the host block device backing the disk, as accounted to the
+// instance, so disks backed by the same block device report the same counters.
+//
+// swagger:model
+//
+// API extension: instance_state_disk_counters.
+type InstanceStateDiskCounters struct {
+	// Number of bytes read
+	// Example: 102445056
+	BytesRead int64 `json:"bytes_read" yaml:"bytes_read"`
+
+	// Number of bytes written
+	// Example: 17211392
+	BytesWritten int64 `json:"bytes_written" yaml:"bytes_written"`
+
+	// Number of read operations completed
+	// Example: 4352
+	ReadsCompleted int64 `json:"reads_completed" yaml:"reads_completed"`
+
+	// Number of write operations completed
+	// Example: 1063
+	WritesCompleted int64 `json:"writes_completed" yaml:"writes_completed"`
+
+	// Total time spent on read operations in milliseconds (virtual machines only)
+	// Example: 1831
+	ReadTime int64 `json:"read_time" yaml:"read_time"`
+
+	// Total time spent on write operations in milliseconds (virtual machines only)
+	// Example: 942
+	WriteTime int64 `json:"write_time" yaml:"write_time"`
 }
 
 // InstanceStateCPU represents the cpu information section of a LXD instance's state.
@@ -211,3 +284,52 @@ type InstanceStateNetworkCounters struct {
 	// Example: 179
 	PacketsDroppedInbound int64 `json:"packets_dropped_inbound" yaml:"packets_dropped_inbound"`
 }
+
+// InstanceStateHistory represents the resource usage history of a LXD instance.
+//
+// swagger:model
+//
+// API extension: instance_state_history.
+type InstanceStateHistory struct {
+	// Resource usage samples, oldest first
+	Samples []InstanceStateHistorySample `json:"samples" yaml:"samples"`
+}
+
+// InstanceStateHistorySample represents the resource usage of a LXD instance over a sampling interval.
+//
+// swagger:model
+//
+// API extension: instance_state_history.
+type InstanceStateHistorySample struct {
+	// End of the sampling interval
+	// Example: 2021-03-23T20:00:00-04:00
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+
+	// Length of the sampling interval in seconds
+	// Example: 60
+	Interval int64 `json:"interval" yaml:"interval"`
+
+	// Average CPU usage over the interval (in CPU seconds per second)
+	// Example: 0.25
+	CPUUsage float64 `json:"cpu_usage" yaml:"cpu_usage"`
+
+	// Memory usage in bytes (averaged over the interval for downsampled samples)
+	// Example: 73248768
+	MemoryUsage int64 `json:"memory_usage" yaml:"memory_usage"`
+
+	// Average number of bytes read from disks per second
+	// Example: 4096
+	DiskReadRate int64 `json:"disk_read_rate" yaml:"disk_read_rate"`
+
+	// Average number of bytes written to disks per second
+	// Example: 16384
+	DiskWriteRate int64 `json:"disk_write_rate" yaml:"disk_write_rate"`
+
+	// Average number of bytes received over the network per second
+	// Example: 2048
+	NetworkReceiveRate int64 `json:"network_receive_rate" yaml:"network_receive_rate"`
+
+	// Average number of bytes sent over the network per second
+	// Example: 1024
+	NetworkTransmitRate int64 `json:"network_transmit_rate" yaml:"network_transmit_rate"`
+}
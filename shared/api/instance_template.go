@@ -0,0 +1,105 @@
+package api
+
+// InstanceTemplatesPost represents the fields of a new LXD instance template
+//
+// swagger:model
+//
+// API extension: instance_templates.
+type InstanceTemplatesPost struct {
+	InstanceTemplatePut `yaml:",inline"`
+
+	// The name of the instance template
+	// Example: web
+	Name string `json:"name" yaml:"name"`
+}
+
+// InstanceTemplatePost represents the fields required to rename a LXD instance template
+//
+// swagger:model
+//
+// API extension: instance_templates.
+type InstanceTemplatePost struct {
+	// The new name for the instance template
+	// Example: web-server
+	Name string `json:"name" yaml:"name"`
+}
+
+// InstanceTemplatePut represents the modifiable fields of a LXD instance template
+//
+// swagger:model
+//
+// API extension: instance_templates.
+type InstanceTemplatePut struct {
+	// Description of the instance template
+	// Example: Web server
+	Description string `json:"description" yaml:"description"`
+
+	// Parameters that can be substituted in the instance definition, by name.
+	// Parameters are referenced as {{ name }} in the string fields of the instance definition.
+	// Example: {"size": {"description": "Instance size", "default": "small"}}
+	Parameters map[string]InstanceTemplateParameter `json:"parameters" yaml:"parameters"`
+
+	// Definition of the instances created from the template
+	Instance InstancesPost `json:"instance" yaml:"instance"`
+}
+
+// InstanceTemplateParameter represents a parameter of a LXD instance template
+//
+// swagger:model
+//
+// API extension: instance_templates.
+type InstanceTemplateParameter struct {
+	// Description of the parameter
+	// Example: Instance size
+	Description string `json:"description" yaml:"description"`
+
+	// Value used when the parameter isn't provided
+	// Example: small
+	Default string `json:"default" yaml:"default"`
+
+	// Whether a value must be provided for the parameter
+	// Example: false
+	Required bool `json:"required" yaml:"required"`
+}
+
+// InstanceTemplate represents a LXD instance template
+//
+// swagger:model
+//
+// API extension: instance_templates.
+type InstanceTemplate struct {
+	// The name of the instance template
+	// Example: web
+	Name string `json:"name" yaml:"name"`
+
+	// Description of the instance template
+	// Example: Web server
+	Description string `json:"description" yaml:"description"`
+
+	// Parameters that can be substituted in the instance definition, by name.
+	// Example: {"size": {"description": "Instance size", "default": "small"}}
+	Parameters map[string]InstanceTemplateParameter `json:"parameters" yaml:"parameters"`
+
+	// Definition of the instances created from the template
+	Instance InstancesPost `json:"instance" yaml:"instance"`
+
+	// Project name
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+}
+
+// Writable converts a full InstanceTemplate struct into a InstanceTemplatePut struct (filters read-only fields).
+func (template *InstanceTemplate) Writable() InstanceTemplatePut {
+	return InstanceTemplatePut{
+		Description: template.Description,
+		Parameters:  template.Parameters,
+		Instance:    template.Instance,
+	}
+}
+
+// SetWritable sets applicable values from InstanceTemplatePut struct to InstanceTemplate struct.
+func (template *InstanceTemplate) SetWritable(put InstanceTemplatePut) {
+	template.Description = put.Description
+	template.Parameters = put.Parameters
+	template.Instance = put.Instance
+}
@@ -0,0 +1,299 @@
+package api
+
+import (
+	"net"
+	"strings"
+	"time"
+)
+
+// NetworkIngressRoute represents a route of a network ingress
+//
+// swagger:model
+//
+// API extension: network_ingress.
+type NetworkIngressRoute struct {
+	// lxdmeta:generate(entities=network-ingress; group=route-properties; key=description)
+	//
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: Description of the route
+
+	// Description of the route
+	// Example: My web server
+	Description string `json:"description" yaml:"description"`
+
+	// lxdmeta:generate(entities=network-ingress; group=route-properties; key=path)
+	// Requests are sent to the route with the longest matching path prefix.
+	// Routes using the same path share the requests between their healthy targets.
+	// ---
+	//  type: string
+	//  required: no
+	//  defaultdesc: `/`
+	//  shortdesc: Path prefix of the requests to route
+
+	// Path prefix of the requests to route
+	// Example: /api
+	Path string `json:"path" yaml:"path"`
+
+	// lxdmeta:generate(entities=network-ingress; group=route-properties; key=target_address)
+	// The address must be within the subnet of the network that the ingress belongs to.
+	// ---
+	//  type: string
+	//  required: yes
+	//  shortdesc: IP address to send the requests to
+
+	// TargetAddress to send the requests to
+	// Example: 198.51.100.2
+	TargetAddress string `json:"target_address" yaml:"target_address"`
+
+	// lxdmeta:generate(entities=network-ingress; group=route-properties; key=target_port)
+	//
+	// ---
+	//  type: integer
+	//  required: no
+	//  defaultdesc: `80`
+	//  shortdesc: Port to send the requests to
+
+	// TargetPort to send the requests to
+	// Example: 8080
+	TargetPort string `json:"target_port" yaml:"target_port"`
+}
+
+// Normalise normalises the fields in the route so that they are comparable with ones stored.
+func (r *NetworkIngressRoute) Normalise() {
+	r.Description = strings.TrimSpace(r.Description)
+	r.TargetAddress = strings.TrimSpace(r.TargetAddress)
+	r.TargetPort = strings.TrimSpace(r.TargetPort)
+
+	ip := net.ParseIP(r.TargetAddress)
+	if ip != nil {
+		r.TargetAddress = ip.String() // Replace with canonical form if specified.
+	}
+
+	r.Path = "/" + strings.Trim(strings.TrimSpace(r.Path), "/")
+
+	if r.TargetPort == "" {
+		r.TargetPort = "80"
+	}
+}
+
+// NetworkIngressesPost represents the fields of a new LXD network ingress
+//
+// swagger:model
+//
+// API extension: network_ingress.
+type NetworkIngressesPost struct {
+	NetworkIngressPut `yaml:",inline"`
+
+	// lxdmeta:generate(entities=network-ingress; group=ingress-properties; key=hostname)
+	// Requests are matched on the `Host` header or the TLS server name.
+	// ---
+	//  type: string
+	//  required: yes
+	//  shortdesc: Host name served by the ingress
+
+	// The host name served by the ingress
+	// Example: www.example.net
+	Hostname string `json:"hostname" yaml:"hostname"`
+
+	// lxdmeta:generate(entities=network-ingress; group=ingress-properties; key=listen_address)
+	// The address must be assigned to the host, LXD listens on ports 80 and 443 of it.
+	// ---
+	//  type: string
+	//  required: yes
+	//  shortdesc: IP address to listen on
+
+	// The listen address of the ingress
+	// Example: 192.0.2.1
+	ListenAddress string `json:"listen_address" yaml:"listen_address"`
+}
+
+// Normalise normalises the fields in the ingress so that they are comparable with ones stored.
+func (i *NetworkIngressesPost) Normalise() {
+	i.Hostname = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(i.Hostname), "."))
+
+	ip := net.ParseIP(i.ListenAddress)
+	if ip != nil {
+		i.ListenAddress = ip.String() // Replace with canonical form if specified.
+	}
+
+	i.NetworkIngressPut.Normalise()
+}
+
+// NetworkIngressPut represents the modifiable fields of a LXD network ingress
+//
+// swagger:model
+//
+// API extension: network_ingress.
+type NetworkIngressPut struct {
+	// lxdmeta:generate(entities=network-ingress; group=ingress-properties; key=description)
+	//
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: Description of the network ingress
+
+	// Description of the ingress
+	// Example: My public web site
+	Description string `json:"description" yaml:"description"`
+
+	// lxdmeta:generate(entities=network-ingress; group=ingress-properties; key=config)
+	// See {ref}`network-ingresses-options`.
+	// ---
+	//  type: string set
+	//  required: no
+	//  shortdesc: Configuration options as key/value pairs
+
+	// Ingress configuration map (refer to doc/howto/network_ingresses.md)
+	// Example: {"healthcheck.path": "/healthz"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// lxdmeta:generate(entities=network-ingress; group=ingress-properties; key=routes)
+	// See {ref}`network-ingresses-routes`.
+	// ---
+	//  type: route list
+	//  required: no
+	//  shortdesc: List of routes
+
+	// Routes of the ingress
+	Routes []NetworkIngressRoute `json:"routes" yaml:"routes"`
+}
+
+// Normalise normalises the fields in the ingress so that they are comparable with ones stored.
+func (i *NetworkIngressPut) Normalise() {
+	i.Description = strings.TrimSpace(i.Description)
+
+	for j := range i.Routes {
+		i.Routes[j].Normalise()
+	}
+}
+
+// NetworkIngress used for displaying a network ingress
+//
+// swagger:model
+//
+// API extension: network_ingress.
+type NetworkIngress struct {
+	// The host name served by the ingress
+	// Example: www.example.net
+	Hostname string `json:"hostname" yaml:"hostname"`
+
+	// The listen address of the ingress
+	// Example: 192.0.2.1
+	ListenAddress string `json:"listen_address" yaml:"listen_address"`
+
+	// What cluster member this record was found on
+	// Example: lxd01
+	Location string `json:"location" yaml:"location"`
+
+	// Description of the ingress
+	// Example: My public web site
+	Description string `json:"description" yaml:"description"`
+
+	// Ingress configuration map (refer to doc/howto/network_ingresses.md)
+	// Example: {"healthcheck.path": "/healthz"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// Routes of the ingress
+	Routes []NetworkIngressRoute `json:"routes" yaml:"routes"`
+}
+
+// Normalise normalises the fields in the ingress so that they are comparable with ones stored.
+func (i *NetworkIngress) Normalise() {
+	iPut := i.Writable()
+	iPut.Normalise()
+	i.SetWritable(iPut)
+}
+
+// Etag returns the values used for etag generation.
+func (i *NetworkIngress) Etag() []any {
+	return []any{i.Hostname, i.ListenAddress, i.Description, i.Config, i.Routes}
+}
+
+// Writable converts a full NetworkIngress struct into a NetworkIngressPut struct (filters read-only fields).
+func (i *NetworkIngress) Writable() NetworkIngressPut {
+	return NetworkIngressPut{
+		Description: i.Description,
+		Config:      i.Config,
+		Routes:      i.Routes,
+	}
+}
+
+// SetWritable sets applicable values from NetworkIngressPut struct to NetworkIngress struct.
+func (i *NetworkIngress) SetWritable(put NetworkIngressPut) {
+	i.Description = put.Description
+	i.Config = put.Config
+	i.Routes = put.Routes
+}
+
+// NetworkIngressState represents the runtime state of a network ingress
+//
+// swagger:model
+//
+// API extension: network_ingress.
+type NetworkIngressState struct {
+	// State of the TLS certificate
+	Certificate NetworkIngressStateCertificate `json:"certificate" yaml:"certificate"`
+
+	// Number of requests received
+	// Example: 1024
+	Requests int64 `json:"requests" yaml:"requests"`
+
+	// Number of requests which failed with a server error
+	// Example: 3
+	Errors int64 `json:"errors" yaml:"errors"`
+
+	// State of the targets
+	Targets []NetworkIngressStateTarget `json:"targets" yaml:"targets"`
+}
+
+// NetworkIngressStateCertificate represents the state of the TLS certificate of a network ingress
+//
+// swagger:model
+//
+// API extension: network_ingress.
+type NetworkIngressStateCertificate struct {
+	// Source of the certificate (none, manual or acme)
+	// Example: acme
+	Source string `json:"source" yaml:"source"`
+
+	// Status of the certificate (none, pending, valid or failed)
+	// Example: valid
+	Status string `json:"status" yaml:"status"`
+
+	// Expiry date of the certificate
+	// Example: 2025-01-14T10:34:03Z
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+
+	// Last error encountered while obtaining the certificate
+	// Example: Failed to obtain certificate: rate limited
+	Error string `json:"error" yaml:"error"`
+}
+
+// NetworkIngressStateTarget represents the state of a target of a network ingress
+//
+// swagger:model
+//
+// API extension: network_ingress.
+type NetworkIngressStateTarget struct {
+	// Address of the target
+	// Example: 198.51.100.2:8080
+	Address string `json:"address" yaml:"address"`
+
+	// Health of the target (unknown, online or offline)
+	// Example: online
+	Status string `json:"status" yaml:"status"`
+
+	// Date of the last health check
+	// Example: 2024-10-15T10:34:03Z
+	LastCheck time.Time `json:"last_check" yaml:"last_check"`
+
+	// Error reported by the last failed health check
+	// Example: connection refused
+	Error string `json:"error" yaml:"error"`
+
+	// Number of requests sent to the target
+	// Example: 512
+	Requests int64 `json:"requests" yaml:"requests"`
+}
@@ -61,6 +61,12 @@ type ResourcesCPU struct {
 	// Total number of CPU threads (from all sockets and cores)
 	// Example: 1
 	Total uint64 `json:"total" yaml:"total"`
+
+	// Confidential computing technologies enabled in the host kernel (sev, sev-es, sev-snp or tdx)
+	// Example: ["sev", "sev-es", "sev-snp"]
+	//
+	// API extension: instance_confidential_computing
+	ConfidentialComputing []string `json:"confidential_computing,omitempty" yaml:"confidential_computing,omitempty"`
 }
 
 // ResourcesCPUSocket represents a CPU socket on the system
@@ -382,6 +388,38 @@ type ResourcesNetwork struct {
 	// Total number of network cards
 	// Example: 1
 	Total uint64 `json:"total" yaml:"total"`
+
+	// List of vDPA devices
+	//
+	// API extension: nic_vdpa
+	VDPA []ResourcesNetworkVDPA `json:"vdpa,omitempty" yaml:"vdpa,omitempty"`
+}
+
+// ResourcesNetworkVDPA represents a vDPA device on the system
+//
+// swagger:model
+//
+// API extension: nic_vdpa.
+type ResourcesNetworkVDPA struct {
+	// Name of the vDPA device
+	// Example: vdpa0
+	Name string `json:"name" yaml:"name"`
+
+	// Management device the vDPA device was created on
+	// Example: pci/0000:0d:00.2
+	ManagementDevice string `json:"management_device" yaml:"management_device"`
+
+	// Driver the vDPA device is bound to
+	// Example: vhost_vdpa
+	Driver string `json:"driver,omitempty" yaml:"driver,omitempty"`
+
+	// vhost-vdpa character device of the vDPA device
+	// Example: vhost-vdpa-0
+	VhostDevice string `json:"vhost_device,omitempty" yaml:"vhost_device,omitempty"`
+
+	// Instance the vDPA device is allocated to
+	// Example: /1.0/instances/c1?project=default
+	UsedBy string `json:"used_by,omitempty" yaml:"used_by,omitempty"`
 }
 
 // ResourcesNetworkCard represents a network card on the system
@@ -798,6 +836,12 @@ type ResourcesStoragePoolSpace struct {
 	// Total disk space (bytes)
 	// Example: 420100937728
 	Total uint64 `json:"total" yaml:"total"`
+
+	// Sum of the sizes of the volumes in the pool (bytes)
+	// Example: 858993459200
+	//
+	// API extension: storage_pool_overcommit
+	Provisioned uint64 `json:"provisioned,omitempty" yaml:"provisioned,omitempty"`
 }
 
 // ResourcesStoragePoolInodes represents the inodes available to a given storage pool
@@ -90,6 +90,9 @@ const (
 
 	// TypeIdentityProviderGroup represents identity provider group resources.
 	TypeIdentityProviderGroup Type = "identity_provider_group"
+
+	// TypeInstanceTemplate represents instance template resources.
+	TypeInstanceTemplate Type = "instance_template"
 )
 
 const (
@@ -123,6 +126,7 @@ var entityTypes = []Type{
 	TypeIdentity,
 	TypeAuthGroup,
 	TypeIdentityProviderGroup,
+	TypeInstanceTemplate,
 }
 
 // String implements fmt.Stringer for Type.
@@ -283,6 +287,8 @@ func (t Type) path() ([]string, error) {
 		return []string{"auth", "groups", pathPlaceholder}, nil
 	case TypeIdentityProviderGroup:
 		return []string{"auth", "identity-provider-groups", pathPlaceholder}, nil
+	case TypeInstanceTemplate:
+		return []string{"instance-templates", pathPlaceholder}, nil
 	default:
 		return nil, fmt.Errorf("Missing path definition for entity type %q", t)
 	}
@@ -430,6 +436,11 @@ func NetworkZoneURL(projectName string, networkZoneName string) *api.URL {
 	return TypeNetworkZone.urlMust(projectName, "", networkZoneName)
 }
 
+// InstanceTemplateURL returns an *api.URL to an instance template.
+func InstanceTemplateURL(projectName string, instanceTemplateName string) *api.URL {
+	return TypeInstanceTemplate.urlMust(projectName, "", instanceTemplateName)
+}
+
 // StoragePoolURL returns an *api.URL to a storage pool.
 func StoragePoolURL(storagePoolName string) *api.URL {
 	return TypeStoragePool.urlMust("", "", storagePoolName)
@@ -407,6 +407,31 @@ var APIExtensions = []string{
 	"container_syscall_intercept_finit_module",
 	"device_usb_serial",
 	"network_allocate_external_ips",
+	"storage_pool_overcommit",
+	"storage_bucket_lifecycle",
+	"storage_bucket_backup",
+	"storage_dir_reflink",
+	"migration_block_diff",
+	"instance_serial_device",
+	"instance_watchdog_device",
+	"instance_shm_device",
+	"proxy_tls_http",
+	"network_ingress",
+	"disk_io_limits_live",
+	"disk_share_protocol",
+	"nic_vdpa",
+	"cluster_device_inventory",
+	"instance_state_disk_counters",
+	"instance_restart_policy_healthchecks",
+	"instance_boot_dependencies",
+	"instance_scheduled_actions",
+	"instance_templates",
+	"instance_stateful_backups",
+	"instance_fork",
+	"instance_vm_cpu_topology",
+	"instance_confidential_computing",
+	"instance_state_history",
+	"instance_session_recording",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
@@ -278,6 +278,7 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_container_devices_nic_ipvlan "container devices - nic - ipvlan"
     run_test test_container_devices_nic_sriov "container devices - nic - sriov"
     run_test test_container_devices_nic_routed "container devices - nic - routed"
+    run_test test_vm_devices_nic_vdpa "vm devices - nic - vdpa"
     run_test test_container_devices_infiniband_physical "container devices - infiniband - physical"
     run_test test_container_devices_infiniband_sriov "container devices - infiniband - sriov"
     run_test test_container_devices_proxy "container devices - proxy"
@@ -285,6 +286,8 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_container_devices_unix_char "container devices - unix-char"
     run_test test_container_devices_unix_block "container devices - unix-block"
     run_test test_container_devices_tpm "container devices - tpm"
+    run_test test_container_devices_serial "container devices - serial"
+    run_test test_container_devices_shm "container devices - shm"
     run_test test_container_move "container server-side move"
     run_test test_container_syscall_interception "container syscall interception"
     run_test test_security "security features"
@@ -317,6 +320,8 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_network "network management"
     run_test test_network_acl "network ACL management"
     run_test test_network_forward "network address forwards"
+    run_test test_network_ingress "network ingresses"
+    run_test test_network_ingress_acme "network ingress ACME certificates"
     run_test test_network_zone "network DNS zones"
     run_test test_idmap "id mapping"
     run_test test_template "file templating"
@@ -343,6 +348,7 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_storage_buckets "storage buckets"
     run_test test_storage_volume_import "storage volume import"
     run_test test_storage_volume_initial_config "storage volume initial configuration"
+    run_test test_storage_pool_overcommit "storage pool overcommit"
     run_test test_resources "resources"
     run_test test_kernel_limits "kernel limits"
     run_test test_console "console"
@@ -204,6 +204,27 @@ fine_grained_authorization() {
   user_is_not_project_manager
   user_is_not_project_operator
 
+  echo "==> Checking setting commands run inside an instance requires 'can_exec'..."
+  lxc init testimage cmd-foo
+  lxc auth group permission add test-group instance cmd-foo can_edit project=default
+  lxc_remote config set oidc:cmd-foo user.foo=bar
+  ! lxc_remote config set oidc:cmd-foo healthcheck.command=true || false
+  lxc auth group permission add test-group instance cmd-foo can_exec project=default
+  lxc_remote config set oidc:cmd-foo healthcheck.command=true
+  lxc auth group permission remove test-group instance cmd-foo can_exec project=default
+  lxc_remote config set oidc:cmd-foo healthcheck.command=true
+  ! lxc_remote config set oidc:cmd-foo healthcheck.command=false || false
+  lxc_remote config set oidc:cmd-foo schedule.test.action=restart schedule.test.cron="0 0 1 1 *"
+  ! lxc_remote config set oidc:cmd-foo schedule.test.action=exec schedule.test.command=true || false
+  lxc delete cmd-foo
+
+  echo "==> Checking setting commands in instance templates requires 'can_operate_instances'..."
+  lxc auth group permission add test-group project default can_create_instance_templates
+  ! lxc_remote query -X POST oidc:/1.0/instance-templates -d '{"name": "cmd-tpl", "instance": {"config": {"healthcheck.command": "true"}}}' || false
+  lxc_remote query -X POST oidc:/1.0/instance-templates -d '{"name": "cmd-tpl", "instance": {"config": {"user.foo": "bar"}}}'
+  lxc query -X DELETE /1.0/instance-templates/cmd-tpl
+  lxc auth group permission remove test-group project default can_create_instance_templates
+
   lxc auth group permission remove test-group project default can_view_events
 
   echo "==> Checking 'can_view_warnings' entitlement..."
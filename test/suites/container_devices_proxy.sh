@@ -8,6 +8,7 @@ test_container_devices_proxy() {
   container_devices_proxy_unix_udp
   container_devices_proxy_unix_tcp
   container_devices_proxy_with_overlapping_forward_net
+  container_devices_proxy_tls_http
 }
 
 container_devices_proxy_validation() {
@@ -48,6 +49,14 @@ container_devices_proxy_validation() {
     false
   fi
 
+  # Check HTTP routes require HTTP mode and HTTP mode requires tcp addresses.
+  ! lxc config device add proxyTester proxyDev proxy "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321 http.routes=example.com=tcp:127.0.0.1:4322 || false
+  ! lxc config device add proxyTester proxyDev proxy "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=unix:/run/app.sock proxy_mode=http || false
+  ! lxc config device add proxyTester proxyDev proxy "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321 proxy_mode=http proxy_protocol=true || false
+
+  # Check TLS termination requires both a certificate and a key.
+  ! lxc config device add proxyTester proxyDev proxy "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321 tls.cert="$(cat "${LXD_CONF}/client.crt")" || false
+
   # Check that old invalid config doesn't prevent device being stopped and removed cleanly.
   lxc config device add proxyTester proxyDev proxy "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:4321 bind=host
   lxd sql global "UPDATE instances_devices_config SET value='tcp:localhost:4321' WHERE value='tcp:127.0.0.1:4321';"
@@ -743,4 +752,59 @@ container_devices_proxy_with_overlapping_forward_net() {
   # Final cleanup
   lxc delete -f proxyTester
   lxc network delete "${netName}"
-}
\ No newline at end of file
+}
+
+container_devices_proxy_tls_http() {
+  echo "====> Testing TLS termination and HTTP routing"
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  # Setup
+  HOST_TCP_PORT=$(local_tcp_port)
+  openssl req -x509 -newkey ec -pkeyopt ec_paramgen_curve:secp384r1 -sha384 -nodes -days 1 -subj "/CN=lxd.test" \
+    -keyout "${TEST_DIR}/proxy.key" -out "${TEST_DIR}/proxy.crt"
+  lxc launch testimage proxyTester
+
+  # Start a web server for each application inside the container.
+  PID="$(lxc query /1.0/containers/proxyTester/state | jq .pid)"
+  for app in 0 1 2; do
+    cat > "${TEST_DIR}/proxy-app${app}.sh" << EOF
+# Read the request headers before replying.
+while read -r line && [ "\${line}" != "\$(printf '\\r')" ]; do :; done
+printf 'HTTP/1.1 200 OK\\r\\nContent-Length: 4\\r\\nConnection: close\\r\\n\\r\\napp${app}'
+EOF
+    nsenter -n -U -t "${PID}" -- socat "tcp-listen:800${app},fork,reuseaddr" system:"sh ${TEST_DIR}/proxy-app${app}.sh" &
+  done
+
+  lxc config device add proxyTester proxyDev proxy "listen=tcp:127.0.0.1:$HOST_TCP_PORT" connect=tcp:127.0.0.1:8000 bind=host \
+    proxy_mode=http http.routes=app1.lxd.test=tcp:127.0.0.1:8001,/api=tcp:127.0.0.1:8002 \
+    tls.cert="$(cat "${TEST_DIR}/proxy.crt")" tls.key="$(cat "${TEST_DIR}/proxy.key")"
+  sleep 0.5
+
+  # Check the requests are routed by host and path.
+  CURL_OPTS="--silent --insecure --resolve app1.lxd.test:${HOST_TCP_PORT}:127.0.0.1 --resolve other.lxd.test:${HOST_TCP_PORT}:127.0.0.1"
+  # shellcheck disable=SC2086
+  [ "$(curl ${CURL_OPTS} "https://app1.lxd.test:${HOST_TCP_PORT}/")" = "app1" ]
+  # shellcheck disable=SC2086
+  [ "$(curl ${CURL_OPTS} "https://other.lxd.test:${HOST_TCP_PORT}/api/v1")" = "app2" ]
+  # shellcheck disable=SC2086
+  [ "$(curl ${CURL_OPTS} "https://other.lxd.test:${HOST_TCP_PORT}/")" = "app0" ]
+
+  # Check plain HTTP isn't accepted once TLS is terminated.
+  ! curl --silent --fail "http://127.0.0.1:${HOST_TCP_PORT}/" || false
+
+  # Check the private key isn't returned by the API but is kept when the config is sent back.
+  ! lxc config show proxyTester | grep -q "PRIVATE KEY" || false
+  ! lxc query /1.0/instances/proxyTester?recursion=1 | grep -q "PRIVATE KEY" || false
+  lxc config show proxyTester | sed 's/connect: tcp:127.0.0.1:8000/connect: tcp:127.0.0.1:8001/' | lxc config edit proxyTester
+  sleep 0.5
+  # shellcheck disable=SC2086
+  [ "$(curl ${CURL_OPTS} "https://other.lxd.test:${HOST_TCP_PORT}/")" = "app1" ]
+
+  # Cleanup
+  lxc delete -f proxyTester
+  # shellcheck disable=SC2046
+  kill $(jobs -p) 2>/dev/null || true
+  wait 2>/dev/null || true
+  rm -f "${TEST_DIR}/proxy.key" "${TEST_DIR}/proxy.crt" "${TEST_DIR}"/proxy-app*.sh
+}
@@ -0,0 +1,70 @@
+test_container_devices_serial() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+  ctName="ct$$"
+  lxc launch testimage "${ctName}"
+
+  # Check adding a device with no path or an invalid listen address
+  ! lxc config device add "${ctName}" test-dev-invalid serial || false
+  ! lxc config device add "${ctName}" test-dev-invalid serial path=/dev/ttyS1 listen=udp:127.0.0.1:4000 || false
+  ! lxc config device add "${ctName}" test-dev-invalid serial path=/dev/ttyS1 bus=isa || false
+  ! lxc config device add "${ctName}" test-dev-invalid serial path=/dev/ttyS1 listen=unix:/run/lxd.sock || false
+  ! lxc config device add "${ctName}" test-dev-invalid serial path=/dev/ttyS1 listen=unix:.. || false
+
+  # Add device
+  lxc config device add "${ctName}" test-dev1 serial path=/dev/ttyS1
+  lxc exec "${ctName}" -- stat /dev/ttyS1
+  [ -S "${LXD_DIR}/devices/${ctName}/serial.test-dev1.sock" ]
+
+  # Check the output of the serial port is logged
+  lxc exec "${ctName}" -- sh -c "echo serial-test > /dev/ttyS1"
+  sleep 1
+  grep -q serial-test "${LXD_DIR}/logs/${ctName}/serial.test-dev1.log"
+
+  # Check the device can be opened for reading and gets the input from the listener
+  lxc exec "${ctName}" -- timeout 10 head -n1 /dev/ttyS1 > "${TEST_DIR}/serial.out" &
+  readPID=$!
+  sleep 1
+  (echo serial-input; sleep 1) | socat - "UNIX-CONNECT:${LXD_DIR}/devices/${ctName}/serial.test-dev1.sock"
+  wait "${readPID}"
+  grep -q serial-input "${TEST_DIR}/serial.out"
+  rm "${TEST_DIR}/serial.out"
+
+  # Check the device is set up with its ownership and mode when the container starts
+  lxc config device add "${ctName}" test-dev2 serial path=/dev/ttyS2 uid=1000 gid=1000 mode=0600
+  lxc restart "${ctName}" --force
+  [ "$(lxc exec "${ctName}" -- stat -c '%u:%g:%a' /dev/ttyS2)" = "1000:1000:600" ]
+  lxc exec "${ctName}" -- sh -c "echo serial-test2 > /dev/ttyS2"
+  sleep 1
+  grep -q serial-test2 "${LXD_DIR}/logs/${ctName}/serial.test-dev2.log"
+  lxc config device rm "${ctName}" test-dev2
+
+  # Check a custom socket name can't be shared between devices
+  lxc config device add "${ctName}" test-dev3 serial path=/dev/ttyS3 listen=unix:custom
+  [ -S "${LXD_DIR}/devices/${ctName}/serial.custom.sock" ]
+  ! lxc config device add "${ctName}" test-dev4 serial path=/dev/ttyS4 listen=unix:custom || false
+  ! lxc config device add "${ctName}" test-dev4 serial path=/dev/ttyS4 listen=unix:test-dev1 || false
+  lxc config device rm "${ctName}" test-dev3
+  [ ! -e "${LXD_DIR}/devices/${ctName}/serial.custom.sock" ]
+
+  # Check TCP listeners are only allowed in restricted projects when explicitly allowed
+  lxc project create serial-restricted -c features.images=false -c restricted=true
+  lxc profile device add default root disk path="/" pool="lxdtest-$(basename "${LXD_DIR}")" --project serial-restricted
+  lxc init testimage c1 --project serial-restricted
+  lxc config device add c1 ser1 serial path=/dev/ttyS1 --project serial-restricted
+  ! lxc config device add c1 ser2 serial path=/dev/ttyS2 listen=tcp:127.0.0.1:4000 --project serial-restricted || false
+  lxc project set serial-restricted restricted.devices.serial=allow
+  lxc config device add c1 ser2 serial path=/dev/ttyS2 listen=tcp:127.0.0.1:4000 --project serial-restricted
+  lxc config device remove c1 ser2 --project serial-restricted
+  ! lxc project set serial-restricted restricted.devices.serial=block || false
+  lxc delete c1 --project serial-restricted
+  lxc project delete serial-restricted
+
+  # Remove device
+  lxc config device rm "${ctName}" test-dev1
+  ! lxc exec "${ctName}" -- stat /dev/ttyS1 || false
+  [ ! -e "${LXD_DIR}/devices/${ctName}/serial.test-dev1.sock" ]
+
+  # Clean up
+  lxc rm -f "${ctName}"
+}
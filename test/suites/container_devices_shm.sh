@@ -0,0 +1,35 @@
+test_container_devices_shm() {
+  ensure_import_testimage
+  ensure_has_localhost_remote "${LXD_ADDR}"
+  ctName1="ct1-$$"
+  ctName2="ct2-$$"
+  lxc launch testimage "${ctName1}"
+  lxc launch testimage "${ctName2}"
+
+  # Check adding a device with no path, an invalid size or a path in /dev/shm
+  ! lxc config device add "${ctName1}" test-dev-invalid shm name=shm-test size=16MiB || false
+  ! lxc config device add "${ctName1}" test-dev-invalid shm name=shm-test size=10MiB path=/dev/lxd-shm || false
+  ! lxc config device add "${ctName1}" test-dev-invalid shm name=shm-test size=16MiB path=/dev/shm/test || false
+
+  # Add the same shared memory object to both containers
+  lxc config device add "${ctName1}" test-dev1 shm name=shm-test size=16MiB path=/dev/lxd-shm
+  lxc config device add "${ctName2}" test-dev1 shm name=shm-test size=16MiB path=/dev/lxd-shm
+  [ "$(stat -c %s /dev/shm/lxd-shm/shm-test/memory)" = "16777216" ]
+
+  # Check a different size is rejected for an existing object
+  ! lxc config device add "${ctName2}" test-dev2 shm name=shm-test size=32MiB path=/dev/lxd-shm2 || false
+
+  # Check the memory is shared
+  echo shm-test | lxc exec "${ctName1}" -- dd of=/dev/lxd-shm conv=notrunc
+  lxc exec "${ctName2}" -- head -c 8 /dev/lxd-shm | grep -q shm-test
+
+  # Check the object is only removed once the last user is gone
+  lxc config device rm "${ctName1}" test-dev1
+  ! lxc exec "${ctName1}" -- stat /dev/lxd-shm || false
+  [ -e /dev/shm/lxd-shm/shm-test/memory ]
+  lxc stop -f "${ctName2}"
+  [ ! -e /dev/shm/lxd-shm/shm-test ]
+
+  # Clean up
+  lxc rm -f "${ctName1}" "${ctName2}"
+}
@@ -0,0 +1,163 @@
+test_network_ingress() {
+  ensure_has_localhost_remote "${LXD_ADDR}"
+
+  netName=lxdt$$
+
+  lxc network create "${netName}" \
+        ipv4.address=192.0.2.1/24 \
+        ipv6.address=fd42:4242:4242:1010::1/64
+
+  # Check invalid host names and listen addresses are rejected.
+  ! lxc network ingress create "${netName}" 192.0.2.1 192.0.2.1 || false
+  ! lxc network ingress create "${netName}" "www_1.example.net" 192.0.2.1 || false
+  ! lxc network ingress create "${netName}" www.example.net 0.0.0.0 || false
+
+  # Check ACME requires the server options.
+  ! lxc network ingress create "${netName}" www.example.net 192.0.2.1 tls.acme=true || false
+
+  # Check a certificate without key is rejected.
+  ! lxc network ingress create "${netName}" www.example.net 192.0.2.1 tls.certificate="$(cat "${LXD_DIR}/server.crt")" || false
+
+  lxc network ingress create "${netName}" www.example.net 192.0.2.1 user.foo=bar
+  lxc network ingress list "${netName}" | grep -F "www.example.net"
+  lxc network ingress show "${netName}" www.example.net | grep -F "listen_address: 192.0.2.1"
+  [ "$(lxc network ingress get "${netName}" www.example.net user.foo)" = "bar" ]
+
+  # Check the private key is kept but never returned.
+  lxc network ingress set "${netName}" www.example.net tls.certificate="$(cat "${LXD_DIR}/server.crt")" tls.key="$(cat "${LXD_DIR}/server.key")"
+  ! lxc network ingress show "${netName}" www.example.net | grep -F "tls.key" || false
+  ! lxc query "/1.0/networks/${netName}/ingresses?recursion=1" | grep -F "tls.key" || false
+  lxc network ingress set "${netName}" www.example.net user.foo=baz
+  lxc network ingress show "${netName}" www.example.net | grep -F "tls.certificate"
+  lxc network ingress unset "${netName}" www.example.net tls.certificate
+  ! lxc network ingress set "${netName}" www.example.net tls.certificate="$(cat "${LXD_DIR}/server.crt")" || false
+  lxc network ingress set "${netName}" www.example.net user.foo=bar
+
+  # Check the host name is unique.
+  ! lxc network ingress create "${netName}" www.example.net 192.0.2.1 || false
+
+  # Check the targets must be within the network subnets.
+  ! lxc network ingress route add "${netName}" www.example.net / 198.51.100.2 || false
+
+  # Check routes are rejected on a network without subnets.
+  lxc network create "${netName}n" ipv4.address=none ipv6.address=none
+  lxc network ingress create "${netName}n" www.example.org 192.0.2.1
+  ! lxc network ingress route add "${netName}n" www.example.org / 192.0.2.2 || false
+  lxc network delete "${netName}n"
+
+  # Serve a backend on the network address.
+  mkdir -p "${TEST_DIR}/ingress/app"
+  echo "ingress backend" > "${TEST_DIR}/ingress/app/index.html"
+  (cd "${TEST_DIR}/ingress" && exec python3 -m http.server 8080 --bind 192.0.2.1) > /dev/null 2>&1 &
+  backendPID=$!
+  sleep 1
+
+  lxc network ingress route add "${netName}" www.example.net /app 192.0.2.1 8080
+  lxc network ingress show "${netName}" www.example.net | grep -F "target_port: \"8080\""
+
+  # Check the requests are routed on host name and path.
+  curl -s --noproxy "*" -H "Host: www.example.net" "http://192.0.2.1/app/index.html" | grep -F "ingress backend"
+  [ "$(curl -s --noproxy "*" -o /dev/null -w "%{http_code}" -H "Host: www.example.net" "http://192.0.2.1/other")" = "404" ]
+  [ "$(curl -s --noproxy "*" -o /dev/null -w "%{http_code}" -H "Host: unknown.example.net" "http://192.0.2.1/app/index.html")" = "404" ]
+
+  # Check the state and metrics report the requests.
+  lxc network ingress info "${netName}" www.example.net | grep -F "Requests: 2"
+  lxc network ingress info "${netName}" www.example.net | grep -F "192.0.2.1:8080"
+  lxc query /1.0/metrics | grep -F "lxd_network_ingress_requests_total{hostname=\"www.example.net\",network=\"${netName}\",project=\"default\"} 2"
+
+  # Check the target is reported offline once the backend stops.
+  lxc network ingress set "${netName}" www.example.net healthcheck.interval=1
+  kill -9 "${backendPID}"
+  sleep 3
+  lxc network ingress info "${netName}" www.example.net | grep -F "Status: offline"
+  [ "$(curl -s --noproxy "*" -o /dev/null -w "%{http_code}" -H "Host: www.example.net" "http://192.0.2.1/app/index.html")" = "503" ]
+
+  # Check routes can be removed.
+  lxc network ingress route remove "${netName}" www.example.net /app
+  ! lxc network ingress show "${netName}" www.example.net | grep -F "target_address" || false
+
+  lxc network ingress delete "${netName}" www.example.net
+  ! lxc network ingress show "${netName}" www.example.net || false
+  ! curl -s --noproxy "*" -H "Host: www.example.net" "http://192.0.2.1/" || false
+
+  # Check deleting the network removes its ingresses.
+  lxc network ingress create "${netName}" www.example.net 192.0.2.1
+  lxc network delete "${netName}"
+  ! curl -s --noproxy "*" -H "Host: www.example.net" "http://192.0.2.1/" || false
+
+  rm -rf "${TEST_DIR}/ingress"
+}
+
+test_network_ingress_acme() {
+  if ! command -v "pebble" >/dev/null 2>&1; then
+    echo "==> SKIP: Skip network ingress ACME test due to missing pebble"
+    return
+  fi
+
+  # Run pebble as the ACME CA, skipping the challenge validation as the host name doesn't resolve.
+  mkdir -p "${TEST_DIR}/pebble"
+  openssl req -x509 -newkey ec -pkeyopt ec_paramgen_curve:prime256v1 -nodes -days 1 -subj "/CN=127.0.0.1" \
+    -addext "subjectAltName=IP:127.0.0.1" -keyout "${TEST_DIR}/pebble/server.key" -out "${TEST_DIR}/pebble/server.crt"
+  cat > "${TEST_DIR}/pebble/config.json" << EOF2
+{
+  "pebble": {
+    "listenAddress": "127.0.0.1:14000",
+    "managementListenAddress": "127.0.0.1:15000",
+    "certificate": "${TEST_DIR}/pebble/server.crt",
+    "privateKey": "${TEST_DIR}/pebble/server.key",
+    "httpPort": 80,
+    "tlsPort": 443
+  }
+}
+EOF2
+
+  PEBBLE_VA_ALWAYS_VALID=1 PEBBLE_WFE_NONCEREJECT=0 pebble -config "${TEST_DIR}/pebble/config.json" > "${TEST_DIR}/pebble/pebble.log" 2>&1 &
+  pebblePID=$!
+  sleep 1
+
+  # Use a dedicated daemon trusting the pebble certificate.
+  LXD_ACME_DIR=$(mktemp -d -p "${TEST_DIR}" XXX)
+  chmod +x "${LXD_ACME_DIR}"
+  LEGO_CA_CERTIFICATES="${TEST_DIR}/pebble/server.crt" spawn_lxd "${LXD_ACME_DIR}" true
+
+  (
+    set -e
+    # shellcheck disable=SC2030
+    LXD_DIR=${LXD_ACME_DIR}
+
+    netName=lxdt$$
+
+    lxc network create "${netName}" ipv4.address=192.0.2.1/24 ipv6.address=none
+    lxc config set acme.ca_url=https://127.0.0.1:14000/dir acme.email=admin@example.net acme.agree_tos=true
+
+    # Check a certificate is obtained and served.
+    lxc network ingress create "${netName}" www.example.net 192.0.2.1 tls.acme=true
+    for _ in $(seq 30); do
+      lxc network ingress info "${netName}" www.example.net | grep -F "Status: valid" && break
+      sleep 1
+    done
+
+    lxc network ingress info "${netName}" www.example.net | grep -F "Source: acme"
+    lxc network ingress info "${netName}" www.example.net | grep -F "Status: valid"
+    openssl s_client -connect 192.0.2.1:443 -servername www.example.net < /dev/null 2>/dev/null | openssl x509 -noout -ext subjectAltName | grep -F "DNS:www.example.net"
+
+    # Check the private key of the obtained certificate isn't returned.
+    ! lxc query "/1.0/networks/${netName}/ingresses?recursion=1" | grep -F "PRIVATE KEY" || false
+
+    # Check the ingress keeps being served after renaming the network.
+    lxc network rename "${netName}" "${netName}-r"
+    lxc network ingress info "${netName}-r" www.example.net | grep -F "Status: valid"
+    lxc query /1.0/metrics | grep -F "lxd_network_ingress_requests_total{hostname=\"www.example.net\",network=\"${netName}-r\",project=\"default\"}"
+
+    lxc network delete "${netName}-r"
+    lxc config unset acme.ca_url
+    lxc config unset acme.email
+    lxc config unset acme.agree_tos
+  )
+
+  # shellcheck disable=SC2031,2269
+  LXD_DIR=${LXD_DIR}
+  kill_lxd "${LXD_ACME_DIR}"
+  kill -9 "${pebblePID}"
+  rm -rf "${TEST_DIR}/pebble"
+}
@@ -922,6 +922,10 @@ test_projects_restrictions() {
   ! lxc profile device add default tty unix-char path=/dev/ttyS0 || false
   ! lxc config device add c1 tty unix-char path=/dev/ttyS0 || false
 
+  # It's not possible to attach shared memory devices.
+  ! lxc profile device add default shm shm name=shm-proj size=16MiB path=/dev/lxd-shm || false
+  ! lxc config device add c1 shm shm name=shm-proj size=16MiB path=/dev/lxd-shm || false
+
   # It's not possible to attach raw network devices.
   ! lxc profile device add default eth0 nic nictype=p2p || false
 
@@ -206,6 +206,11 @@ test_storage() {
     lxc storage create "lxdtest-$(basename "${LXD_DIR}")-valid-dir-pool-config" dir rsync.bwlimit=1024
     lxc storage delete "lxdtest-$(basename "${LXD_DIR}")-valid-dir-pool-config"
 
+    ! lxc storage create "lxdtest-$(basename "${LXD_DIR}")-invalid-dir-pool-config" dir dir.dedup.schedule=invalid || false
+    lxc storage create "lxdtest-$(basename "${LXD_DIR}")-valid-dir-pool-config" dir dir.dedup.schedule=@daily
+    lxc storage set "lxdtest-$(basename "${LXD_DIR}")-valid-dir-pool-config" dir.dedup.schedule "0 3 * * *"
+    lxc storage delete "lxdtest-$(basename "${LXD_DIR}")-valid-dir-pool-config"
+
     if [ "$lxd_backend" = "lvm" ]; then
       # Create lvm pool.
       configure_loop_device loop_file_3 loop_device_3
@@ -143,6 +143,47 @@ test_storage_buckets() {
   s3cmdrun "${lxd_backend}" "${adAccessKey}" "${adSecretKey}" delpolicy "s3://${bucketPrefix}.foo"
   curl -sI --insecure o /dev/null -w "%{http_code}" "${bucketURL}/${lxdTestFile}" | grep -Fx "403"
 
+  # Test setting bucket policy through the bucket config.
+  ! lxc storage bucket set "${poolName}" "${bucketPrefix}.foo" policy="invalid" || false
+  lxc storage bucket set "${poolName}" "${bucketPrefix}.foo" policy="$(cat deps/s3_global_read_policy.json)"
+  curl -sI --insecure -o /dev/null -w "%{http_code}" "${bucketURL}/${lxdTestFile}" | grep -Fx "200"
+  lxc storage bucket unset "${poolName}" "${bucketPrefix}.foo" policy
+  curl -sI --insecure -o /dev/null -w "%{http_code}" "${bucketURL}/${lxdTestFile}" | grep -Fx "403"
+
+  # Test bucket versioning and lifecycle config.
+  ! lxc storage bucket create "${poolName}" "${bucketPrefix}.foo3" lifecycle.expiry=-1 || false
+  initCreds=$(lxc storage bucket create "${poolName}" "${bucketPrefix}.foo3" versioning=true lifecycle.expiry=30)
+  initAccessKey=$(echo "${initCreds}" | awk '{ if ($2 == "access" && $3 == "key:") {print $4}}')
+  initSecretKey=$(echo "${initCreds}" | awk '{ if ($2 == "secret" && $3 == "key:") {print $4}}')
+  s3cmdrun "${lxd_backend}" "${initAccessKey}" "${initSecretKey}" getlifecycle "s3://${bucketPrefix}.foo3" | grep -F "<Days>30</Days>"
+  lxc storage bucket set "${poolName}" "${bucketPrefix}.foo3" lifecycle.noncurrent_expiry=7
+  s3cmdrun "${lxd_backend}" "${initAccessKey}" "${initSecretKey}" getlifecycle "s3://${bucketPrefix}.foo3" | grep -F "<NoncurrentDays>7</NoncurrentDays>"
+  lxc storage bucket unset "${poolName}" "${bucketPrefix}.foo3" lifecycle.expiry
+  lxc storage bucket unset "${poolName}" "${bucketPrefix}.foo3" lifecycle.noncurrent_expiry
+  ! s3cmdrun "${lxd_backend}" "${initAccessKey}" "${initSecretKey}" getlifecycle "s3://${bucketPrefix}.foo3" || false
+  lxc storage bucket set "${poolName}" "${bucketPrefix}.foo3" versioning=false
+  lxc storage bucket delete "${poolName}" "${bucketPrefix}.foo3"
+
+  # Test exporting and importing a bucket (the original bucket is deleted first as its keys are restored).
+  lxc storage bucket create "${poolName}" "${bucketPrefix}.foo4" user.foo=comment
+  lxc storage bucket key create "${poolName}" "${bucketPrefix}.foo4" backup-key --role=admin --access-key="${bucketPrefix}.foo4.admin" --secret-key="password"
+  s3cmdrun "${lxd_backend}" "${bucketPrefix}.foo4.admin" "password" put "${lxdTestFile}" "s3://${bucketPrefix}.foo4/dir/${lxdTestFile}"
+  lxc storage bucket export "${poolName}" "${bucketPrefix}.foo4" "${LXD_DIR}/bucket.tar.gz"
+  [ "$(lxc query "/1.0/storage-pools/${poolName}/buckets/${bucketPrefix}.foo4/backups")" = "[]" ]
+  tar -tzf "${LXD_DIR}/bucket.tar.gz" | grep -xF "backup/index.yaml"
+  tar -tzf "${LXD_DIR}/bucket.tar.gz" | grep -xF "backup/bucket/dir/${lxdTestFile}"
+  lxc storage bucket delete "${poolName}" "${bucketPrefix}.foo4"
+  lxc storage bucket import "${poolName}" "${LXD_DIR}/bucket.tar.gz"
+  [ "$(lxc storage bucket get "${poolName}" "${bucketPrefix}.foo4" user.foo)" = "comment" ]
+  lxc storage bucket key show "${poolName}" "${bucketPrefix}.foo4" backup-key | grep -F "${bucketPrefix}.foo4.admin"
+  s3cmdrun "${lxd_backend}" "${bucketPrefix}.foo4.admin" "password" get "s3://${bucketPrefix}.foo4/dir/${lxdTestFile}" "${lxdTestFile}.get"
+  cmp "${lxdTestFile}" "${lxdTestFile}.get"
+  rm "${lxdTestFile}.get"
+  ! lxc storage bucket import "${poolName}" "${LXD_DIR}/bucket.tar.gz" || false
+  s3cmdrun "${lxd_backend}" "${bucketPrefix}.foo4.admin" "password" del "s3://${bucketPrefix}.foo4/dir/${lxdTestFile}"
+  lxc storage bucket delete "${poolName}" "${bucketPrefix}.foo4"
+  rm "${LXD_DIR}/bucket.tar.gz"
+
   # Test deleting a file from a bucket.
   ! s3cmdrun "${lxd_backend}" "${roAccessKey}" "${roSecretKey}" del "s3://${bucketPrefix}.foo/${lxdTestFile}" || false
   s3cmdrun "${lxd_backend}" "${adAccessKey}" "${adSecretKey}" del "s3://${bucketPrefix}.foo/${lxdTestFile}"
@@ -0,0 +1,41 @@
+test_storage_pool_overcommit() {
+  # shellcheck disable=2039,3043
+  local lxd_backend pool
+
+  lxd_backend=$(storage_backend "$LXD_DIR")
+  if [ "${lxd_backend}" != "zfs" ] && [ "${lxd_backend}" != "lvm" ]; then
+    return
+  fi
+
+  pool="lxdtest-$(basename "${LXD_DIR}")-overcommit"
+  lxc storage create "${pool}" "${lxd_backend}" size=1GiB
+
+  # Check validation.
+  ! lxc storage set "${pool}" volume.overcommit_ratio=0 || false
+  ! lxc storage set "${pool}" volume.overcommit_ratio=foo || false
+  ! lxc storage set "${pool}" space.warning_levels=101 || false
+  lxc storage set "${pool}" space.warning_levels=80,90
+
+  # Check the provisioned space is reported.
+  lxc storage volume create "${pool}" vol1 size=256MiB
+  [ "$(lxc query "/1.0/storage-pools/${pool}/resources" | jq -r '.space.provisioned')" = "268435456" ]
+  lxc storage info "${pool}" | grep -q "space provisioned"
+
+  # Check the provisioned space is limited by the overcommit ratio.
+  lxc storage set "${pool}" volume.overcommit_ratio=1.5
+  [ "$(lxc storage volume get "${pool}" vol1 overcommit_ratio)" = "" ]
+  lxc storage volume create "${pool}" vol2 size=1GiB
+  ! lxc storage volume create "${pool}" vol3 size=1GiB || false
+  ! lxc storage volume set "${pool}" vol2 size=2GiB || false
+  lxc storage set "${pool}" volume.overcommit_ratio=4
+  lxc storage volume create "${pool}" vol3 size=1GiB
+  lxc storage volume set "${pool}" vol2 size=1536MiB
+
+  # Check the pool space metrics.
+  lxc query /1.0/metrics | grep -F "lxd_storage_pool_space_provisioned_bytes{driver=\"${lxd_backend}\",pool=\"${pool}\"}"
+
+  lxc storage volume delete "${pool}" vol1
+  lxc storage volume delete "${pool}" vol2
+  lxc storage volume delete "${pool}" vol3
+  lxc storage delete "${pool}"
+}
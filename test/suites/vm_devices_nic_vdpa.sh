@@ -0,0 +1,51 @@
+# Activating the vDPA simulator:
+# sudo modprobe vdpa_sim_net
+test_vm_devices_nic_vdpa() {
+  ensure_import_testimage
+
+  if [ ! -e /dev/kvm ]; then
+    echo "==> SKIP: No KVM support"
+    return
+  fi
+
+  if ! modprobe vdpa_sim_net; then
+    echo "==> SKIP: No vdpa_sim_net kernel module"
+    return
+  fi
+
+  ctName="nt$$"
+  vmName="v$$"
+  vmMAC="da:da:9d:42:e5:d$(shuf -i 0-9 -n 1)"
+
+  # Check vDPA NICs are rejected for containers and with an unknown parent.
+  lxc init testimage "${ctName}"
+  ! lxc config device add "${ctName}" eth0 nic nictype=vdpa parent=vdpasim_net || false
+  lxc delete -f "${ctName}"
+
+  lxc init --vm --empty "${vmName}" -c security.secureboot=false
+  ! lxc config device add "${vmName}" eth0 nic nictype=vdpa parent=missing || false
+
+  # Check the vDPA device is created on start with the requested MAC address.
+  lxc config device add "${vmName}" eth0 nic nictype=vdpa parent=vdpasim_net hwaddr="${vmMAC}"
+  startVDPACount=$(find /sys/bus/vdpa/devices -mindepth 1 -maxdepth 1 | wc -l)
+  lxc start "${vmName}"
+  vdpaName=$(lxc config get "${vmName}" volatile.eth0.last_state.vdpa.name)
+  [ -n "${vdpaName}" ]
+  [ -e "/sys/bus/vdpa/devices/${vdpaName}" ]
+  [ "$(find /sys/bus/vdpa/devices -mindepth 1 -maxdepth 1 | wc -l)" = "$((startVDPACount+1))" ]
+
+  # Check the vDPA device is removed and the volatile keys are cleared on stop.
+  lxc stop -f "${vmName}"
+  [ ! -e "/sys/bus/vdpa/devices/${vdpaName}" ]
+  [ "$(lxc config get "${vmName}" volatile.eth0.last_state.vdpa.name)" = "" ]
+  [ "$(find /sys/bus/vdpa/devices -mindepth 1 -maxdepth 1 | wc -l)" = "${startVDPACount}" ]
+
+  # Check the instance can be started again.
+  lxc start "${vmName}"
+  [ -n "$(lxc config get "${vmName}" volatile.eth0.last_state.vdpa.name)" ]
+  lxc stop -f "${vmName}"
+  [ "$(find /sys/bus/vdpa/devices -mindepth 1 -maxdepth 1 | wc -l)" = "${startVDPACount}" ]
+
+  # Clean up.
+  lxc delete -f "${vmName}"
+}